@@ -148,7 +148,8 @@ func (r *CacheClusterReconciler) reconcileResources(ctx context.Context, cr *cac
 	// If scaling down, gracefully remove nodes from Raft cluster first
 	desiredReplicas := cr.Spec.Replicas
 	existingSts := &appsv1.StatefulSet{}
-	if err := r.Get(ctx, client.ObjectKeyFromObject(sts), existingSts); err == nil && existingSts.Spec.Replicas != nil {
+	stsErr := r.Get(ctx, client.ObjectKeyFromObject(sts), existingSts)
+	if stsErr == nil && existingSts.Spec.Replicas != nil {
 		currentReplicas := *existingSts.Spec.Replicas
 		if desiredReplicas < currentReplicas {
 			r.gracefulScaleDown(ctx, cr, int(currentReplicas), int(desiredReplicas))
@@ -158,6 +159,9 @@ func (r *CacheClusterReconciler) reconcileResources(ctx context.Context, cr *cac
 	if err := r.createOrUpdate(ctx, cr, sts); err != nil {
 		return fmt.Errorf("statefulset: %w", err)
 	}
+	if stsErr == nil {
+		r.handoffLeadership(ctx, cr, existingSts)
+	}
 
 	// 5. ServiceMonitor (optional — only when monitoring is enabled and CRD exists)
 	if cr.Spec.Monitoring.ServiceMonitor.Enabled {
@@ -206,6 +210,36 @@ func (r *CacheClusterReconciler) gracefulScaleDown(ctx context.Context, cr *cach
 	}
 }
 
+// handoffLeadership moves Raft leadership off the leader pod while a rolling
+// update is pending for it, so that restarting the leader does not leave the
+// cluster leaderless for an election timeout. It is best effort: errors are
+// logged and the rolling update proceeds regardless.
+func (r *CacheClusterReconciler) handoffLeadership(ctx context.Context, cr *cachev1.CacheCluster, sts *appsv1.StatefulSet) {
+	logger := log.FromContext(ctx)
+	rev := sts.Status.UpdateRevision
+	if cr.Spec.Replicas < 2 || rev == "" || rev == sts.Status.CurrentRevision {
+		return
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(cr.Namespace), client.MatchingLabels(labels(cr))); err != nil {
+		logger.Info("unable to list pods for leadership handoff", "error", err)
+		return
+	}
+	leader := leaderPod(ctx, podList.Items, cr.Spec.TLS.Enabled)
+	if leader == nil {
+		return
+	}
+	target := handoffTarget(podList.Items, leader, rev)
+	if target == nil {
+		return
+	}
+	logger.Info("transferring Raft leadership ahead of rolling update", "from", leader.Name, "to", target.Name)
+	if err := transferLeadership(ctx, leader.Status.PodIP, target.Name, cr.Spec.TLS.Enabled); err != nil {
+		logger.Info("leadership transfer failed, rolling update proceeds", "from", leader.Name, "to", target.Name, "error", err)
+	}
+}
+
 func (r *CacheClusterReconciler) createOrUpdate(ctx context.Context, cr *cachev1.CacheCluster, obj client.Object) error {
 	logger := log.FromContext(ctx)
 	kind := obj.GetObjectKind().GroupVersionKind().Kind
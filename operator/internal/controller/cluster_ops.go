@@ -7,8 +7,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -25,16 +28,108 @@ func ignoreTLSClient() *http.Client {
 // leaderPodIP probes each running pod's /healthz endpoint and returns the IP of
 // the current Raft leader. Returns empty string if no leader is found.
 func leaderPodIP(ctx context.Context, k8sClient client.Client, ns string, pods []corev1.Pod, useTLS bool) string {
-	for _, pod := range pods {
+	if pod := leaderPod(ctx, pods, useTLS); pod != nil {
+		return pod.Status.PodIP
+	}
+	return ""
+}
+
+// leaderPod probes each running pod's /healthz endpoint and returns the
+// current Raft leader, or nil if no leader is found.
+func leaderPod(ctx context.Context, pods []corev1.Pod, useTLS bool) *corev1.Pod {
+	for i := range pods {
+		pod := &pods[i]
 		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
 			continue
 		}
 		health := probeHealth(ctx, pod.Status.PodIP, useTLS)
 		if health.Role == "leader" {
-			return pod.Status.PodIP
+			return pod
 		}
 	}
-	return ""
+	return nil
+}
+
+// handoffTarget picks the pod that should lead while a rolling update
+// restarts the leader: a ready pod already at updateRevision, otherwise the
+// ready pod with the lowest ordinal, which the StatefulSet restarts last.
+// It returns nil if the leader is already at updateRevision or no other
+// pod is ready.
+func handoffTarget(pods []corev1.Pod, leader *corev1.Pod, updateRevision string) *corev1.Pod {
+	if leader.Labels[appsv1.StatefulSetRevisionLabel] == updateRevision {
+		return nil
+	}
+	var updated, lowest *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Name == leader.Name || pod.DeletionTimestamp != nil || !podReadyCondition(pod) {
+			continue
+		}
+		if pod.Labels[appsv1.StatefulSetRevisionLabel] == updateRevision {
+			if updated == nil || podOrdinal(pod) < podOrdinal(updated) {
+				updated = pod
+			}
+			continue
+		}
+		if lowest == nil || podOrdinal(pod) < podOrdinal(lowest) {
+			lowest = pod
+		}
+	}
+	if updated != nil {
+		return updated
+	}
+	return lowest
+}
+
+// podOrdinal returns the StatefulSet ordinal at the end of a pod name.
+func podOrdinal(pod *corev1.Pod) int {
+	i := strings.LastIndex(pod.Name, "-")
+	n, err := strconv.Atoi(pod.Name[i+1:])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// transferLeadership calls POST /cluster/stepdown on the leader's HTTP port
+// with target, the node ID (pod name) that should take over, so the cluster
+// elects it at once instead of after an election timeout.
+func transferLeadership(ctx context.Context, leaderIP string, target string, useTLS bool) error {
+	body, err := json.Marshal(map[string]string{"target": target})
+	if err != nil {
+		return fmt.Errorf("marshal stepdown payload: %w", err)
+	}
+
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s:%d/cluster/stepdown", scheme, leaderIP, httpPort)
+
+	reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create stepdown request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	if useTLS {
+		client = ignoreTLSClient()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stepdown request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("stepdown returned HTTP %d", resp.StatusCode)
+	}
+	return nil
 }
 
 // removeNodeFromRaft calls POST /cluster/leave on the leader's HTTP port to
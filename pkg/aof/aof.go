@@ -0,0 +1,636 @@
+// Package aof implements an append-only command log for single mode.
+//
+// The log lives in its own directory as a base dump plus one or more
+// incremental files, tied together by a manifest:
+//
+//	manifest.json      {"gen": 3, "base": "base.3.dump", "incrs": ["incr.3.aof"]}
+//	base.3.dump        cache dump (binary) taken at the last rewrite
+//	incr.3.aof         commands applied since that rewrite
+//
+// Replay loads the base and applies the incremental files in order. A
+// rewrite switches appends to a new incremental file, dumps the cache as the
+// new base and then commits the manifest, so a crash at any point leaves a
+// manifest that replays to the latest state.
+package aof
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/cache"
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/common"
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"go.uber.org/zap"
+)
+
+const manifestName = "manifest.json"
+
+// maxRecordSize rejects corrupt record lengths before allocating.
+const maxRecordSize = 1 << 30
+
+// Options configures an AOF.
+type Options struct {
+	Dir    string
+	NodeID string
+	Fsync  common.AOFFsync
+	// RewriteMinSize and RewritePercent control automatic rewrites: one is
+	// started once the current incremental file is at least RewriteMinSize
+	// bytes and has grown RewritePercent percent relative to the base.
+	// RewritePercent 0 disables automatic rewrites.
+	RewriteMinSize int64
+	RewritePercent int
+}
+
+type manifest struct {
+	Gen   uint64   `json:"gen"`
+	Base  string   `json:"base,omitempty"`
+	Incrs []string `json:"incrs"`
+}
+
+// cacheCommand is implemented by every command the log can hold.
+type cacheCommand interface {
+	Apply(c *cache.Cache) (interface{}, error)
+}
+
+// record is one logged command. At is when it was applied, so relative
+// TTLs can be shortened by the time that passed before a replay.
+type record struct {
+	At      int64           `json:"at"`
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// AOF appends applied write commands to disk.
+type AOF struct {
+	opts   Options
+	cache  *cache.Cache
+	logger *zap.Logger
+
+	mu       sync.Mutex // serializes apply+append so the log matches apply order
+	manifest manifest
+	f        *os.File
+	size     int64 // bytes in the current incremental file
+	baseSize int64 // size of the base at the last rewrite
+	dirty    bool  // written but not yet fsynced (everysec)
+
+	rewriteMu sync.Mutex
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Exists reports whether dir already holds an AOF.
+func Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, manifestName))
+	return err == nil
+}
+
+// Open opens the AOF in opts.Dir, replaying it into c. A new directory
+// takes whatever c already holds as its first base.
+func Open(opts Options, c *cache.Cache, logger *zap.Logger) (*AOF, error) {
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create aof directory: %w", err)
+	}
+	a := &AOF{opts: opts, cache: c, logger: logger, stop: make(chan struct{})}
+
+	fresh := !Exists(opts.Dir)
+	if fresh {
+		a.manifest = manifest{Gen: 1, Incrs: []string{incrName(1)}}
+		if err := a.writeManifest(a.manifest); err != nil {
+			return nil, err
+		}
+	} else {
+		b, err := os.ReadFile(filepath.Join(opts.Dir, manifestName))
+		if err != nil {
+			return nil, fmt.Errorf("read aof manifest: %w", err)
+		}
+		if err := json.Unmarshal(b, &a.manifest); err != nil {
+			return nil, fmt.Errorf("parse aof manifest: %w", err)
+		}
+		if len(a.manifest.Incrs) == 0 {
+			return nil, fmt.Errorf("aof manifest lists no incremental files")
+		}
+		if err := a.replay(); err != nil {
+			return nil, err
+		}
+	}
+
+	path := filepath.Join(opts.Dir, a.manifest.Incrs[len(a.manifest.Incrs)-1])
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open aof file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	a.f = f
+	a.size = info.Size()
+	if a.manifest.Base != "" {
+		if info, err := os.Stat(filepath.Join(opts.Dir, a.manifest.Base)); err == nil {
+			a.baseSize = info.Size()
+		}
+	}
+
+	if fresh && c.Stats().KeyCount > 0 {
+		if err := a.Rewrite(); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+
+	if opts.Fsync == common.AOFFsyncEverySec {
+		a.wg.Add(1)
+		go a.syncLoop()
+	}
+	return a, nil
+}
+
+// Logged reports whether cmd is recorded in the AOF: every cache write.
+// Published messages never touch the cache and are not logged.
+func Logged(cmd any) bool {
+	switch cmd.(type) {
+	case *command.SetCommand, *command.DelCommand, *command.ExpireKeyCommand, *command.ResetCommand,
+		*command.AcquireLockCommand, *command.RenewLockCommand, *command.ReleaseLockCommand,
+		*command.XAddCommand, *command.XTrimCommand, *command.XGroupCreateCommand, *command.XCommitCommand,
+		*command.BFReserveCommand, *command.BFAddCommand, *command.PFAddCommand, *command.PFMergeCommand,
+		*command.JSONSetCommand, *command.JSONDelCommand, *command.JSONNumIncrByCommand,
+		*command.ImportBeginCommand, *command.ImportBatchCommand, *command.ImportEndCommand:
+		return true
+	default:
+		return false
+	}
+}
+
+// Apply runs apply and, if it succeeds, appends cmd to the log. Apply and
+// append happen under one lock so records are in apply order.
+func (a *AOF) Apply(cmd any, apply func() (any, error)) (any, error) {
+	kind, payload, err := command.Encode(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("encode aof record: %w", err)
+	}
+
+	a.mu.Lock()
+	resp, err := apply()
+	if err != nil {
+		a.mu.Unlock()
+		return resp, err
+	}
+	logged, ok := loggedAs(cmd, resp)
+	if !ok {
+		a.mu.Unlock()
+		return resp, nil
+	}
+	if logged != cmd {
+		if kind, payload, err = command.Encode(logged); err != nil {
+			a.mu.Unlock()
+			return resp, fmt.Errorf("encode aof record: %w", err)
+		}
+	}
+	werr := a.appendLocked(record{At: time.Now().UnixNano(), Kind: kind, Payload: payload})
+	rewrite := a.needsRewriteLocked()
+	a.mu.Unlock()
+
+	if werr != nil {
+		a.logger.Error("aof append failed", zap.String("kind", kind), zap.Error(werr))
+		if a.opts.Fsync == common.AOFFsyncAlways {
+			return resp, fmt.Errorf("aof append: %w", werr)
+		}
+	}
+	if rewrite {
+		go a.autoRewrite()
+	}
+	return resp, nil
+}
+
+func (a *AOF) appendLocked(r record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 8, 8+len(b))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(b)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(b))
+	buf = append(buf, b...)
+	n, err := a.f.Write(buf)
+	a.size += int64(n)
+	if err != nil {
+		return err
+	}
+	switch a.opts.Fsync {
+	case common.AOFFsyncAlways:
+		return a.f.Sync()
+	case common.AOFFsyncEverySec:
+		a.dirty = true
+	}
+	return nil
+}
+
+func (a *AOF) needsRewriteLocked() bool {
+	if a.opts.RewritePercent <= 0 || a.size < a.opts.RewriteMinSize {
+		return false
+	}
+	return a.size*100 >= a.baseSize*int64(a.opts.RewritePercent)
+}
+
+func (a *AOF) autoRewrite() {
+	if !a.rewriteMu.TryLock() {
+		return
+	}
+	defer a.rewriteMu.Unlock()
+	select {
+	case <-a.stop:
+		return // closed while this rewrite was queued
+	default:
+	}
+	if err := a.rewriteLocked(); err != nil {
+		a.logger.Warn("automatic aof rewrite failed", zap.Error(err))
+	}
+}
+
+func (a *AOF) syncLoop() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+			a.mu.Lock()
+			if a.dirty {
+				if err := a.f.Sync(); err != nil {
+					a.logger.Warn("aof fsync failed", zap.Error(err))
+				} else {
+					a.dirty = false
+				}
+			}
+			a.mu.Unlock()
+		}
+	}
+}
+
+// Rewrite compacts the log: appends move to a new incremental file, the
+// cache is dumped as the new base and the old files are removed. Writes are
+// only blocked while the file is switched. Concurrent calls wait for each
+// other.
+func (a *AOF) Rewrite() error {
+	a.rewriteMu.Lock()
+	defer a.rewriteMu.Unlock()
+	return a.rewriteLocked()
+}
+
+func (a *AOF) rewriteLocked() error {
+	start := time.Now()
+	err := a.rewrite()
+	if err != nil {
+		metrics.IncPersistenceOp("aof_rewrite", "error")
+		return err
+	}
+	metrics.IncPersistenceOp("aof_rewrite", "success")
+	metrics.ObservePersistenceDuration("aof_rewrite", time.Since(start).Seconds())
+	a.logger.Info("aof rewrite completed", zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+// loggedAs returns the record to log for cmd after it was applied with
+// response resp, or false if it changed nothing and must not be replayed.
+// A lock acquire is logged only if it took the lock, with the fencing token
+// it got, so a replay hands out the same token.
+func loggedAs(cmd, resp any) (any, bool) {
+	acquire, ok := cmd.(*command.AcquireLockCommand)
+	if !ok {
+		return cmd, true
+	}
+	r, ok := resp.(*pb.AcquireLockResponse)
+	if !ok || !r.Acquired {
+		return nil, false
+	}
+	logged := *acquire
+	logged.SetIndex(r.Token)
+	return &logged, true
+}
+
+func (a *AOF) rewrite() error {
+	// Switch appends to a new file and capture the base in the same
+	// critical section, so every command is in exactly one of them. Until
+	// the new base is committed the manifest replays the old base and
+	// every incremental file.
+	a.mu.Lock()
+	gen := a.manifest.Gen + 1
+	f, err := os.OpenFile(filepath.Join(a.opts.Dir, incrName(gen)), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		a.mu.Unlock()
+		return fmt.Errorf("create aof file: %w", err)
+	}
+	switching := manifest{
+		Gen:   gen,
+		Base:  a.manifest.Base,
+		Incrs: append(append([]string(nil), a.manifest.Incrs...), incrName(gen)),
+	}
+	if err := a.writeManifest(switching); err != nil {
+		a.mu.Unlock()
+		_ = f.Close()
+		return err
+	}
+	old := a.f
+	_ = old.Sync()
+	_ = old.Close()
+	a.f = f
+	a.size = 0
+	a.dirty = false
+	prev := a.manifest
+	a.manifest = switching
+	capture := a.cache.CaptureDump()
+	a.mu.Unlock()
+
+	base := baseName(gen)
+	size, err := writeFileAtomic(filepath.Join(a.opts.Dir, base), func(w io.Writer) error {
+		return capture.WriteTo(w, a.opts.NodeID, common.DumpFormatBinary.String())
+	})
+	if err != nil {
+		return fmt.Errorf("write aof base: %w", err)
+	}
+
+	a.mu.Lock()
+	committed := manifest{Gen: gen, Base: base, Incrs: []string{incrName(gen)}}
+	if err := a.writeManifest(committed); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.manifest = committed
+	a.baseSize = size
+	a.mu.Unlock()
+
+	for _, name := range append([]string{prev.Base}, prev.Incrs...) {
+		if name != "" {
+			_ = os.Remove(filepath.Join(a.opts.Dir, name))
+		}
+	}
+	return nil
+}
+
+// Close flushes the log and stops the background fsync.
+func (a *AOF) Close() error {
+	close(a.stop)
+	a.wg.Wait()
+	a.rewriteMu.Lock()
+	defer a.rewriteMu.Unlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.f.Sync(); err != nil {
+		_ = a.f.Close()
+		return err
+	}
+	return a.f.Close()
+}
+
+func (a *AOF) replay() error {
+	start := time.Now()
+	if a.manifest.Base != "" {
+		f, err := os.Open(filepath.Join(a.opts.Dir, a.manifest.Base))
+		if err != nil {
+			return fmt.Errorf("open aof base: %w", err)
+		}
+		_, err = a.cache.LoadFrom(f, a.opts.NodeID)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("load aof base: %w", err)
+		}
+	} else {
+		a.cache.Reset()
+	}
+
+	applied := 0
+	for i, name := range a.manifest.Incrs {
+		last := i == len(a.manifest.Incrs)-1
+		n, err := a.replayFile(filepath.Join(a.opts.Dir, name), last)
+		applied += n
+		if err != nil {
+			return err
+		}
+	}
+	a.logger.Info("aof replayed",
+		zap.String("base", a.manifest.Base),
+		zap.Int("commands", applied),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+// replayFile applies the records in path. A torn record at the end of the
+// last file is the expected result of a crash mid-append: it is cut off and
+// replay continues. Anywhere else it is an error.
+func (a *AOF) replayFile(path string, last bool) (int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("open aof file: %w", err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	now := time.Now()
+	var good int64
+	applied := 0
+	for {
+		r, n, err := readRecord(br)
+		if err == io.EOF {
+			return applied, nil
+		}
+		if err != nil {
+			if !last {
+				return applied, fmt.Errorf("%s at offset %d: %w", filepath.Base(path), good, err)
+			}
+			a.logger.Warn("truncating torn aof tail",
+				zap.String("file", filepath.Base(path)),
+				zap.Int64("offset", good),
+				zap.Error(err),
+			)
+			if terr := os.Truncate(path, good); terr != nil {
+				return applied, fmt.Errorf("truncate aof file: %w", terr)
+			}
+			return applied, nil
+		}
+		good += n
+
+		cmd, err := command.Decode(r.Kind, r.Payload)
+		if err != nil {
+			return applied, fmt.Errorf("decode aof record at offset %d: %w", good-n, err)
+		}
+		applier, ok := replayable(cmd, time.Unix(0, r.At), now).(cacheCommand)
+		if !ok {
+			return applied, fmt.Errorf("aof record %q is not a cache command", r.Kind)
+		}
+		// Failures (e.g. max_keys reached) were returned to the client the
+		// first time and are skipped the same way here.
+		if _, err := applier.Apply(a.cache); err == nil {
+			applied++
+		}
+	}
+}
+
+// readRecord reads one length-prefixed, checksummed record. A clean end of
+// file returns io.EOF; anything partial is io.ErrUnexpectedEOF.
+func readRecord(r io.Reader) (record, int64, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.EOF {
+			return record{}, 0, io.EOF
+		}
+		return record{}, 0, io.ErrUnexpectedEOF
+	}
+	size := binary.BigEndian.Uint32(hdr[0:4])
+	if size > maxRecordSize {
+		return record{}, 0, fmt.Errorf("invalid record length %d", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return record{}, 0, io.ErrUnexpectedEOF
+	}
+	if crc32.ChecksumIEEE(b) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return record{}, 0, fmt.Errorf("crc32 mismatch")
+	}
+	var rec record
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return record{}, 0, err
+	}
+	return rec, int64(8 + size), nil
+}
+
+// replayable shortens relative TTLs by the time elapsed since the command
+// was logged. A key whose TTL has already run out is deleted instead. Lock
+// commands that carry the clock they were proposed with replay unchanged:
+// their leases end where they originally did.
+func replayable(cmd any, at, now time.Time) any {
+	switch c := cmd.(type) {
+	case *command.SetCommand:
+		expire, live := remaining(c.Expire, at, now)
+		if !live {
+			return &command.DelCommand{Key: c.Key}
+		}
+		return &command.SetCommand{Key: c.Key, Value: c.Value, Expire: expire}
+	case *command.ExpireKeyCommand:
+		expire, live := remaining(c.Expire, at, now)
+		if !live {
+			return &command.DelCommand{Key: c.Key}
+		}
+		return &command.ExpireKeyCommand{Key: c.Key, Expire: expire}
+	case *command.AcquireLockCommand:
+		if c.Now != 0 {
+			return cmd
+		}
+		// An acquire whose lease has run out still replays, with a lease
+		// that ends at once, so the fencing counter keeps its token.
+		ttl, live := remaining(c.TTL, at, now)
+		if !live {
+			ttl = "1ns"
+		}
+		replayed := *c
+		replayed.TTL = ttl
+		return &replayed
+	case *command.RenewLockCommand:
+		if c.Now != 0 {
+			return cmd
+		}
+		ttl, live := remaining(c.TTL, at, now)
+		if !live {
+			return &command.ReleaseLockCommand{Name: c.Name, Token: c.Token}
+		}
+		return &command.RenewLockCommand{Name: c.Name, Token: c.Token, TTL: ttl}
+	default:
+		return cmd
+	}
+}
+
+func remaining(expire string, at, now time.Time) (string, bool) {
+	if expire == "" {
+		return "", true
+	}
+	d, err := time.ParseDuration(expire)
+	if err != nil {
+		return expire, true // rejected by Apply, as it was originally
+	}
+	left := at.Add(d).Sub(now)
+	if left <= 0 {
+		return "", false
+	}
+	return left.String(), true
+}
+
+func (a *AOF) writeManifest(m manifest) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = writeFileAtomic(filepath.Join(a.opts.Dir, manifestName), func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("write aof manifest: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes path through a synced temp file and rename, and
+// returns the number of bytes written.
+func writeFileAtomic(path string, write func(io.Writer) error) (int64, error) {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriterSize(f, 1<<20)
+	cw := &countingWriter{w: bw}
+	err = write(cw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+	return cw.n, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+func incrName(gen uint64) string { return fmt.Sprintf("incr.%d.aof", gen) }
+func baseName(gen uint64) string { return fmt.Sprintf("base.%d.dump", gen) }
@@ -0,0 +1,262 @@
+package aof
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/cache"
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func newCache(t *testing.T) *cache.Cache {
+	c := cache.New(time.Minute, zap.NewNop())
+	t.Cleanup(c.Close)
+	return c
+}
+
+func open(t *testing.T, dir string, c *cache.Cache) *AOF {
+	a, err := Open(Options{Dir: dir, NodeID: "n1", Fsync: common.AOFFsyncAlways}, c, zap.NewNop())
+	require.NoError(t, err)
+	return a
+}
+
+func apply(t *testing.T, a *AOF, c *cache.Cache, cmd cacheCommand) {
+	_, err := a.Apply(cmd, func() (any, error) { return cmd.Apply(c) })
+	require.NoError(t, err)
+}
+
+func str(t *testing.T, s string) *anypb.Any {
+	v, err := anypb.New(wrapperspb.String(s))
+	require.NoError(t, err)
+	return v
+}
+
+func value(t *testing.T, c *cache.Cache, key string) (string, bool) {
+	v, found := c.Get(key)
+	if !found {
+		return "", false
+	}
+	var w wrapperspb.StringValue
+	require.NoError(t, v.(*anypb.Any).UnmarshalTo(&w))
+	return w.GetValue(), true
+}
+
+func TestReplayRestoresState(t *testing.T) {
+	dir := t.TempDir()
+	c := newCache(t)
+	a := open(t, dir, c)
+	apply(t, a, c, &command.SetCommand{Key: "gone", Value: str(t, "x")})
+	apply(t, a, c, &command.ResetCommand{})
+	apply(t, a, c, &command.SetCommand{Key: "a", Value: str(t, "1")})
+	apply(t, a, c, &command.SetCommand{Key: "a", Value: str(t, "2")})
+	apply(t, a, c, &command.SetCommand{Key: "b", Value: str(t, "b")})
+	apply(t, a, c, &command.DelCommand{Key: "b"})
+	apply(t, a, c, &command.SetCommand{Key: "ttl", Value: str(t, "t")})
+	apply(t, a, c, &command.ExpireKeyCommand{Key: "ttl", Expire: "1h"})
+	require.NoError(t, a.Close())
+
+	restored := newCache(t)
+	a = open(t, dir, restored)
+	defer a.Close()
+	v, found := value(t, restored, "a")
+	assert.True(t, found)
+	assert.Equal(t, "2", v)
+	for _, k := range []string{"gone", "b"} {
+		_, found := restored.Get(k)
+		assert.False(t, found, k)
+	}
+	_, found = value(t, restored, "ttl")
+	assert.True(t, found)
+}
+
+func TestReplayRestoresStructuredValues(t *testing.T) {
+	dir := t.TempDir()
+	c := newCache(t)
+	a := open(t, dir, c)
+	now := time.Now().UnixNano()
+	apply(t, a, c, &command.AcquireLockCommand{Name: "gone", Owner: "o", TTL: "1h"})
+	apply(t, a, c, &command.ReleaseLockCommand{Name: "gone", Token: 1})
+	apply(t, a, c, &command.AcquireLockCommand{Name: "lock", Owner: "o", TTL: "1h"})
+	apply(t, a, c, &command.AcquireLockCommand{Name: "lock", Owner: "other", TTL: "1h"})
+	apply(t, a, c, &command.XAddCommand{Key: "s", Fields: map[string]string{"f": "1"}, Now: now})
+	apply(t, a, c, &command.XAddCommand{Key: "s", Fields: map[string]string{"f": "2"}, Now: now})
+	apply(t, a, c, &command.JSONSetCommand{Key: "doc", Path: "$", Value: `{"n":1}`})
+	apply(t, a, c, &command.JSONNumIncrByCommand{Key: "doc", Path: "$.n", Delta: 2})
+	apply(t, a, c, &command.PFAddCommand{Key: "hll", Items: []string{"a", "b"}})
+	// A rewrite in the middle must not replay anything twice.
+	require.NoError(t, a.Rewrite())
+	apply(t, a, c, &command.JSONNumIncrByCommand{Key: "doc", Path: "$.n", Delta: 4})
+	apply(t, a, c, &command.XAddCommand{Key: "s", Fields: map[string]string{"f": "3"}, Now: now})
+	require.NoError(t, a.Close())
+
+	restored := newCache(t)
+	a = open(t, dir, restored)
+	defer a.Close()
+	holder, acquired, err := restored.AcquireLock("lock", "other", time.Hour, 0, time.Now())
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, "o", holder.Owner)
+	assert.Equal(t, uint64(2), holder.Token)
+	holder, acquired, err = restored.AcquireLock("gone", "new", time.Hour, 0, time.Now())
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, uint64(3), holder.Token, "fencing tokens never go back")
+	doc, _, err := restored.JSONGet("doc", "$.n")
+	require.NoError(t, err)
+	assert.Equal(t, "7", doc)
+	entries, err := restored.StreamRead("s", cache.StreamID{}, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	count, err := restored.HLLCount("hll")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+func TestReplayShortensTTL(t *testing.T) {
+	now := time.Now()
+	set := &command.SetCommand{Key: "k", Expire: "10s"}
+
+	got := replayable(set, now.Add(-4*time.Second), now).(*command.SetCommand)
+	d, err := time.ParseDuration(got.Expire)
+	require.NoError(t, err)
+	assert.InDelta(t, 6*time.Second, d, float64(100*time.Millisecond))
+
+	assert.Equal(t, &command.DelCommand{Key: "k"}, replayable(set, now.Add(-11*time.Second), now))
+	assert.Equal(t, &command.DelCommand{Key: "k"},
+		replayable(&command.ExpireKeyCommand{Key: "k", Expire: "1s"}, now.Add(-time.Minute), now))
+	assert.Equal(t, &command.SetCommand{Key: "k"}, replayable(&command.SetCommand{Key: "k"}, now.Add(-time.Hour), now))
+
+	assert.Equal(t, "1ns", replayable(&command.AcquireLockCommand{Name: "l", TTL: "1s"}, now.Add(-time.Minute), now).(*command.AcquireLockCommand).TTL)
+	assert.Equal(t, &command.ReleaseLockCommand{Name: "l", Token: 3},
+		replayable(&command.RenewLockCommand{Name: "l", Token: 3, TTL: "1s"}, now.Add(-time.Minute), now))
+
+	// Lock commands that carry their clock replay as logged.
+	acquire := &command.AcquireLockCommand{Name: "l", TTL: "1s", Now: now.Add(-time.Minute).UnixNano()}
+	assert.Same(t, acquire, replayable(acquire, now.Add(-time.Minute), now))
+	renew := &command.RenewLockCommand{Name: "l", Token: 3, TTL: "1s", Now: now.Add(-time.Minute).UnixNano()}
+	assert.Same(t, renew, replayable(renew, now.Add(-time.Minute), now))
+}
+
+func TestTornTailIsTruncated(t *testing.T) {
+	dir := t.TempDir()
+	c := newCache(t)
+	a := open(t, dir, c)
+	apply(t, a, c, &command.SetCommand{Key: "a", Value: str(t, "1")})
+	require.NoError(t, a.Close())
+
+	// A crash mid-append leaves a partial record behind.
+	path := filepath.Join(dir, incrName(1))
+	intact, err := os.Stat(path)
+	require.NoError(t, err)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 99, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	restored := newCache(t)
+	a = open(t, dir, restored)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, intact.Size(), info.Size())
+	apply(t, a, restored, &command.SetCommand{Key: "b", Value: str(t, "2")})
+	require.NoError(t, a.Close())
+
+	again := newCache(t)
+	a = open(t, dir, again)
+	defer a.Close()
+	for _, k := range []string{"a", "b"} {
+		_, found := again.Get(k)
+		assert.True(t, found, k)
+	}
+}
+
+func TestRewriteCompactsLog(t *testing.T) {
+	dir := t.TempDir()
+	c := newCache(t)
+	a := open(t, dir, c)
+	for i := 0; i < 100; i++ {
+		apply(t, a, c, &command.SetCommand{Key: "k", Value: str(t, "v")})
+	}
+	_, err := c.BloomAdd("bf", []string{"x"}) // not logged, carried by the base
+	require.NoError(t, err)
+	before, err := os.Stat(filepath.Join(dir, incrName(1)))
+	require.NoError(t, err)
+
+	require.NoError(t, a.Rewrite())
+	apply(t, a, c, &command.SetCommand{Key: "after", Value: str(t, "v")})
+	require.NoError(t, a.Close())
+
+	var m manifest
+	b, err := os.ReadFile(filepath.Join(dir, manifestName))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &m))
+	assert.Equal(t, manifest{Gen: 2, Base: baseName(2), Incrs: []string{incrName(2)}}, m)
+	_, err = os.Stat(filepath.Join(dir, incrName(1)))
+	assert.True(t, os.IsNotExist(err))
+	after, err := os.Stat(filepath.Join(dir, incrName(2)))
+	require.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size())
+
+	restored := newCache(t)
+	a = open(t, dir, restored)
+	defer a.Close()
+	for _, k := range []string{"k", "after"} {
+		_, found := restored.Get(k)
+		assert.True(t, found, k)
+	}
+	exists, err := restored.BloomExists("bf", []string{"x"})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, exists)
+}
+
+func TestReplayInterruptedRewrite(t *testing.T) {
+	dir := t.TempDir()
+	c := newCache(t)
+	a := open(t, dir, c)
+	apply(t, a, c, &command.SetCommand{Key: "a", Value: str(t, "1")})
+	require.NoError(t, a.Close())
+
+	// State after the file switch, before the new base was committed.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, incrName(2)), nil, 0o644))
+	a = &AOF{opts: Options{Dir: dir}}
+	require.NoError(t, a.writeManifest(manifest{Gen: 2, Incrs: []string{incrName(1), incrName(2)}}))
+
+	restored := newCache(t)
+	a = open(t, dir, restored)
+	apply(t, a, restored, &command.DelCommand{Key: "a"})
+	apply(t, a, restored, &command.SetCommand{Key: "b", Value: str(t, "2")})
+	require.NoError(t, a.Close())
+
+	again := newCache(t)
+	a = open(t, dir, again)
+	defer a.Close()
+	_, found := again.Get("a")
+	assert.False(t, found)
+	v, found := value(t, again, "b")
+	assert.True(t, found)
+	assert.Equal(t, "2", v)
+}
+
+func TestOpenFreshUsesExistingCacheAsBase(t *testing.T) {
+	dir := t.TempDir()
+	c := newCache(t)
+	require.NoError(t, c.Set("from-dump", str(t, "v"), ""))
+	a := open(t, dir, c)
+	require.NoError(t, a.Close())
+
+	restored := newCache(t)
+	a = open(t, dir, restored)
+	defer a.Close()
+	_, found := restored.Get("from-dump")
+	assert.True(t, found)
+}
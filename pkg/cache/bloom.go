@@ -0,0 +1,267 @@
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// Defaults used when BloomAdd creates a filter implicitly.
+	DefaultBloomErrorRate = 0.01
+	DefaultBloomCapacity  = 1000
+
+	// MaxBloomBits caps the size of a single filter (512 MiB of bits)
+	// regardless of max_value_size, so a reserve cannot exhaust memory.
+	MaxBloomBits = 1 << 32
+)
+
+// BloomFilter is a fixed-size Bloom filter value. Hashing is deterministic
+// (FNV-1a based double hashing) so replicas and restored dumps agree.
+type BloomFilter struct {
+	bits      []uint64
+	m         uint64 // number of bits
+	k         uint32 // number of hash functions
+	capacity  uint64
+	errorRate float64
+	count     uint64 // items added that were not already present
+}
+
+// bloomBits returns the number of bits (a multiple of 64) and hash
+// functions for capacity items at the given false positive rate.
+func bloomBits(errorRate float64, capacity uint64) (uint64, uint32, error) {
+	if errorRate <= 0 || errorRate >= 1 {
+		return 0, 0, fmt.Errorf("bloom error rate must be between 0 and 1")
+	}
+	if capacity == 0 {
+		return 0, 0, fmt.Errorf("bloom capacity must be positive")
+	}
+	// Computed in float64 so that huge capacities cannot wrap around.
+	bits := math.Ceil(-float64(capacity) * math.Log(errorRate) / (math.Ln2 * math.Ln2))
+	if math.IsNaN(bits) || bits > MaxBloomBits {
+		return 0, 0, fmt.Errorf("bloom filter too large: capacity %d at error rate %g exceeds %d bits", capacity, errorRate, uint64(MaxBloomBits))
+	}
+	m := (uint64(bits) + 63) &^ 63
+	k := uint32(math.Round(float64(m) / float64(capacity) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return m, k, nil
+}
+
+// bloomApproxSize is approxSize for a filter of m bits.
+func bloomApproxSize(m uint64) int {
+	return int(m/8) + 48
+}
+
+// newBloomFilter sizes a filter for capacity items at the given false
+// positive rate.
+func newBloomFilter(errorRate float64, capacity uint64) (*BloomFilter, error) {
+	m, k, err := bloomBits(errorRate, capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &BloomFilter{
+		bits:      make([]uint64, m/64),
+		m:         m,
+		k:         k,
+		capacity:  capacity,
+		errorRate: errorRate,
+	}, nil
+}
+
+func (b *BloomFilter) approxSize() int {
+	return bloomApproxSize(b.m)
+}
+
+func (b *BloomFilter) snapshot() any {
+	out := *b
+	out.bits = append([]uint64(nil), b.bits...)
+	return &out
+}
+
+// Capacity returns the number of items the filter was sized for.
+func (b *BloomFilter) Capacity() uint64 { return b.capacity }
+
+// ErrorRate returns the configured false positive rate.
+func (b *BloomFilter) ErrorRate() float64 { return b.errorRate }
+
+// Count returns the number of distinct items added (subject to false positives).
+func (b *BloomFilter) Count() uint64 { return b.count }
+
+func (b *BloomFilter) locations(item string) (uint64, uint64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(item))
+	h1 := h.Sum64()
+	h2 := mix64(h1) | 1
+	return h1, h2
+}
+
+func (b *BloomFilter) add(item string) bool {
+	h1, h2 := b.locations(item)
+	added := false
+	for i := uint64(0); i < uint64(b.k); i++ {
+		pos := (h1 + i*h2) % b.m
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if b.bits[word]&mask == 0 {
+			b.bits[word] |= mask
+			added = true
+		}
+	}
+	if added {
+		b.count++
+	}
+	return added
+}
+
+func (b *BloomFilter) has(item string) bool {
+	h1, h2 := b.locations(item)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		pos := (h1 + i*h2) % b.m
+		if b.bits[pos/64]&(uint64(1)<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary encodes the filter as a fixed header followed by the raw
+// bit array.
+func (b *BloomFilter) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 36, 36+len(b.bits)*8)
+	binary.LittleEndian.PutUint64(buf[0:8], b.m)
+	binary.LittleEndian.PutUint32(buf[8:12], b.k)
+	binary.LittleEndian.PutUint64(buf[12:20], b.capacity)
+	binary.LittleEndian.PutUint64(buf[20:28], math.Float64bits(b.errorRate))
+	binary.LittleEndian.PutUint64(buf[28:36], b.count)
+	for _, w := range b.bits {
+		buf = binary.LittleEndian.AppendUint64(buf, w)
+	}
+	return buf, nil
+}
+
+func (b *BloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 36 {
+		return fmt.Errorf("bloom filter data too short")
+	}
+	m := binary.LittleEndian.Uint64(data[0:8])
+	if m == 0 || m%64 != 0 || uint64(len(data)-36) != m/8 {
+		return fmt.Errorf("bloom filter data has invalid size")
+	}
+	b.m = m
+	b.k = binary.LittleEndian.Uint32(data[8:12])
+	b.capacity = binary.LittleEndian.Uint64(data[12:20])
+	b.errorRate = math.Float64frombits(binary.LittleEndian.Uint64(data[20:28]))
+	b.count = binary.LittleEndian.Uint64(data[28:36])
+	b.bits = make([]uint64, m/64)
+	for i := range b.bits {
+		b.bits[i] = binary.LittleEndian.Uint64(data[36+i*8:])
+	}
+	return nil
+}
+
+// ErrKeyExists is returned when creating a structured value on a key that
+// already holds one.
+type ErrKeyExists struct {
+	Key string
+}
+
+func (e ErrKeyExists) Error() string {
+	return fmt.Sprintf("key %q already exists", e.Key)
+}
+
+// BloomReserve creates an empty Bloom filter sized for capacity items at the
+// given false positive rate.
+func (c *Cache) BloomReserve(key string, errorRate float64, capacity uint64) error {
+	c.logger.Debug("bloom reserve", zap.String("key", key))
+
+	m, _, err := bloomBits(errorRate, capacity)
+	if err != nil {
+		return err
+	}
+	if c.maxValueSize > 0 {
+		if sz := bloomApproxSize(m); sz > c.maxValueSize {
+			return ErrValueTooLarge{Size: sz, MaxSize: c.maxValueSize}
+		}
+	}
+	b, err := newBloomFilter(errorRate, capacity)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	if _, ok := c.liveItemLocked(key); ok {
+		return ErrKeyExists{Key: key}
+	}
+	return c.storeLocked(key, b, time.Time{})
+}
+
+// BloomAdd adds items to the filter under key, creating a default-sized
+// filter if needed. For each item it reports whether it was newly added
+// (false means it was probably already present).
+func (c *Cache) BloomAdd(key string, items []string) ([]bool, error) {
+	c.logger.Debug("bloom add", zap.String("key", key), zap.Int("items", len(items)))
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	var b *BloomFilter
+	if item, ok := c.liveItemLocked(key); ok {
+		var isBloom bool
+		if b, isBloom = item.value.(*BloomFilter); !isBloom {
+			return nil, ErrWrongType{Key: key, Want: "bloom"}
+		}
+		c.access(key)
+	} else {
+		b, _ = newBloomFilter(DefaultBloomErrorRate, DefaultBloomCapacity)
+		if err := c.storeLocked(key, b, time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]bool, len(items))
+	for i, it := range items {
+		out[i] = b.add(it)
+	}
+	return out, nil
+}
+
+// BloomExists reports for each item whether it may be in the filter. A
+// missing key reports false for every item.
+func (c *Cache) BloomExists(key string, items []string) ([]bool, error) {
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
+
+	out := make([]bool, len(items))
+	item, ok := c.items[key]
+	if !ok || (!item.expiration.IsZero() && time.Now().After(item.expiration)) {
+		return out, nil
+	}
+	b, isBloom := item.value.(*BloomFilter)
+	if !isBloom {
+		return nil, ErrWrongType{Key: key, Want: "bloom"}
+	}
+	c.access(key)
+	for i, it := range items {
+		out[i] = b.has(it)
+	}
+	return out, nil
+}
+
+// mix64 is the MurmurHash3 64-bit finalizer. It spreads FNV output, whose
+// low bits are weak, across the whole word.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
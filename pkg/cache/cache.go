@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/armon/go-radix"
+	"github.com/lushenle/simple-cache/pkg/encrypt"
 	"github.com/lushenle/simple-cache/pkg/metrics"
 	"go.uber.org/zap"
 )
@@ -43,6 +44,11 @@ type Cache struct {
 	lruList        *list.List               // front = most recent, back = evict candidate
 	lruElements    map[string]*list.Element // key -> list element
 
+	fenceSeq uint64 // highest fencing token handed out to a lock holder
+
+	keys          *encrypt.Keyring // encrypts dump files; nil writes plaintext
+	compressDumps bool             // flate-compress binary dumps
+
 	logger *zap.Logger
 }
 
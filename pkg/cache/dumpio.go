@@ -0,0 +1,390 @@
+package cache
+
+import (
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/common"
+	"github.com/lushenle/simple-cache/pkg/encrypt"
+)
+
+// Binary dump v4 is written as a stream so neither side needs the whole
+// dump in memory:
+//
+//	header: magic "SCDF" | version uint32 = 4 | count uint32 | flags uint32
+//	chunk:  length uint32 | payload (entries, v3 entry layout) | crc uint32
+//	end:    length uint32 = 0 | crc uint32
+//
+// Each chunk's crc is a rolling CRC32 over the header and every chunk
+// length and payload so far, so corruption is detected at the chunk where
+// it occurs and truncation is detected by the missing end marker.
+//
+// With dumpFlagFence set, the header is followed by fence uint64, the
+// highest fencing token the cache had handed out, covered by the checksum.
+// With dumpFlagFlate set, everything after that is a single compress/flate
+// stream; checksums cover the uncompressed bytes.
+const (
+	// dumpChunkSize is the payload size at which the writer closes a chunk.
+	// A single larger entry gets a chunk of its own.
+	dumpChunkSize = 1 << 20
+	// maxDumpChunkSize rejects corrupt chunk lengths before allocating.
+	maxDumpChunkSize = 1 << 30
+
+	dumpFlagFlate uint32 = 1 << 0
+	dumpFlagFence uint32 = 1 << 1
+	// knownDumpFlags rejects dumps written with features this build lacks.
+	knownDumpFlags = dumpFlagFlate | dumpFlagFence
+)
+
+// dumpWriter receives entries in key order.
+type dumpWriter interface {
+	writeEntry(e DumpEntry) error
+	close(expired int) error
+}
+
+// newDumpWriter starts a dump of count entries. fence is the highest
+// fencing token handed out so far; a zero fence is not recorded.
+func newDumpWriter(w io.Writer, format, nodeID string, count int, fence uint64, compress bool) (dumpWriter, error) {
+	if format == common.DumpFormatJSON.String() {
+		return newJSONDumpWriter(w, nodeID, fence)
+	}
+	return newBinaryDumpWriter(w, count, fence, compress)
+}
+
+type binaryDumpWriter struct {
+	w   io.Writer
+	fw  *flate.Writer // nil unless compressed
+	crc uint32
+	buf []byte
+}
+
+func newBinaryDumpWriter(w io.Writer, count int, fence uint64, compress bool) (*binaryDumpWriter, error) {
+	bw := &binaryDumpWriter{w: w, buf: make([]byte, 0, dumpChunkSize)}
+	var flags uint32
+	if compress {
+		flags |= dumpFlagFlate
+	}
+	if fence > 0 {
+		flags |= dumpFlagFence
+	}
+	hdr := make([]byte, 0, 24)
+	hdr = append(hdr, dumpMagic...)
+	hdr = binary.BigEndian.AppendUint32(hdr, dumpVersion)
+	hdr = binary.BigEndian.AppendUint32(hdr, uint32(count))
+	hdr = binary.BigEndian.AppendUint32(hdr, flags)
+	if fence > 0 {
+		hdr = binary.BigEndian.AppendUint64(hdr, fence)
+	}
+	if err := bw.emit(hdr); err != nil {
+		return nil, err
+	}
+	if compress {
+		// BestSpeed: dumps are large and written while serving traffic.
+		fw, err := flate.NewWriter(w, flate.BestSpeed)
+		if err != nil {
+			return nil, err
+		}
+		bw.fw, bw.w = fw, fw
+	}
+	return bw, nil
+}
+
+// emit writes p and folds it into the rolling checksum.
+func (bw *binaryDumpWriter) emit(p []byte) error {
+	bw.crc = crc32.Update(bw.crc, crc32.IEEETable, p)
+	_, err := bw.w.Write(p)
+	return err
+}
+
+func (bw *binaryDumpWriter) writeEntry(e DumpEntry) error {
+	bw.buf = appendDumpEntry(bw.buf, e)
+	if len(bw.buf) >= dumpChunkSize {
+		return bw.flushChunk()
+	}
+	return nil
+}
+
+// flushChunk writes the buffered entries as one chunk. An empty buffer
+// writes the end marker.
+func (bw *binaryDumpWriter) flushChunk() error {
+	var word [4]byte
+	binary.BigEndian.PutUint32(word[:], uint32(len(bw.buf)))
+	if err := bw.emit(word[:]); err != nil {
+		return err
+	}
+	if err := bw.emit(bw.buf); err != nil {
+		return err
+	}
+	bw.buf = bw.buf[:0]
+	binary.BigEndian.PutUint32(word[:], bw.crc)
+	_, err := bw.w.Write(word[:])
+	return err
+}
+
+func (bw *binaryDumpWriter) close(int) error {
+	if len(bw.buf) > 0 {
+		if err := bw.flushChunk(); err != nil {
+			return err
+		}
+	}
+	if err := bw.flushChunk(); err != nil {
+		return err
+	}
+	if bw.fw != nil {
+		return bw.fw.Close()
+	}
+	return nil
+}
+
+// jsonDumpWriter writes the DumpJSON layout one entry at a time. The key
+// counts follow the entries since they are only known at the end.
+type jsonDumpWriter struct {
+	w io.Writer
+	n int
+}
+
+func newJSONDumpWriter(w io.Writer, nodeID string, fence uint64) (*jsonDumpWriter, error) {
+	node, err := json.Marshal(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "{\n  \"version\": %d,\n  \"node_id\": %s,\n  \"dumped_at\": %q,\n",
+		dumpJSONVersion, node, time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
+		return nil, err
+	}
+	if fence > 0 {
+		if _, err := fmt.Fprintf(w, "  \"fence_seq\": %d,\n", fence); err != nil {
+			return nil, err
+		}
+	}
+	_, err = io.WriteString(w, "  \"entries\": [")
+	return &jsonDumpWriter{w: w}, err
+}
+
+func (jw *jsonDumpWriter) writeEntry(e DumpEntry) error {
+	b, err := json.MarshalIndent(e, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n    "
+	if jw.n == 0 {
+		sep = "\n    "
+	}
+	jw.n++
+	if _, err := io.WriteString(jw.w, sep); err != nil {
+		return err
+	}
+	_, err = jw.w.Write(b)
+	return err
+}
+
+func (jw *jsonDumpWriter) close(expired int) error {
+	end := "\n  ]"
+	if jw.n == 0 {
+		end = "]"
+	}
+	_, err := fmt.Fprintf(jw.w, "%s,\n  \"total_keys\": %d,\n  \"expired_keys\": %d\n}\n", end, jw.n, expired)
+	return err
+}
+
+// readDump streams entries from r to fn, detecting the format from the
+// first bytes, and returns the format and the fencing token the dump
+// records (0 if none). Entries from a chunk are only passed on once that
+// chunk's checksum has been verified. Encrypted dumps are decrypted with
+// keys.
+func readDump(r io.Reader, keys *encrypt.Keyring, fn func(DumpEntry) error) (string, uint64, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	head, err := br.Peek(len(dumpMagic))
+	if err == nil && encrypt.IsEncrypted(head) {
+		er, err := encrypt.NewReader(br, keys)
+		if err != nil {
+			return "", 0, err
+		}
+		format, fence, err := readDump(er, nil, fn)
+		if err != nil {
+			return format, fence, err
+		}
+		// Authenticate the rest of the stream up to its final segment.
+		_, err = io.Copy(io.Discard, er)
+		return format, fence, err
+	}
+	if err == nil && string(head) == dumpMagic {
+		fence, err := readBinaryDump(br, fn)
+		return common.DumpFormatBinary.String(), fence, err
+	}
+	fence, err := readJSONDump(br, fn)
+	return common.DumpFormatJSON.String(), fence, err
+}
+
+func readBinaryDump(r io.Reader, fn func(DumpEntry) error) (uint64, error) {
+	hdr := make([]byte, 16)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return 0, fmt.Errorf("read header: %w", err)
+	}
+	version := binary.BigEndian.Uint32(hdr[4:8])
+	if version < dumpVersion {
+		// v1-v3 files are a single checksummed buffer.
+		rest, err := io.ReadAll(r)
+		if err != nil {
+			return 0, err
+		}
+		entries, err := decodeBinaryDump(append(hdr, rest...))
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range entries {
+			if err := fn(e); err != nil {
+				return 0, err
+			}
+		}
+		return 0, nil
+	}
+	if version != dumpVersion {
+		return 0, fmt.Errorf("unsupported version: %d", version)
+	}
+	count := binary.BigEndian.Uint32(hdr[8:12])
+	flags := binary.BigEndian.Uint32(hdr[12:16])
+	if flags&^knownDumpFlags != 0 {
+		return 0, fmt.Errorf("unsupported dump flags: %#x", flags)
+	}
+	crc := crc32.ChecksumIEEE(hdr)
+	var fence uint64
+	if flags&dumpFlagFence != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, fmt.Errorf("read header: %w", err)
+		}
+		crc = crc32.Update(crc, crc32.IEEETable, b[:])
+		fence = binary.BigEndian.Uint64(b[:])
+	}
+	if flags&dumpFlagFlate != 0 {
+		fr := flate.NewReader(r)
+		defer fr.Close()
+		r = fr
+	}
+
+	var word [4]byte
+	var payload []byte
+	var read uint32
+	for chunk := 0; ; chunk++ {
+		if _, err := io.ReadFull(r, word[:]); err != nil {
+			return 0, fmt.Errorf("truncated dump at chunk %d: %w", chunk, err)
+		}
+		size := binary.BigEndian.Uint32(word[:])
+		if size > maxDumpChunkSize {
+			return 0, fmt.Errorf("invalid chunk %d length: %d", chunk, size)
+		}
+		crc = crc32.Update(crc, crc32.IEEETable, word[:])
+		if cap(payload) < int(size) {
+			payload = make([]byte, size)
+		}
+		payload = payload[:size]
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return 0, fmt.Errorf("truncated dump at chunk %d: %w", chunk, err)
+		}
+		crc = crc32.Update(crc, crc32.IEEETable, payload)
+		if _, err := io.ReadFull(r, word[:]); err != nil {
+			return 0, fmt.Errorf("truncated dump at chunk %d checksum: %w", chunk, err)
+		}
+		if want := binary.BigEndian.Uint32(word[:]); want != crc {
+			return 0, fmt.Errorf("crc32 mismatch at chunk %d: expected %08x, got %08x", chunk, want, crc)
+		}
+		if size == 0 {
+			break
+		}
+		for offset := 0; offset < len(payload); read++ {
+			e, next, err := decodeDumpEntry(payload, offset, dumpVersion, read)
+			if err != nil {
+				return 0, err
+			}
+			offset = next
+			if err := fn(e); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if read != count {
+		return 0, fmt.Errorf("entry count mismatch: header has %d, read %d", count, read)
+	}
+	return fence, nil
+}
+
+func readJSONDump(r io.Reader, fn func(DumpEntry) error) (uint64, error) {
+	dec := json.NewDecoder(r)
+	if err := expectJSONDelim(dec, '{'); err != nil {
+		return 0, err
+	}
+	var fence uint64
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, err
+		}
+		switch name, _ := tok.(string); name {
+		case "entries":
+		case "fence_seq":
+			if err := dec.Decode(&fence); err != nil {
+				return 0, fmt.Errorf("fence_seq: %w", err)
+			}
+			continue
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		tok, err = dec.Token()
+		if err != nil {
+			return 0, err
+		}
+		if tok == nil {
+			continue // "entries": null
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return 0, fmt.Errorf("entries: expected array, got %v", tok)
+		}
+		for dec.More() {
+			var e DumpEntry
+			if err := dec.Decode(&e); err != nil {
+				return 0, err
+			}
+			if err := fn(e); err != nil {
+				return 0, err
+			}
+		}
+		if err := expectJSONDelim(dec, ']'); err != nil {
+			return 0, err
+		}
+	}
+	return fence, expectJSONDelim(dec, '}')
+}
+
+func expectJSONDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %v, got %v", want, tok)
+	}
+	return nil
+}
+
+// countingWriter counts bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
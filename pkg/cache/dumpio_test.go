@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func fillCache(t *testing.T, c *Cache, n, valueSize int) {
+	t.Helper()
+	value := strings.Repeat("v", valueSize)
+	for i := 0; i < n; i++ {
+		require.NoError(t, c.Set(fmt.Sprintf("key-%05d", i), value, ""))
+	}
+}
+
+func TestDumpStreamsMultipleChunks(t *testing.T) {
+	c := New(0, zap.NewNop())
+	defer c.Close()
+	fillCache(t, c, 3000, 1024) // ~3 MiB, spans several chunks
+
+	var buf bytes.Buffer
+	require.NoError(t, c.DumpTo(&buf, "node1", "binary"))
+	assert.Equal(t, uint32(dumpVersion), binary.BigEndian.Uint32(buf.Bytes()[4:8]))
+	assert.Greater(t, buf.Len(), 2*dumpChunkSize)
+
+	restored := New(0, zap.NewNop())
+	defer restored.Close()
+	// Short reads exercise the partial read paths.
+	result, err := restored.LoadFrom(iotest.HalfReader(&buf), "node1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3000), result.LoadedKeys)
+	v, found := restored.Get("key-02999")
+	require.True(t, found)
+	assert.Len(t, v, 1024)
+}
+
+func TestLoadRejectsCorruptChunkWithoutTouchingCache(t *testing.T) {
+	src := New(0, zap.NewNop())
+	defer src.Close()
+	fillCache(t, src, 3000, 1024)
+	data, err := src.DumpToBytes("node1", "binary")
+	require.NoError(t, err)
+
+	dst := New(0, zap.NewNop())
+	defer dst.Close()
+	require.NoError(t, dst.Set("existing", "kept", ""))
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[len(corrupt)-100] ^= 0xFF // inside the last data chunk
+	_, err = dst.LoadFromBytes("node1", corrupt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "crc32 mismatch at chunk")
+
+	_, err = dst.LoadFromBytes("node1", data[:len(data)-8]) // missing end marker
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "truncated dump")
+
+	v, found := dst.Get("existing")
+	require.True(t, found)
+	assert.Equal(t, "kept", v)
+	_, found = dst.Get("key-00000")
+	assert.False(t, found)
+}
+
+func TestDumpStreamsJSON(t *testing.T) {
+	c := New(0, zap.NewNop())
+	defer c.Close()
+
+	var empty bytes.Buffer
+	require.NoError(t, c.DumpTo(&empty, "node1", "json"))
+	assert.Contains(t, empty.String(), `"entries": []`)
+
+	fillCache(t, c, 10, 8)
+	var buf bytes.Buffer
+	require.NoError(t, c.DumpTo(&buf, "node1", "json"))
+	assert.Contains(t, buf.String(), `"total_keys": 10`)
+
+	restored := New(0, zap.NewNop())
+	defer restored.Close()
+	result, err := restored.LoadFrom(&buf, "node1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), result.LoadedKeys)
+}
+
+func TestLoadReadsV3Dump(t *testing.T) {
+	data, err := encodeBinaryDump([]DumpEntry{
+		{Key: "a", Value: "1", ValueType: "string"},
+		{Key: "b", Value: "2", ValueType: "string"},
+	})
+	require.NoError(t, err)
+
+	c := New(0, zap.NewNop())
+	defer c.Close()
+	result, err := c.LoadFromBytes("node1", data)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), result.LoadedKeys)
+}
+
+func TestCompressedDump(t *testing.T) {
+	c := New(0, zap.NewNop())
+	defer c.Close()
+	fillCache(t, c, 3000, 1024)
+
+	var plain bytes.Buffer
+	require.NoError(t, c.DumpTo(&plain, "node1", "binary"))
+	c.SetDumpCompression(true)
+	var buf bytes.Buffer
+	require.NoError(t, c.DumpTo(&buf, "node1", "binary"))
+	data := buf.Bytes()
+	assert.Equal(t, dumpFlagFlate, binary.BigEndian.Uint32(data[12:16]))
+	assert.Less(t, len(data), plain.Len()/10)
+
+	// Detected from the header; the loading cache need not compress.
+	restored := New(0, zap.NewNop())
+	defer restored.Close()
+	result, err := restored.LoadFrom(iotest.HalfReader(bytes.NewReader(data)), "node1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3000), result.LoadedKeys)
+
+	_, err = restored.LoadFromBytes("node1", data[:len(data)-10])
+	assert.Error(t, err)
+	unknown := append([]byte(nil), data...)
+	binary.BigEndian.PutUint32(unknown[12:16], 1<<7)
+	_, err = restored.LoadFromBytes("node1", unknown)
+	assert.ErrorContains(t, err, "unsupported dump flags")
+
+	// JSON dumps are never compressed.
+	var js bytes.Buffer
+	require.NoError(t, c.DumpTo(&js, "node1", "json"))
+	assert.Equal(t, byte('{'), js.Bytes()[0])
+}
@@ -54,3 +54,16 @@ func (c *Cache) handleExpiredKey(key string) (any, bool) {
 
 	return item.value, true
 }
+
+// IsStructured reports whether v, a value returned by Get, is a structured
+// value (lock, stream, JSON document, Bloom filter or HyperLogLog). These
+// are mutated in place under the cache lock, so callers must not read them
+// after Get returns; use the type's own commands instead.
+func IsStructured(v any) bool {
+	switch v.(type) {
+	case *Lock, *Stream, *JSONDoc, *BloomFilter, *HyperLogLog:
+		return true
+	default:
+		return false
+	}
+}
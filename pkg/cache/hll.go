@@ -0,0 +1,306 @@
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	hllPrecision = 14
+	hllRegisters = 1 << hllPrecision // 16384, ~0.81% standard error
+	// hllSparseMax is the number of non-zero registers above which the
+	// sparse map is converted to a dense register array.
+	hllSparseMax = 2048
+
+	hllEncodingSparse byte = 1
+	hllEncodingDense  byte = 2
+)
+
+// HyperLogLog estimates the number of distinct items added to it. Small
+// sets are kept in a sparse register map and promoted to a dense array
+// once they grow.
+type HyperLogLog struct {
+	sparse map[uint16]uint8
+	dense  []uint8
+}
+
+func newHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{sparse: make(map[uint16]uint8)}
+}
+
+func (h *HyperLogLog) approxSize() int {
+	if h.dense != nil {
+		return len(h.dense) + 16
+	}
+	return len(h.sparse)*4 + 48
+}
+
+func (h *HyperLogLog) get(idx uint16) uint8 {
+	if h.dense != nil {
+		return h.dense[idx]
+	}
+	return h.sparse[idx]
+}
+
+// set raises register idx to rank and reports whether it changed.
+func (h *HyperLogLog) set(idx uint16, rank uint8) bool {
+	if h.get(idx) >= rank {
+		return false
+	}
+	if h.dense != nil {
+		h.dense[idx] = rank
+		return true
+	}
+	h.sparse[idx] = rank
+	if len(h.sparse) > hllSparseMax {
+		h.dense = make([]uint8, hllRegisters)
+		for i, r := range h.sparse {
+			h.dense[i] = r
+		}
+		h.sparse = nil
+	}
+	return true
+}
+
+func (h *HyperLogLog) add(item string) bool {
+	f := fnv.New64a()
+	_, _ = f.Write([]byte(item))
+	x := mix64(f.Sum64())
+	idx := uint16(x >> (64 - hllPrecision))
+	w := x<<hllPrecision | 1<<(hllPrecision-1)
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	return h.set(idx, rank)
+}
+
+func (h *HyperLogLog) merge(other *HyperLogLog) {
+	if other.dense != nil {
+		for i, r := range other.dense {
+			if r > 0 {
+				h.set(uint16(i), r)
+			}
+		}
+		return
+	}
+	for i, r := range other.sparse {
+		h.set(i, r)
+	}
+}
+
+func (h *HyperLogLog) clone() *HyperLogLog {
+	out := newHyperLogLog()
+	out.merge(h)
+	return out
+}
+
+func (h *HyperLogLog) snapshot() any {
+	if h.dense != nil {
+		return &HyperLogLog{dense: append([]uint8(nil), h.dense...)}
+	}
+	return h.clone()
+}
+
+// count returns the HyperLogLog cardinality estimate with linear counting
+// for small cardinalities.
+func (h *HyperLogLog) count() uint64 {
+	const m = float64(hllRegisters)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	sum := 0.0
+	zeros := 0
+	for i := 0; i < hllRegisters; i++ {
+		r := h.get(uint16(i))
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	est := alpha * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(est + 0.5)
+}
+
+// MarshalBinary encodes the registers. Sparse sketches are written as
+// sorted (index, rank) pairs; dense sketches pack each 6-bit register.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	if h.dense == nil {
+		idx := make([]int, 0, len(h.sparse))
+		for i := range h.sparse {
+			idx = append(idx, int(i))
+		}
+		sort.Ints(idx)
+		buf := make([]byte, 0, 1+len(idx)*3)
+		buf = append(buf, hllEncodingSparse)
+		for _, i := range idx {
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(i))
+			buf = append(buf, h.sparse[uint16(i)])
+		}
+		return buf, nil
+	}
+
+	buf := make([]byte, 1+hllRegisters*6/8)
+	buf[0] = hllEncodingDense
+	packed := buf[1:]
+	for i, r := range h.dense {
+		bit := i * 6
+		v := uint16(r&0x3f) << (bit % 8)
+		packed[bit/8] |= byte(v)
+		if bit%8 > 2 {
+			packed[bit/8+1] |= byte(v >> 8)
+		}
+	}
+	return buf, nil
+}
+
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("hyperloglog data is empty")
+	}
+	switch data[0] {
+	case hllEncodingSparse:
+		body := data[1:]
+		if len(body)%3 != 0 {
+			return fmt.Errorf("hyperloglog sparse data has invalid size")
+		}
+		h.sparse = make(map[uint16]uint8, len(body)/3)
+		h.dense = nil
+		for i := 0; i < len(body); i += 3 {
+			idx := binary.LittleEndian.Uint16(body[i:])
+			if idx >= hllRegisters {
+				return fmt.Errorf("hyperloglog register %d out of range", idx)
+			}
+			h.set(idx, body[i+2])
+		}
+	case hllEncodingDense:
+		packed := data[1:]
+		if len(packed) != hllRegisters*6/8 {
+			return fmt.Errorf("hyperloglog dense data has invalid size")
+		}
+		h.sparse = nil
+		h.dense = make([]uint8, hllRegisters)
+		for i := range h.dense {
+			bit := i * 6
+			v := uint16(packed[bit/8])
+			if bit/8+1 < len(packed) {
+				v |= uint16(packed[bit/8+1]) << 8
+			}
+			h.dense[i] = uint8(v>>(bit%8)) & 0x3f
+		}
+	default:
+		return fmt.Errorf("unknown hyperloglog encoding %d", data[0])
+	}
+	return nil
+}
+
+// HLLAdd adds items to the HyperLogLog under key, creating it if needed.
+// It reports whether the estimate may have changed.
+func (c *Cache) HLLAdd(key string, items []string) (bool, error) {
+	c.logger.Debug("hll add", zap.String("key", key), zap.Int("items", len(items)))
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	h, created, err := c.hllLocked(key)
+	if err != nil {
+		return false, err
+	}
+	changed := created
+	for _, it := range items {
+		if h.add(it) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// HLLCount returns the estimated number of distinct items across the
+// union of the given keys. Missing keys count as empty.
+func (c *Cache) HLLCount(keys ...string) (uint64, error) {
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
+
+	var union *HyperLogLog
+	for _, key := range keys {
+		item, ok := c.items[key]
+		if !ok || (!item.expiration.IsZero() && time.Now().After(item.expiration)) {
+			continue
+		}
+		h, isHLL := item.value.(*HyperLogLog)
+		if !isHLL {
+			return 0, ErrWrongType{Key: key, Want: "hyperloglog"}
+		}
+		c.access(key)
+		if len(keys) == 1 {
+			return h.count(), nil
+		}
+		if union == nil {
+			union = h.clone()
+		} else {
+			union.merge(h)
+		}
+	}
+	if union == nil {
+		return 0, nil
+	}
+	return union.count(), nil
+}
+
+// HLLMerge stores the union of dest and sources into dest, creating it if
+// needed. Missing sources are ignored.
+func (c *Cache) HLLMerge(dest string, sources ...string) error {
+	c.logger.Debug("hll merge", zap.String("dest", dest), zap.Strings("sources", sources))
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	srcs := make([]*HyperLogLog, 0, len(sources))
+	for _, key := range sources {
+		item, ok := c.liveItemLocked(key)
+		if !ok {
+			continue
+		}
+		h, isHLL := item.value.(*HyperLogLog)
+		if !isHLL {
+			return ErrWrongType{Key: key, Want: "hyperloglog"}
+		}
+		srcs = append(srcs, h)
+	}
+
+	h, _, err := c.hllLocked(dest)
+	if err != nil {
+		return err
+	}
+	for _, src := range srcs {
+		if src != h {
+			h.merge(src)
+		}
+	}
+	return nil
+}
+
+// hllLocked returns the HyperLogLog under key, creating an empty one if the
+// key is missing. Caller must hold c.mu write lock.
+func (c *Cache) hllLocked(key string) (*HyperLogLog, bool, error) {
+	if item, ok := c.liveItemLocked(key); ok {
+		h, isHLL := item.value.(*HyperLogLog)
+		if !isHLL {
+			return nil, false, ErrWrongType{Key: key, Want: "hyperloglog"}
+		}
+		c.access(key)
+		return h, false, nil
+	}
+	h := newHyperLogLog()
+	if err := c.storeLocked(key, h, time.Time{}); err != nil {
+		return nil, false, err
+	}
+	return h, true, nil
+}
@@ -0,0 +1,506 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// JSONDoc is a JSON document value addressed with a small path syntax:
+// "$" (or "") is the root, ".name" selects an object member, "[n]" an array
+// element (negative n counts from the end) and `["a.b"]` a member whose
+// name needs quoting, e.g. `$.user.tags[0]`.
+type JSONDoc struct {
+	root any // decoded with UseNumber so integers survive round-trips
+}
+
+func (d *JSONDoc) approxSize() int {
+	return jsonValueSize(d.root)
+}
+
+func (d *JSONDoc) snapshot() any {
+	return &JSONDoc{root: cloneJSONValue(d.root)}
+}
+
+func (d *JSONDoc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.root)
+}
+
+func (d *JSONDoc) UnmarshalJSON(b []byte) error {
+	v, err := decodeJSONValue(string(b))
+	if err != nil {
+		return err
+	}
+	d.root = v
+	return nil
+}
+
+func jsonValueSize(v any) int {
+	switch val := v.(type) {
+	case map[string]any:
+		size := 16
+		for k, e := range val {
+			size += len(k) + jsonValueSize(e)
+		}
+		return size
+	case []any:
+		size := 16
+		for _, e := range val {
+			size += jsonValueSize(e)
+		}
+		return size
+	case string:
+		return len(val)
+	case json.Number:
+		return len(val)
+	default:
+		return 8
+	}
+}
+
+// cloneJSONValue deep-copies the containers of a decoded value; scalars are
+// immutable and shared.
+func cloneJSONValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, e := range val {
+			out[k] = cloneJSONValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, e := range val {
+			out[i] = cloneJSONValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func decodeJSONValue(s string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, ErrInvalidJSON{Msg: "invalid JSON value: " + err.Error()}
+	}
+	if dec.More() {
+		return nil, ErrInvalidJSON{Msg: "invalid JSON value: trailing data"}
+	}
+	return v, nil
+}
+
+// jsonPathSegment is one step of a parsed path: either an object member or
+// an array index.
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath parses the path syntax documented on JSONDoc.
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	p := strings.TrimPrefix(path, "$")
+	var segs []jsonPathSegment
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, ErrInvalidJSON{Msg: fmt.Sprintf("invalid JSON path %q: empty member name", path)}
+			}
+			segs = append(segs, jsonPathSegment{key: p[:end]})
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, ErrInvalidJSON{Msg: fmt.Sprintf("invalid JSON path %q: unclosed bracket", path)}
+			}
+			inner := p[1:end]
+			if strings.HasPrefix(inner, `"`) {
+				key, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, ErrInvalidJSON{Msg: fmt.Sprintf("invalid JSON path %q: %v", path, err)}
+				}
+				segs = append(segs, jsonPathSegment{key: key})
+			} else {
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, ErrInvalidJSON{Msg: fmt.Sprintf("invalid JSON path %q: bad index %q", path, inner)}
+				}
+				segs = append(segs, jsonPathSegment{index: idx, isIndex: true})
+			}
+			p = p[end+1:]
+		default:
+			// Allow a bare leading member name: "user.name".
+			if len(segs) == 0 && path == p {
+				p = "." + p
+				continue
+			}
+			return nil, ErrInvalidJSON{Msg: fmt.Sprintf("invalid JSON path %q", path)}
+		}
+	}
+	return segs, nil
+}
+
+// ErrInvalidJSON is returned for a malformed JSON value or path.
+type ErrInvalidJSON struct {
+	Msg string
+}
+
+func (e ErrInvalidJSON) Error() string {
+	return e.Msg
+}
+
+// ErrJSONPath is returned when a path does not resolve within a document.
+type ErrJSONPath struct {
+	Path   string
+	Reason string
+}
+
+func (e ErrJSONPath) Error() string {
+	return fmt.Sprintf("JSON path %q: %s", e.Path, e.Reason)
+}
+
+func resolveIndex(arr []any, idx int) (int, bool) {
+	if idx < 0 {
+		idx += len(arr)
+	}
+	return idx, idx >= 0 && idx < len(arr)
+}
+
+// lookup returns the value at segs, or false if any step is missing.
+func (d *JSONDoc) lookup(segs []jsonPathSegment) (any, bool) {
+	cur := d.root
+	for _, s := range segs {
+		switch node := cur.(type) {
+		case map[string]any:
+			if s.isIndex {
+				return nil, false
+			}
+			v, ok := node[s.key]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			if !s.isIndex {
+				return nil, false
+			}
+			i, ok := resolveIndex(node, s.index)
+			if !ok {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// set stores value at segs. Missing object members along the way are
+// created as empty objects; array indexes must already exist. The whole
+// path is checked before anything changes, and a positive maxSize rejects
+// the write if the resulting document would exceed it.
+func (d *JSONDoc) set(path string, segs []jsonPathSegment, value any, maxSize int) error {
+	growth, err := d.setGrowth(path, segs)
+	if err != nil {
+		return err
+	}
+	if maxSize > 0 {
+		if sz := d.approxSize() + growth + jsonValueSize(value); sz > maxSize {
+			return ErrValueTooLarge{Size: sz, MaxSize: maxSize}
+		}
+	}
+	if len(segs) == 0 {
+		d.root = value
+		return nil
+	}
+	if d.root == nil {
+		d.root = map[string]any{}
+	}
+	parent := d.root
+	for i, s := range segs {
+		last := i == len(segs)-1
+		switch node := parent.(type) {
+		case map[string]any:
+			if last {
+				node[s.key] = value
+				return nil
+			}
+			next, ok := node[s.key]
+			if !ok || next == nil {
+				next = map[string]any{}
+				node[s.key] = next
+			}
+			parent = next
+		case []any:
+			idx, _ := resolveIndex(node, s.index)
+			if last {
+				node[idx] = value
+				return nil
+			}
+			parent = node[idx]
+		}
+	}
+	return nil
+}
+
+// setGrowth checks that set can store a value at segs without changing the
+// document, and returns how much the document's approxSize changes apart
+// from the value itself: the members created on the way, less the value
+// being replaced.
+func (d *JSONDoc) setGrowth(path string, segs []jsonPathSegment) (int, error) {
+	if len(segs) == 0 {
+		return -jsonValueSize(d.root), nil
+	}
+	emptyObject := jsonValueSize(map[string]any{})
+	growth := 0
+	cur, exists := d.root, d.root != nil
+	if !exists {
+		growth += emptyObject - jsonValueSize(nil)
+	}
+	for i, s := range segs {
+		last := i == len(segs)-1
+		if !exists {
+			// cur is an object that set creates.
+			if s.isIndex {
+				return 0, ErrJSONPath{Path: path, Reason: "index applied to an object"}
+			}
+			growth += len(s.key)
+			if !last {
+				growth += emptyObject
+			}
+			continue
+		}
+		switch node := cur.(type) {
+		case map[string]any:
+			if s.isIndex {
+				return 0, ErrJSONPath{Path: path, Reason: "index applied to an object"}
+			}
+			next, ok := node[s.key]
+			if !ok {
+				growth += len(s.key)
+			}
+			if last || next == nil {
+				// The member is replaced: by the value, or by an object
+				// holding the rest of the path.
+				growth -= jsonValueSize(next)
+			}
+			if last {
+				return growth, nil
+			}
+			if next == nil {
+				growth += emptyObject
+				exists = false
+			}
+			cur = next
+		case []any:
+			if !s.isIndex {
+				return 0, ErrJSONPath{Path: path, Reason: "member name applied to an array"}
+			}
+			idx, ok := resolveIndex(node, s.index)
+			if !ok {
+				return 0, ErrJSONPath{Path: path, Reason: "array index out of range"}
+			}
+			if last {
+				return growth - jsonValueSize(node[idx]), nil
+			}
+			cur = node[idx]
+		default:
+			return 0, ErrJSONPath{Path: path, Reason: "cannot descend into a scalar"}
+		}
+	}
+	return growth, nil
+}
+
+// del removes the value at segs and reports whether anything was removed.
+func (d *JSONDoc) del(segs []jsonPathSegment) bool {
+	parent, ok := d.lookup(segs[:len(segs)-1])
+	if !ok {
+		return false
+	}
+	s := segs[len(segs)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		if s.isIndex {
+			return false
+		}
+		if _, exists := node[s.key]; !exists {
+			return false
+		}
+		delete(node, s.key)
+		return true
+	case []any:
+		if !s.isIndex {
+			return false
+		}
+		idx, ok := resolveIndex(node, s.index)
+		if !ok {
+			return false
+		}
+		trimmed := append(node[:idx:idx], node[idx+1:]...)
+		if len(segs) == 1 {
+			d.root = trimmed
+		} else {
+			_ = d.set("", segs[:len(segs)-1], trimmed, 0)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// JSONSet stores the JSON text value at path in the document under key.
+// A missing key is created as a new document.
+func (c *Cache) JSONSet(key, path, value string) error {
+	c.logger.Debug("json set", zap.String("key", key), zap.String("path", path))
+
+	segs, err := parseJSONPath(path)
+	if err != nil {
+		return err
+	}
+	v, err := decodeJSONValue(value)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	if item, ok := c.liveItemLocked(key); ok {
+		doc, isDoc := item.value.(*JSONDoc)
+		if !isDoc {
+			return ErrWrongType{Key: key, Want: "json"}
+		}
+		c.access(key)
+		return doc.set(path, segs, v, c.maxValueSize)
+	}
+
+	doc := &JSONDoc{}
+	if err := doc.set(path, segs, v, c.maxValueSize); err != nil {
+		return err
+	}
+	return c.storeLocked(key, doc, time.Time{})
+}
+
+// JSONGet returns the JSON text at path in the document under key.
+func (c *Cache) JSONGet(key, path string) (string, bool, error) {
+	segs, err := parseJSONPath(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
+
+	item, ok := c.items[key]
+	if !ok || (!item.expiration.IsZero() && time.Now().After(item.expiration)) {
+		return "", false, nil
+	}
+	doc, isDoc := item.value.(*JSONDoc)
+	if !isDoc {
+		return "", false, ErrWrongType{Key: key, Want: "json"}
+	}
+	c.access(key)
+	v, found := doc.lookup(segs)
+	if !found {
+		return "", false, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
+
+// JSONDel removes the value at path. Deleting the root removes the key.
+func (c *Cache) JSONDel(key, path string) (bool, error) {
+	c.logger.Debug("json del", zap.String("key", key), zap.String("path", path))
+
+	segs, err := parseJSONPath(path)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	item, ok := c.liveItemLocked(key)
+	if !ok {
+		return false, nil
+	}
+	doc, isDoc := item.value.(*JSONDoc)
+	if !isDoc {
+		return false, ErrWrongType{Key: key, Want: "json"}
+	}
+	if len(segs) == 0 {
+		c.delLRU(key)
+		c.delInternal(key)
+		return true, nil
+	}
+	return doc.del(segs), nil
+}
+
+// JSONNumIncrBy adds delta to the number at path and returns the new value
+// as JSON text. Integers stay integers when delta is integral.
+func (c *Cache) JSONNumIncrBy(key, path string, delta float64) (string, error) {
+	c.logger.Debug("json incr", zap.String("key", key), zap.String("path", path))
+
+	segs, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	item, ok := c.liveItemLocked(key)
+	if !ok {
+		return "", ErrJSONPath{Path: path, Reason: "key does not exist"}
+	}
+	doc, isDoc := item.value.(*JSONDoc)
+	if !isDoc {
+		return "", ErrWrongType{Key: key, Want: "json"}
+	}
+	cur, found := doc.lookup(segs)
+	if !found {
+		return "", ErrJSONPath{Path: path, Reason: "path does not exist"}
+	}
+	num, isNum := cur.(json.Number)
+	if !isNum {
+		return "", ErrJSONPath{Path: path, Reason: "value is not a number"}
+	}
+
+	var next json.Number
+	if i, err := num.Int64(); err == nil && delta == math.Trunc(delta) && math.Abs(delta) < 1<<53 {
+		next = json.Number(strconv.FormatInt(i+int64(delta), 10))
+	} else {
+		f, err := num.Float64()
+		if err != nil {
+			return "", ErrJSONPath{Path: path, Reason: "value is not a number"}
+		}
+		res := f + delta
+		if math.IsInf(res, 0) || math.IsNaN(res) {
+			return "", ErrJSONPath{Path: path, Reason: "increment overflows"}
+		}
+		next = json.Number(strconv.FormatFloat(res, 'g', -1, 64))
+	}
+	if err := doc.set(path, segs, next, c.maxValueSize); err != nil {
+		return "", err
+	}
+	return next.String(), nil
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONDocPaths(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	require.NoError(t, c.JSONSet("user", "$", `{"name":"ann","tags":["a","b"],"stats":{"visits":1}}`))
+
+	v, found, err := c.JSONGet("user", "$.name")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, `"ann"`, v)
+
+	v, _, err = c.JSONGet("user", "tags[-1]")
+	require.NoError(t, err)
+	assert.Equal(t, `"b"`, v)
+
+	_, found, err = c.JSONGet("user", "$.missing.deep")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// Missing intermediate objects are created.
+	require.NoError(t, c.JSONSet("user", `$.prefs["ui.theme"]`, `"dark"`))
+	v, _, err = c.JSONGet("user", "$.prefs")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ui.theme":"dark"}`, v)
+
+	assert.ErrorAs(t, c.JSONSet("user", "$.tags[5]", `"x"`), &ErrJSONPath{})
+	assert.ErrorAs(t, c.JSONSet("user", "$.name.first", `"x"`), &ErrJSONPath{})
+	assert.ErrorAs(t, c.JSONSet("user", "$.x", `{bad`), &ErrInvalidJSON{})
+	assert.ErrorAs(t, c.JSONSet("user", "$.tags[x]", `1`), &ErrInvalidJSON{})
+
+	// A path that fails part-way leaves the document untouched.
+	assert.ErrorAs(t, c.JSONSet("user", "$.a.b[0]", `1`), &ErrJSONPath{})
+	_, found, err = c.JSONGet("user", "$.a")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	deleted, err := c.JSONDel("user", "$.tags[0]")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	v, _, err = c.JSONGet("user", "$.tags")
+	require.NoError(t, err)
+	assert.Equal(t, `["b"]`, v)
+
+	deleted, err = c.JSONDel("user", "$.nope")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = c.JSONDel("user", "$")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	_, found = c.Get("user")
+	assert.False(t, found)
+
+	require.NoError(t, c.Set("plain", "v", ""))
+	_, _, err = c.JSONGet("plain", "$")
+	assert.ErrorAs(t, err, &ErrWrongType{})
+}
+
+func TestJSONSetMaxValueSize(t *testing.T) {
+	base := newTestCache()
+	defer base.Close()
+	c := NewWithLimits(0, 0, 256, string(EvictionNone), base.logger)
+	defer c.Close()
+
+	require.NoError(t, c.JSONSet("doc", "$", `{"list":[1,2],"nested":{"x":null}}`))
+	// Each fragment is small, but the document they add up to is not.
+	var err error
+	for i := 0; err == nil && i < 100; i++ {
+		err = c.JSONSet("doc", fmt.Sprintf("$.nested.k%d.v", i), `"0123456789012345678901234567890123456789"`)
+	}
+	assert.ErrorAs(t, err, &ErrValueTooLarge{})
+
+	item, ok := c.items["doc"]
+	require.True(t, ok)
+	doc := item.value.(*JSONDoc)
+	assert.LessOrEqual(t, doc.approxSize(), 256)
+
+	// The size checked before a write is the size of the document after it.
+	for _, tc := range []struct{ path, value string }{
+		{"$.nested.x", `"replaced"`},
+		{"$.nested.x.y", `1`},
+		{"$.list[-1]", `{"z":true}`},
+		{"$.nested", `0`},
+		{"$", `[]`},
+	} {
+		fresh := &JSONDoc{}
+		require.NoError(t, fresh.UnmarshalJSON([]byte(`{"list":[1,2],"nested":{"x":null}}`)))
+		segs, err := parseJSONPath(tc.path)
+		require.NoError(t, err)
+		v, err := decodeJSONValue(tc.value)
+		require.NoError(t, err)
+		growth, err := fresh.setGrowth(tc.path, segs)
+		require.NoError(t, err)
+		want := fresh.approxSize() + growth + jsonValueSize(v)
+		require.NoError(t, fresh.set(tc.path, segs, v, 0))
+		assert.Equal(t, want, fresh.approxSize(), tc.path)
+	}
+	fresh := &JSONDoc{}
+	segs, _ := parseJSONPath("$.a.b")
+	growth, err := fresh.setGrowth("$.a.b", segs)
+	require.NoError(t, err)
+	want := fresh.approxSize() + growth + jsonValueSize("v")
+	require.NoError(t, fresh.set("$.a.b", segs, "v", 0))
+	assert.Equal(t, want, fresh.approxSize())
+}
+
+func TestJSONNumIncrBy(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	require.NoError(t, c.JSONSet("doc", "$", `{"n":9007199254740993,"f":1.5,"s":"x"}`))
+
+	v, err := c.JSONNumIncrBy("doc", "$.n", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "9007199254740994", v)
+
+	v, err = c.JSONNumIncrBy("doc", "$.f", 0.25)
+	require.NoError(t, err)
+	assert.Equal(t, "1.75", v)
+
+	_, err = c.JSONNumIncrBy("doc", "$.s", 1)
+	assert.ErrorAs(t, err, &ErrJSONPath{})
+	_, err = c.JSONNumIncrBy("doc", "$.missing", 1)
+	assert.ErrorAs(t, err, &ErrJSONPath{})
+
+	// Concurrent increments on one field and sets on another do not race.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = c.JSONNumIncrBy("doc", "$.f", 1)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = c.JSONSet("doc", "$.s", `"y"`)
+		}()
+	}
+	wg.Wait()
+	v, _, err = c.JSONGet("doc", "$.f")
+	require.NoError(t, err)
+	assert.Equal(t, "51.75", v)
+}
+
+func TestJSONDocDumpLoad(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	require.NoError(t, c.JSONSet("doc", "$", `{"id":12345678901234567,"items":[1,{"k":"v"}]}`))
+
+	data, err := c.DumpToBytes("node1", "binary")
+	require.NoError(t, err)
+	restored := newTestCache()
+	defer restored.Close()
+	_, err = restored.LoadFromBytes("node1", data)
+	require.NoError(t, err)
+
+	v, found, err := restored.JSONGet("doc", "$")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, `{"id":12345678901234567,"items":[1,{"k":"v"}]}`, v)
+}
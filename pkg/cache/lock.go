@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Lock is the value stored under a lock name while the lock is held. The
+// lease is the item's expiration: once it passes, the lock is free again.
+type Lock struct {
+	Owner string `json:"owner"`
+	Token uint64 `json:"token"`
+}
+
+// ErrWrongType is returned when an operation targets a key that holds a
+// value of a different type (e.g. acquiring a lock on a plain string key).
+type ErrWrongType struct {
+	Key  string
+	Want string
+}
+
+func (e ErrWrongType) Error() string {
+	return fmt.Sprintf("key %q does not hold a %s value", e.Key, e.Want)
+}
+
+// AcquireLock takes the lock stored under name for owner with a lease of ttl.
+// If the lock is currently held by a live lease, acquired is false and the
+// current holder is returned, unless owner already holds it: a retried
+// acquire gets the existing lease and token back with acquired true. token is the fencing token to assign; callers
+// in distributed mode pass the Raft log index so every replica hands out the
+// same token. A zero token falls back to a local monotonic counter. now
+// decides whether the current lease has run out and starts the new one;
+// callers in distributed mode pass the proposer's clock so every replica
+// reaches the same decision.
+func (c *Cache) AcquireLock(name, owner string, ttl time.Duration, token uint64, now time.Time) (holder Lock, acquired bool, err error) {
+	c.logger.Debug("acquire lock", zap.String("name", name), zap.String("owner", owner))
+
+	if ttl <= 0 {
+		return Lock{}, false, fmt.Errorf("lock ttl must be positive")
+	}
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	if item, ok := c.liveItemAtLocked(name, now); ok {
+		l, isLock := item.value.(*Lock)
+		if !isLock {
+			return Lock{}, false, ErrWrongType{Key: name, Want: "lock"}
+		}
+		return *l, l.Owner == owner, nil
+	}
+
+	l := &Lock{Owner: owner, Token: c.nextFenceLocked(token)}
+	if err := c.storeLocked(name, l, now.Add(ttl)); err != nil {
+		return Lock{}, false, err
+	}
+	return *l, true, nil
+}
+
+// RenewLock extends the lease of the lock under name to ttl from now,
+// provided it is still held at now with the given fencing token. It returns
+// false once the lease has been lost (expired, released or taken over).
+func (c *Cache) RenewLock(name string, token uint64, ttl time.Duration, now time.Time) (bool, error) {
+	c.logger.Debug("renew lock", zap.String("name", name), zap.Uint64("token", token))
+
+	if ttl <= 0 {
+		return false, fmt.Errorf("lock ttl must be positive")
+	}
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	item, ok := c.liveItemAtLocked(name, now)
+	if !ok {
+		return false, nil
+	}
+	l, isLock := item.value.(*Lock)
+	if !isLock {
+		return false, ErrWrongType{Key: name, Want: "lock"}
+	}
+	if l.Token != token {
+		return false, nil
+	}
+
+	if idx, ok := c.expirationIndex[name]; ok {
+		heap.Remove(c.expirationHeap, idx)
+		delete(c.expirationIndex, name)
+	}
+	item.expiration = now.Add(ttl)
+	heap.Push(c.expirationHeap, &expirationEntry{
+		key:        name,
+		expiration: item.expiration,
+	})
+	metrics.UpdateExpirationHeapSize(c.expirationHeap.Len())
+	return true, nil
+}
+
+// ReleaseLock frees the lock under name if it is held at now with the given
+// fencing token. Releasing a lock that has already been lost is a no-op.
+func (c *Cache) ReleaseLock(name string, token uint64, now time.Time) (bool, error) {
+	c.logger.Debug("release lock", zap.String("name", name), zap.Uint64("token", token))
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	item, ok := c.liveItemAtLocked(name, now)
+	if !ok {
+		return false, nil
+	}
+	l, isLock := item.value.(*Lock)
+	if !isLock {
+		return false, ErrWrongType{Key: name, Want: "lock"}
+	}
+	if l.Token != token {
+		return false, nil
+	}
+	c.delLRU(name)
+	c.delInternal(name)
+	return true, nil
+}
+
+// LockLease returns the holder of the lock under name and when its lease
+// ends, if the lock is held at now. It does not modify the cache.
+func (c *Cache) LockLease(name string, now time.Time) (holder Lock, expiration time.Time, held bool) {
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
+
+	item, ok := c.items[name]
+	if !ok || (!item.expiration.IsZero() && now.After(item.expiration)) {
+		return Lock{}, time.Time{}, false
+	}
+	l, isLock := item.value.(*Lock)
+	if !isLock {
+		return Lock{}, time.Time{}, false
+	}
+	return *l, item.expiration, true
+}
+
+// liveItemLocked returns the item under key if it exists and has not
+// expired. An expired item is removed eagerly. Caller must hold c.mu write lock.
+func (c *Cache) liveItemLocked(key string) (*Item, bool) {
+	return c.liveItemAtLocked(key, time.Now())
+}
+
+// liveItemAtLocked is liveItemLocked with the expiry judged at now.
+func (c *Cache) liveItemAtLocked(key string, now time.Time) (*Item, bool) {
+	item, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if !item.expiration.IsZero() && now.After(item.expiration) {
+		c.delLRU(key)
+		c.delInternal(key)
+		return nil, false
+	}
+	return item, true
+}
+
+// raiseFenceLocked makes later fencing tokens greater than fence. Caller
+// must hold c.mu write lock.
+func (c *Cache) raiseFenceLocked(fence uint64) {
+	if fence > c.fenceSeq {
+		c.fenceSeq = fence
+	}
+}
+
+// nextFenceLocked returns the fencing token for a new lock holder. Tokens
+// never go backwards, even across Reset or when a replicated index is
+// lower than a locally generated one.
+func (c *Cache) nextFenceLocked(token uint64) uint64 {
+	if token <= c.fenceSeq {
+		token = c.fenceSeq + 1
+	}
+	c.fenceSeq = token
+	return token
+}
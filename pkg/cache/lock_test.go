@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockAcquireRenewRelease(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	t.Run("AcquireAndContend", func(t *testing.T) {
+		holder, ok, err := c.AcquireLock("lock:a", "alice", time.Minute, 0, time.Now())
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "alice", holder.Owner)
+		assert.Equal(t, uint64(1), holder.Token)
+
+		holder, ok, err = c.AcquireLock("lock:a", "bob", time.Minute, 0, time.Now())
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, "alice", holder.Owner)
+		assert.Equal(t, uint64(1), holder.Token)
+
+		// A retried acquire by the holder gets its lease back.
+		holder, ok, err = c.AcquireLock("lock:a", "alice", time.Minute, 0, time.Now())
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(1), holder.Token)
+	})
+
+	t.Run("RenewRequiresToken", func(t *testing.T) {
+		renewed, err := c.RenewLock("lock:a", 99, time.Minute, time.Now())
+		require.NoError(t, err)
+		assert.False(t, renewed)
+
+		renewed, err = c.RenewLock("lock:a", 1, time.Minute, time.Now())
+		require.NoError(t, err)
+		assert.True(t, renewed)
+	})
+
+	t.Run("ReleaseThenReacquire", func(t *testing.T) {
+		released, err := c.ReleaseLock("lock:a", 1, time.Now())
+		require.NoError(t, err)
+		assert.True(t, released)
+
+		holder, ok, err := c.AcquireLock("lock:a", "bob", time.Minute, 0, time.Now())
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(2), holder.Token)
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		require.NoError(t, c.Set("plain", "value", ""))
+		_, _, err := c.AcquireLock("plain", "alice", time.Minute, 0, time.Now())
+		assert.ErrorAs(t, err, &ErrWrongType{})
+	})
+}
+
+func TestLockLeaseExpiry(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	now := time.Now()
+	holder, ok, err := c.AcquireLock("lease", "alice", 50*time.Millisecond, 10, now)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, uint64(10), holder.Token)
+
+	renewed, err := c.RenewLock("lease", 10, time.Minute, now.Add(80*time.Millisecond))
+	require.NoError(t, err)
+	assert.False(t, renewed, "expired lease must not be renewable")
+
+	// A lower replicated index must never produce a smaller token.
+	holder, ok, err = c.AcquireLock("lease", "bob", time.Minute, 5, now.Add(80*time.Millisecond))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(11), holder.Token)
+}
+
+func TestLockLeaseUsesGivenClock(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	// The lease is judged by the clock the caller passes, not the local
+	// one, so replicas applying the same commands at different times agree.
+	proposed := time.Now().Add(time.Hour)
+	_, ok, err := c.AcquireLock("clock", "alice", time.Minute, 1, proposed)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	holder, ok, err := c.AcquireLock("clock", "bob", time.Minute, 2, proposed.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "alice", holder.Owner)
+	renewed, err := c.RenewLock("clock", 1, time.Minute, proposed.Add(50*time.Second))
+	require.NoError(t, err)
+	assert.True(t, renewed)
+
+	released, err := c.ReleaseLock("clock", 1, proposed.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.False(t, released, "the renewed lease ran out at the given clock")
+	holder, ok, err = c.AcquireLock("clock", "bob", time.Minute, 3, proposed.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), holder.Token)
+}
+
+func TestLockLease(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	now := time.Now().Add(time.Hour)
+	_, ok, err := c.AcquireLock("lease", "alice", time.Minute, 1, now)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	holder, expiration, held := c.LockLease("lease", now)
+	require.True(t, held)
+	assert.Equal(t, Lock{Owner: "alice", Token: 1}, holder)
+	assert.Equal(t, now.Add(time.Minute), expiration)
+
+	_, _, held = c.LockLease("lease", now.Add(2*time.Minute))
+	assert.False(t, held, "the lease has ended at the given clock")
+	_, _, held = c.LockLease("missing", now)
+	assert.False(t, held)
+	require.NoError(t, c.Set("plain", "v", ""))
+	_, _, held = c.LockLease("plain", now)
+	assert.False(t, held)
+}
+
+func TestLockSurvivesDumpLoad(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	_, ok, err := c.AcquireLock("persisted", "alice", time.Minute, 42, time.Now())
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	data, err := c.DumpToBytes("node1", "binary")
+	require.NoError(t, err)
+
+	restored := newTestCache()
+	defer restored.Close()
+	_, err = restored.LoadFromBytes("node1", data)
+	require.NoError(t, err)
+
+	holder, ok, err := restored.AcquireLock("persisted", "bob", time.Minute, 0, time.Now())
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(42), holder.Token)
+
+	released, err := restored.ReleaseLock("persisted", 42, time.Now())
+	require.NoError(t, err)
+	assert.True(t, released)
+	holder, _, err = restored.AcquireLock("persisted", "bob", time.Minute, 0, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(43), holder.Token)
+}
+
+func TestFenceSurvivesRestartWithoutLocks(t *testing.T) {
+	for _, format := range []string{"binary", "json"} {
+		t.Run(format, func(t *testing.T) {
+			c := newTestCache()
+			defer c.Close()
+			for i := 0; i < 3; i++ {
+				holder, ok, err := c.AcquireLock("job", "alice", time.Minute, 0, time.Now())
+				require.NoError(t, err)
+				require.True(t, ok)
+				released, err := c.ReleaseLock("job", holder.Token, time.Now())
+				require.NoError(t, err)
+				require.True(t, released)
+			}
+
+			// No lock is held when the dump is taken; the counter must
+			// still survive the restart.
+			path := filepath.Join(t.TempDir(), "restart.dump")
+			_, err := c.Dump("node1", format, path)
+			require.NoError(t, err)
+
+			restarted := newTestCache()
+			defer restarted.Close()
+			_, err = restarted.Load("node1", path)
+			require.NoError(t, err)
+			holder, ok, err := restarted.AcquireLock("job", "bob", time.Minute, 0, time.Now())
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, uint64(4), holder.Token, "fencing tokens never go back")
+
+			_, fence, err := restarted.ScanDump(path, nil, func(DumpEntry) error { return nil })
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), fence)
+		})
+	}
+}
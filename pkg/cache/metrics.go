@@ -26,6 +26,8 @@ func (c *Cache) updateSizeMetrics() {
 			return len(val)
 		case []byte:
 			return len(val)
+		case sizedValue:
+			return val.approxSize()
 		default:
 			// For unsupported types, use reflection to get type size
 			return int(reflect.TypeOf(v).Size())
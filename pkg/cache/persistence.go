@@ -1,12 +1,15 @@
 package cache
 
 import (
+	"bufio"
+	"bytes"
 	"container/heap"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"hash/crc32"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -14,14 +17,22 @@ import (
 
 	"github.com/armon/go-radix"
 	"github.com/lushenle/simple-cache/pkg/common"
+	"github.com/lushenle/simple-cache/pkg/encrypt"
 	"github.com/lushenle/simple-cache/pkg/metrics"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 const (
 	dumpMagic     = "SCDF"
-	dumpVersion   = 2
+	dumpVersion   = 4 // chunked, streamed
+	dumpVersionV3 = 3
+	dumpVersionV2 = 2
 	dumpVersionV1 = 1
+
+	// dumpJSONVersion is the version written to JSON dumps, whose layout
+	// did not change with the chunked binary format.
+	dumpJSONVersion = 3
 )
 
 // DumpEntry represents a single cache entry in the dump file.
@@ -29,6 +40,7 @@ type DumpEntry struct {
 	Key           string `json:"key"`
 	Value         string `json:"value"`
 	ValueType     string `json:"value_type"`
+	TypeURL       string `json:"type_url,omitempty"` // v3+, set for "any" values
 	Expiration    string `json:"expiration,omitempty"`
 	HasExpiration bool   `json:"has_expiration"`
 }
@@ -40,6 +52,7 @@ type DumpJSON struct {
 	DumpedAt    string      `json:"dumped_at"`
 	TotalKeys   int         `json:"total_keys"`
 	ExpiredKeys int         `json:"expired_keys"`
+	FenceSeq    uint64      `json:"fence_seq,omitempty"` // highest fencing token handed out
 	Entries     []DumpEntry `json:"entries"`
 }
 
@@ -63,10 +76,52 @@ type LoadResult struct {
 	DurationMs  float64
 }
 
+// UseKeyring encrypts dump files written by Dump with the active key of k
+// and lets Load decrypt dumps written with any key in k. Plaintext dumps
+// still load. It must be called before the cache is used.
+func (c *Cache) UseKeyring(k *encrypt.Keyring) {
+	c.keys = k
+}
+
+// SetDumpCompression makes binary dumps, including those written by DumpTo
+// for raft snapshots, compress their entries with flate. Load detects
+// compressed dumps from the header either way. It must be called before the
+// cache is used.
+func (c *Cache) SetDumpCompression(on bool) {
+	c.compressDumps = on
+}
+
+// DumpOptions narrows a dump.
+type DumpOptions struct {
+	// Filter limits the dump to matching keys; nil dumps every key.
+	Filter *KeyFilter
+}
+
+// LoadOptions controls how a dump is applied to the cache.
+type LoadOptions struct {
+	// Filter limits the load to matching keys in the dump; nil loads every
+	// key.
+	Filter *KeyFilter
+	// Merge writes the loaded keys over the existing ones and deletes
+	// nothing. Without it the load replaces the keys in scope: the whole
+	// cache, or only the keys matching Filter.
+	Merge bool
+}
+
 // Dump exports all cache data to a file.
 // format: "binary" or "json"
 // path: file path, empty means default (data/cache-{nodeID}.dump)
 func (c *Cache) Dump(nodeID, format, path string) (*DumpResult, error) {
+	return c.DumpWith(nodeID, format, path, DumpOptions{})
+}
+
+// DumpWith is Dump restricted by opts.
+func (c *Cache) DumpWith(nodeID, format, path string, opts DumpOptions) (*DumpResult, error) {
+	match, err := opts.Filter.matcher()
+	if err != nil {
+		metrics.IncPersistenceOp("dump", "error")
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
 	switch format {
 	case "":
 		format = common.DumpFormatBinary.String()
@@ -86,12 +141,6 @@ func (c *Cache) Dump(nodeID, format, path string) (*DumpResult, error) {
 		}
 	}
 
-	data, entries, expiredCount, err := c.dumpToBytes(nodeID, format)
-	if err != nil {
-		metrics.IncPersistenceOp("dump", "error")
-		return nil, fmt.Errorf("serialize dump data: %w", err)
-	}
-
 	// Atomic and durable write: write+fsync temp file, rename, then fsync directory
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
@@ -106,11 +155,27 @@ func (c *Cache) Dump(nodeID, format, path string) (*DumpResult, error) {
 		return nil, fmt.Errorf("create temp file: %w", err)
 	}
 
-	if _, err := tmpFile.Write(data); err != nil {
+	bw := bufio.NewWriterSize(tmpFile, 1<<20)
+	cw := &countingWriter{w: bw}
+	var stats dumpStats
+	if c.keys != nil {
+		var ew *encrypt.Writer
+		if ew, err = c.keys.NewWriter(cw); err == nil {
+			if stats, err = c.dumpTo(ew, nodeID, format, match); err == nil {
+				err = ew.Close()
+			}
+		}
+	} else {
+		stats, err = c.dumpTo(cw, nodeID, format, match)
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
 		_ = tmpFile.Close()
 		_ = os.Remove(tmpPath)
 		metrics.IncPersistenceOp("dump", "error")
-		return nil, fmt.Errorf("write temp file: %w", err)
+		return nil, fmt.Errorf("write dump data: %w", err)
 	}
 
 	if err := tmpFile.Sync(); err != nil {
@@ -150,61 +215,71 @@ func (c *Cache) Dump(nodeID, format, path string) (*DumpResult, error) {
 	durationMs := float64(time.Since(start).Microseconds()) / 1000.0
 	metrics.IncPersistenceOp("dump", "success")
 	metrics.ObservePersistenceDuration("dump", time.Since(start).Seconds())
-	metrics.SetDumpKeys(int64(len(entries)))
+	metrics.SetDumpKeys(int64(stats.keys))
 
 	c.logger.Info("cache dump completed",
 		zap.String("path", path),
 		zap.String("format", format),
-		zap.Int("total_keys", len(entries)),
-		zap.Int("expired_skipped", expiredCount),
+		zap.Int("total_keys", stats.keys),
+		zap.Int64("file_size", cw.n),
+		zap.Int("expired_skipped", stats.expired),
+		zap.Bool("encrypted", c.keys != nil),
+		zap.Bool("compressed", c.compressDumps && format == common.DumpFormatBinary.String()),
 		zap.Duration("duration", time.Since(start)),
 	)
 
 	return &DumpResult{
 		Success:    true,
-		TotalKeys:  int32(len(entries)),
-		FileSize:   int64(len(data)),
+		TotalKeys:  int32(stats.keys),
+		FileSize:   cw.n,
 		Path:       path,
 		Format:     format,
 		DurationMs: durationMs,
 	}, nil
 }
 
+// DumpTo streams a dump of all live keys to w.
+func (c *Cache) DumpTo(w io.Writer, nodeID, format string) error {
+	_, err := c.dumpTo(w, nodeID, format, nil)
+	return err
+}
+
 func (c *Cache) DumpToBytes(nodeID, format string) ([]byte, error) {
-	data, _, _, err := c.dumpToBytes(nodeID, format)
-	return data, err
+	var buf bytes.Buffer
+	if _, err := c.dumpTo(&buf, nodeID, format, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // Load imports cache data from a file.
 // path: file path, empty means auto-detect default (tries binary first, then json)
 func (c *Cache) Load(nodeID, path string) (*LoadResult, error) {
+	return c.LoadWith(nodeID, path, LoadOptions{})
+}
+
+// LoadWith is Load restricted and merged as set by opts.
+func (c *Cache) LoadWith(nodeID, path string, opts LoadOptions) (*LoadResult, error) {
 	c.logger.Info("starting cache load", zap.String("path", path))
 
 	start := time.Now()
 
 	// Auto-detect path if not specified
 	if path == "" {
-		binPath := fmt.Sprintf("data/cache-%s.dump", nodeID)
-		jsonPath := fmt.Sprintf("data/cache-%s.dump.json", nodeID)
-
-		// Prefer binary, fall back to json
-		if _, err := os.Stat(binPath); err == nil {
-			path = binPath
-		} else if _, err := os.Stat(jsonPath); err == nil {
-			path = jsonPath
-		} else {
+		if path = FindDefaultDump(nodeID); path == "" {
 			c.logger.Info("no dump file found, skipping load")
 			return &LoadResult{Success: true, Path: "none"}, nil
 		}
 	}
 
-	data, err := os.ReadFile(path)
+	f, err := os.Open(path)
 	if err != nil {
 		metrics.IncPersistenceOp("load", "error")
 		return nil, fmt.Errorf("read dump file: %w", err)
 	}
+	defer f.Close()
 
-	result, err := c.LoadFromBytes(nodeID, data)
+	result, err := c.loadFrom(f, nodeID, opts)
 	if err != nil {
 		metrics.IncPersistenceOp("load", "error")
 		return nil, err
@@ -216,6 +291,22 @@ func (c *Cache) Load(nodeID, path string) (*LoadResult, error) {
 	return result, nil
 }
 
+// FindDefaultDump returns the default dump file Load uses when no path is
+// given: the binary dump if present, else the JSON dump, else "".
+func FindDefaultDump(nodeID string) string {
+	binPath := fmt.Sprintf("data/cache-%s.dump", nodeID)
+	jsonPath := fmt.Sprintf("data/cache-%s.dump.json", nodeID)
+
+	// Prefer binary, fall back to json
+	if _, err := os.Stat(binPath); err == nil {
+		return binPath
+	}
+	if _, err := os.Stat(jsonPath); err == nil {
+		return jsonPath
+	}
+	return ""
+}
+
 // DefaultDumpPath returns the default dump file path for the given nodeID and format.
 func DefaultDumpPath(nodeID, format, dataDir string) string {
 	if dataDir == "" {
@@ -228,119 +319,171 @@ func DefaultDumpPath(nodeID, format, dataDir string) string {
 	return path
 }
 
-func (c *Cache) dumpToBytes(nodeID, format string) ([]byte, []DumpEntry, int, error) {
-	c.mu.Lock(metrics.LockWrite)
-	defer c.mu.Unlock()
+type dumpStats struct {
+	keys    int
+	expired int
+}
+
+// dumpItem is a point-in-time copy of an item taken for a dump.
+type dumpItem struct {
+	key        string
+	value      any
+	expiration time.Time
+}
+
+// snapshotValue is implemented by structured value types that are mutated
+// in place. snapshot returns a copy that later writes cannot change.
+type snapshotValue interface {
+	snapshot() any
+}
+
+// captureForDump copies the live items accepted by match (all if nil) and
+// the fencing counter under the read lock. Plain values are immutable once
+// stored and are shared; structured values are copied through
+// snapshotValue. Writers are only blocked for the copy, not for sorting,
+// serialization or I/O.
+func (c *Cache) captureForDump(match func(string) bool) *DumpCapture {
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
 
-	entries := make([]DumpEntry, 0, len(c.items))
 	now := time.Now()
-	expiredCount := 0
+	expired := 0
+	items := make([]dumpItem, 0, len(c.items))
 	for key, item := range c.items {
-		if !item.expiration.IsZero() && now.After(item.expiration) {
-			expiredCount++
+		if match != nil && !match(key) {
 			continue
 		}
-
-		val, valType := serializeValue(item.value)
-		entry := DumpEntry{
-			Key:           key,
-			Value:         val,
-			ValueType:     valType,
-			HasExpiration: !item.expiration.IsZero(),
+		if !item.expiration.IsZero() && now.After(item.expiration) {
+			expired++
+			continue
 		}
-		if entry.HasExpiration {
-			entry.Expiration = item.expiration.UTC().Format(time.RFC3339Nano)
+		value := item.value
+		if sv, ok := value.(snapshotValue); ok {
+			value = sv.snapshot()
 		}
-		entries = append(entries, entry)
+		items = append(items, dumpItem{key: key, value: value, expiration: item.expiration})
 	}
+	return &DumpCapture{c: c, items: items, expired: expired, fence: c.fenceSeq}
+}
 
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Key < entries[j].Key
-	})
+// DumpCapture is a point-in-time copy of the cache, taken by CaptureDump
+// and written out later by WriteTo.
+type DumpCapture struct {
+	c       *Cache
+	items   []dumpItem
+	expired int
+	// fence is the fencing counter, saved so that tokens handed out after
+	// a restart never go backwards, even if no lock is held.
+	fence uint64
+}
 
-	var data []byte
-	var err error
-	switch format {
-	case common.DumpFormatJSON.String():
-		dump := DumpJSON{
-			Version:     dumpVersion,
-			NodeID:      nodeID,
-			DumpedAt:    time.Now().UTC().Format(time.RFC3339Nano),
-			TotalKeys:   len(entries),
-			ExpiredKeys: expiredCount,
-			Entries:     entries,
-		}
-		data, err = json.MarshalIndent(dump, "", "  ")
-	default:
-		data, err = encodeBinaryDump(entries)
-	}
-	return data, entries, expiredCount, err
+// CaptureDump copies the live entries without serializing them, so a
+// caller can pin the dump to a point in its own sequence of writes and do
+// the slow part afterwards.
+func (c *Cache) CaptureDump() *DumpCapture {
+	return c.captureForDump(nil)
 }
 
-func (c *Cache) LoadFromBytes(nodeID string, data []byte) (*LoadResult, error) {
-	var entries []DumpEntry
-	format := "unknown"
+// WriteTo writes the captured entries to w as a dump.
+func (d *DumpCapture) WriteTo(w io.Writer, nodeID, format string) error {
+	_, err := d.writeTo(w, nodeID, format)
+	return err
+}
 
-	if len(data) >= 4 && string(data[:4]) == dumpMagic {
-		var err error
-		entries, err = decodeBinaryDump(data)
-		if err != nil {
-			return nil, fmt.Errorf("parse dump file: %w", err)
-		}
-		format = common.DumpFormatBinary.String()
-	} else {
-		var dump DumpJSON
-		if err := json.Unmarshal(data, &dump); err != nil {
-			return nil, fmt.Errorf("parse dump file: %w", err)
+// writeTo writes the entries to w in key order. Each entry is serialized
+// as it is written.
+func (d *DumpCapture) writeTo(w io.Writer, nodeID, format string) (dumpStats, error) {
+	sort.Slice(d.items, func(i, j int) bool { return d.items[i].key < d.items[j].key })
+	stats := dumpStats{keys: len(d.items), expired: d.expired}
+
+	dw, err := newDumpWriter(w, format, nodeID, len(d.items), d.fence, d.c.compressDumps)
+	if err != nil {
+		return stats, err
+	}
+	for _, it := range d.items {
+		if err := dw.writeEntry(newDumpEntry(it)); err != nil {
+			return stats, err
 		}
-		entries = dump.Entries
-		format = common.DumpFormatJSON.String()
 	}
+	return stats, dw.close(stats.expired)
+}
 
-	c.mu.Lock(metrics.LockWrite)
-	defer c.mu.Unlock()
+// dumpTo writes a consistent point-in-time view of the live entries to w.
+func (c *Cache) dumpTo(w io.Writer, nodeID, format string, match func(string) bool) (dumpStats, error) {
+	return c.captureForDump(match).writeTo(w, nodeID, format)
+}
 
-	c.resetLRU()
-	c.items = make(map[string]*Item)
-	c.prefixTree = radix.New()
-	c.expirationHeap = &ExpirationHeap{onSwap: c.expirationHeap.onSwap}
-	heap.Init(c.expirationHeap)
-	c.expirationIndex = make(map[string]int)
+func newDumpEntry(item dumpItem) DumpEntry {
+	val, valType := serializeValue(item.value)
+	entry := DumpEntry{
+		Key:           item.key,
+		Value:         val,
+		ValueType:     valType,
+		HasExpiration: !item.expiration.IsZero(),
+	}
+	if a, ok := item.value.(*anypb.Any); ok {
+		entry.TypeURL = a.GetTypeUrl()
+	}
+	if entry.HasExpiration {
+		entry.Expiration = item.expiration.UTC().Format(time.RFC3339Nano)
+	}
+	return entry
+}
+
+func (c *Cache) LoadFromBytes(nodeID string, data []byte) (*LoadResult, error) {
+	return c.LoadFrom(bytes.NewReader(data), nodeID)
+}
+
+// LoadFrom replaces the cache contents with a dump streamed from r. The
+// dump is decoded and verified before the write lock is taken, so a
+// corrupt or truncated dump leaves the cache untouched.
+func (c *Cache) LoadFrom(r io.Reader, nodeID string) (*LoadResult, error) {
+	return c.loadFrom(r, nodeID, LoadOptions{})
+}
 
+func (c *Cache) loadFrom(r io.Reader, nodeID string, opts LoadOptions) (*LoadResult, error) {
+	match, err := opts.Filter.matcher()
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
 	now := time.Now()
-	loaded := 0
+	staged := make(map[string]*Item)
+	var fence uint64
+	total := 0
 	skipped := 0
-	for _, entry := range entries {
-		var expiration time.Time
-		if entry.HasExpiration && entry.Expiration != "" {
-			exp, err := time.Parse(time.RFC3339Nano, entry.Expiration)
-			if err != nil {
-				c.logger.Warn("skip entry with invalid expiration", zap.String("key", entry.Key), zap.Error(err))
-				skipped++
-				continue
-			}
-			expiration = exp
-			if now.After(expiration) {
-				skipped++
-				continue
-			}
+	format, dumpFence, err := readDump(r, c.keys, func(entry DumpEntry) error {
+		if match != nil && !match(entry.Key) {
+			return nil
 		}
+		total++
+		item, ok := c.stageItem(entry, now)
+		if !ok {
+			skipped++
+			return nil
+		}
+		if l, ok := item.value.(*Lock); ok && l.Token > fence {
+			fence = l.Token
+		}
+		staged[entry.Key] = item
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parse dump file: %w", err)
+	}
 
-		value := deserializeValue(entry.Value, entry.ValueType)
-		c.setInternal(entry.Key, &Item{
-			value:      value,
-			expiration: expiration,
-		})
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
 
-		if !expiration.IsZero() {
-			heap.Push(c.expirationHeap, &expirationEntry{
-				key:        entry.Key,
-				expiration: expiration,
-			})
+	c.raiseFenceLocked(max(fence, dumpFence))
+	if match == nil && !opts.Merge {
+		c.replaceAllLocked(staged)
+	} else {
+		if !opts.Merge {
+			c.deleteMatchingLocked(match)
 		}
-
-		loaded++
+		c.mergeLocked(staged)
 	}
+	loaded := len(staged)
 
 	metrics.UpdateKeysTotal(len(c.items))
 	metrics.UpdateExpirationHeapSize(c.expirationHeap.Len())
@@ -352,61 +495,108 @@ func (c *Cache) LoadFromBytes(nodeID string, data []byte) (*LoadResult, error) {
 		zap.String("format", format),
 		zap.Int("loaded", loaded),
 		zap.Int("skipped", skipped),
+		zap.Bool("filtered", match != nil),
+		zap.Bool("merge", opts.Merge),
 	)
 
 	return &LoadResult{
 		Success:     true,
-		TotalKeys:   int32(len(entries)),
+		TotalKeys:   int32(total),
 		LoadedKeys:  int32(loaded),
 		SkippedKeys: int32(skipped),
 		Path:        "memory",
 	}, nil
 }
 
+// stageItem turns a dump entry into an item. ok is false for entries that
+// have expired by now or carry an invalid expiration.
+func (c *Cache) stageItem(entry DumpEntry, now time.Time) (*Item, bool) {
+	var expiration time.Time
+	if entry.HasExpiration && entry.Expiration != "" {
+		exp, err := time.Parse(time.RFC3339Nano, entry.Expiration)
+		if err != nil {
+			c.logger.Warn("skip entry with invalid expiration", zap.String("key", entry.Key), zap.Error(err))
+			return nil, false
+		}
+		if now.After(exp) {
+			return nil, false
+		}
+		expiration = exp
+	}
+	return &Item{value: deserializeEntryValue(entry), expiration: expiration}, true
+}
+
+// mergeLocked writes staged over the existing keys. Caller must hold c.mu
+// write lock.
+func (c *Cache) mergeLocked(staged map[string]*Item) {
+	for key, item := range staged {
+		if _, exists := c.items[key]; exists {
+			c.delLRU(key)
+			c.delInternal(key)
+		}
+		if !item.expiration.IsZero() {
+			heap.Push(c.expirationHeap, &expirationEntry{
+				key:        key,
+				expiration: item.expiration,
+			})
+		}
+		c.setInternal(key, item)
+		c.setLRU(key)
+	}
+}
+
+// deleteMatchingLocked deletes every key accepted by match and returns how
+// many were deleted. Caller must hold c.mu write lock.
+func (c *Cache) deleteMatchingLocked(match func(string) bool) int {
+	deleted := 0
+	for key := range c.items {
+		if match(key) {
+			c.delLRU(key)
+			c.delInternal(key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// replaceAllLocked swaps the whole keyspace for staged. Caller must hold
+// c.mu write lock.
+func (c *Cache) replaceAllLocked(staged map[string]*Item) {
+	c.resetLRU()
+	c.items = make(map[string]*Item, len(staged))
+	c.prefixTree = radix.New()
+	c.expirationHeap = &ExpirationHeap{onSwap: c.expirationHeap.onSwap}
+	heap.Init(c.expirationHeap)
+	c.expirationIndex = make(map[string]int)
+
+	for key, item := range staged {
+		c.setInternal(key, item)
+		if !item.expiration.IsZero() {
+			heap.Push(c.expirationHeap, &expirationEntry{
+				key:        key,
+				expiration: item.expiration,
+			})
+		}
+	}
+}
+
 // --- Binary format encoding/decoding ---
 
+// encodeBinaryDump writes entries in the single-buffer v3 layout: header,
+// entries, then one CRC32 over everything. Dump now writes the chunked v4
+// layout (see dumpio.go); v3 remains readable.
 func encodeBinaryDump(entries []DumpEntry) ([]byte, error) {
 	buf := make([]byte, 0, 16+len(entries)*100)
 
 	// Header
 	buf = append(buf, dumpMagic...)
-	buf = binary.BigEndian.AppendUint32(buf, dumpVersion)
+	buf = binary.BigEndian.AppendUint32(buf, dumpVersionV3)
 	buf = binary.BigEndian.AppendUint32(buf, uint32(len(entries)))
 	buf = binary.BigEndian.AppendUint32(buf, 0) // flags
 
 	// Entries
 	for _, e := range entries {
-		// Key
-		keyBytes := []byte(e.Key)
-		buf = binary.BigEndian.AppendUint32(buf, uint32(len(keyBytes)))
-		buf = append(buf, keyBytes...)
-
-		// Value
-		valBytes := []byte(e.Value)
-		buf = binary.BigEndian.AppendUint32(buf, uint32(len(valBytes)))
-		buf = append(buf, valBytes...)
-
-		// Expiration
-		var expUnix int64
-		if e.HasExpiration && e.Expiration != "" {
-			t, err := time.Parse(time.RFC3339Nano, e.Expiration)
-			if err == nil {
-				expUnix = t.UnixNano()
-			}
-		}
-		buf = binary.BigEndian.AppendUint64(buf, uint64(expUnix))
-
-		// HasExpiration
-		if e.HasExpiration {
-			buf = append(buf, 1)
-		} else {
-			buf = append(buf, 0)
-		}
-
-		// ValueType (v2+)
-		vtBytes := []byte(e.ValueType)
-		buf = binary.BigEndian.AppendUint32(buf, uint32(len(vtBytes)))
-		buf = append(buf, vtBytes...)
+		buf = appendDumpEntry(buf, e)
 	}
 
 	// Footer: CRC32
@@ -417,6 +607,43 @@ func encodeBinaryDump(entries []DumpEntry) ([]byte, error) {
 	return buf, nil
 }
 
+// appendDumpEntry appends one entry in the v3+ entry layout.
+func appendDumpEntry(buf []byte, e DumpEntry) []byte {
+	// Key
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(e.Key)))
+	buf = append(buf, e.Key...)
+
+	// Value
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(e.Value)))
+	buf = append(buf, e.Value...)
+
+	// Expiration
+	var expUnix int64
+	if e.HasExpiration && e.Expiration != "" {
+		t, err := time.Parse(time.RFC3339Nano, e.Expiration)
+		if err == nil {
+			expUnix = t.UnixNano()
+		}
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(expUnix))
+
+	// HasExpiration
+	if e.HasExpiration {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+
+	// ValueType (v2+)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(e.ValueType)))
+	buf = append(buf, e.ValueType...)
+
+	// TypeURL (v3+)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(e.TypeURL)))
+	buf = append(buf, e.TypeURL...)
+	return buf
+}
+
 func decodeBinaryDump(data []byte) ([]DumpEntry, error) {
 	if len(data) < 24 {
 		return nil, fmt.Errorf("file too small: %d bytes", len(data))
@@ -428,7 +655,7 @@ func decodeBinaryDump(data []byte) ([]DumpEntry, error) {
 	}
 
 	version := binary.BigEndian.Uint32(data[4:8])
-	if version != dumpVersion && version != dumpVersionV1 {
+	if version != dumpVersionV3 && version != dumpVersionV2 && version != dumpVersionV1 {
 		return nil, fmt.Errorf("unsupported version: %d", version)
 	}
 
@@ -451,76 +678,11 @@ func decodeBinaryDump(data []byte) ([]DumpEntry, error) {
 	offset := 16
 
 	for i := uint32(0); i < count; i++ {
-		// Key
-		if offset+4 > footerStart {
-			return nil, fmt.Errorf("truncated dump at entry %d key length", i)
-		}
-		keyLen := binary.BigEndian.Uint32(data[offset : offset+4])
-		offset += 4
-		if offset+int(keyLen) > footerStart {
-			return nil, fmt.Errorf("truncated dump at entry %d key data", i)
-		}
-		key := string(data[offset : offset+int(keyLen)])
-		offset += int(keyLen)
-
-		// Value
-		if offset+4 > footerStart {
-			return nil, fmt.Errorf("truncated dump at entry %d value length", i)
-		}
-		valLen := binary.BigEndian.Uint32(data[offset : offset+4])
-		offset += 4
-		if offset+int(valLen) > footerStart {
-			return nil, fmt.Errorf("truncated dump at entry %d value data", i)
-		}
-		value := string(data[offset : offset+int(valLen)])
-		offset += int(valLen)
-
-		// Expiration
-		if offset+8 > footerStart {
-			return nil, fmt.Errorf("truncated dump at entry %d expiration", i)
-		}
-		expUnix := int64(binary.BigEndian.Uint64(data[offset : offset+8]))
-		offset += 8
-
-		// HasExpiration
-		if offset+1 > footerStart {
-			return nil, fmt.Errorf("truncated dump at entry %d expiration flag", i)
-		}
-		var hasExp bool
-		switch data[offset] {
-		case 0:
-			hasExp = false
-		case 1:
-			hasExp = true
-		default:
-			return nil, fmt.Errorf("invalid expiration flag at entry %d: %d", i, data[offset])
-		}
-		offset += 1
-
-		valueType := "string" // default for v1
-		if version >= dumpVersion {
-			// ValueType (v2+)
-			if offset+4 > footerStart {
-				return nil, fmt.Errorf("truncated dump at entry %d value type length", i)
-			}
-			vtLen := binary.BigEndian.Uint32(data[offset : offset+4])
-			offset += 4
-			if offset+int(vtLen) > footerStart {
-				return nil, fmt.Errorf("truncated dump at entry %d value type data", i)
-			}
-			valueType = string(data[offset : offset+int(vtLen)])
-			offset += int(vtLen)
-		}
-
-		entry := DumpEntry{
-			Key:           key,
-			Value:         value,
-			ValueType:     valueType,
-			HasExpiration: hasExp,
-		}
-		if hasExp && expUnix != 0 {
-			entry.Expiration = time.Unix(0, expUnix).UTC().Format(time.RFC3339Nano)
+		entry, next, err := decodeDumpEntry(data[:footerStart], offset, version, i)
+		if err != nil {
+			return nil, err
 		}
+		offset = next
 		entries = append(entries, entry)
 	}
 
@@ -534,6 +696,100 @@ func decodeBinaryDump(data []byte) ([]DumpEntry, error) {
 	return entries, nil
 }
 
+// decodeDumpEntry decodes entry i starting at offset and returns the offset
+// just past it. Fields present depend on the format version.
+func decodeDumpEntry(data []byte, offset int, version uint32, i uint32) (DumpEntry, int, error) {
+	end := len(data)
+
+	// Key
+	if offset+4 > end {
+		return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d key length", i)
+	}
+	keyLen := binary.BigEndian.Uint32(data[offset : offset+4])
+	offset += 4
+	if offset+int(keyLen) > end {
+		return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d key data", i)
+	}
+	key := string(data[offset : offset+int(keyLen)])
+	offset += int(keyLen)
+
+	// Value
+	if offset+4 > end {
+		return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d value length", i)
+	}
+	valLen := binary.BigEndian.Uint32(data[offset : offset+4])
+	offset += 4
+	if offset+int(valLen) > end {
+		return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d value data", i)
+	}
+	value := string(data[offset : offset+int(valLen)])
+	offset += int(valLen)
+
+	// Expiration
+	if offset+8 > end {
+		return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d expiration", i)
+	}
+	expUnix := int64(binary.BigEndian.Uint64(data[offset : offset+8]))
+	offset += 8
+
+	// HasExpiration
+	if offset+1 > end {
+		return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d expiration flag", i)
+	}
+	var hasExp bool
+	switch data[offset] {
+	case 0:
+		hasExp = false
+	case 1:
+		hasExp = true
+	default:
+		return DumpEntry{}, 0, fmt.Errorf("invalid expiration flag at entry %d: %d", i, data[offset])
+	}
+	offset += 1
+
+	valueType := "string" // default for v1
+	if version >= dumpVersionV2 {
+		// ValueType (v2+)
+		if offset+4 > end {
+			return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d value type length", i)
+		}
+		vtLen := binary.BigEndian.Uint32(data[offset : offset+4])
+		offset += 4
+		if offset+int(vtLen) > end {
+			return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d value type data", i)
+		}
+		valueType = string(data[offset : offset+int(vtLen)])
+		offset += int(vtLen)
+	}
+
+	var typeURL string
+	if version >= dumpVersionV3 {
+		// TypeURL (v3+)
+		if offset+4 > end {
+			return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d type url length", i)
+		}
+		tuLen := binary.BigEndian.Uint32(data[offset : offset+4])
+		offset += 4
+		if offset+int(tuLen) > end {
+			return DumpEntry{}, 0, fmt.Errorf("truncated dump at entry %d type url data", i)
+		}
+		typeURL = string(data[offset : offset+int(tuLen)])
+		offset += int(tuLen)
+	}
+
+	entry := DumpEntry{
+		Key:           key,
+		Value:         value,
+		ValueType:     valueType,
+		TypeURL:       typeURL,
+		HasExpiration: hasExp,
+	}
+	if hasExp && expUnix != 0 {
+		entry.Expiration = time.Unix(0, expUnix).UTC().Format(time.RFC3339Nano)
+	}
+	return entry, offset, nil
+}
+
 // --- Value serialization helpers ---
 
 func serializeValue(v any) (string, string) {
@@ -545,6 +801,30 @@ func serializeValue(v any) (string, string) {
 		return val, "string"
 	case []byte:
 		return base64.StdEncoding.EncodeToString(val), "bytes"
+	case *anypb.Any:
+		// The type URL is stored separately in DumpEntry.TypeURL.
+		return base64.StdEncoding.EncodeToString(val.GetValue()), "any"
+	case *Lock:
+		b, _ := json.Marshal(val)
+		return string(b), "lock"
+	case *Stream:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val), "other"
+		}
+		return string(b), "stream"
+	case *BloomFilter:
+		b, _ := val.MarshalBinary()
+		return base64.StdEncoding.EncodeToString(b), "bloom"
+	case *HyperLogLog:
+		b, _ := val.MarshalBinary()
+		return base64.StdEncoding.EncodeToString(b), "hll"
+	case *JSONDoc:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val), "other"
+		}
+		return string(b), "jsondoc"
 	default:
 		// Try JSON marshal for complex types
 		b, err := json.Marshal(val)
@@ -555,6 +835,19 @@ func serializeValue(v any) (string, string) {
 	}
 }
 
+// deserializeEntryValue restores an entry's value, using the type URL for
+// protobuf Any values written by v3+ dumps.
+func deserializeEntryValue(e DumpEntry) any {
+	if e.ValueType != "any" {
+		return deserializeValue(e.Value, e.ValueType)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(e.Value)
+	if err != nil {
+		return e.Value
+	}
+	return &anypb.Any{TypeUrl: e.TypeURL, Value: decoded}
+}
+
 func deserializeValue(data, valueType string) any {
 	switch valueType {
 	case "nil":
@@ -571,6 +864,44 @@ func deserializeValue(data, valueType string) any {
 			return data
 		}
 		return v
+	case "lock":
+		var l Lock
+		if err := json.Unmarshal([]byte(data), &l); err != nil {
+			return data
+		}
+		return &l
+	case "stream":
+		var s Stream
+		if err := json.Unmarshal([]byte(data), &s); err != nil {
+			return data
+		}
+		return &s
+	case "bloom":
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return data
+		}
+		var b BloomFilter
+		if err := b.UnmarshalBinary(decoded); err != nil {
+			return data
+		}
+		return &b
+	case "hll":
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return data
+		}
+		var h HyperLogLog
+		if err := h.UnmarshalBinary(decoded); err != nil {
+			return data
+		}
+		return &h
+	case "jsondoc":
+		var d JSONDoc
+		if err := json.Unmarshal([]byte(data), &d); err != nil {
+			return data
+		}
+		return &d
 	default:
 		return data
 	}
@@ -10,9 +10,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lushenle/simple-cache/pkg/encrypt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestDumpAndLoadBinary(t *testing.T) {
@@ -81,7 +86,7 @@ func TestDumpAndLoadJSON(t *testing.T) {
 	// Verify JSON is valid and readable
 	data, err := os.ReadFile(path)
 	require.NoError(t, err)
-	assert.Contains(t, string(data), `"version": 2`)
+	assert.Contains(t, string(data), `"version": 3`)
 	assert.Contains(t, string(data), `"node_id": "node1"`)
 	assert.Contains(t, string(data), `"key1"`)
 	assert.Contains(t, string(data), `"key2"`)
@@ -356,3 +361,258 @@ func TestDecodeBinaryDumpV1Compatibility(t *testing.T) {
 		assert.False(t, decoded[i].HasExpiration)
 	}
 }
+
+func TestDumpAndLoadPreservesAnyValues(t *testing.T) {
+	for _, format := range []string{"binary", "json"} {
+		t.Run(format, func(t *testing.T) {
+			c := newTestCache()
+			defer c.Close()
+
+			str, err := anypb.New(wrapperspb.String("hello"))
+			require.NoError(t, err)
+			st, err := structpb.NewStruct(map[string]any{"a": 1.5, "b": "x"})
+			require.NoError(t, err)
+			obj, err := anypb.New(st)
+			require.NoError(t, err)
+			require.NoError(t, c.Set("str", str, ""))
+			require.NoError(t, c.Set("obj", obj, "1h"))
+
+			data, err := c.DumpToBytes("node1", format)
+			require.NoError(t, err)
+			restored := newTestCache()
+			defer restored.Close()
+			_, err = restored.LoadFromBytes("node1", data)
+			require.NoError(t, err)
+
+			for key, want := range map[string]*anypb.Any{"str": str, "obj": obj} {
+				v, found := restored.Get(key)
+				require.True(t, found, key)
+				got, ok := v.(*anypb.Any)
+				require.True(t, ok, "expected *anypb.Any for %s, got %T", key, v)
+				assert.True(t, proto.Equal(want, got), key)
+			}
+		})
+	}
+}
+
+func TestDecodeBinaryDumpV2Compatibility(t *testing.T) {
+	// v2 entries end with the value type and have no type URL.
+	buf := make([]byte, 0, 128)
+	buf = append(buf, []byte("SCDF")...)
+	buf = binary.BigEndian.AppendUint32(buf, 2) // version = 2
+	buf = binary.BigEndian.AppendUint32(buf, 1) // count
+	buf = binary.BigEndian.AppendUint32(buf, 0) // flags
+	for _, field := range []string{"k", `{"x":1}`} {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(field)))
+		buf = append(buf, field...)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, 0)
+	buf = append(buf, 0)
+	buf = binary.BigEndian.AppendUint32(buf, 4)
+	buf = append(buf, "json"...)
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+	buf = binary.BigEndian.AppendUint32(buf, 0)
+
+	entries, err := decodeBinaryDump(buf)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "json", entries[0].ValueType)
+	assert.Empty(t, entries[0].TypeURL)
+}
+
+// stallWriter blocks its first Write until release is closed.
+type stallWriter struct {
+	started chan struct{}
+	release chan struct{}
+	buf     []byte
+}
+
+func (w *stallWriter) Write(p []byte) (int, error) {
+	if w.started != nil {
+		close(w.started)
+		w.started = nil
+		<-w.release
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+func TestDumpDoesNotBlockWriters(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	require.NoError(t, c.Set("old", "v", ""))
+	_, err := c.BloomAdd("bf", []string{"before"})
+	require.NoError(t, err)
+	require.NoError(t, c.JSONSet("doc", "$", `{"a":1,"list":[1]}`))
+	_, err = c.StreamAdd("events", map[string]string{"n": "1"}, time.Now(), 0, 0)
+	require.NoError(t, err)
+
+	w := &stallWriter{started: make(chan struct{}), release: make(chan struct{})}
+	started := w.started
+	dumped := make(chan error, 1)
+	go func() { dumped <- c.DumpTo(w, "node1", "binary") }()
+	<-started
+
+	// The dump is stalled on I/O; writes must still go through.
+	wrote := make(chan error, 1)
+	go func() {
+		if err := c.Set("new", "v", ""); err != nil {
+			wrote <- err
+			return
+		}
+		if _, err := c.BloomAdd("bf", []string{"after"}); err != nil {
+			wrote <- err
+			return
+		}
+		if err := c.JSONSet("doc", "$.list[0]", "2"); err != nil {
+			wrote <- err
+			return
+		}
+		if err := c.JSONSet("doc", "$.a", "3"); err != nil {
+			wrote <- err
+			return
+		}
+		_, err := c.StreamAdd("events", map[string]string{"n": "2"}, time.Now(), 0, 0)
+		wrote <- err
+	}()
+	select {
+	case err := <-wrote:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("writes blocked by an in-progress dump")
+	}
+	close(w.release)
+	require.NoError(t, <-dumped)
+
+	// The dump reflects the moment it started.
+	restored := newTestCache()
+	defer restored.Close()
+	_, err = restored.LoadFromBytes("node1", w.buf)
+	require.NoError(t, err)
+	_, found := restored.Get("new")
+	assert.False(t, found)
+	exists, err := restored.BloomExists("bf", []string{"before", "after"})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, exists)
+	doc, _, err := restored.JSONGet("doc", "$")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":1,"list":[1]}`, doc)
+	entries, err := restored.StreamRead("events", StreamID{}, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestDumpAndLoadEncrypted(t *testing.T) {
+	const (
+		oldKey = "2024:000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+		newKey = "2025:1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+	)
+	old, err := encrypt.ParseKeyring(oldKey)
+	require.NoError(t, err)
+	c := New(time.Minute, zap.NewNop())
+	defer c.Close()
+	c.UseKeyring(old)
+	require.NoError(t, c.Set("pii", "alice@example.com", ""))
+
+	dir := t.TempDir()
+	for _, format := range []string{"binary", "json"} {
+		path := filepath.Join(dir, "cache-node1.dump."+format)
+		_, err := c.Dump("node1", format, path)
+		require.NoError(t, err)
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.True(t, encrypt.IsEncrypted(raw), format)
+		assert.NotContains(t, string(raw), "alice@example.com", format)
+
+		// After rotation the old key still decrypts.
+		rotated, err := encrypt.ParseKeyring(newKey + "\n" + oldKey)
+		require.NoError(t, err)
+		c2 := New(time.Minute, zap.NewNop())
+		c2.UseKeyring(rotated)
+		_, err = c2.Load("node1", path)
+		require.NoError(t, err, format)
+		val, found := c2.Get("pii")
+		assert.True(t, found)
+		assert.Equal(t, "alice@example.com", val)
+		c2.Close()
+
+		plain := New(time.Minute, zap.NewNop())
+		_, err = plain.Load("node1", path)
+		assert.ErrorIs(t, err, encrypt.ErrNoKey, format)
+		plain.Close()
+	}
+
+	// Plaintext dumps written before encryption was enabled still load.
+	plain := New(time.Minute, zap.NewNop())
+	defer plain.Close()
+	require.NoError(t, plain.Set("k", "v", ""))
+	path := filepath.Join(dir, "plain.dump")
+	_, err = plain.Dump("node1", "binary", path)
+	require.NoError(t, err)
+	_, err = c.Load("node1", path)
+	require.NoError(t, err)
+	val, found := c.Get("k")
+	assert.True(t, found)
+	assert.Equal(t, "v", val)
+}
+
+func TestDumpAndLoadFilteredByPattern(t *testing.T) {
+	src := New(time.Minute, zap.NewNop())
+	defer src.Close()
+	for _, k := range []string{"tenant-a:1", "tenant-a:2", "tenant-b:1"} {
+		require.NoError(t, src.Set(k, "src-"+k, "1h"))
+	}
+	path := filepath.Join(t.TempDir(), "tenant-a.dump")
+	result, err := src.DumpWith("node1", "binary", path, DumpOptions{Filter: &KeyFilter{Pattern: "tenant-a:*"}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), result.TotalKeys)
+	_, err = src.DumpWith("node1", "binary", path, DumpOptions{Filter: &KeyFilter{Pattern: "(", UseRegex: true}})
+	assert.ErrorContains(t, err, "invalid pattern")
+
+	newDst := func() *Cache {
+		dst := New(time.Minute, zap.NewNop())
+		t.Cleanup(dst.Close)
+		for _, k := range []string{"tenant-a:1", "tenant-a:stale", "tenant-b:1"} {
+			require.NoError(t, dst.Set(k, "dst-"+k, ""))
+		}
+		return dst
+	}
+	get := func(c *Cache, key string) any {
+		v, _ := c.Get(key)
+		return v
+	}
+
+	// Replace within the filter: stale tenant-a keys go, tenant-b is untouched.
+	dst := newDst()
+	loaded, err := dst.LoadWith("node1", path, LoadOptions{Filter: &KeyFilter{Pattern: `^tenant-a:\d$`, UseRegex: true}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), loaded.LoadedKeys)
+	assert.Equal(t, "src-tenant-a:1", get(dst, "tenant-a:1"))
+	assert.Equal(t, "src-tenant-a:2", get(dst, "tenant-a:2"))
+	assert.Equal(t, "dst-tenant-a:stale", get(dst, "tenant-a:stale")) // outside the regex
+	assert.Equal(t, "dst-tenant-b:1", get(dst, "tenant-b:1"))
+
+	dst = newDst()
+	_, err = dst.LoadWith("node1", path, LoadOptions{Filter: &KeyFilter{Pattern: "tenant-a:*"}})
+	require.NoError(t, err)
+	assert.Nil(t, get(dst, "tenant-a:stale"))
+	assert.Equal(t, "dst-tenant-b:1", get(dst, "tenant-b:1"))
+
+	// Merge overwrites loaded keys and deletes nothing.
+	dst = newDst()
+	loaded, err = dst.LoadWith("node1", path, LoadOptions{Filter: &KeyFilter{Pattern: "tenant-a:2"}, Merge: true})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), loaded.LoadedKeys)
+	assert.Equal(t, "dst-tenant-a:1", get(dst, "tenant-a:1"))
+	assert.Equal(t, "src-tenant-a:2", get(dst, "tenant-a:2"))
+	assert.Equal(t, "dst-tenant-a:stale", get(dst, "tenant-a:stale"))
+	assert.Equal(t, 4, dst.Stats().KeyCount)
+
+	// Merged keys carry their TTL from the dump.
+	dst = newDst()
+	_, err = dst.LoadWith("node1", path, LoadOptions{Merge: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, dst.expirationHeap.Len())
+	assert.Contains(t, dst.expirationIndex, "tenant-a:1")
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomFilter(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	require.NoError(t, c.BloomReserve("seen", 0.01, 1000))
+	assert.ErrorAs(t, c.BloomReserve("seen", 0.01, 1000), &ErrKeyExists{})
+	assert.Error(t, c.BloomReserve("bad", 1.5, 1000))
+	assert.Error(t, c.BloomReserve("huge", 0.01, 1<<62))
+	assert.Error(t, c.BloomReserve("tiny-rate", 1e-300, 1<<40))
+
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = fmt.Sprintf("event-%d", i)
+	}
+	added, err := c.BloomAdd("seen", items)
+	require.NoError(t, err)
+	require.Len(t, added, len(items))
+
+	exists, err := c.BloomExists("seen", items)
+	require.NoError(t, err)
+	for i, ok := range exists {
+		require.True(t, ok, "no false negatives: %s", items[i])
+	}
+
+	probes := make([]string, 10000)
+	for i := range probes {
+		probes[i] = fmt.Sprintf("other-%d", i)
+	}
+	exists, err = c.BloomExists("seen", probes)
+	require.NoError(t, err)
+	fp := 0
+	for _, ok := range exists {
+		if ok {
+			fp++
+		}
+	}
+	assert.Less(t, float64(fp)/float64(len(probes)), 0.03)
+
+	again, err := c.BloomAdd("seen", items[:1])
+	require.NoError(t, err)
+	assert.False(t, again[0])
+
+	exists, err = c.BloomExists("missing", []string{"x"})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, exists)
+}
+
+func TestHyperLogLog(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	// Stay sparse, then cross into the dense representation.
+	for _, n := range []int{100, 50000} {
+		key := fmt.Sprintf("visitors-%d", n)
+		items := make([]string, n)
+		for i := range items {
+			items[i] = fmt.Sprintf("user-%d", i)
+		}
+		changed, err := c.HLLAdd(key, items)
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		count, err := c.HLLCount(key)
+		require.NoError(t, err)
+		assert.InEpsilon(t, n, count, 0.03)
+
+		changed, err = c.HLLAdd(key, items[:10])
+		require.NoError(t, err)
+		assert.False(t, changed)
+	}
+
+	require.NoError(t, c.HLLMerge("all", "visitors-100", "visitors-50000", "missing"))
+	count, err := c.HLLCount("all")
+	require.NoError(t, err)
+	assert.InEpsilon(t, 50000, count, 0.03)
+
+	require.NoError(t, c.Set("plain", "v", ""))
+	_, err = c.HLLCount("plain")
+	assert.ErrorAs(t, err, &ErrWrongType{})
+}
+
+func TestProbabilisticDumpLoad(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	_, err := c.BloomAdd("bf", []string{"a", "b"})
+	require.NoError(t, err)
+	items := make([]string, 10000)
+	for i := range items {
+		items[i] = fmt.Sprintf("u%d", i)
+	}
+	_, err = c.HLLAdd("dense", items)
+	require.NoError(t, err)
+	_, err = c.HLLAdd("sparse", items[:5])
+	require.NoError(t, err)
+
+	want := map[string]uint64{}
+	for _, k := range []string{"dense", "sparse"} {
+		want[k], err = c.HLLCount(k)
+		require.NoError(t, err)
+	}
+
+	data, err := c.DumpToBytes("node1", "binary")
+	require.NoError(t, err)
+	restored := newTestCache()
+	defer restored.Close()
+	_, err = restored.LoadFromBytes("node1", data)
+	require.NoError(t, err)
+
+	exists, err := restored.BloomExists("bf", []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true}, exists)
+	for k, n := range want {
+		got, err := restored.HLLCount(k)
+		require.NoError(t, err)
+		assert.Equal(t, n, got, k)
+	}
+	// Dense sketches are packed at 6 bits per register.
+	assert.Less(t, len(data), 20000)
+}
+
+func TestProbabilisticMemoryAccounting(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	require.NoError(t, c.BloomReserve("bf", 0.001, 100000))
+	stats := c.Stats()
+	assert.Greater(t, stats.ApproximateMemoryBytes, int64(100000))
+
+	limited := NewWithLimits(0, 0, 1024, string(EvictionNone), c.logger)
+	defer limited.Close()
+	assert.ErrorAs(t, limited.BloomReserve("bf", 0.001, 100000), &ErrValueTooLarge{})
+}
@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/common"
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// scheduledDumpTimeFormat sorts lexically in time order.
+const scheduledDumpTimeFormat = "20060102T150405.000Z"
+
+// DumpScheduler periodically dumps the cache to timestamped files in a data
+// directory and keeps only the newest ones.
+type DumpScheduler struct {
+	cache     *Cache
+	nodeID    string
+	format    string
+	dataDir   string
+	retention int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// StartDumpScheduler dumps the cache every interval and keeps the newest
+// retention scheduled dumps. Call Stop to end it.
+func (c *Cache) StartDumpScheduler(nodeID, format, dataDir string, interval time.Duration, retention int) *DumpScheduler {
+	if dataDir == "" {
+		dataDir = "data"
+	}
+	s := &DumpScheduler{
+		cache:     c,
+		nodeID:    nodeID,
+		format:    format,
+		dataDir:   dataDir,
+		retention: retention,
+		stop:      make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run(interval)
+	return s
+}
+
+func (s *DumpScheduler) run(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if _, err := s.dumpAt(time.Now()); err != nil {
+				s.cache.logger.Warn("scheduled dump failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Stop ends the scheduler and waits for a dump in progress to finish.
+func (s *DumpScheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *DumpScheduler) dumpAt(at time.Time) (*DumpResult, error) {
+	path := ScheduledDumpPath(s.nodeID, s.format, s.dataDir, at)
+	result, err := s.cache.Dump(s.nodeID, s.format, path)
+	if err != nil {
+		return nil, err
+	}
+	metrics.SetScheduledDump(at, result.FileSize)
+	s.prune()
+	return result, nil
+}
+
+// prune removes all but the newest retention scheduled dumps.
+func (s *DumpScheduler) prune() {
+	files := scheduledDumpFiles(s.nodeID, s.dataDir)
+	if s.retention <= 0 || len(files) <= s.retention {
+		return
+	}
+	for _, path := range files[:len(files)-s.retention] {
+		if err := os.Remove(path); err != nil {
+			s.cache.logger.Warn("failed to remove old dump", zap.String("path", path), zap.Error(err))
+		}
+	}
+}
+
+// ScheduledDumpPath returns the timestamped path a scheduled dump taken at
+// at is written to.
+func ScheduledDumpPath(nodeID, format, dataDir string, at time.Time) string {
+	path := filepath.Join(dataDir, fmt.Sprintf("cache-%s-%s.dump", nodeID, at.UTC().Format(scheduledDumpTimeFormat)))
+	if format == common.DumpFormatJSON.String() {
+		path += ".json"
+	}
+	return path
+}
+
+// scheduledDumpFiles lists the scheduled dumps for nodeID, oldest first.
+func scheduledDumpFiles(nodeID, dataDir string) []string {
+	prefix := fmt.Sprintf("cache-%s-", nodeID)
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".json"), ".dump")
+		if _, err := time.Parse(scheduledDumpTimeFormat, stamp); err != nil {
+			continue
+		}
+		files = append(files, filepath.Join(dataDir, name))
+	}
+	// Names share the prefix and the stamp has a fixed width.
+	sort.Strings(files)
+	return files
+}
+
+// LoadLatest loads the newest readable dump for nodeID in dataDir, looking
+// at scheduled dumps and the default dump files. Files that fail to decode,
+// such as ones with a checksum mismatch, are skipped in favour of the next
+// newest; the cache is only replaced by a dump that loads cleanly.
+func (c *Cache) LoadLatest(nodeID, dataDir string) (*LoadResult, error) {
+	if dataDir == "" {
+		dataDir = "data"
+	}
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+	var candidates []candidate
+	// Newest first, so ties in modification time keep that order.
+	var paths []string
+	scheduled := scheduledDumpFiles(nodeID, dataDir)
+	for i := len(scheduled) - 1; i >= 0; i-- {
+		paths = append(paths, scheduled[i])
+	}
+	for _, format := range []common.DumpFormat{common.DumpFormatBinary, common.DumpFormatJSON} {
+		paths = append(paths, DefaultDumpPath(nodeID, format.String(), dataDir))
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			candidates = append(candidates, candidate{path: path, modTime: info.ModTime()})
+		}
+	}
+	if len(candidates) == 0 {
+		c.logger.Info("no dump file found, skipping load")
+		return &LoadResult{Success: true, Path: "none"}, nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].modTime.After(candidates[j].modTime)
+	})
+
+	var lastErr error
+	for _, cand := range candidates {
+		result, err := c.Load(nodeID, cand.path)
+		if err == nil {
+			return result, nil
+		}
+		c.logger.Warn("skipping unreadable dump file", zap.String("path", cand.path), zap.Error(err))
+		lastErr = err
+	}
+	return nil, fmt.Errorf("no readable dump file in %s: %w", dataDir, lastErr)
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledDumpRetention(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+	dir := t.TempDir()
+
+	s := &DumpScheduler{cache: c, nodeID: "node-1", format: "binary", dataDir: dir, retention: 2, stop: make(chan struct{})}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, c.Set("k", i, ""))
+		_, err := s.dumpAt(start.Add(time.Duration(i) * time.Minute))
+		require.NoError(t, err)
+	}
+	// The shutdown dump must not be pruned as a scheduled one.
+	_, err := c.Dump("node-1", "binary", DefaultDumpPath("node-1", "binary", dir))
+	require.NoError(t, err)
+
+	files := scheduledDumpFiles("node-1", dir)
+	assert.Equal(t, []string{
+		ScheduledDumpPath("node-1", "binary", dir, start.Add(2*time.Minute)),
+		ScheduledDumpPath("node-1", "binary", dir, start.Add(3*time.Minute)),
+	}, files)
+	_, err = os.Stat(DefaultDumpPath("node-1", "binary", dir))
+	assert.NoError(t, err)
+}
+
+func TestLoadLatestSkipsCorruptDumps(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+	dir := t.TempDir()
+
+	start := time.Now().Add(-time.Hour)
+	for i, v := range []string{"old", "new"} {
+		require.NoError(t, c.Set("k", v, ""))
+		path := ScheduledDumpPath("node-1", "binary", dir, start.Add(time.Duration(i)*time.Minute))
+		_, err := c.Dump("node-1", "binary", path)
+		require.NoError(t, err)
+		mtime := start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	restored := newTestCache()
+	defer restored.Close()
+	result, err := restored.LoadLatest("node-1", dir)
+	require.NoError(t, err)
+	v, _ := restored.Get("k")
+	assert.Equal(t, "new", v)
+
+	// Flip a payload byte in the newest dump so its checksum no longer matches.
+	newest := ScheduledDumpPath("node-1", "binary", dir, start.Add(time.Minute))
+	data, err := os.ReadFile(newest)
+	require.NoError(t, err)
+	data[24] ^= 0xff
+	require.NoError(t, os.WriteFile(newest, data, 0o644))
+	require.NoError(t, os.Chtimes(newest, start.Add(time.Minute), start.Add(time.Minute)))
+
+	restored2 := newTestCache()
+	defer restored2.Close()
+	result, err = restored2.LoadLatest("node-1", dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(ScheduledDumpPath("node-1", "binary", dir, start)), filepath.Base(result.Path))
+	v, _ = restored2.Get("k")
+	assert.Equal(t, "old", v)
+}
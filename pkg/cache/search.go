@@ -3,12 +3,53 @@ package cache
 import (
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/lushenle/simple-cache/pkg/metrics"
 	"go.uber.org/zap"
 )
 
+// KeyFilter selects keys with the same pattern semantics as Search: a
+// trailing-'*' prefix or filepath.Match wildcard, or a regular expression
+// when UseRegex is set.
+type KeyFilter struct {
+	Pattern  string
+	UseRegex bool
+}
+
+// Validate reports whether the pattern compiles.
+func (f *KeyFilter) Validate() error {
+	_, err := f.matcher()
+	return err
+}
+
+// matcher compiles f. A nil filter or an empty pattern matches every key.
+func (f *KeyFilter) matcher() (func(string) bool, error) {
+	if f == nil || f.Pattern == "" {
+		return nil, nil
+	}
+	pattern := f.Pattern
+	if f.UseRegex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	if len(pattern) > 1 && pattern[len(pattern)-1] == '*' {
+		prefix := pattern[:len(pattern)-1]
+		return func(key string) bool { return strings.HasPrefix(key, prefix) }, nil
+	}
+	if _, err := filepath.Match(pattern, "dummy"); err != nil {
+		return nil, err
+	}
+	return func(key string) bool {
+		match, _ := filepath.Match(pattern, key)
+		return match
+	}, nil
+}
+
 func (c *Cache) Search(pattern string, useRegex bool) ([]string, error) {
 	c.logger.Debug("search", zap.String("pattern", pattern))
 
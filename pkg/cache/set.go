@@ -36,6 +36,16 @@ func (c *Cache) Set(key string, value any, expire string) error {
 	c.mu.Lock(metrics.LockWrite)
 	defer c.mu.Unlock()
 
+	if err := c.storeLocked(key, value, expiration); err != nil {
+		return err
+	}
+	success = true
+	return nil
+}
+
+// storeLocked inserts or replaces key with the given value and expiration,
+// enforcing max_keys and LRU eviction. Caller must hold c.mu write lock.
+func (c *Cache) storeLocked(key string, value any, expiration time.Time) error {
 	// Check max keys limit (only for new keys, not updates)
 	if c.maxKeys > 0 && len(c.items) >= c.maxKeys {
 		if _, exists := c.items[key]; !exists {
@@ -67,17 +77,23 @@ func (c *Cache) Set(key string, value any, expire string) error {
 		expiration: expiration,
 	})
 
-	success = true
-
 	c.setLRU(key)
 	metrics.UpdateKeysTotal(len(c.items))
 
 	return nil
 }
 
+// sizedValue is implemented by structured value types (streams, etc.) that
+// can estimate their own memory footprint.
+type sizedValue interface {
+	approxSize() int
+}
+
 // approxValueSize returns an approximate byte size of a value for limit checking.
 func approxValueSize(v any) int {
 	switch val := v.(type) {
+	case sizedValue:
+		return val.approxSize()
 	case string:
 		return len(val)
 	case []byte:
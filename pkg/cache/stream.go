@@ -0,0 +1,400 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// StreamID identifies a stream entry as "<ms>-<seq>". IDs are strictly
+// increasing within a stream.
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+func (id StreamID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+// Less reports whether id sorts before other.
+func (id StreamID) Less(other StreamID) bool {
+	if id.Ms != other.Ms {
+		return id.Ms < other.Ms
+	}
+	return id.Seq < other.Seq
+}
+
+func (id StreamID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id *StreamID) UnmarshalText(b []byte) error {
+	parsed, err := ParseStreamID(string(b))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// ParseStreamID parses "<ms>-<seq>" or a bare "<ms>" (sequence 0).
+func ParseStreamID(s string) (StreamID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return StreamID{}, fmt.Errorf("invalid stream id %q", s)
+	}
+	var seq uint64
+	if hasSeq {
+		seq, err = strconv.ParseUint(seqPart, 10, 64)
+		if err != nil {
+			return StreamID{}, fmt.Errorf("invalid stream id %q", s)
+		}
+	}
+	return StreamID{Ms: ms, Seq: seq}, nil
+}
+
+// StreamEntry is a single record appended to a stream. Entries are never
+// mutated after being appended.
+type StreamEntry struct {
+	ID     StreamID          `json:"id"`
+	Fields map[string]string `json:"fields"`
+}
+
+// ConsumerGroup tracks the offset a group of consumers has committed.
+// Entries after Committed are (re)delivered by ReadGroup until committed,
+// which gives at-least-once processing.
+type ConsumerGroup struct {
+	Committed StreamID `json:"committed"`
+}
+
+// Stream is an append-only log value.
+type Stream struct {
+	Entries []StreamEntry             `json:"entries"`
+	LastID  StreamID                  `json:"last_id"`
+	Groups  map[string]*ConsumerGroup `json:"groups,omitempty"`
+	// size is the approximate size of Entries, kept up to date by append
+	// and trim so that size checks do not walk the whole stream.
+	size int
+}
+
+// UnmarshalJSON decodes a stream and recomputes its running size.
+func (s *Stream) UnmarshalJSON(b []byte) error {
+	type plain Stream
+	if err := json.Unmarshal(b, (*plain)(s)); err != nil {
+		return err
+	}
+	s.size = 0
+	for _, e := range s.Entries {
+		s.size += e.approxSize()
+	}
+	return nil
+}
+
+func (e StreamEntry) approxSize() int {
+	size := 16
+	for k, v := range e.Fields {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+func (s *Stream) approxSize() int {
+	size := 32 + s.size
+	for name := range s.Groups {
+		size += len(name) + 16
+	}
+	return size
+}
+
+// append adds e as the newest entry.
+func (s *Stream) append(e StreamEntry) {
+	s.Entries = append(s.Entries, e)
+	s.LastID = e.ID
+	s.size += e.approxSize()
+}
+
+// snapshot shares the entries: they are never mutated, appends past the
+// copied length are not visible and trim reallocates. Only the group
+// offsets are copied.
+func (s *Stream) snapshot() any {
+	out := &Stream{Entries: s.Entries[:len(s.Entries):len(s.Entries)], LastID: s.LastID, size: s.size}
+	if s.Groups != nil {
+		out.Groups = make(map[string]*ConsumerGroup, len(s.Groups))
+		for name, g := range s.Groups {
+			cg := *g
+			out.Groups[name] = &cg
+		}
+	}
+	return out
+}
+
+// after returns up to count entries with an ID greater than id.
+func (s *Stream) after(id StreamID, count int) []StreamEntry {
+	i := sort.Search(len(s.Entries), func(i int) bool {
+		return id.Less(s.Entries[i].ID)
+	})
+	end := len(s.Entries)
+	if count > 0 && i+count < end {
+		end = i + count
+	}
+	return append([]StreamEntry(nil), s.Entries[i:end]...)
+}
+
+// trim drops entries beyond maxLen (0 = no limit) and entries older than
+// minMs (0 = no limit). It returns the number of entries removed.
+func (s *Stream) trim(maxLen int, minMs uint64) int {
+	drop := 0
+	if maxLen > 0 && len(s.Entries) > maxLen {
+		drop = len(s.Entries) - maxLen
+	}
+	for drop < len(s.Entries) && s.Entries[drop].ID.Ms < minMs {
+		drop++
+	}
+	if drop == 0 {
+		return 0
+	}
+	for _, e := range s.Entries[:drop] {
+		s.size -= e.approxSize()
+	}
+	s.Entries = append([]StreamEntry(nil), s.Entries[drop:]...)
+	return drop
+}
+
+// ErrGroupNotFound is returned when a consumer group does not exist.
+type ErrGroupNotFound struct {
+	Key   string
+	Group string
+}
+
+func (e ErrGroupNotFound) Error() string {
+	return fmt.Sprintf("consumer group %q not found on stream %q", e.Group, e.Key)
+}
+
+// StreamAdd appends fields to the stream under key, creating it if needed.
+// now is the proposer's wall clock so every replica derives the same ID;
+// the ID never goes backwards even if clocks do. maxLen and maxAge cap the
+// stream after the append (zero disables each limit). The append is
+// rejected with ErrValueTooLarge if the capped stream exceeds the maximum
+// value size.
+func (c *Cache) StreamAdd(key string, fields map[string]string, now time.Time, maxLen int, maxAge time.Duration) (StreamID, error) {
+	c.logger.Debug("stream add", zap.String("key", key))
+
+	if len(fields) == 0 {
+		return StreamID{}, fmt.Errorf("stream entry must have at least one field")
+	}
+	if c.maxValueSize > 0 {
+		// Checked up front too, so that an oversized entry does not leave
+		// a new empty stream behind.
+		single := &Stream{}
+		single.append(StreamEntry{Fields: fields})
+		if sz := single.approxSize(); sz > c.maxValueSize {
+			return StreamID{}, ErrValueTooLarge{Size: sz, MaxSize: c.maxValueSize}
+		}
+	}
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	s, err := c.streamLocked(key, true)
+	if err != nil {
+		return StreamID{}, err
+	}
+
+	nowMs := uint64(now.UnixMilli())
+	id := StreamID{Ms: nowMs}
+	if !s.LastID.Less(id) {
+		id = StreamID{Ms: s.LastID.Ms, Seq: s.LastID.Seq + 1}
+	}
+	entries, lastID, size := s.Entries, s.LastID, s.size
+	s.append(StreamEntry{ID: id, Fields: fields})
+	s.trim(maxLen, streamMinMs(nowMs, maxAge))
+	if c.maxValueSize > 0 {
+		if sz := s.approxSize(); sz > c.maxValueSize {
+			// Neither the append nor trim touched the first len(entries)
+			// elements, so restoring the slice undoes both.
+			s.Entries, s.LastID, s.size = entries, lastID, size
+			return StreamID{}, ErrValueTooLarge{Size: sz, MaxSize: c.maxValueSize}
+		}
+	}
+	return id, nil
+}
+
+// StreamRange returns up to count entries with start <= ID <= end.
+func (c *Cache) StreamRange(key string, start, end StreamID, count int) ([]StreamEntry, error) {
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
+
+	s, err := c.streamRLocked(key)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	i := sort.Search(len(s.Entries), func(i int) bool {
+		return !s.Entries[i].ID.Less(start)
+	})
+	var out []StreamEntry
+	for ; i < len(s.Entries) && !end.Less(s.Entries[i].ID); i++ {
+		out = append(out, s.Entries[i])
+		if count > 0 && len(out) == count {
+			break
+		}
+	}
+	return out, nil
+}
+
+// StreamRead returns up to count entries with an ID greater than after.
+func (c *Cache) StreamRead(key string, after StreamID, count int) ([]StreamEntry, error) {
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
+
+	s, err := c.streamRLocked(key)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return s.after(after, count), nil
+}
+
+// StreamLastID returns the ID of the last entry ever appended to the stream,
+// or the zero ID if the stream does not exist.
+func (c *Cache) StreamLastID(key string) (StreamID, error) {
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
+
+	s, err := c.streamRLocked(key)
+	if err != nil || s == nil {
+		return StreamID{}, err
+	}
+	return s.LastID, nil
+}
+
+// StreamTrim caps the stream to maxLen entries and drops entries older than
+// maxAge relative to now. It returns the number of entries removed.
+func (c *Cache) StreamTrim(key string, now time.Time, maxLen int, maxAge time.Duration) (int, error) {
+	c.logger.Debug("stream trim", zap.String("key", key))
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	s, err := c.streamLocked(key, false)
+	if err != nil || s == nil {
+		return 0, err
+	}
+	return s.trim(maxLen, streamMinMs(uint64(now.UnixMilli()), maxAge)), nil
+}
+
+// StreamGroupCreate creates a consumer group whose committed offset starts
+// at start. The stream is created if it does not exist. It returns false if
+// the group already exists.
+func (c *Cache) StreamGroupCreate(key, group string, start StreamID) (bool, error) {
+	c.logger.Debug("stream group create", zap.String("key", key), zap.String("group", group))
+
+	if group == "" {
+		return false, fmt.Errorf("group must not be empty")
+	}
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	s, err := c.streamLocked(key, true)
+	if err != nil {
+		return false, err
+	}
+	if _, exists := s.Groups[group]; exists {
+		return false, nil
+	}
+	if s.Groups == nil {
+		s.Groups = make(map[string]*ConsumerGroup)
+	}
+	s.Groups[group] = &ConsumerGroup{Committed: start}
+	return true, nil
+}
+
+// StreamReadGroup returns up to count entries after the group's committed
+// offset. Reading does not advance the offset; see StreamCommit.
+func (c *Cache) StreamReadGroup(key, group string, count int) ([]StreamEntry, error) {
+	c.mu.RLock(metrics.LockRead)
+	defer c.mu.RUnlock()
+
+	s, err := c.streamRLocked(key)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil || s.Groups[group] == nil {
+		return nil, ErrGroupNotFound{Key: key, Group: group}
+	}
+	return s.after(s.Groups[group].Committed, count), nil
+}
+
+// StreamCommit advances the group's committed offset to id. Offsets only
+// move forward; committing an older ID returns false.
+func (c *Cache) StreamCommit(key, group string, id StreamID) (bool, error) {
+	c.logger.Debug("stream commit", zap.String("key", key), zap.String("group", group))
+
+	c.mu.Lock(metrics.LockWrite)
+	defer c.mu.Unlock()
+
+	s, err := c.streamLocked(key, false)
+	if err != nil {
+		return false, err
+	}
+	if s == nil || s.Groups[group] == nil {
+		return false, ErrGroupNotFound{Key: key, Group: group}
+	}
+	g := s.Groups[group]
+	if !g.Committed.Less(id) {
+		return false, nil
+	}
+	g.Committed = id
+	return true, nil
+}
+
+// streamLocked returns the stream stored under key. When create is true a
+// missing stream is created; otherwise nil is returned for a missing key.
+// Caller must hold c.mu write lock.
+func (c *Cache) streamLocked(key string, create bool) (*Stream, error) {
+	if item, ok := c.liveItemLocked(key); ok {
+		s, isStream := item.value.(*Stream)
+		if !isStream {
+			return nil, ErrWrongType{Key: key, Want: "stream"}
+		}
+		c.access(key)
+		return s, nil
+	}
+	if !create {
+		return nil, nil
+	}
+	s := &Stream{}
+	if err := c.storeLocked(key, s, time.Time{}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// streamRLocked is the read-only variant of streamLocked: it neither creates
+// streams nor evicts expired keys. Caller must hold at least c.mu read lock.
+func (c *Cache) streamRLocked(key string) (*Stream, error) {
+	item, ok := c.items[key]
+	if !ok || (!item.expiration.IsZero() && time.Now().After(item.expiration)) {
+		return nil, nil
+	}
+	s, isStream := item.value.(*Stream)
+	if !isStream {
+		return nil, ErrWrongType{Key: key, Want: "stream"}
+	}
+	c.access(key)
+	return s, nil
+}
+
+func streamMinMs(nowMs uint64, maxAge time.Duration) uint64 {
+	if maxAge <= 0 || uint64(maxAge.Milliseconds()) >= nowMs {
+		return 0
+	}
+	return nowMs - uint64(maxAge.Milliseconds())
+}
@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamAddAndRead(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	now := time.UnixMilli(1000)
+	id1, err := c.StreamAdd("events", map[string]string{"n": "1"}, now, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "1000-0", id1.String())
+
+	// Same millisecond and a clock that went backwards both keep IDs increasing.
+	id2, err := c.StreamAdd("events", map[string]string{"n": "2"}, now, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "1000-1", id2.String())
+	id3, err := c.StreamAdd("events", map[string]string{"n": "3"}, time.UnixMilli(900), 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "1000-2", id3.String())
+
+	entries, err := c.StreamRead("events", id1, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "2", entries[0].Fields["n"])
+
+	entries, err = c.StreamRange("events", id2, id3, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	entries, err = c.StreamRange("events", StreamID{}, id3, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, id1, entries[0].ID)
+
+	_, err = c.StreamAdd("events", nil, now, 0, 0)
+	assert.Error(t, err)
+
+	require.NoError(t, c.Set("plain", "v", ""))
+	_, err = c.StreamAdd("plain", map[string]string{"a": "b"}, now, 0, 0)
+	assert.ErrorAs(t, err, &ErrWrongType{})
+}
+
+func TestStreamTrim(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err := c.StreamAdd("s", map[string]string{"i": "x"}, time.UnixMilli(int64(1000*(i+1))), 3, 0)
+		require.NoError(t, err)
+	}
+	entries, err := c.StreamRead("s", StreamID{}, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, uint64(3000), entries[0].ID.Ms)
+
+	trimmed, err := c.StreamTrim("s", time.UnixMilli(5000), 0, 1500*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 1, trimmed)
+
+	last, err := c.StreamLastID("s")
+	require.NoError(t, err)
+	assert.Equal(t, "5000-0", last.String())
+}
+
+func TestStreamAddMaxValueSize(t *testing.T) {
+	base := newTestCache()
+	defer base.Close()
+	c := NewWithLimits(0, 0, 256, string(EvictionNone), base.logger)
+	defer c.Close()
+
+	big := map[string]string{"payload": strings.Repeat("x", 300)}
+	_, err := c.StreamAdd("s", big, time.UnixMilli(1000), 0, 0)
+	assert.ErrorAs(t, err, &ErrValueTooLarge{})
+	_, found := c.Get("s")
+	assert.False(t, found)
+
+	// An uncapped stream grows until it hits the limit; the rejected entry
+	// leaves it as it was.
+	fields := map[string]string{"payload": strings.Repeat("x", 40)}
+	var added int
+	for ; added < 100; added++ {
+		if _, err = c.StreamAdd("s", fields, time.UnixMilli(int64(1000+added)), 0, 0); err != nil {
+			break
+		}
+	}
+	assert.ErrorAs(t, err, &ErrValueTooLarge{})
+	entries, err := c.StreamRead("s", StreamID{}, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, added)
+	last, err := c.StreamLastID("s")
+	require.NoError(t, err)
+	assert.Equal(t, entries[added-1].ID, last)
+
+	// A capped stream keeps accepting entries within the limit.
+	for i := 0; i < 10; i++ {
+		_, err := c.StreamAdd("capped", fields, time.UnixMilli(int64(1000+i)), 2, 0)
+		require.NoError(t, err)
+	}
+}
+
+func TestStreamRunningSize(t *testing.T) {
+	base := newTestCache()
+	defer base.Close()
+	c := NewWithLimits(0, 0, 512, string(EvictionNone), base.logger)
+	defer c.Close()
+
+	stream := func(c *Cache, key string) *Stream {
+		return c.items[key].value.(*Stream)
+	}
+	// The running size must match a walk over the entries after every
+	// kind of change.
+	assertSize := func(s *Stream) {
+		want := 0
+		for _, e := range s.Entries {
+			want += e.approxSize()
+		}
+		assert.Equal(t, want, s.size)
+	}
+
+	fields := map[string]string{"payload": strings.Repeat("x", 40)}
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		_, err = c.StreamAdd("s", fields, time.UnixMilli(int64(1000+i)), 0, 0)
+		assertSize(stream(c, "s"))
+	}
+	assert.ErrorAs(t, err, &ErrValueTooLarge{})
+
+	_, err = c.StreamTrim("s", time.UnixMilli(2000), 3, 0)
+	require.NoError(t, err)
+	assertSize(stream(c, "s"))
+	for i := 0; i < 10; i++ {
+		_, err = c.StreamAdd("s", fields, time.UnixMilli(int64(3000+i)), 4, 0)
+		require.NoError(t, err)
+		assertSize(stream(c, "s"))
+	}
+
+	data, err := c.DumpToBytes("node1", "json")
+	require.NoError(t, err)
+	restored := newTestCache()
+	defer restored.Close()
+	_, err = restored.LoadFromBytes("node1", data)
+	require.NoError(t, err)
+	assert.Equal(t, stream(c, "s").size, stream(restored, "s").size)
+}
+
+func TestStreamConsumerGroup(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	created, err := c.StreamGroupCreate("jobs", "workers", StreamID{})
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = c.StreamGroupCreate("jobs", "workers", StreamID{})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	id1, _ := c.StreamAdd("jobs", map[string]string{"job": "a"}, time.UnixMilli(1), 0, 0)
+	id2, _ := c.StreamAdd("jobs", map[string]string{"job": "b"}, time.UnixMilli(2), 0, 0)
+
+	// Uncommitted entries are redelivered.
+	for i := 0; i < 2; i++ {
+		entries, err := c.StreamReadGroup("jobs", "workers", 1)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, id1, entries[0].ID)
+	}
+
+	ok, err := c.StreamCommit("jobs", "workers", id1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = c.StreamCommit("jobs", "workers", id1)
+	require.NoError(t, err)
+	assert.False(t, ok, "offsets only move forward")
+
+	entries, err := c.StreamReadGroup("jobs", "workers", 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, id2, entries[0].ID)
+
+	_, err = c.StreamReadGroup("jobs", "missing", 0)
+	assert.ErrorAs(t, err, &ErrGroupNotFound{})
+}
+
+func TestStreamSurvivesDumpLoad(t *testing.T) {
+	c := newTestCache()
+	defer c.Close()
+
+	id, err := c.StreamAdd("events", map[string]string{"k": "v"}, time.UnixMilli(42), 0, 0)
+	require.NoError(t, err)
+	_, err = c.StreamGroupCreate("events", "g", StreamID{})
+	require.NoError(t, err)
+	_, err = c.StreamCommit("events", "g", id)
+	require.NoError(t, err)
+
+	data, err := c.DumpToBytes("node1", "binary")
+	require.NoError(t, err)
+
+	restored := newTestCache()
+	defer restored.Close()
+	_, err = restored.LoadFromBytes("node1", data)
+	require.NoError(t, err)
+
+	entries, err := restored.StreamRead("events", StreamID{}, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "v", entries[0].Fields["k"])
+
+	entries, err = restored.StreamReadGroup("events", "g", 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	next, err := restored.StreamAdd("events", map[string]string{"k": "w"}, time.UnixMilli(1), 0, 0)
+	require.NoError(t, err)
+	assert.True(t, id.Less(next))
+}
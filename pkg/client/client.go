@@ -12,6 +12,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lushenle/simple-cache/pkg/pb"
@@ -65,6 +66,12 @@ type Client struct {
 	// ---- http client for health probes ----
 	httpClient *http.Client
 
+	// ---- session consistency ----
+	// lastIndex is the highest commit index reported by a write on this
+	// client; reads send it as min_index so they observe those writes even
+	// when served by a lagging follower.
+	lastIndex atomic.Uint64
+
 	// ---- configuration ----
 	retryCount    int
 	checkInterval time.Duration
@@ -177,10 +184,11 @@ func NewCluster(ctx context.Context, nodes []NodeSpec, opts ...ClientOption) (*C
 		nodeMap:       make(map[string]int),
 		retryCount:    o.retryCount,
 		checkInterval: o.checkInterval,
-		dialOpts:      o.dialOpts,
 		httpClient:    o.httpClient,
 		stopCh:        make(chan struct{}),
 	}
+	c.dialOpts = append(append([]grpc.DialOption(nil), o.dialOpts...),
+		grpc.WithChainUnaryInterceptor(c.trackCommitIndex))
 	for i := range c.nodes {
 		if c.nodes[i].ID != "" {
 			c.nodeMap[c.nodes[i].ID] = i
@@ -513,12 +521,17 @@ func (c *Client) retryableCall(ctx context.Context, fn func(pb.CacheServiceClien
 // Public API methods
 // ---------------------------------------------------------------------------
 
-// Get retrieves a value by key.
+// Get retrieves a value by key. The read is linearizable unless ctx carries
+// another level (see WithReadConsistency).
 func (c *Client) Get(ctx context.Context, key string) (any, bool, error) {
 	var val any
 	var found bool
 	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
-		resp, rpcErr := cli.Get(ctx, &pb.GetRequest{Key: key})
+		resp, rpcErr := cli.Get(ctx, &pb.GetRequest{
+			Key:         key,
+			Consistency: readConsistency(ctx),
+			MinIndex:    c.lastIndex.Load(),
+		})
 		if rpcErr != nil {
 			return rpcErr
 		}
@@ -563,7 +576,8 @@ func (c *Client) Del(ctx context.Context, key string) (bool, error) {
 	return existed, err
 }
 
-// Search finds keys matching the given pattern.
+// Search finds keys matching the given pattern, at the consistency level
+// carried by ctx (see WithReadConsistency).
 func (c *Client) Search(ctx context.Context, pattern string, isRegex bool) ([]string, error) {
 	var mode pb.SearchRequest_MatchMode
 	if isRegex {
@@ -575,8 +589,10 @@ func (c *Client) Search(ctx context.Context, pattern string, isRegex bool) ([]st
 	var keys []string
 	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
 		resp, rpcErr := cli.Search(ctx, &pb.SearchRequest{
-			Pattern: pattern,
-			Mode:    mode,
+			Pattern:     pattern,
+			Mode:        mode,
+			Consistency: readConsistency(ctx),
+			MinIndex:    c.lastIndex.Load(),
 		})
 		if rpcErr != nil {
 			return rpcErr
@@ -651,6 +667,47 @@ func WithAuthToken(ctx context.Context, token string) context.Context {
 	return metadata.AppendToOutgoingContext(ctx, "x-api-token", token)
 }
 
+type readConsistencyKey struct{}
+
+// WithReadConsistency sets the consistency level of the Get and Search calls
+// made with the returned context. pb.ReadConsistency_READ_LEASE and
+// pb.ReadConsistency_READ_STALE trade freshness for latency on hot read
+// paths.
+func WithReadConsistency(ctx context.Context, level pb.ReadConsistency) context.Context {
+	return context.WithValue(ctx, readConsistencyKey{}, level)
+}
+
+func readConsistency(ctx context.Context) pb.ReadConsistency {
+	level, _ := ctx.Value(readConsistencyKey{}).(pb.ReadConsistency)
+	return level
+}
+
+// trackCommitIndex records the commit index carried by every write reply.
+func (c *Client) trackCommitIndex(ctx context.Context, method string, req, reply any,
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if r, ok := reply.(interface{ GetCommitIndex() uint64 }); ok && err == nil {
+		c.observeCommitIndex(r.GetCommitIndex())
+	}
+	return err
+}
+
+func (c *Client) observeCommitIndex(idx uint64) {
+	for {
+		cur := c.lastIndex.Load()
+		if idx <= cur || c.lastIndex.CompareAndSwap(cur, idx) {
+			return
+		}
+	}
+}
+
+// LastCommitIndex returns the highest raft index at which a write made
+// through this client committed. Get and Search send it as min_index, so a
+// client always reads its own writes, whichever node serves the read.
+func (c *Client) LastCommitIndex() uint64 {
+	return c.lastIndex.Load()
+}
+
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
@@ -729,6 +786,7 @@ func (c *Client) BatchSetStream(ctx context.Context, items map[string]string, tt
 	if err != nil {
 		return 0, 0, err
 	}
+	c.observeCommitIndex(resp.CommitIndex)
 	return int(resp.SuccessCount), int(resp.ErrorCount), nil
 }
 
@@ -783,6 +841,76 @@ func (c *Client) Watch(ctx context.Context, pattern string) (<-chan *pb.WatchEve
 	return ch, nil
 }
 
+// Publish broadcasts payload on channel to subscribers on every node.
+func (c *Client) Publish(ctx context.Context, channel string, payload []byte) error {
+	return c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		_, rpcErr := cli.Publish(ctx, &pb.PublishRequest{
+			Channel: channel,
+			Payload: payload,
+		})
+		return rpcErr
+	})
+}
+
+// Subscribe streams messages published on the given channels or channel
+// patterns until the context is cancelled. Messages published while the
+// stream is reconnecting are not redelivered.
+func (c *Client) Subscribe(ctx context.Context, channels, patterns []string) (<-chan *pb.PubSubMessage, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil {
+		return nil, ErrNoClient
+	}
+	stream, err := cli.Subscribe(ctx, &pb.SubscribeRequest{
+		Channels: channels,
+		Patterns: patterns,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *pb.PubSubMessage, 64)
+	go func() {
+		defer close(ch)
+		for {
+			for {
+				msg, err := stream.Recv()
+				if err != nil {
+					if ctx.Err() != nil || status.Code(err) != codes.Unavailable {
+						return
+					}
+					break // reconnect
+				}
+				select {
+				case ch <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+			// The node went away; re-subscribe on whichever node the
+			// client is connected to now.
+			reCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_ = c.rebalance(reCtx)
+			cancel()
+			c.mu.Lock()
+			cli := c.client
+			c.mu.Unlock()
+			if cli == nil {
+				return
+			}
+			stream, err = cli.Subscribe(ctx, &pb.SubscribeRequest{
+				Channels: channels,
+				Patterns: patterns,
+			})
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
 // ---------------------------------------------------------------------------
 // Sentinel errors
 // ---------------------------------------------------------------------------
@@ -790,4 +918,6 @@ func (c *Client) Watch(ctx context.Context, pattern string) (<-chan *pb.WatchEve
 var (
 	ErrNoLeader = errors.New("no cluster leader reachable")
 	ErrNoClient = errors.New("client is not connected")
+
+	ErrLockNotAcquired = errors.New("lock is held by another owner")
 )
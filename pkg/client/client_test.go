@@ -40,6 +40,7 @@ func init() {
 	// Use a real cache for integration-like tests within the unit test file.
 	// The cache.New parameters (e.g., cleanup interval) might be relevant for specific timing tests.
 	cacheSrv := server.New(cache.New(10*time.Second, logger), "test-node")
+	cacheSrv.SetPubSubService(server.NewPubSubService())
 	pb.RegisterCacheServiceServer(s, cacheSrv)
 	go func() {
 		if err := s.Serve(lis); err != nil {
@@ -509,3 +510,17 @@ func TestClient_Close(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "connection is closing")
 }
+
+func TestClient_TrackCommitIndex(t *testing.T) {
+	cli := &Client{}
+	invoke := func(reply any) error {
+		return cli.trackCommitIndex(context.Background(), "/m", nil, reply, nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error { return nil })
+	}
+
+	require.NoError(t, invoke(&pb.SetResponse{CommitIndex: 7}))
+	require.NoError(t, invoke(&pb.DelResponse{CommitIndex: 5}))
+	require.NoError(t, invoke(&pb.GetResponse{AppliedIndex: 9}))
+	// The session index only moves forward, and reads do not advance it.
+	assert.EqualValues(t, 7, cli.LastCommitIndex())
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+
+	"github.com/lushenle/simple-cache/pkg/pb"
+)
+
+// JSONSet stores the JSON text value at path in the document under key,
+// creating the document if needed. Use "$" for the whole document.
+func (c *Client) JSONSet(ctx context.Context, key, path, value string) error {
+	return c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		_, rpcErr := cli.JSONSet(ctx, &pb.JSONSetRequest{
+			Key:   key,
+			Path:  path,
+			Value: value,
+		})
+		return rpcErr
+	})
+}
+
+// JSONGet returns the JSON text at path and whether it was found.
+func (c *Client) JSONGet(ctx context.Context, key, path string) (string, bool, error) {
+	var (
+		value string
+		found bool
+	)
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.JSONGet(ctx, &pb.JSONGetRequest{Key: key, Path: path})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		value, found = resp.Value, resp.Found
+		return nil
+	})
+	return value, found, err
+}
+
+// JSONDel removes the value at path. Deleting "$" removes the key.
+func (c *Client) JSONDel(ctx context.Context, key, path string) (bool, error) {
+	var deleted bool
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.JSONDel(ctx, &pb.JSONDelRequest{Key: key, Path: path})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		deleted = resp.Deleted
+		return nil
+	})
+	return deleted, err
+}
+
+// JSONNumIncrBy atomically adds delta to the number at path and returns the
+// new value as JSON text.
+func (c *Client) JSONNumIncrBy(ctx context.Context, key, path string, delta float64) (string, error) {
+	var value string
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.JSONNumIncrBy(ctx, &pb.JSONNumIncrByRequest{
+			Key:   key,
+			Path:  path,
+			Delta: delta,
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		value = resp.Value
+		return nil
+	})
+	return value, err
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClient_JSONDoc(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	cli := newTestClient(t)
+	defer cli.Close()
+	ctx := context.Background()
+
+	require.NoError(t, cli.JSONSet(ctx, "client-json", "$", `{"user":{"name":"ann"},"visits":0}`))
+	require.NoError(t, cli.JSONSet(ctx, "client-json", "$.user.email", `"ann@example.com"`))
+
+	v, err := cli.JSONNumIncrBy(ctx, "client-json", "$.visits", 3)
+	require.NoError(t, err)
+	assert.Equal(t, "3", v)
+
+	v, found, err := cli.JSONGet(ctx, "client-json", "$.user")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.JSONEq(t, `{"name":"ann","email":"ann@example.com"}`, v)
+
+	deleted, err := cli.JSONDel(ctx, "client-json", "$.user.name")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	err = cli.JSONSet(ctx, "client-json", "$.bad", `{`)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = cli.JSONNumIncrBy(ctx, "client-json", "$.user", 1)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
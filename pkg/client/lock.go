@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/pb"
+)
+
+// Lock is a held distributed lock. While held, a background goroutine renews
+// the lease every ttl/3. If the lease cannot be renewed before it would have
+// expired, or the server reports it lost, the Lost channel is closed and the
+// holder must stop using the protected resource.
+type Lock struct {
+	c     *Client
+	name  string
+	owner string
+	token uint64
+	ttl   time.Duration
+
+	lost     chan struct{}
+	lostOnce sync.Once
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// AcquireLock takes the named lock with a lease of ttl, blocking up to wait
+// while it is held by another owner. It returns ErrLockNotAcquired if the
+// lock is still held when wait elapses. Auth metadata on ctx is reused for
+// background renewals.
+func (c *Client) AcquireLock(ctx context.Context, name string, ttl, wait time.Duration) (*Lock, error) {
+	owner := newLockOwner()
+	var resp *pb.AcquireLockResponse
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		r, rpcErr := cli.AcquireLock(ctx, &pb.AcquireLockRequest{
+			Name:  name,
+			Owner: owner,
+			Ttl:   ttl.String(),
+			Wait:  formatTTL(wait),
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Acquired {
+		return nil, ErrLockNotAcquired
+	}
+
+	l := &Lock{
+		c:      c,
+		name:   name,
+		owner:  owner,
+		token:  resp.Token,
+		ttl:    ttl,
+		lost:   make(chan struct{}),
+		stopCh: make(chan struct{}),
+	}
+	l.wg.Add(1)
+	go l.renewLoop(context.WithoutCancel(ctx))
+	return l, nil
+}
+
+// Name returns the lock name.
+func (l *Lock) Name() string { return l.name }
+
+// Token returns the fencing token assigned when the lock was acquired.
+func (l *Lock) Token() uint64 { return l.token }
+
+// Lost is closed when the lease has been lost.
+func (l *Lock) Lost() <-chan struct{} { return l.lost }
+
+// Release stops renewing and releases the lock on the server. Releasing a
+// lease that has already been lost is not an error.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stopCh) })
+	l.wg.Wait()
+	return l.c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		_, rpcErr := cli.ReleaseLock(ctx, &pb.ReleaseLockRequest{
+			Name:  l.name,
+			Token: l.token,
+		})
+		return rpcErr
+	})
+}
+
+func (l *Lock) renewLoop(ctx context.Context) {
+	defer l.wg.Done()
+
+	interval := max(l.ttl/3, 10*time.Millisecond)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// The lease is only known to be valid until the last successful renewal
+	// plus ttl; measure from before the request was sent.
+	validUntil := time.Now().Add(l.ttl)
+
+	for {
+		select {
+		case <-l.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		sent := time.Now()
+		var renewed bool
+		callCtx, cancel := context.WithTimeout(ctx, interval)
+		err := l.c.retryableCall(callCtx, func(cli pb.CacheServiceClient) error {
+			resp, rpcErr := cli.RenewLock(callCtx, &pb.RenewLockRequest{
+				Name:  l.name,
+				Token: l.token,
+				Ttl:   l.ttl.String(),
+			})
+			if rpcErr != nil {
+				return rpcErr
+			}
+			renewed = resp.Renewed
+			return nil
+		})
+		cancel()
+
+		switch {
+		case err == nil && renewed:
+			validUntil = sent.Add(l.ttl)
+		case err == nil && !renewed:
+			l.markLost()
+			return
+		case time.Now().After(validUntil):
+			l.markLost()
+			return
+		}
+	}
+}
+
+func (l *Lock) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
+
+func newLockOwner() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Lock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	cli := newTestClient(t)
+	defer cli.Close()
+	ctx := context.Background()
+
+	t.Run("AcquireRenewRelease", func(t *testing.T) {
+		lock, err := cli.AcquireLock(ctx, "client-lock", 150*time.Millisecond, 0)
+		require.NoError(t, err)
+		assert.NotZero(t, lock.Token())
+
+		// Outlive several leases; the background renewal must keep it held.
+		time.Sleep(400 * time.Millisecond)
+		select {
+		case <-lock.Lost():
+			t.Fatal("lease lost while renewing")
+		default:
+		}
+
+		_, err = cli.AcquireLock(ctx, "client-lock", time.Second, 0)
+		assert.ErrorIs(t, err, ErrLockNotAcquired)
+
+		require.NoError(t, lock.Release(ctx))
+
+		next, err := cli.AcquireLock(ctx, "client-lock", time.Second, 0)
+		require.NoError(t, err)
+		assert.Greater(t, next.Token(), lock.Token())
+		require.NoError(t, next.Release(ctx))
+	})
+
+	t.Run("WaitForRelease", func(t *testing.T) {
+		first, err := cli.AcquireLock(ctx, "client-wait", time.Second, 0)
+		require.NoError(t, err)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_ = first.Release(ctx)
+		}()
+
+		second, err := cli.AcquireLock(ctx, "client-wait", time.Second, 2*time.Second)
+		require.NoError(t, err)
+		assert.Greater(t, second.Token(), first.Token())
+		require.NoError(t, second.Release(ctx))
+	})
+
+	t.Run("LostAfterTakeover", func(t *testing.T) {
+		lock, err := cli.AcquireLock(ctx, "client-lost", 150*time.Millisecond, 0)
+		require.NoError(t, err)
+
+		// Simulate the lease being taken over behind the holder's back.
+		_, err = cli.Del(ctx, "client-lost")
+		require.NoError(t, err)
+
+		select {
+		case <-lock.Lost():
+		case <-time.After(time.Second):
+			t.Fatal("expected lease loss to be reported")
+		}
+		require.NoError(t, lock.Release(ctx))
+	})
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+
+	"github.com/lushenle/simple-cache/pkg/pb"
+)
+
+// BFReserve creates an empty Bloom filter sized for capacity items at the
+// given false positive rate.
+func (c *Client) BFReserve(ctx context.Context, key string, errorRate float64, capacity uint64) error {
+	return c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		_, rpcErr := cli.BFReserve(ctx, &pb.BFReserveRequest{
+			Key:       key,
+			ErrorRate: errorRate,
+			Capacity:  capacity,
+		})
+		return rpcErr
+	})
+}
+
+// BFAdd adds items to a Bloom filter. For each item it reports whether it
+// was newly added.
+func (c *Client) BFAdd(ctx context.Context, key string, items ...string) ([]bool, error) {
+	var added []bool
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.BFAdd(ctx, &pb.BFAddRequest{Key: key, Items: items})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		added = resp.Added
+		return nil
+	})
+	return added, err
+}
+
+// BFExists reports for each item whether it may be in the Bloom filter.
+func (c *Client) BFExists(ctx context.Context, key string, items ...string) ([]bool, error) {
+	var exists []bool
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.BFExists(ctx, &pb.BFExistsRequest{Key: key, Items: items})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		exists = resp.Exists
+		return nil
+	})
+	return exists, err
+}
+
+// PFAdd adds items to a HyperLogLog.
+func (c *Client) PFAdd(ctx context.Context, key string, items ...string) (bool, error) {
+	var changed bool
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.PFAdd(ctx, &pb.PFAddRequest{Key: key, Items: items})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		changed = resp.Changed
+		return nil
+	})
+	return changed, err
+}
+
+// PFCount estimates the distinct count of the union of the given keys.
+func (c *Client) PFCount(ctx context.Context, keys ...string) (uint64, error) {
+	var count uint64
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.PFCount(ctx, &pb.PFCountRequest{Keys: keys})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		count = resp.Count
+		return nil
+	})
+	return count, err
+}
+
+// PFMerge stores the union of dest and sources into dest.
+func (c *Client) PFMerge(ctx context.Context, dest string, sources ...string) error {
+	return c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		_, rpcErr := cli.PFMerge(ctx, &pb.PFMergeRequest{Dest: dest, Sources: sources})
+		return rpcErr
+	})
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClient_Probabilistic(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	cli := newTestClient(t)
+	defer cli.Close()
+	ctx := context.Background()
+
+	t.Run("Bloom", func(t *testing.T) {
+		require.NoError(t, cli.BFReserve(ctx, "client-bf", 0.01, 100))
+		err := cli.BFReserve(ctx, "client-bf", 0.01, 100)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		added, err := cli.BFAdd(ctx, "client-bf", "a", "b", "a")
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true, true, false}, added)
+
+		exists, err := cli.BFExists(ctx, "client-bf", "a", "zzz")
+		require.NoError(t, err)
+		assert.True(t, exists[0])
+	})
+
+	t.Run("HyperLogLog", func(t *testing.T) {
+		_, err := cli.PFAdd(ctx, "client-hll-1", "a", "b", "c")
+		require.NoError(t, err)
+		_, err = cli.PFAdd(ctx, "client-hll-2", "c", "d")
+		require.NoError(t, err)
+
+		count, err := cli.PFCount(ctx, "client-hll-1", "client-hll-2")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), count)
+
+		require.NoError(t, cli.PFMerge(ctx, "client-hll-all", "client-hll-1", "client-hll-2"))
+		count, err = cli.PFCount(ctx, "client-hll-all")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), count)
+	})
+}
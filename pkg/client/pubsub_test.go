@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PublishSubscribe(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	cli := newTestClient(t)
+	defer cli.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msgs, err := cli.Subscribe(ctx, []string{"client-news"}, []string{"client-inv:*"})
+	require.NoError(t, err)
+
+	// The subscription is registered asynchronously on the server; publish
+	// until the first message arrives.
+	require.Eventually(t, func() bool {
+		if err := cli.Publish(ctx, "client-news", []byte("n1")); err != nil {
+			return false
+		}
+		select {
+		case msg := <-msgs:
+			return msg.Channel == "client-news"
+		case <-time.After(20 * time.Millisecond):
+			return false
+		}
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, cli.Publish(ctx, "client-inv:user:1", []byte("u1")))
+	for msg := range msgs {
+		if msg.Channel == "client-news" {
+			continue // extra deliveries from the warm-up loop
+		}
+		assert.Equal(t, "client-inv:user:1", msg.Channel)
+		assert.Equal(t, "client-inv:*", msg.Pattern)
+		assert.Equal(t, []byte("u1"), msg.Payload)
+		break
+	}
+
+	_, found, err := cli.Get(ctx, "client-news")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
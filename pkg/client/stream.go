@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/pb"
+)
+
+// XAdd appends fields to the stream under key and returns the new entry ID.
+// maxLen and maxAge trim the stream after the append (zero disables each).
+func (c *Client) XAdd(ctx context.Context, key string, fields map[string]string, maxLen int64, maxAge time.Duration) (string, error) {
+	var id string
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.XAdd(ctx, &pb.XAddRequest{
+			Key:    key,
+			Fields: fields,
+			MaxLen: maxLen,
+			MaxAge: formatTTL(maxAge),
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		id = resp.Id
+		return nil
+	})
+	return id, err
+}
+
+// XRange returns up to count entries between start and end inclusive.
+// Use "-" and "+" for the first and last entry.
+func (c *Client) XRange(ctx context.Context, key, start, end string, count int32) ([]*pb.StreamEntry, error) {
+	var entries []*pb.StreamEntry
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.XRange(ctx, &pb.XRangeRequest{
+			Key:   key,
+			Start: start,
+			End:   end,
+			Count: count,
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		entries = resp.Entries
+		return nil
+	})
+	return entries, err
+}
+
+// XRead returns up to count entries after the given ID, waiting up to block
+// for new entries when none are available. Use "$" to read only new entries.
+func (c *Client) XRead(ctx context.Context, key, after string, count int32, block time.Duration) ([]*pb.StreamEntry, error) {
+	var entries []*pb.StreamEntry
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.XRead(ctx, &pb.XReadRequest{
+			Key:   key,
+			After: after,
+			Count: count,
+			Block: formatTTL(block),
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		entries = resp.Entries
+		return nil
+	})
+	return entries, err
+}
+
+// XTrim caps the stream by length and/or age and returns how many entries
+// were removed.
+func (c *Client) XTrim(ctx context.Context, key string, maxLen int64, maxAge time.Duration) (int64, error) {
+	var trimmed int64
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.XTrim(ctx, &pb.XTrimRequest{
+			Key:    key,
+			MaxLen: maxLen,
+			MaxAge: formatTTL(maxAge),
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		trimmed = resp.Trimmed
+		return nil
+	})
+	return trimmed, err
+}
+
+// XGroupCreate creates a consumer group starting at start ("0", "$" or an
+// entry ID). It returns false if the group already exists.
+func (c *Client) XGroupCreate(ctx context.Context, key, group, start string) (bool, error) {
+	var created bool
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.XGroupCreate(ctx, &pb.XGroupCreateRequest{
+			Key:   key,
+			Group: group,
+			Start: start,
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		created = resp.Created
+		return nil
+	})
+	return created, err
+}
+
+// XReadGroup returns up to count entries after the group's committed offset,
+// waiting up to block for new entries. Entries are returned again until
+// they are committed with XCommit.
+func (c *Client) XReadGroup(ctx context.Context, key, group string, count int32, block time.Duration) ([]*pb.StreamEntry, error) {
+	var entries []*pb.StreamEntry
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.XReadGroup(ctx, &pb.XReadGroupRequest{
+			Key:   key,
+			Group: group,
+			Count: count,
+			Block: formatTTL(block),
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		entries = resp.Entries
+		return nil
+	})
+	return entries, err
+}
+
+// XCommit advances the group's committed offset to id.
+func (c *Client) XCommit(ctx context.Context, key, group, id string) (bool, error) {
+	var committed bool
+	err := c.retryableCall(ctx, func(cli pb.CacheServiceClient) error {
+		resp, rpcErr := cli.XCommit(ctx, &pb.XCommitRequest{
+			Key:   key,
+			Group: group,
+			Id:    id,
+		})
+		if rpcErr != nil {
+			return rpcErr
+		}
+		committed = resp.Committed
+		return nil
+	})
+	return committed, err
+}
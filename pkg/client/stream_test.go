@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Stream(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	cli := newTestClient(t)
+	defer cli.Close()
+	ctx := context.Background()
+
+	t.Run("AddRange", func(t *testing.T) {
+		id1, err := cli.XAdd(ctx, "client-stream", map[string]string{"n": "1"}, 0, 0)
+		require.NoError(t, err)
+		_, err = cli.XAdd(ctx, "client-stream", map[string]string{"n": "2"}, 0, 0)
+		require.NoError(t, err)
+
+		entries, err := cli.XRange(ctx, "client-stream", "-", "+", 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, id1, entries[0].Id)
+	})
+
+	t.Run("BlockingRead", func(t *testing.T) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_, _ = cli.XAdd(ctx, "client-block", map[string]string{"late": "yes"}, 0, 0)
+		}()
+		entries, err := cli.XRead(ctx, "client-block", "$", 10, 2*time.Second)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "yes", entries[0].Fields["late"])
+
+		entries, err = cli.XRead(ctx, "client-block", "$", 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("ConsumerGroup", func(t *testing.T) {
+		created, err := cli.XGroupCreate(ctx, "client-jobs", "workers", "0")
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		_, err = cli.XAdd(ctx, "client-jobs", map[string]string{"job": "a"}, 0, 0)
+		require.NoError(t, err)
+
+		entries, err := cli.XReadGroup(ctx, "client-jobs", "workers", 10, time.Second)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		committed, err := cli.XCommit(ctx, "client-jobs", "workers", entries[0].Id)
+		require.NoError(t, err)
+		assert.True(t, committed)
+
+		entries, err = cli.XReadGroup(ctx, "client-jobs", "workers", 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Trim", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			_, err := cli.XAdd(ctx, "client-trim", map[string]string{"i": "x"}, 0, 0)
+			require.NoError(t, err)
+		}
+		trimmed, err := cli.XTrim(ctx, "client-trim", 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), trimmed)
+	})
+}
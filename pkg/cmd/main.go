@@ -5,6 +5,7 @@ import (
 	"embed"
 	"encoding/json"
 	"errors"
+	"io"
 	"io/fs"
 	"net"
 	"net/http"
@@ -16,9 +17,11 @@ import (
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/lushenle/simple-cache/pkg/aof"
 	"github.com/lushenle/simple-cache/pkg/cache"
 	"github.com/lushenle/simple-cache/pkg/common"
 	"github.com/lushenle/simple-cache/pkg/config"
+	"github.com/lushenle/simple-cache/pkg/encrypt"
 	"github.com/lushenle/simple-cache/pkg/log"
 	"github.com/lushenle/simple-cache/pkg/metrics"
 	"github.com/lushenle/simple-cache/pkg/pb"
@@ -80,30 +83,84 @@ func main() {
 		}
 	}()
 
+	keys, err := encrypt.LoadKeyring(cfg.EncryptionKeyFile, cfg.EncryptionKey)
+	if err != nil {
+		logger.Fatal("failed to load encryption keys", zap.Error(err))
+	}
+	if keys != nil {
+		logger.Info("encryption at rest enabled", zap.String("active_key_id", keys.ActiveID()))
+	}
+
 	// Create a new gRPC server
 	c := cache.NewWithLimits(30*time.Second, cfg.MaxKeys, cfg.MaxValueSize, cfg.EvictionPolicy, logger)
+	c.UseKeyring(keys)
+	c.SetDumpCompression(cfg.DumpCompression)
 	srv := server.New(c, cfg.NodeID)
 
-	// Auto-load from dump file on startup. Distributed mode relies on WAL replay instead.
-	if cfg.LoadOnStartup && !cfg.Mode.IsDistributed() {
-		defaultPath := cache.DefaultDumpPath(cfg.NodeID, cfg.DumpFormat.String(), cfg.DataDir)
-		if _, err := os.Stat(defaultPath); err == nil {
-			result, err := c.Load(cfg.NodeID, defaultPath)
-			if err != nil {
-				logger.Warn("failed to load cache from dump file", zap.Error(err), zap.String("path", defaultPath))
-			} else if result != nil && result.LoadedKeys > 0 {
-				logger.Info("loaded cache from dump file",
-					zap.String("path", result.Path),
-					zap.Int32("loaded", result.LoadedKeys),
-					zap.Int32("skipped", result.SkippedKeys),
-				)
-			}
+	// Pub/sub must be attached before the Raft node starts applying entries.
+	pubsubSvc := server.NewPubSubService()
+	srv.SetPubSubService(pubsubSvc)
+
+	// Auto-load from dump file on startup. Distributed mode relies on WAL replay instead,
+	// and an existing AOF replaces the dump as the source of truth.
+	aofDir := filepath.Join(cfg.DataDir, "aof-"+cfg.NodeID)
+	if cfg.LoadOnStartup && !cfg.Mode.IsDistributed() && !(cfg.AOFEnabled && aof.Exists(aofDir)) {
+		// Scheduled and shutdown dumps are both candidates; the newest one
+		// that passes its checksum wins.
+		result, err := c.LoadLatest(cfg.NodeID, cfg.DataDir)
+		if err != nil {
+			logger.Warn("failed to load cache from dump file", zap.Error(err), zap.String("data_dir", cfg.DataDir))
+		} else if result != nil && result.LoadedKeys > 0 {
+			logger.Info("loaded cache from dump file",
+				zap.String("path", result.Path),
+				zap.Int32("loaded", result.LoadedKeys),
+				zap.Int32("skipped", result.SkippedKeys),
+			)
+		}
+	}
+
+	// A new AOF starts from whatever the dump restored above.
+	var appendLog *aof.AOF
+	if cfg.AOFEnabled {
+		appendLog, err = aof.Open(aof.Options{
+			Dir:            aofDir,
+			NodeID:         cfg.NodeID,
+			Fsync:          cfg.AOFFsync,
+			RewriteMinSize: cfg.AOFRewriteMinSize,
+			RewritePercent: cfg.AOFRewritePercent,
+		}, c, logger)
+		if err != nil {
+			logger.Fatal("failed to open aof", zap.Error(err), zap.String("dir", aofDir))
 		}
+		srv.UseAOF(appendLog)
+	}
+
+	var dumpScheduler *cache.DumpScheduler
+	if cfg.DumpInterval > 0 {
+		dumpScheduler = c.StartDumpScheduler(cfg.NodeID, cfg.DumpFormat.String(), cfg.DataDir, cfg.DumpInterval, cfg.DumpRetention)
 	}
 
 	var raftNode *raft.Node
 	if cfg.Mode == common.ModeDistributed {
 		st := raft.NewStorage(filepath.Join(cfg.DataDir, "raft-"+cfg.NodeID+".wal"))
+		st.UseKeyring(keys)
+		var raftOpts []raft.NodeOption
+		if cfg.RaftLearner {
+			raftOpts = append(raftOpts, raft.AsLearner())
+		}
+		if cfg.RaftMaxSnapshot > 0 {
+			raftOpts = append(raftOpts, raft.WithMaxSnapshotSize(cfg.RaftMaxSnapshot))
+		}
+		if cfg.RaftTransport == common.RaftTransportGRPC {
+			raftOpts = append(raftOpts, raft.WithGRPCTransport())
+		}
+		if cfg.RaftTLSEnabled() {
+			tlsCfg, err := raft.LoadTransportTLS(cfg.RaftTLSCertFile, cfg.RaftTLSKeyFile, cfg.RaftTLSCAFile)
+			if err != nil {
+				logger.Fatal("failed to load raft tls", zap.Error(err))
+			}
+			raftOpts = append(raftOpts, raft.WithTransportTLS(tlsCfg))
+		}
 		var err error
 		raftNode, err = raft.NewNode(
 			cfg.NodeID,
@@ -117,12 +174,15 @@ func main() {
 			cfg.SnapshotThreshold,
 			logger,
 			cfg.AuthToken,
+			raftOpts...,
 		)
 		if err != nil {
 			logger.Fatal("failed to create raft node", zap.Error(err))
 		}
+		raftNode.SetProposalBatching(cfg.RaftBatchWindow, cfg.RaftMaxBatch)
 		srv.UseRaft(raftNode)
 	}
+	srv.SetReadPolicy(cfg.ReadPolicy)
 	if cfg.MaxQPS > 0 {
 		srv.SetRateLimiter(cfg.MaxQPS)
 	}
@@ -156,6 +216,7 @@ func main() {
 	}
 	grpcOptions := []grpc.ServerOption{
 		grpc.UnaryInterceptor(server.UnaryAuthInterceptor(cfg.AuthToken)),
+		grpc.StreamInterceptor(server.StreamAuthInterceptor(cfg.AuthToken)),
 	}
 	if cfg.EnableTLS {
 		creds, err := credentials.NewServerTLSFromFile(cfg.TLSCertFile, cfg.TLSKeyFile)
@@ -190,12 +251,20 @@ func main() {
 		logger.Error("gateway shutdown error", zap.Error(err))
 	}
 
-	// 4. Close raft node
+	// 4. Close raft node and AOF
 	if raftNode != nil {
 		raftNode.Close()
 	}
+	if appendLog != nil {
+		if err := appendLog.Close(); err != nil {
+			logger.Warn("failed to close aof", zap.Error(err))
+		}
+	}
 
-	// 5. Auto-dump cache before closing
+	// 5. Stop scheduled dumps, then auto-dump cache before closing
+	if dumpScheduler != nil {
+		dumpScheduler.Stop()
+	}
 	if cfg.DumpOnShutdown {
 		defaultPath := cache.DefaultDumpPath(cfg.NodeID, cfg.DumpFormat.String(), cfg.DataDir)
 		if result, err := c.Dump(cfg.NodeID, cfg.DumpFormat.String(), defaultPath); err != nil {
@@ -211,8 +280,9 @@ func main() {
 	// 6. Close cache (stops Set/Del operations that might publish events)
 	c.Close()
 
-	// 7. Close watch service (no more events to publish)
+	// 7. Close watch and pub/sub services (no more events to publish)
 	watchSvc.Close()
+	pubsubSvc.Close()
 
 	// 8. Stop config watcher
 	close(stop)
@@ -290,7 +360,13 @@ func runGatewayServer(ctx context.Context, waitGroup *errgroup.Group, svr *serve
 			http.Error(w, "invalid addr", http.StatusBadRequest)
 			return
 		}
-		err = svr.AddPeer(normalizedAddr)
+		// learner=true joins without a vote; promote it with /cluster/promote
+		// once it has caught up.
+		if r.URL.Query().Get("learner") == "true" {
+			err = svr.AddLearner(normalizedAddr)
+		} else {
+			err = svr.AddPeer(normalizedAddr)
+		}
 		if err != nil {
 			status := http.StatusInternalServerError
 			body := err.Error()
@@ -351,6 +427,83 @@ func runGatewayServer(ctx context.Context, waitGroup *errgroup.Group, svr *serve
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("left"))
 	})
+	mux.HandleFunc("/cluster/promote", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		type req struct {
+			Addr string `json:"addr"`
+		}
+		var in req
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid request body"))
+			return
+		}
+		normalizedAddr, err := raft.NormalizePeerAddr(in.Addr)
+		if err != nil {
+			http.Error(w, "invalid addr", http.StatusBadRequest)
+			return
+		}
+		// Wait up to 30s for the learner to catch up before promoting it.
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+		if err := svr.PromoteLearner(ctx, normalizedAddr); err != nil {
+			status := http.StatusInternalServerError
+			switch err.(type) {
+			case raft.ErrNotLeader:
+				status = http.StatusPreconditionFailed
+			case raft.ErrPeerNotFound:
+				status = http.StatusNotFound
+			case raft.ErrNotLearner, raft.ErrPeerChangeInFlight:
+				status = http.StatusConflict
+			case raft.ErrLearnerBehind:
+				status = http.StatusServiceUnavailable
+			case raft.ErrInvalidPeerChange:
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("promoted"))
+	})
+	mux.HandleFunc("/cluster/reconfigure", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var in raft.Reconfiguration
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid request body"))
+			return
+		}
+		// Wait up to 30s for the joint and the final configuration to
+		// commit; after that the leader still completes the change.
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+		if err := svr.Reconfigure(ctx, in); err != nil {
+			status := http.StatusInternalServerError
+			switch err.(type) {
+			case raft.ErrNotLeader:
+				status = http.StatusPreconditionFailed
+			case raft.ErrPeerNotFound:
+				status = http.StatusNotFound
+			case raft.ErrPeerExists, raft.ErrPeerChangeInFlight:
+				status = http.StatusConflict
+			case raft.ErrInvalidPeerChange:
+				status = http.StatusBadRequest
+			case raft.ErrCommit:
+				status = http.StatusGatewayTimeout
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("reconfigured"))
+	})
 	mux.HandleFunc("/cluster/peers", func(w http.ResponseWriter, r *http.Request) {
 		out, _ := json.Marshal(svr.Peers())
 		w.Header().Set("Content-Type", "application/json")
@@ -362,17 +515,50 @@ func runGatewayServer(ctx context.Context, waitGroup *errgroup.Group, svr *serve
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		if err := svr.StepDown(r.Context()); err != nil {
+		// An optional target (node ID or raft address) hands leadership to
+		// that node instead of leaving the election to chance.
+		var in struct {
+			Target string `json:"target"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("invalid request body"))
+				return
+			}
+		}
+		if q := r.URL.Query().Get("target"); q != "" {
+			in.Target = q
+		}
+		var err error
+		if in.Target != "" {
+			err = svr.TransferLeadership(r.Context(), in.Target)
+		} else {
+			err = svr.StepDown(r.Context())
+		}
+		if err != nil {
 			status := http.StatusInternalServerError
 			body := err.Error()
 			switch err.(type) {
 			case raft.ErrNotLeader:
 				status = http.StatusPreconditionFailed
+			case raft.ErrPeerNotFound:
+				status = http.StatusNotFound
+			case raft.ErrInvalidPeerChange:
+				status = http.StatusBadRequest
+			case raft.ErrTransferInProgress:
+				status = http.StatusConflict
+			case raft.ErrTransferFailed:
+				status = http.StatusServiceUnavailable
 			}
 			http.Error(w, body, status)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
+		if in.Target != "" {
+			w.Write([]byte("transferred"))
+			return
+		}
 		w.Write([]byte("stepped down"))
 	})
 
@@ -3,7 +3,9 @@ package command
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/lushenle/simple-cache/pkg/cache"
 	"github.com/lushenle/simple-cache/pkg/utils"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -14,6 +16,30 @@ const (
 	TypeDel       = "del"
 	TypeExpireKey = "expire_key"
 	TypeReset     = "reset"
+
+	TypeAcquireLock = "acquire_lock"
+	TypeRenewLock   = "renew_lock"
+	TypeReleaseLock = "release_lock"
+
+	TypePublish = "publish"
+
+	TypeXAdd         = "xadd"
+	TypeXTrim        = "xtrim"
+	TypeXGroupCreate = "xgroup_create"
+	TypeXCommit      = "xcommit"
+
+	TypeBFReserve = "bf_reserve"
+	TypeBFAdd     = "bf_add"
+	TypePFAdd     = "pf_add"
+	TypePFMerge   = "pf_merge"
+
+	TypeJSONSet       = "json_set"
+	TypeJSONDel       = "json_del"
+	TypeJSONNumIncrBy = "json_num_incr_by"
+
+	TypeImportBegin = "import_begin"
+	TypeImportBatch = "import_batch"
+	TypeImportEnd   = "import_end"
 )
 
 type encodedSetCommand struct {
@@ -31,6 +57,105 @@ type encodedExpireKeyCommand struct {
 	Expire string `json:"expire,omitempty"`
 }
 
+type encodedAcquireLockCommand struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"`
+	TTL   string `json:"ttl"`
+	Now   int64  `json:"now,omitempty"`
+	Index uint64 `json:"index,omitempty"`
+}
+
+type encodedRenewLockCommand struct {
+	Name  string `json:"name"`
+	Token uint64 `json:"token"`
+	TTL   string `json:"ttl"`
+	Now   int64  `json:"now,omitempty"`
+}
+
+type encodedReleaseLockCommand struct {
+	Name  string `json:"name"`
+	Token uint64 `json:"token"`
+	Now   int64  `json:"now,omitempty"`
+}
+
+type encodedPublishCommand struct {
+	Channel     string `json:"channel"`
+	Payload     []byte `json:"payload,omitempty"`
+	PublishedAt int64  `json:"published_at"`
+}
+
+type encodedXAddCommand struct {
+	Key    string            `json:"key"`
+	Fields map[string]string `json:"fields"`
+	MaxLen int64             `json:"max_len,omitempty"`
+	MaxAge string            `json:"max_age,omitempty"`
+	Now    int64             `json:"now"`
+}
+
+type encodedXTrimCommand struct {
+	Key    string `json:"key"`
+	MaxLen int64  `json:"max_len,omitempty"`
+	MaxAge string `json:"max_age,omitempty"`
+	Now    int64  `json:"now"`
+}
+
+type encodedXGroupCreateCommand struct {
+	Key   string `json:"key"`
+	Group string `json:"group"`
+	Start string `json:"start,omitempty"`
+}
+
+type encodedXCommitCommand struct {
+	Key   string `json:"key"`
+	Group string `json:"group"`
+	ID    string `json:"id"`
+}
+
+type encodedBFReserveCommand struct {
+	Key       string  `json:"key"`
+	ErrorRate float64 `json:"error_rate"`
+	Capacity  uint64  `json:"capacity"`
+}
+
+type encodedItemsCommand struct {
+	Key   string   `json:"key"`
+	Items []string `json:"items"`
+}
+
+type encodedPFMergeCommand struct {
+	Dest    string   `json:"dest"`
+	Sources []string `json:"sources"`
+}
+
+type encodedJSONSetCommand struct {
+	Key   string `json:"key"`
+	Path  string `json:"path,omitempty"`
+	Value string `json:"value"`
+}
+
+type encodedJSONDelCommand struct {
+	Key  string `json:"key"`
+	Path string `json:"path,omitempty"`
+}
+
+type encodedJSONNumIncrByCommand struct {
+	Key   string  `json:"key"`
+	Path  string  `json:"path,omitempty"`
+	Delta float64 `json:"delta"`
+}
+
+type encodedImportBeginCommand struct {
+	Pattern  string `json:"pattern,omitempty"`
+	UseRegex bool   `json:"use_regex,omitempty"`
+	Merge    bool   `json:"merge,omitempty"`
+	Fence    uint64 `json:"fence,omitempty"`
+}
+
+type encodedImportBatchCommand struct {
+	Entries []cache.DumpEntry `json:"entries"`
+	NowNano int64             `json:"now_nano,omitempty"`
+}
+
 // Encode serializes a replicated command into a stable type name and payload.
 func Encode(cmd interface{}) (string, []byte, error) {
 	switch c := cmd.(type) {
@@ -65,6 +190,172 @@ func Encode(cmd interface{}) (string, []byte, error) {
 		return TypeExpireKey, payload, nil
 	case *ResetCommand:
 		return TypeReset, []byte("{}"), nil
+	case *AcquireLockCommand:
+		payload, err := json.Marshal(encodedAcquireLockCommand{
+			Name:  c.Name,
+			Owner: c.Owner,
+			TTL:   c.TTL,
+			Now:   c.Now,
+			Index: c.index,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeAcquireLock, payload, nil
+	case *RenewLockCommand:
+		payload, err := json.Marshal(encodedRenewLockCommand{
+			Name:  c.Name,
+			Token: c.Token,
+			TTL:   c.TTL,
+			Now:   c.Now,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeRenewLock, payload, nil
+	case *ReleaseLockCommand:
+		payload, err := json.Marshal(encodedReleaseLockCommand{
+			Name:  c.Name,
+			Token: c.Token,
+			Now:   c.Now,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeReleaseLock, payload, nil
+	case *PublishCommand:
+		payload, err := json.Marshal(encodedPublishCommand{
+			Channel:     c.Channel,
+			Payload:     c.Payload,
+			PublishedAt: c.PublishedAt,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypePublish, payload, nil
+	case *XAddCommand:
+		payload, err := json.Marshal(encodedXAddCommand{
+			Key:    c.Key,
+			Fields: c.Fields,
+			MaxLen: c.MaxLen,
+			MaxAge: c.MaxAge,
+			Now:    c.Now,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeXAdd, payload, nil
+	case *XTrimCommand:
+		payload, err := json.Marshal(encodedXTrimCommand{
+			Key:    c.Key,
+			MaxLen: c.MaxLen,
+			MaxAge: c.MaxAge,
+			Now:    c.Now,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeXTrim, payload, nil
+	case *XGroupCreateCommand:
+		payload, err := json.Marshal(encodedXGroupCreateCommand{
+			Key:   c.Key,
+			Group: c.Group,
+			Start: c.Start,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeXGroupCreate, payload, nil
+	case *XCommitCommand:
+		payload, err := json.Marshal(encodedXCommitCommand{
+			Key:   c.Key,
+			Group: c.Group,
+			ID:    c.ID,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeXCommit, payload, nil
+	case *BFReserveCommand:
+		payload, err := json.Marshal(encodedBFReserveCommand{
+			Key:       c.Key,
+			ErrorRate: c.ErrorRate,
+			Capacity:  c.Capacity,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeBFReserve, payload, nil
+	case *BFAddCommand:
+		payload, err := json.Marshal(encodedItemsCommand{Key: c.Key, Items: c.Items})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeBFAdd, payload, nil
+	case *PFAddCommand:
+		payload, err := json.Marshal(encodedItemsCommand{Key: c.Key, Items: c.Items})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypePFAdd, payload, nil
+	case *PFMergeCommand:
+		payload, err := json.Marshal(encodedPFMergeCommand{
+			Dest:    c.Dest,
+			Sources: c.Sources,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypePFMerge, payload, nil
+	case *JSONSetCommand:
+		payload, err := json.Marshal(encodedJSONSetCommand{
+			Key:   c.Key,
+			Path:  c.Path,
+			Value: c.Value,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeJSONSet, payload, nil
+	case *JSONDelCommand:
+		payload, err := json.Marshal(encodedJSONDelCommand{Key: c.Key, Path: c.Path})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeJSONDel, payload, nil
+	case *JSONNumIncrByCommand:
+		payload, err := json.Marshal(encodedJSONNumIncrByCommand{
+			Key:   c.Key,
+			Path:  c.Path,
+			Delta: c.Delta,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeJSONNumIncrBy, payload, nil
+	case *ImportBeginCommand:
+		payload, err := json.Marshal(encodedImportBeginCommand{
+			Pattern:  c.Pattern,
+			UseRegex: c.UseRegex,
+			Merge:    c.Merge,
+			Fence:    c.Fence,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeImportBegin, payload, nil
+	case *ImportBatchCommand:
+		in := encodedImportBatchCommand{Entries: c.Entries}
+		if !c.Now.IsZero() {
+			in.NowNano = c.Now.UnixNano()
+		}
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return "", nil, err
+		}
+		return TypeImportBatch, payload, nil
+	case *ImportEndCommand:
+		return TypeImportEnd, []byte("{}"), nil
 	default:
 		return "", nil, fmt.Errorf("unsupported replicated command type: %T", cmd)
 	}
@@ -106,6 +397,169 @@ func Decode(kind string, payload []byte) (interface{}, error) {
 		}, nil
 	case TypeReset:
 		return &ResetCommand{}, nil
+	case TypeAcquireLock:
+		var in encodedAcquireLockCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &AcquireLockCommand{
+			Name:  in.Name,
+			Owner: in.Owner,
+			TTL:   in.TTL,
+			Now:   in.Now,
+			index: in.Index,
+		}, nil
+	case TypeRenewLock:
+		var in encodedRenewLockCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &RenewLockCommand{
+			Name:  in.Name,
+			Token: in.Token,
+			TTL:   in.TTL,
+			Now:   in.Now,
+		}, nil
+	case TypeReleaseLock:
+		var in encodedReleaseLockCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &ReleaseLockCommand{
+			Name:  in.Name,
+			Token: in.Token,
+			Now:   in.Now,
+		}, nil
+	case TypePublish:
+		var in encodedPublishCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &PublishCommand{
+			Channel:     in.Channel,
+			Payload:     in.Payload,
+			PublishedAt: in.PublishedAt,
+		}, nil
+	case TypeXAdd:
+		var in encodedXAddCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &XAddCommand{
+			Key:    in.Key,
+			Fields: in.Fields,
+			MaxLen: in.MaxLen,
+			MaxAge: in.MaxAge,
+			Now:    in.Now,
+		}, nil
+	case TypeXTrim:
+		var in encodedXTrimCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &XTrimCommand{
+			Key:    in.Key,
+			MaxLen: in.MaxLen,
+			MaxAge: in.MaxAge,
+			Now:    in.Now,
+		}, nil
+	case TypeXGroupCreate:
+		var in encodedXGroupCreateCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &XGroupCreateCommand{
+			Key:   in.Key,
+			Group: in.Group,
+			Start: in.Start,
+		}, nil
+	case TypeXCommit:
+		var in encodedXCommitCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &XCommitCommand{
+			Key:   in.Key,
+			Group: in.Group,
+			ID:    in.ID,
+		}, nil
+	case TypeBFReserve:
+		var in encodedBFReserveCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &BFReserveCommand{
+			Key:       in.Key,
+			ErrorRate: in.ErrorRate,
+			Capacity:  in.Capacity,
+		}, nil
+	case TypeBFAdd:
+		var in encodedItemsCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &BFAddCommand{Key: in.Key, Items: in.Items}, nil
+	case TypePFAdd:
+		var in encodedItemsCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &PFAddCommand{Key: in.Key, Items: in.Items}, nil
+	case TypePFMerge:
+		var in encodedPFMergeCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &PFMergeCommand{Dest: in.Dest, Sources: in.Sources}, nil
+	case TypeJSONSet:
+		var in encodedJSONSetCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &JSONSetCommand{
+			Key:   in.Key,
+			Path:  in.Path,
+			Value: in.Value,
+		}, nil
+	case TypeJSONDel:
+		var in encodedJSONDelCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &JSONDelCommand{Key: in.Key, Path: in.Path}, nil
+	case TypeJSONNumIncrBy:
+		var in encodedJSONNumIncrByCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &JSONNumIncrByCommand{
+			Key:   in.Key,
+			Path:  in.Path,
+			Delta: in.Delta,
+		}, nil
+	case TypeImportBegin:
+		var in encodedImportBeginCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		return &ImportBeginCommand{
+			Pattern:  in.Pattern,
+			UseRegex: in.UseRegex,
+			Merge:    in.Merge,
+			Fence:    in.Fence,
+		}, nil
+	case TypeImportBatch:
+		var in encodedImportBatchCommand
+		if err := json.Unmarshal(payload, &in); err != nil {
+			return nil, err
+		}
+		out := &ImportBatchCommand{Entries: in.Entries}
+		if in.NowNano != 0 {
+			out.Now = time.Unix(0, in.NowNano)
+		}
+		return out, nil
+	case TypeImportEnd:
+		return &ImportEndCommand{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported replicated command kind: %s", kind)
 	}
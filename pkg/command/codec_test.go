@@ -2,7 +2,9 @@ package command
 
 import (
 	"testing"
+	"time"
 
+	"github.com/lushenle/simple-cache/pkg/cache"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -38,3 +40,144 @@ func TestEncodeDecodeDelCommand(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "k1", decoded.(*DelCommand).Key)
 }
+
+func TestEncodeDecodeLockCommands(t *testing.T) {
+	kind, payload, err := Encode(&AcquireLockCommand{Name: "jobs", Owner: "w1", TTL: "10s", Now: 42})
+	require.NoError(t, err)
+	require.Equal(t, TypeAcquireLock, kind)
+	decoded, err := Decode(kind, payload)
+	require.NoError(t, err)
+	acquire := decoded.(*AcquireLockCommand)
+	require.Equal(t, "jobs", acquire.Name)
+	require.Equal(t, "w1", acquire.Owner)
+	require.Equal(t, "10s", acquire.TTL)
+	require.Equal(t, int64(42), acquire.Now)
+
+	kind, payload, err = Encode(&RenewLockCommand{Name: "jobs", Token: 7, TTL: "10s", Now: 43})
+	require.NoError(t, err)
+	require.Equal(t, TypeRenewLock, kind)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), decoded.(*RenewLockCommand).Token)
+	require.Equal(t, int64(43), decoded.(*RenewLockCommand).Now)
+
+	kind, payload, err = Encode(&ReleaseLockCommand{Name: "jobs", Token: 7, Now: 44})
+	require.NoError(t, err)
+	require.Equal(t, TypeReleaseLock, kind)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), decoded.(*ReleaseLockCommand).Token)
+	require.Equal(t, int64(44), decoded.(*ReleaseLockCommand).Now)
+}
+
+func TestEncodeDecodeStreamCommands(t *testing.T) {
+	kind, payload, err := Encode(&XAddCommand{
+		Key:    "events",
+		Fields: map[string]string{"a": "1"},
+		MaxLen: 100,
+		MaxAge: "1h",
+		Now:    12345,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TypeXAdd, kind)
+	decoded, err := Decode(kind, payload)
+	require.NoError(t, err)
+	add := decoded.(*XAddCommand)
+	require.Equal(t, map[string]string{"a": "1"}, add.Fields)
+	require.Equal(t, int64(100), add.MaxLen)
+	require.Equal(t, int64(12345), add.Now)
+
+	kind, payload, err = Encode(&XGroupCreateCommand{Key: "events", Group: "g", Start: "$"})
+	require.NoError(t, err)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, "$", decoded.(*XGroupCreateCommand).Start)
+
+	kind, payload, err = Encode(&XCommitCommand{Key: "events", Group: "g", ID: "1-0"})
+	require.NoError(t, err)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, "1-0", decoded.(*XCommitCommand).ID)
+
+	kind, payload, err = Encode(&XTrimCommand{Key: "events", MaxLen: 5, Now: 1})
+	require.NoError(t, err)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), decoded.(*XTrimCommand).MaxLen)
+}
+
+func TestEncodeDecodeProbabilisticCommands(t *testing.T) {
+	kind, payload, err := Encode(&BFReserveCommand{Key: "bf", ErrorRate: 0.01, Capacity: 500})
+	require.NoError(t, err)
+	require.Equal(t, TypeBFReserve, kind)
+	decoded, err := Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, uint64(500), decoded.(*BFReserveCommand).Capacity)
+
+	kind, payload, err = Encode(&BFAddCommand{Key: "bf", Items: []string{"a", "b"}})
+	require.NoError(t, err)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, decoded.(*BFAddCommand).Items)
+
+	kind, payload, err = Encode(&PFAddCommand{Key: "hll", Items: []string{"x"}})
+	require.NoError(t, err)
+	require.Equal(t, TypePFAdd, kind)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, "hll", decoded.(*PFAddCommand).Key)
+
+	kind, payload, err = Encode(&PFMergeCommand{Dest: "all", Sources: []string{"a", "b"}})
+	require.NoError(t, err)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, decoded.(*PFMergeCommand).Sources)
+}
+
+func TestEncodeDecodeJSONDocCommands(t *testing.T) {
+	kind, payload, err := Encode(&JSONSetCommand{Key: "doc", Path: "$.a", Value: `{"b":1}`})
+	require.NoError(t, err)
+	require.Equal(t, TypeJSONSet, kind)
+	decoded, err := Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, &JSONSetCommand{Key: "doc", Path: "$.a", Value: `{"b":1}`}, decoded)
+
+	kind, payload, err = Encode(&JSONDelCommand{Key: "doc", Path: "$.a"})
+	require.NoError(t, err)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, "$.a", decoded.(*JSONDelCommand).Path)
+
+	kind, payload, err = Encode(&JSONNumIncrByCommand{Key: "doc", Path: "$.n", Delta: 2.5})
+	require.NoError(t, err)
+	require.Equal(t, TypeJSONNumIncrBy, kind)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, 2.5, decoded.(*JSONNumIncrByCommand).Delta)
+}
+
+func TestEncodeDecodeImportCommands(t *testing.T) {
+	kind, payload, err := Encode(&ImportBeginCommand{Pattern: "tenant:*", Merge: true, Fence: 9})
+	require.NoError(t, err)
+	require.Equal(t, TypeImportBegin, kind)
+	decoded, err := Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, &ImportBeginCommand{Pattern: "tenant:*", Merge: true, Fence: 9}, decoded)
+
+	entries := []cache.DumpEntry{{Key: "k", Value: "v", ValueType: "string"}}
+	now := time.Unix(1700000000, 123)
+	kind, payload, err = Encode(&ImportBatchCommand{Entries: entries, Now: now})
+	require.NoError(t, err)
+	require.Equal(t, TypeImportBatch, kind)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, entries, decoded.(*ImportBatchCommand).Entries)
+	require.True(t, now.Equal(decoded.(*ImportBatchCommand).Now))
+
+	kind, payload, err = Encode(&ImportEndCommand{})
+	require.NoError(t, err)
+	require.Equal(t, TypeImportEnd, kind)
+	decoded, err = Decode(kind, payload)
+	require.NoError(t, err)
+	require.Equal(t, &ImportEndCommand{}, decoded)
+}
@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lushenle/simple-cache/pkg/cache"
 	"github.com/lushenle/simple-cache/pkg/pb"
@@ -71,6 +72,441 @@ func (c *SearchCommand) Apply(cache *cache.Cache) (interface{}, error) {
 	return &pb.SearchResponse{Keys: keys}, nil
 }
 
+// RejectedError is returned by the state machine for a command it refuses
+// deterministically, so that every replica refuses it the same way. The
+// Raft node hands it back to the proposer and keeps applying, unlike other
+// apply errors, which stop the node.
+type RejectedError struct {
+	Err error
+}
+
+func (e RejectedError) Error() string { return e.Err.Error() }
+
+func (e RejectedError) Unwrap() error { return e.Err }
+
+// Indexed is implemented by commands that need the Raft log index they were
+// committed at. The Raft node calls SetIndex before applying the command, so
+// every replica sees the same value.
+type Indexed interface {
+	SetIndex(index uint64)
+}
+
+// AcquireLockCommand takes a lease-based lock. The fencing token is the
+// commit index in distributed mode, or a local counter in single mode. Now
+// is the proposer's clock in Unix nanoseconds so every replica judges the
+// current lease and starts the new one at the same instant.
+type AcquireLockCommand struct {
+	Name  string
+	Owner string
+	TTL   string
+	Now   int64
+	index uint64
+}
+
+func (c *AcquireLockCommand) SetIndex(index uint64) {
+	c.index = index
+}
+
+func (c *AcquireLockCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Name); err != nil {
+		return &pb.AcquireLockResponse{}, err
+	}
+	ttl, err := parseLockTTL(c.TTL)
+	if err != nil {
+		return &pb.AcquireLockResponse{}, err
+	}
+	holder, acquired, err := cache.AcquireLock(c.Name, c.Owner, ttl, c.index, lockNow(c.Now))
+	if err != nil {
+		return &pb.AcquireLockResponse{}, err
+	}
+	return &pb.AcquireLockResponse{
+		Acquired: acquired,
+		Token:    holder.Token,
+		Owner:    holder.Owner,
+	}, nil
+}
+
+// RenewLockCommand extends a held lease. Now is the proposer's clock, as
+// in AcquireLockCommand.
+type RenewLockCommand struct {
+	Name  string
+	Token uint64
+	TTL   string
+	Now   int64
+}
+
+func (c *RenewLockCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Name); err != nil {
+		return &pb.RenewLockResponse{}, err
+	}
+	ttl, err := parseLockTTL(c.TTL)
+	if err != nil {
+		return &pb.RenewLockResponse{}, err
+	}
+	renewed, err := cache.RenewLock(c.Name, c.Token, ttl, lockNow(c.Now))
+	if err != nil {
+		return &pb.RenewLockResponse{}, err
+	}
+	return &pb.RenewLockResponse{Renewed: renewed}, nil
+}
+
+// ReleaseLockCommand frees a held lock. Now is the proposer's clock, as in
+// AcquireLockCommand.
+type ReleaseLockCommand struct {
+	Name  string
+	Token uint64
+	Now   int64
+}
+
+func (c *ReleaseLockCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Name); err != nil {
+		return &pb.ReleaseLockResponse{}, err
+	}
+	released, err := cache.ReleaseLock(c.Name, c.Token, lockNow(c.Now))
+	if err != nil {
+		return &pb.ReleaseLockResponse{}, err
+	}
+	return &pb.ReleaseLockResponse{Released: released}, nil
+}
+
+// XAddCommand appends an entry to a stream. Now is the proposer's clock in
+// Unix nanoseconds so every replica assigns the same entry ID and applies
+// the same age cutoff.
+type XAddCommand struct {
+	Key    string
+	Fields map[string]string
+	MaxLen int64
+	MaxAge string
+	Now    int64
+}
+
+func (c *XAddCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.XAddResponse{}, err
+	}
+	maxAge, err := parseOptionalDuration(c.MaxAge)
+	if err != nil {
+		return &pb.XAddResponse{}, err
+	}
+	id, err := cache.StreamAdd(c.Key, c.Fields, time.Unix(0, c.Now), int(c.MaxLen), maxAge)
+	if err != nil {
+		return &pb.XAddResponse{}, err
+	}
+	return &pb.XAddResponse{Id: id.String()}, nil
+}
+
+type XTrimCommand struct {
+	Key    string
+	MaxLen int64
+	MaxAge string
+	Now    int64
+}
+
+func (c *XTrimCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.XTrimResponse{}, err
+	}
+	maxAge, err := parseOptionalDuration(c.MaxAge)
+	if err != nil {
+		return &pb.XTrimResponse{}, err
+	}
+	trimmed, err := cache.StreamTrim(c.Key, time.Unix(0, c.Now), int(c.MaxLen), maxAge)
+	if err != nil {
+		return &pb.XTrimResponse{}, err
+	}
+	return &pb.XTrimResponse{Trimmed: int64(trimmed)}, nil
+}
+
+// XGroupCreateCommand creates a consumer group. Start is resolved at apply
+// time, so "$" means the last entry as of this point in the log.
+type XGroupCreateCommand struct {
+	Key   string
+	Group string
+	Start string
+}
+
+func (c *XGroupCreateCommand) Apply(cc *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.XGroupCreateResponse{}, err
+	}
+	var start cache.StreamID
+	switch c.Start {
+	case "", "0", "-":
+	case "$":
+		last, err := cc.StreamLastID(c.Key)
+		if err != nil {
+			return &pb.XGroupCreateResponse{}, err
+		}
+		start = last
+	default:
+		id, err := cache.ParseStreamID(c.Start)
+		if err != nil {
+			return &pb.XGroupCreateResponse{}, err
+		}
+		start = id
+	}
+	created, err := cc.StreamGroupCreate(c.Key, c.Group, start)
+	if err != nil {
+		return &pb.XGroupCreateResponse{}, err
+	}
+	return &pb.XGroupCreateResponse{Created: created}, nil
+}
+
+type XCommitCommand struct {
+	Key   string
+	Group string
+	ID    string
+}
+
+func (c *XCommitCommand) Apply(cc *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.XCommitResponse{}, err
+	}
+	id, err := cache.ParseStreamID(c.ID)
+	if err != nil {
+		return &pb.XCommitResponse{}, err
+	}
+	committed, err := cc.StreamCommit(c.Key, c.Group, id)
+	if err != nil {
+		return &pb.XCommitResponse{}, err
+	}
+	return &pb.XCommitResponse{Committed: committed}, nil
+}
+
+type BFReserveCommand struct {
+	Key       string
+	ErrorRate float64
+	Capacity  uint64
+}
+
+func (c *BFReserveCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.BFReserveResponse{Success: false}, err
+	}
+	if err := cache.BloomReserve(c.Key, c.ErrorRate, c.Capacity); err != nil {
+		return &pb.BFReserveResponse{Success: false}, err
+	}
+	return &pb.BFReserveResponse{Success: true}, nil
+}
+
+type BFAddCommand struct {
+	Key   string
+	Items []string
+}
+
+func (c *BFAddCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.BFAddResponse{}, err
+	}
+	added, err := cache.BloomAdd(c.Key, c.Items)
+	if err != nil {
+		return &pb.BFAddResponse{}, err
+	}
+	return &pb.BFAddResponse{Added: added}, nil
+}
+
+type PFAddCommand struct {
+	Key   string
+	Items []string
+}
+
+func (c *PFAddCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.PFAddResponse{}, err
+	}
+	changed, err := cache.HLLAdd(c.Key, c.Items)
+	if err != nil {
+		return &pb.PFAddResponse{}, err
+	}
+	return &pb.PFAddResponse{Changed: changed}, nil
+}
+
+type PFMergeCommand struct {
+	Dest    string
+	Sources []string
+}
+
+func (c *PFMergeCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Dest); err != nil {
+		return &pb.PFMergeResponse{Success: false}, err
+	}
+	if err := cache.HLLMerge(c.Dest, c.Sources...); err != nil {
+		return &pb.PFMergeResponse{Success: false}, err
+	}
+	return &pb.PFMergeResponse{Success: true}, nil
+}
+
+type JSONSetCommand struct {
+	Key   string
+	Path  string
+	Value string
+}
+
+func (c *JSONSetCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.JSONSetResponse{Success: false}, err
+	}
+	if err := cache.JSONSet(c.Key, c.Path, c.Value); err != nil {
+		return &pb.JSONSetResponse{Success: false}, err
+	}
+	return &pb.JSONSetResponse{Success: true}, nil
+}
+
+type JSONDelCommand struct {
+	Key  string
+	Path string
+}
+
+func (c *JSONDelCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.JSONDelResponse{}, err
+	}
+	deleted, err := cache.JSONDel(c.Key, c.Path)
+	if err != nil {
+		return &pb.JSONDelResponse{}, err
+	}
+	return &pb.JSONDelResponse{Deleted: deleted}, nil
+}
+
+type JSONNumIncrByCommand struct {
+	Key   string
+	Path  string
+	Delta float64
+}
+
+func (c *JSONNumIncrByCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	if err := validateKey(c.Key); err != nil {
+		return &pb.JSONNumIncrByResponse{}, err
+	}
+	value, err := cache.JSONNumIncrBy(c.Key, c.Path, c.Delta)
+	if err != nil {
+		return &pb.JSONNumIncrByResponse{}, err
+	}
+	return &pb.JSONNumIncrByResponse{Value: value}, nil
+}
+
+// ImportBeginCommand starts a replicated load. Unless Merge is set it
+// deletes the keys in scope: those matching Pattern, or every key. Fence is
+// the fencing counter recorded in the dump; later tokens exceed it.
+type ImportBeginCommand struct {
+	Pattern  string
+	UseRegex bool
+	Merge    bool
+	Fence    uint64
+}
+
+func (c *ImportBeginCommand) Apply(cc *cache.Cache) (interface{}, error) {
+	cc.RaiseFence(c.Fence)
+	if c.Merge {
+		return &pb.LoadResponse{Success: true}, nil
+	}
+	var filter *cache.KeyFilter
+	if c.Pattern != "" {
+		filter = &cache.KeyFilter{Pattern: c.Pattern, UseRegex: c.UseRegex}
+	}
+	if _, err := cc.DeleteMatching(filter); err != nil {
+		return &pb.LoadResponse{Success: false}, err
+	}
+	return &pb.LoadResponse{Success: true}, nil
+}
+
+// ImportBatchCommand writes one batch of a replicated load over the
+// existing keys. The leader has already filtered the entries. Now is the
+// leader's clock when it proposed the batch; entries that had expired by
+// then are skipped on every replica.
+type ImportBatchCommand struct {
+	Entries []cache.DumpEntry
+	Now     time.Time
+}
+
+func (c *ImportBatchCommand) Apply(cache *cache.Cache) (interface{}, error) {
+	loaded, skipped := cache.ImportEntries(c.Entries, c.Now)
+	return &pb.LoadResponse{
+		Success:     true,
+		TotalKeys:   int32(len(c.Entries)),
+		LoadedKeys:  int32(loaded),
+		SkippedKeys: int32(skipped),
+	}, nil
+}
+
+// ImportEndCommand ends a replicated load, lifting the write gate that
+// ImportBeginCommand set on every replica.
+type ImportEndCommand struct{}
+
+func (c *ImportEndCommand) Apply(*cache.Cache) (interface{}, error) {
+	return &pb.LoadResponse{Success: true}, nil
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration must not be negative: %s", s)
+	}
+	return d, nil
+}
+
+// Broker fans out published messages to subscribers on this node.
+type Broker interface {
+	Publish(channel string, payload []byte) int
+}
+
+// Deliverable is implemented by replicated commands that are handed to the
+// Broker instead of being applied to the cache.
+type Deliverable interface {
+	Deliver(b Broker) (interface{}, error)
+}
+
+// maxPublishDelay bounds how late a message may be delivered. Publishes
+// re-applied from the log after a restart, or by a follower that has fallen
+// far behind, are dropped rather than replayed to live subscribers.
+const maxPublishDelay = 30 * time.Second
+
+// PublishCommand broadcasts a message on a channel. It is replicated so that
+// every node delivers it to its own subscribers, but it never touches the
+// cache. PublishedAt is stamped by the proposing node in Unix nanoseconds.
+type PublishCommand struct {
+	Channel     string
+	Payload     []byte
+	PublishedAt int64
+}
+
+func (c *PublishCommand) Deliver(b Broker) (interface{}, error) {
+	if c.Channel == "" {
+		return &pb.PublishResponse{Success: false}, fmt.Errorf("channel must not be empty")
+	}
+	if b == nil || time.Since(time.Unix(0, c.PublishedAt)) > maxPublishDelay {
+		return &pb.PublishResponse{Success: true}, nil
+	}
+	b.Publish(c.Channel, c.Payload)
+	return &pb.PublishResponse{Success: true}, nil
+}
+
+// lockNow returns the clock a lock command was proposed with, or the local
+// clock for a command logged before lock commands carried one.
+func lockNow(now int64) time.Time {
+	if now == 0 {
+		return time.Now()
+	}
+	return time.Unix(0, now)
+}
+
+func parseLockTTL(ttl string) (time.Duration, error) {
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lock ttl %q: %w", ttl, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("lock ttl must be positive")
+	}
+	return d, nil
+}
+
 func validateKey(key string) error {
 	if key == "" {
 		return fmt.Errorf("key must not be empty")
@@ -65,3 +65,31 @@ func TestExpireKeyCommand(t *testing.T) {
 	_, found := c.Get("k1")
 	assert.True(t, found)
 }
+
+type recordingBroker struct {
+	channels []string
+}
+
+func (b *recordingBroker) Publish(channel string, payload []byte) int {
+	b.channels = append(b.channels, channel)
+	return 1
+}
+
+func TestPublishCommandDeliver(t *testing.T) {
+	b := &recordingBroker{}
+
+	resp, err := (&PublishCommand{Channel: "news", Payload: []byte("hi"), PublishedAt: time.Now().UnixNano()}).Deliver(b)
+	assert.Nil(t, err)
+	assert.True(t, resp.(*pb.PublishResponse).Success)
+	assert.Equal(t, []string{"news"}, b.channels)
+
+	// Stale publishes (e.g. replayed from the log after restart) are dropped.
+	stale := time.Now().Add(-time.Hour).UnixNano()
+	resp, err = (&PublishCommand{Channel: "news", PublishedAt: stale}).Deliver(b)
+	assert.Nil(t, err)
+	assert.True(t, resp.(*pb.PublishResponse).Success)
+	assert.Len(t, b.channels, 1)
+
+	_, err = (&PublishCommand{Channel: ""}).Deliver(b)
+	assert.Error(t, err)
+}
@@ -37,3 +37,33 @@ const (
 )
 
 func (f DumpFormat) String() string { return string(f) }
+
+type AOFFsync string
+
+const (
+	AOFFsyncAlways   AOFFsync = "always"
+	AOFFsyncEverySec AOFFsync = "everysec"
+	AOFFsyncNo       AOFFsync = "no"
+)
+
+func (f AOFFsync) String() string { return string(f) }
+
+// ReadPolicy selects which nodes serve reads in distributed mode.
+type ReadPolicy string
+
+const (
+	ReadPolicyLeader   ReadPolicy = "leader"
+	ReadPolicyFollower ReadPolicy = "follower"
+)
+
+func (p ReadPolicy) String() string { return string(p) }
+
+// RaftTransport selects how a node sends raft RPCs to its peers.
+type RaftTransport string
+
+const (
+	RaftTransportHTTP RaftTransport = "http"
+	RaftTransportGRPC RaftTransport = "grpc"
+)
+
+func (t RaftTransport) String() string { return string(t) }
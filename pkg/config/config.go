@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -13,32 +14,50 @@ import (
 )
 
 type Config struct {
-	Mode              common.Mode       `yaml:"mode"`
-	NodeID            string            `yaml:"node_id"`
-	GRPCAddr          string            `yaml:"grpc_addr"`
-	HTTPAddr          string            `yaml:"http_addr"`
-	RaftHTTPAddr      string            `yaml:"raft_http_addr"`
-	MetricsAddr       string            `yaml:"metrics_addr"`
-	Peers             []string          `yaml:"peers"`
-	PeerAddresses     map[string]string `yaml:"peer_addresses"` // nodeID → gRPC addr (Phase 2 leader discovery)
-	HeartbeatMS       int               `yaml:"heartbeat_ms"`
-	ElectionMS        int               `yaml:"election_ms"`
-	HotReload         bool              `yaml:"hot_reload"`
-	LoadOnStartup     bool              `yaml:"load_on_startup"`
-	DumpOnShutdown    bool              `yaml:"dump_on_shutdown"`
-	DumpFormat        common.DumpFormat `yaml:"dump_format"`
-	DataDir           string            `yaml:"data_dir"`
-	AuthToken         string            `yaml:"auth_token"`
-	EnableTLS         bool              `yaml:"enable_tls"`
-	TLSCertFile       string            `yaml:"tls_cert_file"`
-	TLSKeyFile        string            `yaml:"tls_key_file"`
-	AllowedOrigins    []string          `yaml:"allowed_origins"`
-	SnapshotEnabled   bool              `yaml:"snapshot_enabled"`
-	SnapshotThreshold uint64            `yaml:"snapshot_threshold"`
-	MaxKeys           int               `yaml:"max_keys"`        // max cache keys (0 = unlimited)
-	MaxValueSize      int               `yaml:"max_value_size"`  // max value size in bytes (0 = unlimited)
-	MaxQPS            int               `yaml:"max_qps"`         // max requests/sec per client (0 = unlimited)
-	EvictionPolicy    string            `yaml:"eviction_policy"` // "none" or "lru" (default "none")
+	Mode              common.Mode          `yaml:"mode"`
+	NodeID            string               `yaml:"node_id"`
+	GRPCAddr          string               `yaml:"grpc_addr"`
+	HTTPAddr          string               `yaml:"http_addr"`
+	RaftHTTPAddr      string               `yaml:"raft_http_addr"`
+	MetricsAddr       string               `yaml:"metrics_addr"`
+	Peers             []string             `yaml:"peers"`
+	PeerAddresses     map[string]string    `yaml:"peer_addresses"` // nodeID → gRPC addr (Phase 2 leader discovery)
+	HeartbeatMS       int                  `yaml:"heartbeat_ms"`
+	ElectionMS        int                  `yaml:"election_ms"`
+	ReadPolicy        common.ReadPolicy    `yaml:"read_policy"`        // "leader" or "follower" (distributed mode)
+	RaftBatchWindow   time.Duration        `yaml:"raft_batch_window"`  // how long the leader waits to group concurrent writes
+	RaftMaxBatch      int                  `yaml:"raft_max_batch"`     // most writes per group commit (0 = default)
+	RaftTransport     common.RaftTransport `yaml:"raft_transport"`     // "http" or "grpc" for sending raft RPCs
+	RaftTLSCertFile   string               `yaml:"raft_tls_cert_file"` // mTLS between raft peers (all three or none)
+	RaftTLSKeyFile    string               `yaml:"raft_tls_key_file"`
+	RaftTLSCAFile     string               `yaml:"raft_tls_ca_file"`
+	RaftLearner       bool                 `yaml:"raft_learner"`           // join as a non-voting learner until promoted
+	RaftMaxSnapshot   int64                `yaml:"raft_max_snapshot_size"` // bytes spooled for one InstallSnapshot (0 = 1 GiB)
+	HotReload         bool                 `yaml:"hot_reload"`
+	LoadOnStartup     bool                 `yaml:"load_on_startup"`
+	DumpOnShutdown    bool                 `yaml:"dump_on_shutdown"`
+	DumpFormat        common.DumpFormat    `yaml:"dump_format"`
+	DumpCompression   bool                 `yaml:"dump_compression"` // flate-compress binary dumps and raft snapshots
+	DataDir           string               `yaml:"data_dir"`
+	DumpInterval      time.Duration        `yaml:"dump_interval"`  // periodic dump interval (0 = off)
+	DumpRetention     int                  `yaml:"dump_retention"` // scheduled dumps to keep
+	AOFEnabled        bool                 `yaml:"aof_enabled"`
+	AOFFsync          common.AOFFsync      `yaml:"aof_fsync"`
+	AOFRewriteMinSize int64                `yaml:"aof_rewrite_min_size"`   // bytes; auto rewrite below this size never triggers
+	AOFRewritePercent int                  `yaml:"aof_rewrite_percentage"` // growth over the last base that triggers a rewrite (0 = off)
+	EncryptionKeyFile string               `yaml:"encryption_key_file"`    // keys for dumps, snapshots and WAL at rest
+	EncryptionKey     string               `yaml:"-"`                      // key text from SIMPLE_CACHE_ENCRYPTION_KEY
+	AuthToken         string               `yaml:"auth_token"`
+	EnableTLS         bool                 `yaml:"enable_tls"`
+	TLSCertFile       string               `yaml:"tls_cert_file"`
+	TLSKeyFile        string               `yaml:"tls_key_file"`
+	AllowedOrigins    []string             `yaml:"allowed_origins"`
+	SnapshotEnabled   bool                 `yaml:"snapshot_enabled"`
+	SnapshotThreshold uint64               `yaml:"snapshot_threshold"`
+	MaxKeys           int                  `yaml:"max_keys"`        // max cache keys (0 = unlimited)
+	MaxValueSize      int                  `yaml:"max_value_size"`  // max value size in bytes (0 = unlimited)
+	MaxQPS            int                  `yaml:"max_qps"`         // max requests/sec per client (0 = unlimited)
+	EvictionPolicy    string               `yaml:"eviction_policy"` // "none" or "lru" (default "none")
 }
 
 func Default() *Config {
@@ -51,11 +70,19 @@ func Default() *Config {
 		MetricsAddr:       ":2112",
 		HeartbeatMS:       200,
 		ElectionMS:        5000,
+		ReadPolicy:        common.ReadPolicyLeader,
+		RaftBatchWindow:   500 * time.Microsecond,
+		RaftMaxBatch:      256,
+		RaftTransport:     common.RaftTransportHTTP,
 		HotReload:         false,
 		LoadOnStartup:     true,
 		DumpOnShutdown:    true,
 		DumpFormat:        common.DumpFormatBinary,
 		DataDir:           "data",
+		DumpRetention:     5,
+		AOFFsync:          common.AOFFsyncEverySec,
+		AOFRewriteMinSize: 64 << 20,
+		AOFRewritePercent: 100,
 		AllowedOrigins:    nil,
 		SnapshotEnabled:   true,
 		SnapshotThreshold: 1024,
@@ -103,6 +130,9 @@ func (c *Config) OverrideFromEnv() {
 	if v := os.Getenv("SIMPLE_CACHE_DATA_DIR"); v != "" {
 		c.DataDir = v
 	}
+	if v := os.Getenv("SIMPLE_CACHE_ENCRYPTION_KEY"); v != "" {
+		c.EncryptionKey = v
+	}
 	if v := os.Getenv("SIMPLE_CACHE_MAX_KEYS"); v != "" {
 		if n, err := fmt.Sscanf(v, "%d", &c.MaxKeys); err == nil && n == 1 {
 		}
@@ -123,11 +153,22 @@ func (c *Config) OverrideFromEnv() {
 		if n, err := fmt.Sscanf(v, "%d", &c.ElectionMS); err == nil && n == 1 {
 		}
 	}
+	if v := os.Getenv("SIMPLE_CACHE_READ_POLICY"); v != "" {
+		c.ReadPolicy = common.ReadPolicy(v)
+	}
+	if v := os.Getenv("SIMPLE_CACHE_RAFT_TRANSPORT"); v != "" {
+		c.RaftTransport = common.RaftTransport(v)
+	}
 	if v := os.Getenv("SIMPLE_CACHE_EVICTION_POLICY"); v != "" {
 		c.EvictionPolicy = v
 	}
 }
 
+// RaftTLSEnabled reports whether raft RPCs between peers use mutual TLS.
+func (c *Config) RaftTLSEnabled() bool {
+	return c.RaftTLSCertFile != "" || c.RaftTLSKeyFile != "" || c.RaftTLSCAFile != ""
+}
+
 func (c *Config) Validate() error {
 	switch c.Mode {
 	case common.ModeSingle, common.ModeDistributed:
@@ -159,6 +200,61 @@ func (c *Config) Validate() error {
 	if c.ElectionMS <= c.HeartbeatMS {
 		return fmt.Errorf("election_ms (%d) must be greater than heartbeat_ms (%d)", c.ElectionMS, c.HeartbeatMS)
 	}
+	switch c.ReadPolicy {
+	case common.ReadPolicyLeader, common.ReadPolicyFollower, "":
+	default:
+		return fmt.Errorf("invalid read_policy: %q (expected 'leader' or 'follower')", c.ReadPolicy)
+	}
+	if c.RaftBatchWindow < 0 || c.RaftBatchWindow > 100*time.Millisecond {
+		return fmt.Errorf("raft_batch_window must be between 0 and 100ms")
+	}
+	if c.RaftMaxBatch < 0 {
+		return fmt.Errorf("raft_max_batch must not be negative")
+	}
+	if c.RaftMaxSnapshot < 0 {
+		return fmt.Errorf("raft_max_snapshot_size must not be negative")
+	}
+	switch c.RaftTransport {
+	case common.RaftTransportHTTP, common.RaftTransportGRPC, "":
+	default:
+		return fmt.Errorf("invalid raft_transport: %q (expected 'http' or 'grpc')", c.RaftTransport)
+	}
+	if c.RaftTLSEnabled() {
+		if c.RaftTLSCertFile == "" || c.RaftTLSKeyFile == "" || c.RaftTLSCAFile == "" {
+			return fmt.Errorf("raft_tls_cert_file, raft_tls_key_file and raft_tls_ca_file must be set together")
+		}
+		for _, peer := range c.Peers {
+			if !strings.HasPrefix(peer, "https://") {
+				return fmt.Errorf("peer %q must use https:// when raft tls is enabled", peer)
+			}
+		}
+	}
+	if c.DumpInterval < 0 || (c.DumpInterval > 0 && c.DumpInterval < time.Second) {
+		return fmt.Errorf("dump_interval must be 0 (off) or at least 1s")
+	}
+	if c.DumpInterval > 0 && c.DumpRetention < 1 {
+		return fmt.Errorf("dump_retention must be at least 1 when dump_interval is set")
+	}
+	if c.AOFEnabled {
+		if c.Mode.IsDistributed() {
+			return fmt.Errorf("aof_enabled is only supported in single mode")
+		}
+		switch c.AOFFsync {
+		case common.AOFFsyncAlways, common.AOFFsyncEverySec, common.AOFFsyncNo:
+		default:
+			return fmt.Errorf("invalid aof_fsync: %q (expected 'always', 'everysec' or 'no')", c.AOFFsync)
+		}
+		if c.AOFRewriteMinSize < 0 || c.AOFRewritePercent < 0 {
+			return fmt.Errorf("aof_rewrite_min_size and aof_rewrite_percentage must not be negative")
+		}
+	}
+	if c.EncryptionKeyFile != "" && c.EncryptionKey != "" {
+		return fmt.Errorf("set only one of encryption_key_file and SIMPLE_CACHE_ENCRYPTION_KEY")
+	}
+	if c.AOFEnabled && (c.EncryptionKeyFile != "" || c.EncryptionKey != "") {
+		// AOF files are not encrypted; refuse rather than write plaintext.
+		return fmt.Errorf("aof_enabled cannot be combined with encryption at rest")
+	}
 	if c.SnapshotEnabled && c.SnapshotThreshold == 0 {
 		return fmt.Errorf("snapshot_threshold must be > 0 when snapshot_enabled is true")
 	}
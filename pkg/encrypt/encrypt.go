@@ -0,0 +1,389 @@
+// Package encrypt provides AES-GCM encryption for data written to data_dir.
+//
+// Keys are identified by an ID that is stored next to every ciphertext, so
+// a keyring holding the current key plus older ones can still read data
+// written before a key rotation. Keys are given as text, one per line (or
+// separated by commas):
+//
+//	<id>:<base64 or hex AES key of 16, 24 or 32 bytes>
+//
+// The first key is the active one and is used for everything written; the
+// others are only used to decrypt.
+//
+// Files are encrypted as a stream of independently sealed segments:
+//
+//	header:  magic "SCEN" | version uint8 = 1 | id length uint8 | id | nonce prefix [7]byte
+//	segment: length uint32 (top bit set on the last segment) | ciphertext
+//
+// Each segment's nonce is the prefix, a uint32 segment counter and a final
+// flag byte, and the header is authenticated with every segment, so
+// reordered, truncated or re-keyed segments fail to decrypt.
+package encrypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Magic starts every encrypted stream.
+const Magic = "SCEN"
+
+const (
+	streamVersion    byte = 1
+	noncePrefixSize       = 7
+	segmentSize           = 64 << 10
+	finalSegmentFlag      = 1 << 31
+)
+
+// ErrNoKey is returned when encrypted data is read without a keyring.
+var ErrNoKey = errors.New("data is encrypted but no encryption key is configured")
+
+// Keyring holds the keys used to encrypt and decrypt data at rest. A nil
+// *Keyring is valid and means encryption is disabled.
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// LoadKeyring reads keys from keyFile or, if that is empty, from the key
+// text in env. It returns nil if both are empty.
+func LoadKeyring(keyFile, env string) (*Keyring, error) {
+	text := env
+	if keyFile != "" {
+		b, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read encryption key file: %w", err)
+		}
+		text = string(b)
+	}
+	if strings.TrimSpace(text) == "" {
+		if keyFile != "" {
+			return nil, fmt.Errorf("encryption key file %s has no keys", keyFile)
+		}
+		return nil, nil
+	}
+	return ParseKeyring(text)
+}
+
+// ParseKeyring parses key text in the format described in the package
+// documentation. Lines starting with '#' are ignored.
+func ParseKeyring(text string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]cipher.AEAD)}
+	fields := strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ',' })
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(field, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid key entry: expected <id>:<key>")
+		}
+		if len(id) > 255 {
+			return nil, fmt.Errorf("key id %.16q... is longer than 255 bytes", id)
+		}
+		if _, dup := k.keys[id]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+		raw, err := decodeKey(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		if k.active == "" {
+			k.active = id
+		}
+		k.keys[id] = aead
+	}
+	if k.active == "" {
+		return nil, fmt.Errorf("no encryption keys found")
+	}
+	return k, nil
+}
+
+func decodeKey(s string) ([]byte, error) {
+	for _, decode := range []func(string) ([]byte, error){
+		hex.DecodeString,
+		base64.StdEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+	} {
+		if b, err := decode(s); err == nil {
+			switch len(b) {
+			case 16, 24, 32:
+				return b, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("expected a base64 or hex encoded 16, 24 or 32 byte key")
+}
+
+// ActiveID returns the ID of the key used for new data.
+func (k *Keyring) ActiveID() string { return k.active }
+
+func (k *Keyring) aead(id string) (cipher.AEAD, error) {
+	if k == nil {
+		return nil, ErrNoKey
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key id %q", id)
+	}
+	return aead, nil
+}
+
+// IsEncrypted reports whether head, the first bytes of a file, starts an
+// encrypted stream.
+func IsEncrypted(head []byte) bool {
+	return len(head) >= len(Magic) && string(head[:len(Magic)]) == Magic
+}
+
+// Seal encrypts a single record with the active key:
+// id length uint8 | id | nonce | ciphertext.
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	aead := k.keys[k.active]
+	out := make([]byte, 0, 1+len(k.active)+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out = append(out, byte(len(k.active)))
+	out = append(out, k.active...)
+	nonce := out[len(out) : len(out)+aead.NonceSize()]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = out[:len(out)+aead.NonceSize()]
+	return aead.Seal(out, nonce, plaintext, out[:1+len(k.active)]), nil
+}
+
+// Open decrypts a record produced by Seal.
+func (k *Keyring) Open(record []byte) ([]byte, error) {
+	if len(record) < 1 || len(record) < 1+int(record[0]) {
+		return nil, fmt.Errorf("encrypted record too short")
+	}
+	idEnd := 1 + int(record[0])
+	aead, err := k.aead(string(record[1:idEnd]))
+	if err != nil {
+		return nil, err
+	}
+	if len(record) < idEnd+aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted record too short")
+	}
+	nonce := record[idEnd : idEnd+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, record[idEnd+aead.NonceSize():], record[:idEnd])
+	if err != nil {
+		return nil, fmt.Errorf("decrypt record: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Writer encrypts a stream with the active key. Close writes the final
+// segment and must be called for the stream to be readable; it does not
+// close the underlying writer.
+type Writer struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	nonce  []byte
+	seq    uint32
+	buf    []byte
+	out    []byte
+	closed bool
+}
+
+// NewWriter writes the stream header to w and returns a writer that
+// encrypts everything written to it.
+func (k *Keyring) NewWriter(w io.Writer) (*Writer, error) {
+	aead := k.keys[k.active]
+	header := make([]byte, 0, len(Magic)+2+len(k.active)+noncePrefixSize)
+	header = append(header, Magic...)
+	header = append(header, streamVersion, byte(len(k.active)))
+	header = append(header, k.active...)
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		w:      w,
+		aead:   aead,
+		header: header,
+		nonce:  append(prefix, make([]byte, aead.NonceSize()-noncePrefixSize)...),
+		buf:    make([]byte, 0, segmentSize),
+	}, nil
+}
+
+func (ew *Writer) Write(p []byte) (int, error) {
+	if ew.closed {
+		return 0, errors.New("write to closed encrypted stream")
+	}
+	n := 0
+	for len(p) > 0 {
+		// A full segment is only sealed once more data arrives, so the
+		// last one can be marked final by Close.
+		if len(ew.buf) == segmentSize {
+			if err := ew.seal(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(ew.buf[len(ew.buf):segmentSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close seals the final segment.
+func (ew *Writer) Close() error {
+	if ew.closed {
+		return nil
+	}
+	ew.closed = true
+	return ew.seal(true)
+}
+
+func (ew *Writer) seal(final bool) error {
+	if ew.seq == ^uint32(0) && !final {
+		return errors.New("encrypted stream too long")
+	}
+	setSegmentNonce(ew.nonce, ew.seq, final)
+	ew.seq++
+	ew.out = ew.aead.Seal(append(ew.out[:0], 0, 0, 0, 0), ew.nonce, ew.buf, ew.header)
+	word := uint32(len(ew.out) - 4)
+	if final {
+		word |= finalSegmentFlag
+	}
+	binary.BigEndian.PutUint32(ew.out, word)
+	ew.buf = ew.buf[:0]
+	_, err := ew.w.Write(ew.out)
+	return err
+}
+
+func setSegmentNonce(nonce []byte, seq uint32, final bool) {
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], seq)
+	nonce[len(nonce)-1] = 0
+	if final {
+		nonce[len(nonce)-1] = 1
+	}
+}
+
+// Reader decrypts a stream written by Writer.
+type Reader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	header []byte
+	nonce  []byte
+	seq    uint32
+	in     []byte
+	buf    []byte
+	done   bool
+}
+
+// NewReader reads the stream header from r and returns a reader over the
+// decrypted data. It fails if the key the stream was written with is not
+// in k.
+func NewReader(r io.Reader, k *Keyring) (*Reader, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	fixed := make([]byte, len(Magic)+2)
+	if _, err := io.ReadFull(br, fixed); err != nil {
+		return nil, fmt.Errorf("read encryption header: %w", err)
+	}
+	if !IsEncrypted(fixed) {
+		return nil, fmt.Errorf("not an encrypted stream")
+	}
+	if fixed[len(Magic)] != streamVersion {
+		return nil, fmt.Errorf("unsupported encryption version: %d", fixed[len(Magic)])
+	}
+	rest := make([]byte, int(fixed[len(Magic)+1])+noncePrefixSize)
+	if _, err := io.ReadFull(br, rest); err != nil {
+		return nil, fmt.Errorf("read encryption header: %w", err)
+	}
+	id := string(rest[:len(rest)-noncePrefixSize])
+	aead, err := k.aead(id)
+	if err != nil {
+		return nil, err
+	}
+	prefix := rest[len(id):]
+	return &Reader{
+		r:      br,
+		aead:   aead,
+		header: append(fixed, rest...),
+		nonce:  append(append([]byte(nil), prefix...), make([]byte, aead.NonceSize()-noncePrefixSize)...),
+	}, nil
+}
+
+func (er *Reader) Read(p []byte) (int, error) {
+	for len(er.buf) == 0 {
+		if er.done {
+			return 0, io.EOF
+		}
+		if err := er.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, er.buf)
+	er.buf = er.buf[n:]
+	return n, nil
+}
+
+func (er *Reader) next() error {
+	var word [4]byte
+	if _, err := io.ReadFull(er.r, word[:]); err != nil {
+		return fmt.Errorf("truncated encrypted stream at segment %d: %w", er.seq, io.ErrUnexpectedEOF)
+	}
+	length := binary.BigEndian.Uint32(word[:])
+	final := length&finalSegmentFlag != 0
+	length &^= finalSegmentFlag
+	if length > segmentSize+uint32(er.aead.Overhead()) {
+		return fmt.Errorf("invalid encrypted segment %d length: %d", er.seq, length)
+	}
+	if cap(er.in) < int(length) {
+		er.in = make([]byte, length)
+	}
+	er.in = er.in[:length]
+	if _, err := io.ReadFull(er.r, er.in); err != nil {
+		return fmt.Errorf("truncated encrypted stream at segment %d: %w", er.seq, io.ErrUnexpectedEOF)
+	}
+	setSegmentNonce(er.nonce, er.seq, final)
+	plaintext, err := er.aead.Open(er.in[:0], er.nonce, er.in, er.header)
+	if err != nil {
+		return fmt.Errorf("decrypt segment %d: %w", er.seq, err)
+	}
+	er.seq++
+	er.buf = plaintext
+	er.done = final
+	return nil
+}
+
+// PlaintextSize returns the size of the data in an encrypted stream of n
+// bytes, header included, written with the same key as this one's.
+func (er *Reader) PlaintextSize(n int64) int64 {
+	overhead := int64(4 + er.aead.Overhead()) // length word and tag per segment
+	n -= int64(len(er.header))
+	if n < overhead {
+		return 0
+	}
+	segments := (n + segmentSize + overhead - 1) / (segmentSize + overhead)
+	return n - segments*overhead
+}
@@ -0,0 +1,139 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T) string {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func encryptAll(t *testing.T, k *Keyring, data []byte) []byte {
+	var buf bytes.Buffer
+	w, err := k.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	k, err := ParseKeyring("k1:" + testKey(t))
+	require.NoError(t, err)
+	for _, size := range []int{0, 1, segmentSize - 1, segmentSize, segmentSize + 1, 3*segmentSize + 17} {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		enc := encryptAll(t, k, data)
+		assert.True(t, IsEncrypted(enc))
+		r, err := NewReader(bytes.NewReader(enc), k)
+		require.NoError(t, err)
+		assert.Equal(t, int64(size), r.PlaintextSize(int64(len(enc))), "size %d", size)
+		got, err := io.ReadAll(r)
+		require.NoError(t, err, "size %d", size)
+		assert.True(t, bytes.Equal(data, got), "size %d", size)
+	}
+}
+
+func TestStreamDetectsTamperingAndTruncation(t *testing.T) {
+	k, err := ParseKeyring("k1:" + testKey(t))
+	require.NoError(t, err)
+	data := bytes.Repeat([]byte("x"), 2*segmentSize+10)
+	enc := encryptAll(t, k, data)
+
+	read := func(b []byte) error {
+		r, err := NewReader(bytes.NewReader(b), k)
+		if err != nil {
+			return err
+		}
+		_, err = io.ReadAll(r)
+		return err
+	}
+	flipped := append([]byte(nil), enc...)
+	flipped[len(flipped)/2] ^= 1
+	assert.Error(t, read(flipped))
+	// Dropping the final segment must not look like a clean end.
+	assert.ErrorIs(t, read(enc[:len(enc)-(4+10+16)]), io.ErrUnexpectedEOF)
+	assert.Error(t, read(enc[:len(enc)-1]))
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, newKey := testKey(t), testKey(t)
+	old, err := ParseKeyring("2024:" + oldKey)
+	require.NoError(t, err)
+	enc := encryptAll(t, old, []byte("written before rotation"))
+	sealed, err := old.Seal([]byte("record"))
+	require.NoError(t, err)
+
+	rotated, err := ParseKeyring("# new key first\n2025:" + newKey + "\n2024:" + oldKey + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, "2025", rotated.ActiveID())
+	r, err := NewReader(bytes.NewReader(enc), rotated)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "written before rotation", string(got))
+	plain, err := rotated.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "record", string(plain))
+
+	retired, err := ParseKeyring("2025:" + newKey)
+	require.NoError(t, err)
+	_, err = NewReader(bytes.NewReader(enc), retired)
+	assert.ErrorContains(t, err, `unknown encryption key id "2024"`)
+	_, err = NewReader(bytes.NewReader(enc), nil)
+	assert.ErrorIs(t, err, ErrNoKey)
+}
+
+func TestSealOpen(t *testing.T) {
+	k, err := ParseKeyring("k1:" + testKey(t))
+	require.NoError(t, err)
+	a, err := k.Seal([]byte("same"))
+	require.NoError(t, err)
+	b, err := k.Seal([]byte("same"))
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+
+	plain, err := k.Open(a)
+	require.NoError(t, err)
+	assert.Equal(t, "same", string(plain))
+	a[len(a)-1] ^= 1
+	_, err = k.Open(a)
+	assert.Error(t, err)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	k, err := LoadKeyring("", "")
+	require.NoError(t, err)
+	assert.Nil(t, k)
+
+	hexKey := "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	k, err = LoadKeyring("", "a:"+hexKey+",b:"+testKey(t))
+	require.NoError(t, err)
+	assert.Equal(t, "a", k.ActiveID())
+
+	path := filepath.Join(t.TempDir(), "keys")
+	require.NoError(t, os.WriteFile(path, []byte("file:"+hexKey+"\n"), 0o600))
+	k, err = LoadKeyring(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, "file", k.ActiveID())
+
+	for _, bad := range []string{"nokey", "a:tooshort", "a:" + hexKey + ",a:" + hexKey, ":" + hexKey} {
+		_, err := ParseKeyring(bad)
+		assert.Error(t, err, bad)
+	}
+}
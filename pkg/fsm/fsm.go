@@ -1,18 +1,47 @@
 package fsm
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"sync/atomic"
 
+	"github.com/lushenle/simple-cache/pkg/aof"
 	"github.com/lushenle/simple-cache/pkg/cache"
+	"github.com/lushenle/simple-cache/pkg/command"
 	"github.com/lushenle/simple-cache/pkg/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ErrLoadInProgress rejects cache writes while a replicated load runs, so
+// they cannot interleave with the imported batches.
+var ErrLoadInProgress = status.Error(codes.Unavailable, "load in progress, writes are rejected")
+
+// snapshotMagic starts a snapshot that carries FSM state besides the cache
+// dump: magic | flags uint32. It is only written while a load runs, so
+// other snapshots stay plain cache dumps.
+const snapshotMagic = "SCFM"
+
+const snapshotFlagImporting = 1 << 0
+
 type Command interface {
 	Apply(c *cache.Cache) (interface{}, error)
 }
 
 type FSM struct {
 	Cache *cache.Cache
+	// Broker receives pub/sub messages. Nil drops them.
+	Broker command.Broker
+	// AOF logs applied writes in single mode. Nil disables logging.
+	AOF *aof.AOF
+
+	// importing is set between ImportBeginCommand and ImportEndCommand. It
+	// is part of the replicated state, so every node rejects the same
+	// writes during a load.
+	importing atomic.Bool
 }
 
 func New(c *cache.Cache) *FSM {
@@ -24,19 +53,80 @@ func (f *FSM) Apply(cmd interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("nil command")
 	}
 
+	if msg, ok := cmd.(command.Deliverable); ok {
+		return msg.Deliver(f.Broker)
+	}
+
 	realCmd, ok := cmd.(Command)
 	if !ok {
 		return nil, fmt.Errorf("invalid command type: %T", cmd)
 	}
 
-	return realCmd.Apply(f.Cache)
+	switch cmd.(type) {
+	case *command.ImportBeginCommand:
+		f.importing.Store(true)
+	case *command.ImportEndCommand:
+		f.importing.Store(false)
+	case *command.ImportBatchCommand:
+	default:
+		if f.importing.Load() {
+			return nil, command.RejectedError{Err: ErrLoadInProgress}
+		}
+	}
+
+	apply := func() (interface{}, error) {
+		resp, err := realCmd.Apply(f.Cache)
+		if err != nil {
+			// A command fails the same way on every replica, such as a
+			// value over max_value_size, so it must not stop the node.
+			err = command.RejectedError{Err: err}
+		}
+		return resp, err
+	}
+	if f.AOF != nil && aof.Logged(cmd) {
+		return f.AOF.Apply(cmd, apply)
+	}
+	return apply()
+}
+
+// Importing reports whether a replicated load has begun and not ended.
+func (f *FSM) Importing() bool {
+	return f.importing.Load()
 }
 
-func (f *FSM) Snapshot(nodeID string) ([]byte, error) {
-	return f.Cache.DumpToBytes(nodeID, common.DumpFormatBinary.String())
+func (f *FSM) Snapshot(nodeID string, w io.Writer) error {
+	if f.importing.Load() {
+		var hdr [8]byte
+		copy(hdr[:4], snapshotMagic)
+		binary.LittleEndian.PutUint32(hdr[4:], snapshotFlagImporting)
+		if _, err := w.Write(hdr[:]); err != nil {
+			return err
+		}
+	}
+	return f.Cache.DumpTo(w, nodeID, common.DumpFormatBinary.String())
 }
 
-func (f *FSM) RestoreSnapshot(nodeID string, data []byte) error {
-	_, err := f.Cache.LoadFromBytes(nodeID, data)
+func (f *FSM) RestoreSnapshot(nodeID string, r io.Reader) error {
+	_, err := f.Restore(nodeID, r)
 	return err
 }
+
+// Restore is RestoreSnapshot that also reports what was loaded into the
+// cache.
+func (f *FSM) Restore(nodeID string, r io.Reader) (*cache.LoadResult, error) {
+	br := bufio.NewReader(r)
+	var flags uint32
+	if magic, err := br.Peek(len(snapshotMagic)); err == nil && bytes.Equal(magic, []byte(snapshotMagic)) {
+		var hdr [8]byte
+		if _, err := io.ReadFull(br, hdr[:]); err != nil {
+			return nil, fmt.Errorf("read snapshot header: %w", err)
+		}
+		flags = binary.LittleEndian.Uint32(hdr[4:])
+	}
+	loaded, err := f.Cache.LoadFrom(br, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	f.importing.Store(flags&snapshotFlagImporting != 0)
+	return loaded, nil
+}
@@ -1,6 +1,9 @@
 package fsm
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,6 +12,8 @@ import (
 	"github.com/lushenle/simple-cache/pkg/log"
 	"github.com/lushenle/simple-cache/pkg/pb"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -37,3 +42,58 @@ func TestFSMApply(t *testing.T) {
 		assert.ErrorContains(t, err, "invalid command type")
 	})
 }
+
+func TestFSMSnapshotCompressed(t *testing.T) {
+	c := cache.New(time.Minute, zap.NewNop())
+	defer c.Close()
+	c.SetDumpCompression(true)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, c.Set(fmt.Sprintf("k%d", i), strings.Repeat("v", 100), ""))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, New(c).Snapshot("n1", &buf))
+	assert.Less(t, buf.Len(), 100*100)
+
+	restored := cache.New(time.Minute, zap.NewNop())
+	defer restored.Close()
+	require.NoError(t, New(restored).RestoreSnapshot("n1", &buf))
+	v, found := restored.Get("k99")
+	assert.True(t, found)
+	assert.Equal(t, strings.Repeat("v", 100), v)
+}
+
+func TestFSMImportGate(t *testing.T) {
+	c := cache.New(time.Minute, zap.NewNop())
+	defer c.Close()
+	f := New(c)
+
+	_, err := f.Apply(&command.ImportBeginCommand{Merge: true})
+	require.NoError(t, err)
+	_, err = f.Apply(&command.SetCommand{Key: "k", Value: "v"})
+	assert.ErrorIs(t, err, ErrLoadInProgress)
+	assert.ErrorAs(t, err, &command.RejectedError{})
+	_, err = f.Apply(&command.ImportBatchCommand{Entries: []cache.DumpEntry{{Key: "i", Value: "v", ValueType: "string"}}})
+	require.NoError(t, err)
+
+	// The gate survives a snapshot taken mid-load.
+	var buf bytes.Buffer
+	require.NoError(t, f.Snapshot("n1", &buf))
+	restored := cache.New(time.Minute, zap.NewNop())
+	defer restored.Close()
+	rf := New(restored)
+	require.NoError(t, rf.RestoreSnapshot("n1", &buf))
+	assert.True(t, rf.Importing())
+	_, found := restored.Get("i")
+	assert.True(t, found)
+
+	_, err = rf.Apply(&command.ImportEndCommand{})
+	require.NoError(t, err)
+	_, err = rf.Apply(&command.SetCommand{Key: "k", Value: "v"})
+	require.NoError(t, err)
+
+	buf.Reset()
+	require.NoError(t, rf.Snapshot("n1", &buf))
+	require.NoError(t, f.RestoreSnapshot("n1", &buf))
+	assert.False(t, f.Importing())
+}
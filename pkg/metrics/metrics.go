@@ -162,6 +162,14 @@ var (
 		},
 	)
 
+	RaftProposalBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "raft_proposal_batch_size",
+			Help:    "Number of proposals appended to the raft log per WAL write (group commit)",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+		},
+	)
+
 	PeersTotal = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "simple_cache_peers_total",
@@ -215,6 +223,20 @@ var (
 		},
 		[]string{"state"}, // loaded/skipped
 	)
+
+	ScheduledDumpLastSuccess = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cache_scheduled_dump_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful scheduled dump",
+		},
+	)
+
+	ScheduledDumpLastSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cache_scheduled_dump_last_size_bytes",
+			Help: "File size of the last successful scheduled dump",
+		},
+	)
 )
 
 func Init() {
@@ -235,6 +257,7 @@ func Init() {
 			RaftLastApplied,
 			RaftLeaderChanges,
 			RaftAppendEntriesLatency,
+			RaftProposalBatchSize,
 			PeersTotal,
 			RaftPendingEntries,
 			RaftSnapshotAge,
@@ -242,6 +265,8 @@ func Init() {
 			PersistenceDuration,
 			DumpKeysGauge,
 			LoadKeysGauge,
+			ScheduledDumpLastSuccess,
+			ScheduledDumpLastSize,
 		)
 
 		stopCh = make(chan struct{})
@@ -314,6 +339,7 @@ func SetRaftCommitIndex(v uint64)                 { RaftCommitIndex.Set(float64(
 func SetRaftLastApplied(v uint64)                 { RaftLastApplied.Set(float64(v)) }
 func IncRaftLeaderChanges()                       { RaftLeaderChanges.Inc() }
 func ObserveAppendEntriesLatency(d time.Duration) { RaftAppendEntriesLatency.Observe(d.Seconds()) }
+func ObserveRaftProposalBatch(n int)              { RaftProposalBatchSize.Observe(float64(n)) }
 func SetPeersTotal(n int)                         { PeersTotal.Set(float64(n)) }
 func SetRaftPendingEntries(n int)                 { RaftPendingEntries.Set(float64(n)) }
 func SetRaftSnapshotAge(seconds float64)          { RaftSnapshotAge.Set(seconds) }
@@ -332,6 +358,11 @@ func SetLoadKeys(loaded, skipped int64) {
 	LoadKeysGauge.WithLabelValues("skipped").Set(float64(skipped))
 }
 
+func SetScheduledDump(at time.Time, size int64) {
+	ScheduledDumpLastSuccess.Set(float64(at.Unix()))
+	ScheduledDumpLastSize.Set(float64(size))
+}
+
 func (m *InstrumentedRWMutex) Lock(opType LockOp) {
 	start := time.Now()
 	m.mu.Lock()
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
-// 	protoc        v5.29.3
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
 // source: batch_set.proto
 
 package pb
@@ -12,6 +12,7 @@ import (
 	anypb "google.golang.org/protobuf/types/known/anypb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -22,13 +23,12 @@ const (
 )
 
 type BatchSetRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         *anypb.Any             `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Expire        string                 `protobuf:"bytes,3,opt,name=expire,proto3" json:"expire,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Key    string     `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value  *anypb.Any `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
-	Expire string     `protobuf:"bytes,3,opt,name=expire,proto3" json:"expire,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BatchSetRequest) Reset() {
@@ -83,13 +83,14 @@ func (x *BatchSetRequest) GetExpire() string {
 }
 
 type BatchSetResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	SuccessCount int32                  `protobuf:"varint,1,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
+	ErrorCount   int32                  `protobuf:"varint,2,opt,name=error_count,json=errorCount,proto3" json:"error_count,omitempty"`
+	FirstError   string                 `protobuf:"bytes,3,opt,name=first_error,json=firstError,proto3" json:"first_error,omitempty"`
+	// commit_index is that of the last successful write, as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,4,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	SuccessCount int32  `protobuf:"varint,1,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
-	ErrorCount   int32  `protobuf:"varint,2,opt,name=error_count,json=errorCount,proto3" json:"error_count,omitempty"`
-	FirstError   string `protobuf:"bytes,3,opt,name=first_error,json=firstError,proto3" json:"first_error,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BatchSetResponse) Reset() {
@@ -143,40 +144,38 @@ func (x *BatchSetResponse) GetFirstError() string {
 	return ""
 }
 
+func (x *BatchSetResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
 var File_batch_set_proto protoreflect.FileDescriptor
 
-var file_batch_set_proto_rawDesc = []byte{
-	0x0a, 0x0f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x73, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x19, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x22, 0x67, 0x0a, 0x0f, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x22, 0x79, 0x0a, 0x10, 0x42, 0x61, 0x74,
-	0x63, 0x68, 0x53, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a,
-	0x0d, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x75,
-	0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x75, 0x6e,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f,
-	0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72,
-	0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x66, 0x69, 0x72, 0x73, 0x74, 0x45,
-	0x72, 0x72, 0x6f, 0x72, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
-	0x6f, 0x6d, 0x2f, 0x6c, 0x75, 0x73, 0x68, 0x65, 0x6e, 0x6c, 0x65, 0x2f, 0x73, 0x69, 0x6d, 0x70,
-	0x6c, 0x65, 0x2d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_batch_set_proto_rawDesc = "" +
+	"\n" +
+	"\x0fbatch_set.proto\x12\x02pb\x1a\x19google/protobuf/any.proto\"g\n" +
+	"\x0fBatchSetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x05value\x12\x16\n" +
+	"\x06expire\x18\x03 \x01(\tR\x06expire\"\x9c\x01\n" +
+	"\x10BatchSetResponse\x12#\n" +
+	"\rsuccess_count\x18\x01 \x01(\x05R\fsuccessCount\x12\x1f\n" +
+	"\verror_count\x18\x02 \x01(\x05R\n" +
+	"errorCount\x12\x1f\n" +
+	"\vfirst_error\x18\x03 \x01(\tR\n" +
+	"firstError\x12!\n" +
+	"\fcommit_index\x18\x04 \x01(\x04R\vcommitIndexB)Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
 
 var (
 	file_batch_set_proto_rawDescOnce sync.Once
-	file_batch_set_proto_rawDescData = file_batch_set_proto_rawDesc
+	file_batch_set_proto_rawDescData []byte
 )
 
 func file_batch_set_proto_rawDescGZIP() []byte {
 	file_batch_set_proto_rawDescOnce.Do(func() {
-		file_batch_set_proto_rawDescData = protoimpl.X.CompressGZIP(file_batch_set_proto_rawDescData)
+		file_batch_set_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_batch_set_proto_rawDesc), len(file_batch_set_proto_rawDesc)))
 	})
 	return file_batch_set_proto_rawDescData
 }
@@ -205,7 +204,7 @@ func file_batch_set_proto_init() {
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_batch_set_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_batch_set_proto_rawDesc), len(file_batch_set_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   2,
 			NumExtensions: 0,
@@ -216,7 +215,6 @@ func file_batch_set_proto_init() {
 		MessageInfos:      file_batch_set_proto_msgTypes,
 	}.Build()
 	File_batch_set_proto = out.File
-	file_batch_set_proto_rawDesc = nil
 	file_batch_set_proto_goTypes = nil
 	file_batch_set_proto_depIdxs = nil
 }
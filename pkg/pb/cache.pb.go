@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
-// 	protoc        v5.29.3
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
 // source: cache.proto
 
 package pb
@@ -12,6 +12,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
+	unsafe "unsafe"
 )
 
 const (
@@ -23,141 +24,147 @@ const (
 
 var File_cache_proto protoreflect.FileDescriptor
 
-var file_cache_proto_rawDesc = []byte{
-	0x0a, 0x0b, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70,
-	0x62, 0x1a, 0x09, 0x67, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x09, 0x73, 0x65,
-	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x09, 0x64, 0x65, 0x6c, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x1a, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
-	0x0c, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x10, 0x65,
-	0x78, 0x70, 0x69, 0x72, 0x65, 0x5f, 0x6b, 0x65, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
-	0x0a, 0x64, 0x75, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0f, 0x62, 0x61, 0x74,
-	0x63, 0x68, 0x5f, 0x73, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x77, 0x61,
-	0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d,
-	0x67, 0x65, 0x6e, 0x2d, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x76, 0x32, 0x2f, 0x6f, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0xf9, 0x0a, 0x0a, 0x0c, 0x43, 0x61, 0x63, 0x68,
-	0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x84, 0x01, 0x0a, 0x03, 0x47, 0x65, 0x74,
-	0x12, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x5c, 0x92, 0x41, 0x46, 0x0a, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x12, 0x13, 0x47,
-	0x65, 0x74, 0x20, 0x61, 0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x20, 0x62, 0x79, 0x20, 0x6b, 0x65,
-	0x79, 0x2e, 0x1a, 0x23, 0x55, 0x53, 0x65, 0x20, 0x74, 0x68, 0x69, 0x73, 0x20, 0x61, 0x70, 0x69,
-	0x20, 0x74, 0x6f, 0x20, 0x67, 0x65, 0x74, 0x20, 0x61, 0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x20,
-	0x62, 0x79, 0x20, 0x6b, 0x65, 0x79, 0x2e, 0x2a, 0x03, 0x67, 0x65, 0x74, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x0d, 0x12, 0x0b, 0x2f, 0x76, 0x31, 0x2f, 0x7b, 0x6b, 0x65, 0x79, 0x3d, 0x2a, 0x7d, 0x12,
-	0x87, 0x01, 0x0a, 0x03, 0x53, 0x65, 0x74, 0x12, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x5f, 0x92, 0x41, 0x46, 0x0a, 0x05, 0x63,
-	0x61, 0x63, 0x68, 0x65, 0x12, 0x13, 0x53, 0x65, 0x74, 0x20, 0x61, 0x20, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x20, 0x62, 0x79, 0x20, 0x6b, 0x65, 0x79, 0x2e, 0x1a, 0x23, 0x55, 0x53, 0x65, 0x20, 0x74,
-	0x68, 0x69, 0x73, 0x20, 0x61, 0x70, 0x69, 0x20, 0x74, 0x6f, 0x20, 0x73, 0x65, 0x74, 0x20, 0x61,
-	0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x20, 0x62, 0x79, 0x20, 0x6b, 0x65, 0x79, 0x2e, 0x2a, 0x03,
-	0x73, 0x65, 0x74, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x10, 0x3a, 0x01, 0x2a, 0x22, 0x0b, 0x2f, 0x76,
-	0x31, 0x2f, 0x7b, 0x6b, 0x65, 0x79, 0x3d, 0x2a, 0x7d, 0x12, 0x8a, 0x01, 0x0a, 0x03, 0x44, 0x65,
-	0x6c, 0x12, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x62, 0x92, 0x41, 0x4c, 0x0a, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x12, 0x16,
-	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x20, 0x61, 0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x20, 0x62,
-	0x79, 0x20, 0x6b, 0x65, 0x79, 0x2e, 0x1a, 0x26, 0x55, 0x53, 0x65, 0x20, 0x74, 0x68, 0x69, 0x73,
-	0x20, 0x61, 0x70, 0x69, 0x20, 0x74, 0x6f, 0x20, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x20, 0x61,
-	0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x20, 0x62, 0x79, 0x20, 0x6b, 0x65, 0x79, 0x2e, 0x2a, 0x03,
-	0x64, 0x65, 0x6c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0d, 0x2a, 0x0b, 0x2f, 0x76, 0x31, 0x2f, 0x7b,
-	0x6b, 0x65, 0x79, 0x3d, 0x2a, 0x7d, 0x12, 0x7e, 0x0a, 0x05, 0x52, 0x65, 0x73, 0x65, 0x74, 0x12,
-	0x10, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x50, 0x92, 0x41, 0x42, 0x0a, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65,
-	0x12, 0x10, 0x52, 0x65, 0x73, 0x65, 0x74, 0x20, 0x74, 0x68, 0x65, 0x20, 0x63, 0x61, 0x63, 0x68,
-	0x65, 0x2e, 0x1a, 0x20, 0x55, 0x53, 0x65, 0x20, 0x74, 0x68, 0x69, 0x73, 0x20, 0x61, 0x70, 0x69,
-	0x20, 0x74, 0x6f, 0x20, 0x72, 0x65, 0x73, 0x65, 0x74, 0x20, 0x74, 0x68, 0x65, 0x20, 0x63, 0x61,
-	0x63, 0x68, 0x65, 0x2e, 0x2a, 0x05, 0x72, 0x65, 0x73, 0x65, 0x74, 0x82, 0xd3, 0xe4, 0x93, 0x02,
-	0x05, 0x2a, 0x03, 0x2f, 0x76, 0x31, 0x12, 0xd3, 0x01, 0x0a, 0x06, 0x53, 0x65, 0x61, 0x72, 0x63,
-	0x68, 0x12, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xa1, 0x01, 0x92, 0x41, 0x4f, 0x0a, 0x05,
-	0x63, 0x61, 0x63, 0x68, 0x65, 0x12, 0x16, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x20, 0x6b, 0x65,
-	0x79, 0x73, 0x20, 0x62, 0x79, 0x20, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x2e, 0x1a, 0x26, 0x55,
-	0x53, 0x65, 0x20, 0x74, 0x68, 0x69, 0x73, 0x20, 0x61, 0x70, 0x69, 0x20, 0x74, 0x6f, 0x20, 0x73,
-	0x65, 0x61, 0x72, 0x63, 0x68, 0x20, 0x6b, 0x65, 0x79, 0x73, 0x20, 0x62, 0x79, 0x20, 0x70, 0x72,
-	0x65, 0x66, 0x69, 0x78, 0x2e, 0x2a, 0x06, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x82, 0xd3, 0xe4,
-	0x93, 0x02, 0x49, 0x5a, 0x18, 0x12, 0x16, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x65, 0x61, 0x72, 0x63,
-	0x68, 0x2f, 0x7b, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x3d, 0x2a, 0x7d, 0x5a, 0x21, 0x12,
-	0x1f, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x2f, 0x7b, 0x70, 0x61, 0x74,
-	0x74, 0x65, 0x72, 0x6e, 0x3d, 0x2a, 0x7d, 0x2f, 0x7b, 0x6d, 0x6f, 0x64, 0x65, 0x3d, 0x2a, 0x7d,
-	0x12, 0x0a, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x12, 0x97, 0x01, 0x0a,
-	0x09, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x14, 0x2e, 0x70, 0x62, 0x2e,
-	0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x5d, 0x92, 0x41, 0x40, 0x0a, 0x05, 0x63, 0x61,
-	0x63, 0x68, 0x65, 0x12, 0x0d, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x20, 0x61, 0x20, 0x6b, 0x65,
-	0x79, 0x2e, 0x1a, 0x1d, 0x55, 0x53, 0x65, 0x20, 0x74, 0x68, 0x69, 0x73, 0x20, 0x61, 0x70, 0x69,
-	0x20, 0x74, 0x6f, 0x20, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x20, 0x61, 0x20, 0x6b, 0x65, 0x79,
-	0x2e, 0x2a, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x14, 0x22, 0x12, 0x2f, 0x76, 0x31, 0x2f, 0x7b, 0x6b, 0x65, 0x79, 0x3d, 0x2a, 0x7d, 0x2f,
-	0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x12, 0xa4, 0x01, 0x0a, 0x04, 0x44, 0x75, 0x6d, 0x70, 0x12,
-	0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x75, 0x6d, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x75, 0x6d, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x79, 0x92, 0x41, 0x63, 0x0a, 0x0b, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74,
-	0x65, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x44, 0x75, 0x6d, 0x70, 0x20, 0x63, 0x61, 0x63, 0x68, 0x65,
-	0x20, 0x64, 0x61, 0x74, 0x61, 0x20, 0x74, 0x6f, 0x20, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x1a, 0x34,
-	0x44, 0x75, 0x6d, 0x70, 0x20, 0x61, 0x6c, 0x6c, 0x20, 0x63, 0x61, 0x63, 0x68, 0x65, 0x20, 0x64,
-	0x61, 0x74, 0x61, 0x20, 0x74, 0x6f, 0x20, 0x61, 0x20, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x20, 0x66,
-	0x69, 0x6c, 0x65, 0x20, 0x66, 0x6f, 0x72, 0x20, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65,
-	0x6e, 0x63, 0x65, 0x2e, 0x2a, 0x04, 0x64, 0x75, 0x6d, 0x70, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0d,
-	0x3a, 0x01, 0x2a, 0x22, 0x08, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x75, 0x6d, 0x70, 0x12, 0x51, 0x0a,
-	0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x74, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x42,
-	0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14,
-	0x2e, 0x70, 0x62, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x18, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x12, 0x3a, 0x01, 0x2a, 0x22,
-	0x0d, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x2d, 0x73, 0x65, 0x74, 0x28, 0x01,
-	0x12, 0x3e, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x10, 0x2e, 0x70, 0x62, 0x2e, 0x57,
-	0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x70, 0x62,
-	0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x11, 0x82, 0xd3, 0xe4,
-	0x93, 0x02, 0x0b, 0x12, 0x09, 0x2f, 0x76, 0x31, 0x2f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x30, 0x01,
-	0x12, 0xa0, 0x01, 0x0a, 0x04, 0x4c, 0x6f, 0x61, 0x64, 0x12, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
-	0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x70, 0x62, 0x2e,
-	0x4c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x75, 0x92, 0x41,
-	0x5f, 0x0a, 0x0b, 0x70, 0x65, 0x72, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1a,
-	0x4c, 0x6f, 0x61, 0x64, 0x20, 0x63, 0x61, 0x63, 0x68, 0x65, 0x20, 0x64, 0x61, 0x74, 0x61, 0x20,
-	0x66, 0x72, 0x6f, 0x6d, 0x20, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x1a, 0x2e, 0x4c, 0x6f, 0x61, 0x64,
-	0x20, 0x63, 0x61, 0x63, 0x68, 0x65, 0x20, 0x64, 0x61, 0x74, 0x61, 0x20, 0x66, 0x72, 0x6f, 0x6d,
-	0x20, 0x61, 0x20, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x6c, 0x79, 0x20, 0x64, 0x75,
-	0x6d, 0x70, 0x65, 0x64, 0x20, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x2a, 0x04, 0x6c, 0x6f, 0x61, 0x64,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0d, 0x3a, 0x01, 0x2a, 0x22, 0x08, 0x2f, 0x76, 0x31, 0x2f, 0x6c,
-	0x6f, 0x61, 0x64, 0x42, 0x86, 0x01, 0x92, 0x41, 0x5a, 0x12, 0x58, 0x0a, 0x10, 0x53, 0x69, 0x6d,
-	0x70, 0x6c, 0x65, 0x20, 0x43, 0x61, 0x63, 0x68, 0x65, 0x20, 0x41, 0x50, 0x49, 0x22, 0x3c, 0x0a,
-	0x09, 0x53, 0x68, 0x65, 0x6e, 0x6c, 0x65, 0x20, 0x4c, 0x75, 0x12, 0x1b, 0x68, 0x74, 0x74, 0x70,
-	0x73, 0x3a, 0x2f, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c,
-	0x75, 0x73, 0x68, 0x65, 0x6e, 0x6c, 0x65, 0x1a, 0x12, 0x6c, 0x75, 0x73, 0x68, 0x65, 0x6e, 0x6c,
-	0x65, 0x40, 0x67, 0x6d, 0x61, 0x69, 0x6c, 0x2e, 0x63, 0x6f, 0x6d, 0x32, 0x06, 0x76, 0x31, 0x2e,
-	0x30, 0x2e, 0x30, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x6c, 0x75, 0x73, 0x68, 0x65, 0x6e, 0x6c, 0x65, 0x2f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x2d,
-	0x63, 0x61, 0x63, 0x68, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x33,
-}
+const file_cache_proto_rawDesc = "" +
+	"\n" +
+	"\vcache.proto\x12\x02pb\x1a\tget.proto\x1a\tset.proto\x1a\tdel.proto\x1a\vreset.proto\x1a\fsearch.proto\x1a\x10expire_key.proto\x1a\n" +
+	"dump.proto\x1a\x0fbatch_set.proto\x1a\vwatch.proto\x1a\n" +
+	"lock.proto\x1a\fpubsub.proto\x1a\fstream.proto\x1a\x13probabilistic.proto\x1a\rjsondoc.proto\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto2\xb0.\n" +
+	"\fCacheService\x12\x84\x01\n" +
+	"\x03Get\x12\x0e.pb.GetRequest\x1a\x0f.pb.GetResponse\"\\\x92AF\n" +
+	"\x05cache\x12\x13Get a value by key.\x1a#USe this api to get a value by key.*\x03get\x82\xd3\xe4\x93\x02\r\x12\v/v1/{key=*}\x12\x87\x01\n" +
+	"\x03Set\x12\x0e.pb.SetRequest\x1a\x0f.pb.SetResponse\"_\x92AF\n" +
+	"\x05cache\x12\x13Set a value by key.\x1a#USe this api to set a value by key.*\x03set\x82\xd3\xe4\x93\x02\x10:\x01*\"\v/v1/{key=*}\x12\x8a\x01\n" +
+	"\x03Del\x12\x0e.pb.DelRequest\x1a\x0f.pb.DelResponse\"b\x92AL\n" +
+	"\x05cache\x12\x16Delete a value by key.\x1a&USe this api to delete a value by key.*\x03del\x82\xd3\xe4\x93\x02\r*\v/v1/{key=*}\x12~\n" +
+	"\x05Reset\x12\x10.pb.ResetRequest\x1a\x11.pb.ResetResponse\"P\x92AB\n" +
+	"\x05cache\x12\x10Reset the cache.\x1a USe this api to reset the cache.*\x05reset\x82\xd3\xe4\x93\x02\x05*\x03/v1\x12\xd3\x01\n" +
+	"\x06Search\x12\x11.pb.SearchRequest\x1a\x12.pb.SearchResponse\"\xa1\x01\x92AO\n" +
+	"\x05cache\x12\x16Search keys by prefix.\x1a&USe this api to search keys by prefix.*\x06search\x82\xd3\xe4\x93\x02IZ\x18\x12\x16/v1/search/{pattern=*}Z!\x12\x1f/v1/search/{pattern=*}/{mode=*}\x12\n" +
+	"/v1/search\x12\x97\x01\n" +
+	"\tExpireKey\x12\x14.pb.ExpireKeyRequest\x1a\x15.pb.ExpireKeyResponse\"]\x92A@\n" +
+	"\x05cache\x12\rExpire a key.\x1a\x1dUSe this api to expire a key.*\texpireKey\x82\xd3\xe4\x93\x02\x14\"\x12/v1/{key=*}/expire\x12\xcf\x01\n" +
+	"\x04Dump\x12\x0f.pb.DumpRequest\x1a\x10.pb.DumpResponse\"\xa3\x01\x92A\x8c\x01\n" +
+	"\vpersistence\x12\x18Dump cache data to file.\x1a]Dump cache data to a local file for persistence, optionally only the keys matching a pattern.*\x04dump\x82\xd3\xe4\x93\x02\r:\x01*\"\b/v1/dump\x12Q\n" +
+	"\bBatchSet\x12\x13.pb.BatchSetRequest\x1a\x14.pb.BatchSetResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/batch-set(\x01\x12>\n" +
+	"\x05Watch\x12\x10.pb.WatchRequest\x1a\x0e.pb.WatchEvent\"\x11\x82\xd3\xe4\x93\x02\v\x12\t/v1/watch0\x01\x120\n" +
+	"\x06Backup\x12\x11.pb.BackupRequest\x1a\x0f.pb.BackupChunk\"\x000\x01\x12\xf3\x02\n" +
+	"\x04Load\x12\x0f.pb.LoadRequest\x1a\x10.pb.LoadResponse\"\xc7\x02\x92A\xb0\x02\n" +
+	"\vpersistence\x12\x1aLoad cache data from file.\x1a\xfe\x01Load cache data from a previously dumped file, optionally only the keys matching a pattern and merged into the existing keys. In distributed mode it must be sent to the leader, which replicates the data to every node and rejects writes until it finishes.*\x04load\x82\xd3\xe4\x93\x02\r:\x01*\"\b/v1/load\x12\xcf\x01\n" +
+	"\vAcquireLock\x12\x16.pb.AcquireLockRequest\x1a\x17.pb.AcquireLockResponse\"\x8e\x01\x92Af\n" +
+	"\x04lock\x12\x0fAcquire a lock.\x1a@Acquire a lease-based lock, optionally waiting while it is held.*\vacquireLock\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/locks/{name=*}/acquire\x12\xc9\x01\n" +
+	"\tRenewLock\x12\x14.pb.RenewLockRequest\x1a\x15.pb.RenewLockResponse\"\x8e\x01\x92Ah\n" +
+	"\x04lock\x12\x13Renew a lock lease.\x1a@Extend the lease of a held lock identified by its fencing token.*\trenewLock\x82\xd3\xe4\x93\x02\x1d:\x01*\"\x18/v1/locks/{name=*}/renew\x12\xc3\x01\n" +
+	"\vReleaseLock\x12\x16.pb.ReleaseLockRequest\x1a\x17.pb.ReleaseLockResponse\"\x82\x01\x92AZ\n" +
+	"\x04lock\x12\x0fRelease a lock.\x1a4Release a held lock identified by its fencing token.*\vreleaseLock\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/locks/{name=*}/release\x12\xbc\x01\n" +
+	"\aPublish\x12\x12.pb.PublishRequest\x1a\x13.pb.PublishResponse\"\x87\x01\x92Ab\n" +
+	"\x06pubsub\x12\x12Publish a message.\x1a;Publish a message on a channel to subscribers on all nodes.*\apublish\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/publish/{channel=*}\x12M\n" +
+	"\tSubscribe\x12\x14.pb.SubscribeRequest\x1a\x11.pb.PubSubMessage\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/subscribe0\x01\x12\xb8\x01\n" +
+	"\x04XAdd\x12\x0f.pb.XAddRequest\x1a\x10.pb.XAddResponse\"\x8c\x01\x92Ag\n" +
+	"\x06stream\x12\x13Append to a stream.\x1aBAppend an entry to a stream, optionally trimming by length or age.*\x04xAdd\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/streams/{key=*}/add\x12\xa6\x01\n" +
+	"\x06XRange\x12\x11.pb.XRangeRequest\x1a\x12.pb.XRangeResponse\"u\x92AQ\n" +
+	"\x06stream\x12\x19Read a range of a stream.\x1a$Read stream entries between two IDs.*\x06xRange\x82\xd3\xe4\x93\x02\x1b\x12\x19/v1/streams/{key=*}/range\x12\xcb\x01\n" +
+	"\x05XRead\x12\x10.pb.XReadRequest\x1a\x11.pb.XReadResponse\"\x9c\x01\x92Ay\n" +
+	"\x06stream\x12\x18Read new stream entries.\x1aNRead stream entries after an ID, optionally blocking until new entries arrive.*\x05xRead\x82\xd3\xe4\x93\x02\x1a\x12\x18/v1/streams/{key=*}/read\x12\x94\x01\n" +
+	"\x05XTrim\x12\x10.pb.XTrimRequest\x1a\x11.pb.XTrimResponse\"f\x92A@\n" +
+	"\x06stream\x12\x0eTrim a stream.\x1a\x1fTrim a stream by length or age.*\x05xTrim\x82\xd3\xe4\x93\x02\x1d:\x01*\"\x18/v1/streams/{key=*}/trim\x12\xe1\x01\n" +
+	"\fXGroupCreate\x12\x17.pb.XGroupCreateRequest\x1a\x18.pb.XGroupCreateResponse\"\x9d\x01\x92Ak\n" +
+	"\x06stream\x12\x18Create a consumer group.\x1a9Create a consumer group with an initial committed offset.*\fxGroupCreate\x82\xd3\xe4\x93\x02):\x01*\"$/v1/streams/{key=*}/groups/{group=*}\x12\xd8\x01\n" +
+	"\n" +
+	"XReadGroup\x12\x15.pb.XReadGroupRequest\x1a\x16.pb.XReadGroupResponse\"\x9a\x01\x92Af\n" +
+	"\x06stream\x12\x19Read as a consumer group.\x1a5Read stream entries after the group committed offset.*\n" +
+	"xReadGroup\x82\xd3\xe4\x93\x02+\x12)/v1/streams/{key=*}/groups/{group=*}/read\x12\xd3\x01\n" +
+	"\aXCommit\x12\x12.pb.XCommitRequest\x1a\x13.pb.XCommitResponse\"\x9e\x01\x92Ae\n" +
+	"\x06stream\x12\x1fCommit a consumer group offset.\x1a1Advance the committed offset of a consumer group.*\axCommit\x82\xd3\xe4\x93\x020:\x01*\"+/v1/streams/{key=*}/groups/{group=*}/commit\x12\xd1\x01\n" +
+	"\tBFReserve\x12\x14.pb.BFReserveRequest\x1a\x15.pb.BFReserveResponse\"\x96\x01\x92Ao\n" +
+	"\x05bloom\x12\x16Create a Bloom filter.\x1aCCreate an empty Bloom filter with a target error rate and capacity.*\tbfReserve\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/bloom/{key=*}/reserve\x12\xc7\x01\n" +
+	"\x05BFAdd\x12\x10.pb.BFAddRequest\x1a\x11.pb.BFAddResponse\"\x98\x01\x92Au\n" +
+	"\x05bloom\x12\x1cAdd items to a Bloom filter.\x1aGAdd items to a Bloom filter, creating it with default sizing if needed.*\x05bfAdd\x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/bloom/{key=*}/add\x12\xbb\x01\n" +
+	"\bBFExists\x12\x13.pb.BFExistsRequest\x1a\x14.pb.BFExistsResponse\"\x83\x01\x92A`\n" +
+	"\x05bloom\x12\x1eCheck Bloom filter membership.\x1a-Check whether items may be in a Bloom filter.*\bbfExists\x82\xd3\xe4\x93\x02\x1a\x12\x18/v1/bloom/{key=*}/exists\x12\xb5\x01\n" +
+	"\x05PFAdd\x12\x10.pb.PFAddRequest\x1a\x11.pb.PFAddResponse\"\x86\x01\x92Ae\n" +
+	"\vhyperloglog\x12\x1bAdd items to a HyperLogLog.\x1a2Add items to a HyperLogLog, creating it if needed.*\x05pfAdd\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/hll/{key=*}/add\x12\xc4\x01\n" +
+	"\aPFCount\x12\x12.pb.PFCountRequest\x1a\x13.pb.PFCountResponse\"\x8f\x01\x92Aw\n" +
+	"\vhyperloglog\x12\x18Estimate distinct count.\x1aEEstimate the distinct count of the union of one or more HyperLogLogs.*\apfCount\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/hll/count\x12\xb7\x01\n" +
+	"\aPFMerge\x12\x12.pb.PFMergeRequest\x1a\x13.pb.PFMergeResponse\"\x82\x01\x92A^\n" +
+	"\vhyperloglog\x12\x13Merge HyperLogLogs.\x1a1Merge source HyperLogLogs into a destination key.*\apfMerge\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/hll/{dest=*}/merge\x12\xcb\x01\n" +
+	"\aJSONSet\x12\x12.pb.JSONSetRequest\x1a\x13.pb.JSONSetResponse\"\x96\x01\x92At\n" +
+	"\x04json\x12#Set a value inside a JSON document.\x1a>Store a JSON value at a path, creating the document if needed.*\ajsonSet\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/json/{key=*}/set\x12\xa3\x01\n" +
+	"\aJSONGet\x12\x12.pb.JSONGetRequest\x1a\x13.pb.JSONGetResponse\"o\x92AT\n" +
+	"\x04json\x12!Get a value from a JSON document.\x1a Return the JSON value at a path.*\ajsonGet\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/json/{key=*}\x12\xcc\x01\n" +
+	"\aJSONDel\x12\x12.pb.JSONDelRequest\x1a\x13.pb.JSONDelResponse\"\x97\x01\x92Au\n" +
+	"\x04json\x12$Delete a value from a JSON document.\x1a>Remove the value at a path; deleting the root removes the key.*\ajsonDel\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/json/{key=*}/del\x12\xdc\x01\n" +
+	"\rJSONNumIncrBy\x12\x18.pb.JSONNumIncrByRequest\x1a\x19.pb.JSONNumIncrByResponse\"\x95\x01\x92Ar\n" +
+	"\x04json\x12*Increment a number inside a JSON document.\x1a/Atomically add a delta to the number at a path.*\rjsonNumIncrBy\x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/json/{key=*}/incrB\x86\x01\x92AZ\x12X\n" +
+	"\x10Simple Cache API\"<\n" +
+	"\tShenle Lu\x12\x1bhttps://github.com/lushenle\x1a\x12lushenle@gmail.com2\x06v1.0.0Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
 
 var file_cache_proto_goTypes = []any{
-	(*GetRequest)(nil),        // 0: pb.GetRequest
-	(*SetRequest)(nil),        // 1: pb.SetRequest
-	(*DelRequest)(nil),        // 2: pb.DelRequest
-	(*ResetRequest)(nil),      // 3: pb.ResetRequest
-	(*SearchRequest)(nil),     // 4: pb.SearchRequest
-	(*ExpireKeyRequest)(nil),  // 5: pb.ExpireKeyRequest
-	(*DumpRequest)(nil),       // 6: pb.DumpRequest
-	(*BatchSetRequest)(nil),   // 7: pb.BatchSetRequest
-	(*WatchRequest)(nil),      // 8: pb.WatchRequest
-	(*LoadRequest)(nil),       // 9: pb.LoadRequest
-	(*GetResponse)(nil),       // 10: pb.GetResponse
-	(*SetResponse)(nil),       // 11: pb.SetResponse
-	(*DelResponse)(nil),       // 12: pb.DelResponse
-	(*ResetResponse)(nil),     // 13: pb.ResetResponse
-	(*SearchResponse)(nil),    // 14: pb.SearchResponse
-	(*ExpireKeyResponse)(nil), // 15: pb.ExpireKeyResponse
-	(*DumpResponse)(nil),      // 16: pb.DumpResponse
-	(*BatchSetResponse)(nil),  // 17: pb.BatchSetResponse
-	(*WatchEvent)(nil),        // 18: pb.WatchEvent
-	(*LoadResponse)(nil),      // 19: pb.LoadResponse
+	(*GetRequest)(nil),            // 0: pb.GetRequest
+	(*SetRequest)(nil),            // 1: pb.SetRequest
+	(*DelRequest)(nil),            // 2: pb.DelRequest
+	(*ResetRequest)(nil),          // 3: pb.ResetRequest
+	(*SearchRequest)(nil),         // 4: pb.SearchRequest
+	(*ExpireKeyRequest)(nil),      // 5: pb.ExpireKeyRequest
+	(*DumpRequest)(nil),           // 6: pb.DumpRequest
+	(*BatchSetRequest)(nil),       // 7: pb.BatchSetRequest
+	(*WatchRequest)(nil),          // 8: pb.WatchRequest
+	(*BackupRequest)(nil),         // 9: pb.BackupRequest
+	(*LoadRequest)(nil),           // 10: pb.LoadRequest
+	(*AcquireLockRequest)(nil),    // 11: pb.AcquireLockRequest
+	(*RenewLockRequest)(nil),      // 12: pb.RenewLockRequest
+	(*ReleaseLockRequest)(nil),    // 13: pb.ReleaseLockRequest
+	(*PublishRequest)(nil),        // 14: pb.PublishRequest
+	(*SubscribeRequest)(nil),      // 15: pb.SubscribeRequest
+	(*XAddRequest)(nil),           // 16: pb.XAddRequest
+	(*XRangeRequest)(nil),         // 17: pb.XRangeRequest
+	(*XReadRequest)(nil),          // 18: pb.XReadRequest
+	(*XTrimRequest)(nil),          // 19: pb.XTrimRequest
+	(*XGroupCreateRequest)(nil),   // 20: pb.XGroupCreateRequest
+	(*XReadGroupRequest)(nil),     // 21: pb.XReadGroupRequest
+	(*XCommitRequest)(nil),        // 22: pb.XCommitRequest
+	(*BFReserveRequest)(nil),      // 23: pb.BFReserveRequest
+	(*BFAddRequest)(nil),          // 24: pb.BFAddRequest
+	(*BFExistsRequest)(nil),       // 25: pb.BFExistsRequest
+	(*PFAddRequest)(nil),          // 26: pb.PFAddRequest
+	(*PFCountRequest)(nil),        // 27: pb.PFCountRequest
+	(*PFMergeRequest)(nil),        // 28: pb.PFMergeRequest
+	(*JSONSetRequest)(nil),        // 29: pb.JSONSetRequest
+	(*JSONGetRequest)(nil),        // 30: pb.JSONGetRequest
+	(*JSONDelRequest)(nil),        // 31: pb.JSONDelRequest
+	(*JSONNumIncrByRequest)(nil),  // 32: pb.JSONNumIncrByRequest
+	(*GetResponse)(nil),           // 33: pb.GetResponse
+	(*SetResponse)(nil),           // 34: pb.SetResponse
+	(*DelResponse)(nil),           // 35: pb.DelResponse
+	(*ResetResponse)(nil),         // 36: pb.ResetResponse
+	(*SearchResponse)(nil),        // 37: pb.SearchResponse
+	(*ExpireKeyResponse)(nil),     // 38: pb.ExpireKeyResponse
+	(*DumpResponse)(nil),          // 39: pb.DumpResponse
+	(*BatchSetResponse)(nil),      // 40: pb.BatchSetResponse
+	(*WatchEvent)(nil),            // 41: pb.WatchEvent
+	(*BackupChunk)(nil),           // 42: pb.BackupChunk
+	(*LoadResponse)(nil),          // 43: pb.LoadResponse
+	(*AcquireLockResponse)(nil),   // 44: pb.AcquireLockResponse
+	(*RenewLockResponse)(nil),     // 45: pb.RenewLockResponse
+	(*ReleaseLockResponse)(nil),   // 46: pb.ReleaseLockResponse
+	(*PublishResponse)(nil),       // 47: pb.PublishResponse
+	(*PubSubMessage)(nil),         // 48: pb.PubSubMessage
+	(*XAddResponse)(nil),          // 49: pb.XAddResponse
+	(*XRangeResponse)(nil),        // 50: pb.XRangeResponse
+	(*XReadResponse)(nil),         // 51: pb.XReadResponse
+	(*XTrimResponse)(nil),         // 52: pb.XTrimResponse
+	(*XGroupCreateResponse)(nil),  // 53: pb.XGroupCreateResponse
+	(*XReadGroupResponse)(nil),    // 54: pb.XReadGroupResponse
+	(*XCommitResponse)(nil),       // 55: pb.XCommitResponse
+	(*BFReserveResponse)(nil),     // 56: pb.BFReserveResponse
+	(*BFAddResponse)(nil),         // 57: pb.BFAddResponse
+	(*BFExistsResponse)(nil),      // 58: pb.BFExistsResponse
+	(*PFAddResponse)(nil),         // 59: pb.PFAddResponse
+	(*PFCountResponse)(nil),       // 60: pb.PFCountResponse
+	(*PFMergeResponse)(nil),       // 61: pb.PFMergeResponse
+	(*JSONSetResponse)(nil),       // 62: pb.JSONSetResponse
+	(*JSONGetResponse)(nil),       // 63: pb.JSONGetResponse
+	(*JSONDelResponse)(nil),       // 64: pb.JSONDelResponse
+	(*JSONNumIncrByResponse)(nil), // 65: pb.JSONNumIncrByResponse
 }
 var file_cache_proto_depIdxs = []int32{
 	0,  // 0: pb.CacheService.Get:input_type -> pb.GetRequest
@@ -169,19 +176,65 @@ var file_cache_proto_depIdxs = []int32{
 	6,  // 6: pb.CacheService.Dump:input_type -> pb.DumpRequest
 	7,  // 7: pb.CacheService.BatchSet:input_type -> pb.BatchSetRequest
 	8,  // 8: pb.CacheService.Watch:input_type -> pb.WatchRequest
-	9,  // 9: pb.CacheService.Load:input_type -> pb.LoadRequest
-	10, // 10: pb.CacheService.Get:output_type -> pb.GetResponse
-	11, // 11: pb.CacheService.Set:output_type -> pb.SetResponse
-	12, // 12: pb.CacheService.Del:output_type -> pb.DelResponse
-	13, // 13: pb.CacheService.Reset:output_type -> pb.ResetResponse
-	14, // 14: pb.CacheService.Search:output_type -> pb.SearchResponse
-	15, // 15: pb.CacheService.ExpireKey:output_type -> pb.ExpireKeyResponse
-	16, // 16: pb.CacheService.Dump:output_type -> pb.DumpResponse
-	17, // 17: pb.CacheService.BatchSet:output_type -> pb.BatchSetResponse
-	18, // 18: pb.CacheService.Watch:output_type -> pb.WatchEvent
-	19, // 19: pb.CacheService.Load:output_type -> pb.LoadResponse
-	10, // [10:20] is the sub-list for method output_type
-	0,  // [0:10] is the sub-list for method input_type
+	9,  // 9: pb.CacheService.Backup:input_type -> pb.BackupRequest
+	10, // 10: pb.CacheService.Load:input_type -> pb.LoadRequest
+	11, // 11: pb.CacheService.AcquireLock:input_type -> pb.AcquireLockRequest
+	12, // 12: pb.CacheService.RenewLock:input_type -> pb.RenewLockRequest
+	13, // 13: pb.CacheService.ReleaseLock:input_type -> pb.ReleaseLockRequest
+	14, // 14: pb.CacheService.Publish:input_type -> pb.PublishRequest
+	15, // 15: pb.CacheService.Subscribe:input_type -> pb.SubscribeRequest
+	16, // 16: pb.CacheService.XAdd:input_type -> pb.XAddRequest
+	17, // 17: pb.CacheService.XRange:input_type -> pb.XRangeRequest
+	18, // 18: pb.CacheService.XRead:input_type -> pb.XReadRequest
+	19, // 19: pb.CacheService.XTrim:input_type -> pb.XTrimRequest
+	20, // 20: pb.CacheService.XGroupCreate:input_type -> pb.XGroupCreateRequest
+	21, // 21: pb.CacheService.XReadGroup:input_type -> pb.XReadGroupRequest
+	22, // 22: pb.CacheService.XCommit:input_type -> pb.XCommitRequest
+	23, // 23: pb.CacheService.BFReserve:input_type -> pb.BFReserveRequest
+	24, // 24: pb.CacheService.BFAdd:input_type -> pb.BFAddRequest
+	25, // 25: pb.CacheService.BFExists:input_type -> pb.BFExistsRequest
+	26, // 26: pb.CacheService.PFAdd:input_type -> pb.PFAddRequest
+	27, // 27: pb.CacheService.PFCount:input_type -> pb.PFCountRequest
+	28, // 28: pb.CacheService.PFMerge:input_type -> pb.PFMergeRequest
+	29, // 29: pb.CacheService.JSONSet:input_type -> pb.JSONSetRequest
+	30, // 30: pb.CacheService.JSONGet:input_type -> pb.JSONGetRequest
+	31, // 31: pb.CacheService.JSONDel:input_type -> pb.JSONDelRequest
+	32, // 32: pb.CacheService.JSONNumIncrBy:input_type -> pb.JSONNumIncrByRequest
+	33, // 33: pb.CacheService.Get:output_type -> pb.GetResponse
+	34, // 34: pb.CacheService.Set:output_type -> pb.SetResponse
+	35, // 35: pb.CacheService.Del:output_type -> pb.DelResponse
+	36, // 36: pb.CacheService.Reset:output_type -> pb.ResetResponse
+	37, // 37: pb.CacheService.Search:output_type -> pb.SearchResponse
+	38, // 38: pb.CacheService.ExpireKey:output_type -> pb.ExpireKeyResponse
+	39, // 39: pb.CacheService.Dump:output_type -> pb.DumpResponse
+	40, // 40: pb.CacheService.BatchSet:output_type -> pb.BatchSetResponse
+	41, // 41: pb.CacheService.Watch:output_type -> pb.WatchEvent
+	42, // 42: pb.CacheService.Backup:output_type -> pb.BackupChunk
+	43, // 43: pb.CacheService.Load:output_type -> pb.LoadResponse
+	44, // 44: pb.CacheService.AcquireLock:output_type -> pb.AcquireLockResponse
+	45, // 45: pb.CacheService.RenewLock:output_type -> pb.RenewLockResponse
+	46, // 46: pb.CacheService.ReleaseLock:output_type -> pb.ReleaseLockResponse
+	47, // 47: pb.CacheService.Publish:output_type -> pb.PublishResponse
+	48, // 48: pb.CacheService.Subscribe:output_type -> pb.PubSubMessage
+	49, // 49: pb.CacheService.XAdd:output_type -> pb.XAddResponse
+	50, // 50: pb.CacheService.XRange:output_type -> pb.XRangeResponse
+	51, // 51: pb.CacheService.XRead:output_type -> pb.XReadResponse
+	52, // 52: pb.CacheService.XTrim:output_type -> pb.XTrimResponse
+	53, // 53: pb.CacheService.XGroupCreate:output_type -> pb.XGroupCreateResponse
+	54, // 54: pb.CacheService.XReadGroup:output_type -> pb.XReadGroupResponse
+	55, // 55: pb.CacheService.XCommit:output_type -> pb.XCommitResponse
+	56, // 56: pb.CacheService.BFReserve:output_type -> pb.BFReserveResponse
+	57, // 57: pb.CacheService.BFAdd:output_type -> pb.BFAddResponse
+	58, // 58: pb.CacheService.BFExists:output_type -> pb.BFExistsResponse
+	59, // 59: pb.CacheService.PFAdd:output_type -> pb.PFAddResponse
+	60, // 60: pb.CacheService.PFCount:output_type -> pb.PFCountResponse
+	61, // 61: pb.CacheService.PFMerge:output_type -> pb.PFMergeResponse
+	62, // 62: pb.CacheService.JSONSet:output_type -> pb.JSONSetResponse
+	63, // 63: pb.CacheService.JSONGet:output_type -> pb.JSONGetResponse
+	64, // 64: pb.CacheService.JSONDel:output_type -> pb.JSONDelResponse
+	65, // 65: pb.CacheService.JSONNumIncrBy:output_type -> pb.JSONNumIncrByResponse
+	33, // [33:66] is the sub-list for method output_type
+	0,  // [0:33] is the sub-list for method input_type
 	0,  // [0:0] is the sub-list for extension type_name
 	0,  // [0:0] is the sub-list for extension extendee
 	0,  // [0:0] is the sub-list for field type_name
@@ -201,11 +254,16 @@ func file_cache_proto_init() {
 	file_dump_proto_init()
 	file_batch_set_proto_init()
 	file_watch_proto_init()
+	file_lock_proto_init()
+	file_pubsub_proto_init()
+	file_stream_proto_init()
+	file_probabilistic_proto_init()
+	file_jsondoc_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_cache_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cache_proto_rawDesc), len(file_cache_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   0,
 			NumExtensions: 0,
@@ -215,7 +273,6 @@ func file_cache_proto_init() {
 		DependencyIndexes: file_cache_proto_depIdxs,
 	}.Build()
 	File_cache_proto = out.File
-	file_cache_proto_rawDesc = nil
 	file_cache_proto_goTypes = nil
 	file_cache_proto_depIdxs = nil
 }
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.5.1
-// - protoc             v5.29.3
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
 // source: cache.proto
 
 package pb
@@ -19,16 +19,39 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CacheService_Get_FullMethodName       = "/pb.CacheService/Get"
-	CacheService_Set_FullMethodName       = "/pb.CacheService/Set"
-	CacheService_Del_FullMethodName       = "/pb.CacheService/Del"
-	CacheService_Reset_FullMethodName     = "/pb.CacheService/Reset"
-	CacheService_Search_FullMethodName    = "/pb.CacheService/Search"
-	CacheService_ExpireKey_FullMethodName = "/pb.CacheService/ExpireKey"
-	CacheService_Dump_FullMethodName      = "/pb.CacheService/Dump"
-	CacheService_BatchSet_FullMethodName  = "/pb.CacheService/BatchSet"
-	CacheService_Watch_FullMethodName     = "/pb.CacheService/Watch"
-	CacheService_Load_FullMethodName      = "/pb.CacheService/Load"
+	CacheService_Get_FullMethodName           = "/pb.CacheService/Get"
+	CacheService_Set_FullMethodName           = "/pb.CacheService/Set"
+	CacheService_Del_FullMethodName           = "/pb.CacheService/Del"
+	CacheService_Reset_FullMethodName         = "/pb.CacheService/Reset"
+	CacheService_Search_FullMethodName        = "/pb.CacheService/Search"
+	CacheService_ExpireKey_FullMethodName     = "/pb.CacheService/ExpireKey"
+	CacheService_Dump_FullMethodName          = "/pb.CacheService/Dump"
+	CacheService_BatchSet_FullMethodName      = "/pb.CacheService/BatchSet"
+	CacheService_Watch_FullMethodName         = "/pb.CacheService/Watch"
+	CacheService_Backup_FullMethodName        = "/pb.CacheService/Backup"
+	CacheService_Load_FullMethodName          = "/pb.CacheService/Load"
+	CacheService_AcquireLock_FullMethodName   = "/pb.CacheService/AcquireLock"
+	CacheService_RenewLock_FullMethodName     = "/pb.CacheService/RenewLock"
+	CacheService_ReleaseLock_FullMethodName   = "/pb.CacheService/ReleaseLock"
+	CacheService_Publish_FullMethodName       = "/pb.CacheService/Publish"
+	CacheService_Subscribe_FullMethodName     = "/pb.CacheService/Subscribe"
+	CacheService_XAdd_FullMethodName          = "/pb.CacheService/XAdd"
+	CacheService_XRange_FullMethodName        = "/pb.CacheService/XRange"
+	CacheService_XRead_FullMethodName         = "/pb.CacheService/XRead"
+	CacheService_XTrim_FullMethodName         = "/pb.CacheService/XTrim"
+	CacheService_XGroupCreate_FullMethodName  = "/pb.CacheService/XGroupCreate"
+	CacheService_XReadGroup_FullMethodName    = "/pb.CacheService/XReadGroup"
+	CacheService_XCommit_FullMethodName       = "/pb.CacheService/XCommit"
+	CacheService_BFReserve_FullMethodName     = "/pb.CacheService/BFReserve"
+	CacheService_BFAdd_FullMethodName         = "/pb.CacheService/BFAdd"
+	CacheService_BFExists_FullMethodName      = "/pb.CacheService/BFExists"
+	CacheService_PFAdd_FullMethodName         = "/pb.CacheService/PFAdd"
+	CacheService_PFCount_FullMethodName       = "/pb.CacheService/PFCount"
+	CacheService_PFMerge_FullMethodName       = "/pb.CacheService/PFMerge"
+	CacheService_JSONSet_FullMethodName       = "/pb.CacheService/JSONSet"
+	CacheService_JSONGet_FullMethodName       = "/pb.CacheService/JSONGet"
+	CacheService_JSONDel_FullMethodName       = "/pb.CacheService/JSONDel"
+	CacheService_JSONNumIncrBy_FullMethodName = "/pb.CacheService/JSONNumIncrBy"
 )
 
 // CacheServiceClient is the client API for CacheService service.
@@ -52,7 +75,44 @@ type CacheServiceClient interface {
 	// messages for Set, Del, and Expire operations on keys matching the
 	// requested pattern.
 	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
+	// Backup streams a backup file of the cluster state tied to a raft index
+	// and term, for restoring a new cluster offline. It is only available in
+	// distributed mode; use Dump in single mode.
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error)
 	Load(ctx context.Context, in *LoadRequest, opts ...grpc.CallOption) (*LoadResponse, error)
+	// AcquireLock takes a lease-based distributed lock. On success the
+	// response carries a fencing token that callers should pass to any
+	// downstream resource to reject writes from stale holders.
+	AcquireLock(ctx context.Context, in *AcquireLockRequest, opts ...grpc.CallOption) (*AcquireLockResponse, error)
+	RenewLock(ctx context.Context, in *RenewLockRequest, opts ...grpc.CallOption) (*RenewLockResponse, error)
+	ReleaseLock(ctx context.Context, in *ReleaseLockRequest, opts ...grpc.CallOption) (*ReleaseLockResponse, error)
+	// Publish broadcasts a message on a channel. The message is replicated
+	// through Raft so subscribers on every node receive it.
+	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error)
+	// Subscribe streams messages published on the requested channels or
+	// channel patterns. It can be served by any node.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PubSubMessage], error)
+	// XAdd appends an entry to a stream with an automatically assigned ID.
+	XAdd(ctx context.Context, in *XAddRequest, opts ...grpc.CallOption) (*XAddResponse, error)
+	XRange(ctx context.Context, in *XRangeRequest, opts ...grpc.CallOption) (*XRangeResponse, error)
+	// XRead returns entries after an ID, optionally blocking for new ones.
+	XRead(ctx context.Context, in *XReadRequest, opts ...grpc.CallOption) (*XReadResponse, error)
+	XTrim(ctx context.Context, in *XTrimRequest, opts ...grpc.CallOption) (*XTrimResponse, error)
+	XGroupCreate(ctx context.Context, in *XGroupCreateRequest, opts ...grpc.CallOption) (*XGroupCreateResponse, error)
+	// XReadGroup reads entries after the group's committed offset. Delivery
+	// is at-least-once: entries are returned again until committed.
+	XReadGroup(ctx context.Context, in *XReadGroupRequest, opts ...grpc.CallOption) (*XReadGroupResponse, error)
+	XCommit(ctx context.Context, in *XCommitRequest, opts ...grpc.CallOption) (*XCommitResponse, error)
+	BFReserve(ctx context.Context, in *BFReserveRequest, opts ...grpc.CallOption) (*BFReserveResponse, error)
+	BFAdd(ctx context.Context, in *BFAddRequest, opts ...grpc.CallOption) (*BFAddResponse, error)
+	BFExists(ctx context.Context, in *BFExistsRequest, opts ...grpc.CallOption) (*BFExistsResponse, error)
+	PFAdd(ctx context.Context, in *PFAddRequest, opts ...grpc.CallOption) (*PFAddResponse, error)
+	PFCount(ctx context.Context, in *PFCountRequest, opts ...grpc.CallOption) (*PFCountResponse, error)
+	PFMerge(ctx context.Context, in *PFMergeRequest, opts ...grpc.CallOption) (*PFMergeResponse, error)
+	JSONSet(ctx context.Context, in *JSONSetRequest, opts ...grpc.CallOption) (*JSONSetResponse, error)
+	JSONGet(ctx context.Context, in *JSONGetRequest, opts ...grpc.CallOption) (*JSONGetResponse, error)
+	JSONDel(ctx context.Context, in *JSONDelRequest, opts ...grpc.CallOption) (*JSONDelResponse, error)
+	JSONNumIncrBy(ctx context.Context, in *JSONNumIncrByRequest, opts ...grpc.CallOption) (*JSONNumIncrByResponse, error)
 }
 
 type cacheServiceClient struct {
@@ -165,6 +225,25 @@ func (c *cacheServiceClient) Watch(ctx context.Context, in *WatchRequest, opts .
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type CacheService_WatchClient = grpc.ServerStreamingClient[WatchEvent]
 
+func (c *cacheServiceClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CacheService_ServiceDesc.Streams[2], CacheService_Backup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BackupRequest, BackupChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CacheService_BackupClient = grpc.ServerStreamingClient[BackupChunk]
+
 func (c *cacheServiceClient) Load(ctx context.Context, in *LoadRequest, opts ...grpc.CallOption) (*LoadResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(LoadResponse)
@@ -175,6 +254,235 @@ func (c *cacheServiceClient) Load(ctx context.Context, in *LoadRequest, opts ...
 	return out, nil
 }
 
+func (c *cacheServiceClient) AcquireLock(ctx context.Context, in *AcquireLockRequest, opts ...grpc.CallOption) (*AcquireLockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AcquireLockResponse)
+	err := c.cc.Invoke(ctx, CacheService_AcquireLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) RenewLock(ctx context.Context, in *RenewLockRequest, opts ...grpc.CallOption) (*RenewLockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenewLockResponse)
+	err := c.cc.Invoke(ctx, CacheService_RenewLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) ReleaseLock(ctx context.Context, in *ReleaseLockRequest, opts ...grpc.CallOption) (*ReleaseLockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseLockResponse)
+	err := c.cc.Invoke(ctx, CacheService_ReleaseLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishResponse)
+	err := c.cc.Invoke(ctx, CacheService_Publish_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PubSubMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CacheService_ServiceDesc.Streams[3], CacheService_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, PubSubMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CacheService_SubscribeClient = grpc.ServerStreamingClient[PubSubMessage]
+
+func (c *cacheServiceClient) XAdd(ctx context.Context, in *XAddRequest, opts ...grpc.CallOption) (*XAddResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(XAddResponse)
+	err := c.cc.Invoke(ctx, CacheService_XAdd_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) XRange(ctx context.Context, in *XRangeRequest, opts ...grpc.CallOption) (*XRangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(XRangeResponse)
+	err := c.cc.Invoke(ctx, CacheService_XRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) XRead(ctx context.Context, in *XReadRequest, opts ...grpc.CallOption) (*XReadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(XReadResponse)
+	err := c.cc.Invoke(ctx, CacheService_XRead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) XTrim(ctx context.Context, in *XTrimRequest, opts ...grpc.CallOption) (*XTrimResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(XTrimResponse)
+	err := c.cc.Invoke(ctx, CacheService_XTrim_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) XGroupCreate(ctx context.Context, in *XGroupCreateRequest, opts ...grpc.CallOption) (*XGroupCreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(XGroupCreateResponse)
+	err := c.cc.Invoke(ctx, CacheService_XGroupCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) XReadGroup(ctx context.Context, in *XReadGroupRequest, opts ...grpc.CallOption) (*XReadGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(XReadGroupResponse)
+	err := c.cc.Invoke(ctx, CacheService_XReadGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) XCommit(ctx context.Context, in *XCommitRequest, opts ...grpc.CallOption) (*XCommitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(XCommitResponse)
+	err := c.cc.Invoke(ctx, CacheService_XCommit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) BFReserve(ctx context.Context, in *BFReserveRequest, opts ...grpc.CallOption) (*BFReserveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BFReserveResponse)
+	err := c.cc.Invoke(ctx, CacheService_BFReserve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) BFAdd(ctx context.Context, in *BFAddRequest, opts ...grpc.CallOption) (*BFAddResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BFAddResponse)
+	err := c.cc.Invoke(ctx, CacheService_BFAdd_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) BFExists(ctx context.Context, in *BFExistsRequest, opts ...grpc.CallOption) (*BFExistsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BFExistsResponse)
+	err := c.cc.Invoke(ctx, CacheService_BFExists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) PFAdd(ctx context.Context, in *PFAddRequest, opts ...grpc.CallOption) (*PFAddResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PFAddResponse)
+	err := c.cc.Invoke(ctx, CacheService_PFAdd_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) PFCount(ctx context.Context, in *PFCountRequest, opts ...grpc.CallOption) (*PFCountResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PFCountResponse)
+	err := c.cc.Invoke(ctx, CacheService_PFCount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) PFMerge(ctx context.Context, in *PFMergeRequest, opts ...grpc.CallOption) (*PFMergeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PFMergeResponse)
+	err := c.cc.Invoke(ctx, CacheService_PFMerge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) JSONSet(ctx context.Context, in *JSONSetRequest, opts ...grpc.CallOption) (*JSONSetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JSONSetResponse)
+	err := c.cc.Invoke(ctx, CacheService_JSONSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) JSONGet(ctx context.Context, in *JSONGetRequest, opts ...grpc.CallOption) (*JSONGetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JSONGetResponse)
+	err := c.cc.Invoke(ctx, CacheService_JSONGet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) JSONDel(ctx context.Context, in *JSONDelRequest, opts ...grpc.CallOption) (*JSONDelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JSONDelResponse)
+	err := c.cc.Invoke(ctx, CacheService_JSONDel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) JSONNumIncrBy(ctx context.Context, in *JSONNumIncrByRequest, opts ...grpc.CallOption) (*JSONNumIncrByResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JSONNumIncrByResponse)
+	err := c.cc.Invoke(ctx, CacheService_JSONNumIncrBy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CacheServiceServer is the server API for CacheService service.
 // All implementations must embed UnimplementedCacheServiceServer
 // for forward compatibility.
@@ -196,7 +504,44 @@ type CacheServiceServer interface {
 	// messages for Set, Del, and Expire operations on keys matching the
 	// requested pattern.
 	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error
+	// Backup streams a backup file of the cluster state tied to a raft index
+	// and term, for restoring a new cluster offline. It is only available in
+	// distributed mode; use Dump in single mode.
+	Backup(*BackupRequest, grpc.ServerStreamingServer[BackupChunk]) error
 	Load(context.Context, *LoadRequest) (*LoadResponse, error)
+	// AcquireLock takes a lease-based distributed lock. On success the
+	// response carries a fencing token that callers should pass to any
+	// downstream resource to reject writes from stale holders.
+	AcquireLock(context.Context, *AcquireLockRequest) (*AcquireLockResponse, error)
+	RenewLock(context.Context, *RenewLockRequest) (*RenewLockResponse, error)
+	ReleaseLock(context.Context, *ReleaseLockRequest) (*ReleaseLockResponse, error)
+	// Publish broadcasts a message on a channel. The message is replicated
+	// through Raft so subscribers on every node receive it.
+	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
+	// Subscribe streams messages published on the requested channels or
+	// channel patterns. It can be served by any node.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[PubSubMessage]) error
+	// XAdd appends an entry to a stream with an automatically assigned ID.
+	XAdd(context.Context, *XAddRequest) (*XAddResponse, error)
+	XRange(context.Context, *XRangeRequest) (*XRangeResponse, error)
+	// XRead returns entries after an ID, optionally blocking for new ones.
+	XRead(context.Context, *XReadRequest) (*XReadResponse, error)
+	XTrim(context.Context, *XTrimRequest) (*XTrimResponse, error)
+	XGroupCreate(context.Context, *XGroupCreateRequest) (*XGroupCreateResponse, error)
+	// XReadGroup reads entries after the group's committed offset. Delivery
+	// is at-least-once: entries are returned again until committed.
+	XReadGroup(context.Context, *XReadGroupRequest) (*XReadGroupResponse, error)
+	XCommit(context.Context, *XCommitRequest) (*XCommitResponse, error)
+	BFReserve(context.Context, *BFReserveRequest) (*BFReserveResponse, error)
+	BFAdd(context.Context, *BFAddRequest) (*BFAddResponse, error)
+	BFExists(context.Context, *BFExistsRequest) (*BFExistsResponse, error)
+	PFAdd(context.Context, *PFAddRequest) (*PFAddResponse, error)
+	PFCount(context.Context, *PFCountRequest) (*PFCountResponse, error)
+	PFMerge(context.Context, *PFMergeRequest) (*PFMergeResponse, error)
+	JSONSet(context.Context, *JSONSetRequest) (*JSONSetResponse, error)
+	JSONGet(context.Context, *JSONGetRequest) (*JSONGetResponse, error)
+	JSONDel(context.Context, *JSONDelRequest) (*JSONDelResponse, error)
+	JSONNumIncrBy(context.Context, *JSONNumIncrByRequest) (*JSONNumIncrByResponse, error)
 	mustEmbedUnimplementedCacheServiceServer()
 }
 
@@ -208,34 +553,103 @@ type CacheServiceServer interface {
 type UnimplementedCacheServiceServer struct{}
 
 func (UnimplementedCacheServiceServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+	return nil, status.Error(codes.Unimplemented, "method Get not implemented")
 }
 func (UnimplementedCacheServiceServer) Set(context.Context, *SetRequest) (*SetResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Set not implemented")
+	return nil, status.Error(codes.Unimplemented, "method Set not implemented")
 }
 func (UnimplementedCacheServiceServer) Del(context.Context, *DelRequest) (*DelResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Del not implemented")
+	return nil, status.Error(codes.Unimplemented, "method Del not implemented")
 }
 func (UnimplementedCacheServiceServer) Reset(context.Context, *ResetRequest) (*ResetResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Reset not implemented")
+	return nil, status.Error(codes.Unimplemented, "method Reset not implemented")
 }
 func (UnimplementedCacheServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+	return nil, status.Error(codes.Unimplemented, "method Search not implemented")
 }
 func (UnimplementedCacheServiceServer) ExpireKey(context.Context, *ExpireKeyRequest) (*ExpireKeyResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ExpireKey not implemented")
+	return nil, status.Error(codes.Unimplemented, "method ExpireKey not implemented")
 }
 func (UnimplementedCacheServiceServer) Dump(context.Context, *DumpRequest) (*DumpResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Dump not implemented")
+	return nil, status.Error(codes.Unimplemented, "method Dump not implemented")
 }
 func (UnimplementedCacheServiceServer) BatchSet(grpc.ClientStreamingServer[BatchSetRequest, BatchSetResponse]) error {
-	return status.Errorf(codes.Unimplemented, "method BatchSet not implemented")
+	return status.Error(codes.Unimplemented, "method BatchSet not implemented")
 }
 func (UnimplementedCacheServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error {
-	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+	return status.Error(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedCacheServiceServer) Backup(*BackupRequest, grpc.ServerStreamingServer[BackupChunk]) error {
+	return status.Error(codes.Unimplemented, "method Backup not implemented")
 }
 func (UnimplementedCacheServiceServer) Load(context.Context, *LoadRequest) (*LoadResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Load not implemented")
+	return nil, status.Error(codes.Unimplemented, "method Load not implemented")
+}
+func (UnimplementedCacheServiceServer) AcquireLock(context.Context, *AcquireLockRequest) (*AcquireLockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AcquireLock not implemented")
+}
+func (UnimplementedCacheServiceServer) RenewLock(context.Context, *RenewLockRequest) (*RenewLockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RenewLock not implemented")
+}
+func (UnimplementedCacheServiceServer) ReleaseLock(context.Context, *ReleaseLockRequest) (*ReleaseLockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReleaseLock not implemented")
+}
+func (UnimplementedCacheServiceServer) Publish(context.Context, *PublishRequest) (*PublishResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Publish not implemented")
+}
+func (UnimplementedCacheServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[PubSubMessage]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedCacheServiceServer) XAdd(context.Context, *XAddRequest) (*XAddResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method XAdd not implemented")
+}
+func (UnimplementedCacheServiceServer) XRange(context.Context, *XRangeRequest) (*XRangeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method XRange not implemented")
+}
+func (UnimplementedCacheServiceServer) XRead(context.Context, *XReadRequest) (*XReadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method XRead not implemented")
+}
+func (UnimplementedCacheServiceServer) XTrim(context.Context, *XTrimRequest) (*XTrimResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method XTrim not implemented")
+}
+func (UnimplementedCacheServiceServer) XGroupCreate(context.Context, *XGroupCreateRequest) (*XGroupCreateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method XGroupCreate not implemented")
+}
+func (UnimplementedCacheServiceServer) XReadGroup(context.Context, *XReadGroupRequest) (*XReadGroupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method XReadGroup not implemented")
+}
+func (UnimplementedCacheServiceServer) XCommit(context.Context, *XCommitRequest) (*XCommitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method XCommit not implemented")
+}
+func (UnimplementedCacheServiceServer) BFReserve(context.Context, *BFReserveRequest) (*BFReserveResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BFReserve not implemented")
+}
+func (UnimplementedCacheServiceServer) BFAdd(context.Context, *BFAddRequest) (*BFAddResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BFAdd not implemented")
+}
+func (UnimplementedCacheServiceServer) BFExists(context.Context, *BFExistsRequest) (*BFExistsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BFExists not implemented")
+}
+func (UnimplementedCacheServiceServer) PFAdd(context.Context, *PFAddRequest) (*PFAddResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PFAdd not implemented")
+}
+func (UnimplementedCacheServiceServer) PFCount(context.Context, *PFCountRequest) (*PFCountResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PFCount not implemented")
+}
+func (UnimplementedCacheServiceServer) PFMerge(context.Context, *PFMergeRequest) (*PFMergeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PFMerge not implemented")
+}
+func (UnimplementedCacheServiceServer) JSONSet(context.Context, *JSONSetRequest) (*JSONSetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method JSONSet not implemented")
+}
+func (UnimplementedCacheServiceServer) JSONGet(context.Context, *JSONGetRequest) (*JSONGetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method JSONGet not implemented")
+}
+func (UnimplementedCacheServiceServer) JSONDel(context.Context, *JSONDelRequest) (*JSONDelResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method JSONDel not implemented")
+}
+func (UnimplementedCacheServiceServer) JSONNumIncrBy(context.Context, *JSONNumIncrByRequest) (*JSONNumIncrByResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method JSONNumIncrBy not implemented")
 }
 func (UnimplementedCacheServiceServer) mustEmbedUnimplementedCacheServiceServer() {}
 func (UnimplementedCacheServiceServer) testEmbeddedByValue()                      {}
@@ -248,7 +662,7 @@ type UnsafeCacheServiceServer interface {
 }
 
 func RegisterCacheServiceServer(s grpc.ServiceRegistrar, srv CacheServiceServer) {
-	// If the following call pancis, it indicates UnimplementedCacheServiceServer was
+	// If the following call panics, it indicates UnimplementedCacheServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
@@ -402,6 +816,17 @@ func _CacheService_Watch_Handler(srv interface{}, stream grpc.ServerStream) erro
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type CacheService_WatchServer = grpc.ServerStreamingServer[WatchEvent]
 
+func _CacheService_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CacheServiceServer).Backup(m, &grpc.GenericServerStream[BackupRequest, BackupChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CacheService_BackupServer = grpc.ServerStreamingServer[BackupChunk]
+
 func _CacheService_Load_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LoadRequest)
 	if err := dec(in); err != nil {
@@ -420,55 +845,538 @@ func _CacheService_Load_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
-// CacheService_ServiceDesc is the grpc.ServiceDesc for CacheService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var CacheService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.CacheService",
-	HandlerType: (*CacheServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Get",
-			Handler:    _CacheService_Get_Handler,
-		},
-		{
-			MethodName: "Set",
-			Handler:    _CacheService_Set_Handler,
-		},
-		{
-			MethodName: "Del",
-			Handler:    _CacheService_Del_Handler,
-		},
-		{
-			MethodName: "Reset",
-			Handler:    _CacheService_Reset_Handler,
-		},
-		{
-			MethodName: "Search",
-			Handler:    _CacheService_Search_Handler,
-		},
-		{
-			MethodName: "ExpireKey",
-			Handler:    _CacheService_ExpireKey_Handler,
-		},
-		{
-			MethodName: "Dump",
-			Handler:    _CacheService_Dump_Handler,
-		},
-		{
-			MethodName: "Load",
-			Handler:    _CacheService_Load_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "BatchSet",
-			Handler:       _CacheService_BatchSet_Handler,
-			ClientStreams: true,
-		},
-		{
-			StreamName:    "Watch",
-			Handler:       _CacheService_Watch_Handler,
+func _CacheService_AcquireLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcquireLockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).AcquireLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_AcquireLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).AcquireLock(ctx, req.(*AcquireLockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_RenewLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewLockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).RenewLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_RenewLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).RenewLock(ctx, req.(*RenewLockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_ReleaseLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseLockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).ReleaseLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_ReleaseLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).ReleaseLock(ctx, req.(*ReleaseLockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).Publish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_Publish_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).Publish(ctx, req.(*PublishRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CacheServiceServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, PubSubMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CacheService_SubscribeServer = grpc.ServerStreamingServer[PubSubMessage]
+
+func _CacheService_XAdd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XAddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).XAdd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_XAdd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).XAdd(ctx, req.(*XAddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_XRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).XRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_XRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).XRange(ctx, req.(*XRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_XRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).XRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_XRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).XRead(ctx, req.(*XReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_XTrim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XTrimRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).XTrim(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_XTrim_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).XTrim(ctx, req.(*XTrimRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_XGroupCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XGroupCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).XGroupCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_XGroupCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).XGroupCreate(ctx, req.(*XGroupCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_XReadGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XReadGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).XReadGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_XReadGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).XReadGroup(ctx, req.(*XReadGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_XCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XCommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).XCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_XCommit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).XCommit(ctx, req.(*XCommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_BFReserve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BFReserveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).BFReserve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_BFReserve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).BFReserve(ctx, req.(*BFReserveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_BFAdd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BFAddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).BFAdd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_BFAdd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).BFAdd(ctx, req.(*BFAddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_BFExists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BFExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).BFExists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_BFExists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).BFExists(ctx, req.(*BFExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_PFAdd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PFAddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).PFAdd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_PFAdd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).PFAdd(ctx, req.(*PFAddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_PFCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PFCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).PFCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_PFCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).PFCount(ctx, req.(*PFCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_PFMerge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PFMergeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).PFMerge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_PFMerge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).PFMerge(ctx, req.(*PFMergeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_JSONSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JSONSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).JSONSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_JSONSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).JSONSet(ctx, req.(*JSONSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_JSONGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JSONGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).JSONGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_JSONGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).JSONGet(ctx, req.(*JSONGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_JSONDel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JSONDelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).JSONDel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_JSONDel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).JSONDel(ctx, req.(*JSONDelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_JSONNumIncrBy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JSONNumIncrByRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).JSONNumIncrBy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_JSONNumIncrBy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).JSONNumIncrBy(ctx, req.(*JSONNumIncrByRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CacheService_ServiceDesc is the grpc.ServiceDesc for CacheService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CacheService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.CacheService",
+	HandlerType: (*CacheServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _CacheService_Get_Handler,
+		},
+		{
+			MethodName: "Set",
+			Handler:    _CacheService_Set_Handler,
+		},
+		{
+			MethodName: "Del",
+			Handler:    _CacheService_Del_Handler,
+		},
+		{
+			MethodName: "Reset",
+			Handler:    _CacheService_Reset_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _CacheService_Search_Handler,
+		},
+		{
+			MethodName: "ExpireKey",
+			Handler:    _CacheService_ExpireKey_Handler,
+		},
+		{
+			MethodName: "Dump",
+			Handler:    _CacheService_Dump_Handler,
+		},
+		{
+			MethodName: "Load",
+			Handler:    _CacheService_Load_Handler,
+		},
+		{
+			MethodName: "AcquireLock",
+			Handler:    _CacheService_AcquireLock_Handler,
+		},
+		{
+			MethodName: "RenewLock",
+			Handler:    _CacheService_RenewLock_Handler,
+		},
+		{
+			MethodName: "ReleaseLock",
+			Handler:    _CacheService_ReleaseLock_Handler,
+		},
+		{
+			MethodName: "Publish",
+			Handler:    _CacheService_Publish_Handler,
+		},
+		{
+			MethodName: "XAdd",
+			Handler:    _CacheService_XAdd_Handler,
+		},
+		{
+			MethodName: "XRange",
+			Handler:    _CacheService_XRange_Handler,
+		},
+		{
+			MethodName: "XRead",
+			Handler:    _CacheService_XRead_Handler,
+		},
+		{
+			MethodName: "XTrim",
+			Handler:    _CacheService_XTrim_Handler,
+		},
+		{
+			MethodName: "XGroupCreate",
+			Handler:    _CacheService_XGroupCreate_Handler,
+		},
+		{
+			MethodName: "XReadGroup",
+			Handler:    _CacheService_XReadGroup_Handler,
+		},
+		{
+			MethodName: "XCommit",
+			Handler:    _CacheService_XCommit_Handler,
+		},
+		{
+			MethodName: "BFReserve",
+			Handler:    _CacheService_BFReserve_Handler,
+		},
+		{
+			MethodName: "BFAdd",
+			Handler:    _CacheService_BFAdd_Handler,
+		},
+		{
+			MethodName: "BFExists",
+			Handler:    _CacheService_BFExists_Handler,
+		},
+		{
+			MethodName: "PFAdd",
+			Handler:    _CacheService_PFAdd_Handler,
+		},
+		{
+			MethodName: "PFCount",
+			Handler:    _CacheService_PFCount_Handler,
+		},
+		{
+			MethodName: "PFMerge",
+			Handler:    _CacheService_PFMerge_Handler,
+		},
+		{
+			MethodName: "JSONSet",
+			Handler:    _CacheService_JSONSet_Handler,
+		},
+		{
+			MethodName: "JSONGet",
+			Handler:    _CacheService_JSONGet_Handler,
+		},
+		{
+			MethodName: "JSONDel",
+			Handler:    _CacheService_JSONDel_Handler,
+		},
+		{
+			MethodName: "JSONNumIncrBy",
+			Handler:    _CacheService_JSONNumIncrBy_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchSet",
+			Handler:       _CacheService_BatchSet_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _CacheService_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Backup",
+			Handler:       _CacheService_Backup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _CacheService_Subscribe_Handler,
 			ServerStreams: true,
 		},
 	},
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
-// 	protoc        v5.29.3
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
 // source: del.proto
 
 package pb
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -21,11 +22,10 @@ const (
 )
 
 type DelRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DelRequest) Reset() {
@@ -66,12 +66,13 @@ func (x *DelRequest) GetKey() string {
 }
 
 type DelResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Existed bool                   `protobuf:"varint,2,opt,name=existed,proto3" json:"existed,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,3,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Existed bool `protobuf:"varint,2,opt,name=existed,proto3" json:"existed,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DelResponse) Reset() {
@@ -118,30 +119,34 @@ func (x *DelResponse) GetExisted() bool {
 	return false
 }
 
+func (x *DelResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
 var File_del_proto protoreflect.FileDescriptor
 
-var file_del_proto_rawDesc = []byte{
-	0x0a, 0x09, 0x64, 0x65, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22,
-	0x1e, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a,
-	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22,
-	0x41, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
-	0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x69, 0x73,
-	0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x78, 0x69, 0x73, 0x74,
-	0x65, 0x64, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x6c, 0x75, 0x73, 0x68, 0x65, 0x6e, 0x6c, 0x65, 0x2f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65,
-	0x2d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_del_proto_rawDesc = "" +
+	"\n" +
+	"\tdel.proto\x12\x02pb\"\x1e\n" +
+	"\n" +
+	"DelRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"d\n" +
+	"\vDelResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\aexisted\x18\x02 \x01(\bR\aexisted\x12!\n" +
+	"\fcommit_index\x18\x03 \x01(\x04R\vcommitIndexB)Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
 
 var (
 	file_del_proto_rawDescOnce sync.Once
-	file_del_proto_rawDescData = file_del_proto_rawDesc
+	file_del_proto_rawDescData []byte
 )
 
 func file_del_proto_rawDescGZIP() []byte {
 	file_del_proto_rawDescOnce.Do(func() {
-		file_del_proto_rawDescData = protoimpl.X.CompressGZIP(file_del_proto_rawDescData)
+		file_del_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_del_proto_rawDesc), len(file_del_proto_rawDesc)))
 	})
 	return file_del_proto_rawDescData
 }
@@ -168,7 +173,7 @@ func file_del_proto_init() {
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_del_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_del_proto_rawDesc), len(file_del_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   2,
 			NumExtensions: 0,
@@ -179,7 +184,6 @@ func file_del_proto_init() {
 		MessageInfos:      file_del_proto_msgTypes,
 	}.Build()
 	File_del_proto = out.File
-	file_del_proto_rawDesc = nil
 	file_del_proto_goTypes = nil
 	file_del_proto_depIdxs = nil
 }
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
-// 	protoc        v5.29.3
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
 // source: dump.proto
 
 package pb
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -21,12 +22,13 @@ const (
 )
 
 type DumpRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Format        string                  `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`                              // "json" or "binary", default "binary"
+	Path          string                  `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                                  // file path, empty means default
+	Pattern       string                  `protobuf:"bytes,3,opt,name=pattern,proto3" json:"pattern,omitempty"`                            // only dump keys matching pattern, empty means all keys
+	Mode          SearchRequest_MatchMode `protobuf:"varint,4,opt,name=mode,proto3,enum=pb.SearchRequest_MatchMode" json:"mode,omitempty"` // how pattern is matched, as in SearchRequest
 	unknownFields protoimpl.UnknownFields
-
-	Format string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"` // "json" or "binary", default "binary"
-	Path   string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`     // file path, empty means default
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DumpRequest) Reset() {
@@ -73,17 +75,30 @@ func (x *DumpRequest) GetPath() string {
 	return ""
 }
 
+func (x *DumpRequest) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *DumpRequest) GetMode() SearchRequest_MatchMode {
+	if x != nil {
+		return x.Mode
+	}
+	return SearchRequest_WILDCARD
+}
+
 type DumpResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TotalKeys     int32                  `protobuf:"varint,2,opt,name=total_keys,json=totalKeys,proto3" json:"total_keys,omitempty"`
+	FileSize      int64                  `protobuf:"varint,3,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	Path          string                 `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	Format        string                 `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`
+	DurationMs    float64                `protobuf:"fixed64,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Success    bool    `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	TotalKeys  int32   `protobuf:"varint,2,opt,name=total_keys,json=totalKeys,proto3" json:"total_keys,omitempty"`
-	FileSize   int64   `protobuf:"varint,3,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
-	Path       string  `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
-	Format     string  `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`
-	DurationMs float64 `protobuf:"fixed64,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DumpResponse) Reset() {
@@ -159,11 +174,16 @@ func (x *DumpResponse) GetDurationMs() float64 {
 }
 
 type LoadRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState  `protogen:"open.v1"`
+	Path    string                  `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`                                  // file path, empty means auto-detect default
+	Pattern string                  `protobuf:"bytes,2,opt,name=pattern,proto3" json:"pattern,omitempty"`                            // only load keys matching pattern, empty means all keys
+	Mode    SearchRequest_MatchMode `protobuf:"varint,3,opt,name=mode,proto3,enum=pb.SearchRequest_MatchMode" json:"mode,omitempty"` // how pattern is matched, as in SearchRequest
+	// merge writes the loaded keys over existing ones and deletes nothing.
+	// Otherwise the load replaces the keys in scope: the whole cache, or only
+	// the keys matching pattern.
+	Merge         bool `protobuf:"varint,4,opt,name=merge,proto3" json:"merge,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // file path, empty means auto-detect default
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *LoadRequest) Reset() {
@@ -203,17 +223,39 @@ func (x *LoadRequest) GetPath() string {
 	return ""
 }
 
+func (x *LoadRequest) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *LoadRequest) GetMode() SearchRequest_MatchMode {
+	if x != nil {
+		return x.Mode
+	}
+	return SearchRequest_WILDCARD
+}
+
+func (x *LoadRequest) GetMerge() bool {
+	if x != nil {
+		return x.Merge
+	}
+	return false
+}
+
 type LoadResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Success     bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TotalKeys   int32                  `protobuf:"varint,2,opt,name=total_keys,json=totalKeys,proto3" json:"total_keys,omitempty"`
+	LoadedKeys  int32                  `protobuf:"varint,3,opt,name=loaded_keys,json=loadedKeys,proto3" json:"loaded_keys,omitempty"`
+	SkippedKeys int32                  `protobuf:"varint,4,opt,name=skipped_keys,json=skippedKeys,proto3" json:"skipped_keys,omitempty"`
+	Path        string                 `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	DurationMs  float64                `protobuf:"fixed64,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,7,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Success     bool    `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	TotalKeys   int32   `protobuf:"varint,2,opt,name=total_keys,json=totalKeys,proto3" json:"total_keys,omitempty"`
-	LoadedKeys  int32   `protobuf:"varint,3,opt,name=loaded_keys,json=loadedKeys,proto3" json:"loaded_keys,omitempty"`
-	SkippedKeys int32   `protobuf:"varint,4,opt,name=skipped_keys,json=skippedKeys,proto3" json:"skipped_keys,omitempty"`
-	Path        string  `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
-	DurationMs  float64 `protobuf:"fixed64,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *LoadResponse) Reset() {
@@ -288,70 +330,203 @@ func (x *LoadResponse) GetDurationMs() float64 {
 	return 0
 }
 
-var File_dump_proto protoreflect.FileDescriptor
+func (x *LoadResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+type BackupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// from_snapshot returns the latest raft snapshot instead of capturing the
+	// state at the leader's applied index. It can be served by any node.
+	FromSnapshot  bool `protobuf:"varint,1,opt,name=from_snapshot,json=fromSnapshot,proto3" json:"from_snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-var file_dump_proto_rawDesc = []byte{
-	0x0a, 0x0a, 0x64, 0x75, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62,
-	0x22, 0x39, 0x0a, 0x0b, 0x44, 0x75, 0x6d, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x16, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0xb1, 0x01, 0x0a, 0x0c,
-	0x44, 0x75, 0x6d, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07,
-	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73,
-	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f,
-	0x6b, 0x65, 0x79, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61,
-	0x6c, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x69,
-	0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69,
-	0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74,
-	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1f,
-	0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x01, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x22,
-	0x21, 0x0a, 0x0b, 0x4c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
-	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
-	0x74, 0x68, 0x22, 0xc0, 0x01, 0x0a, 0x0c, 0x4c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x1d, 0x0a,
-	0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x1f, 0x0a, 0x0b,
-	0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x5f, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x0a, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x21, 0x0a,
-	0x0c, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x5f, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x4b, 0x65, 0x79, 0x73,
-	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x70, 0x61, 0x74, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x5f, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x75, 0x73, 0x68, 0x65, 0x6e, 0x6c, 0x65, 0x2f, 0x73, 0x69, 0x6d,
-	0x70, 0x6c, 0x65, 0x2d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *BackupRequest) Reset() {
+	*x = BackupRequest{}
+	mi := &file_dump_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
+func (x *BackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupRequest) ProtoMessage() {}
+
+func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dump_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return file_dump_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BackupRequest) GetFromSnapshot() bool {
+	if x != nil {
+		return x.FromSnapshot
+	}
+	return false
+}
+
+// BackupChunk carries a piece of the backup file. The first chunk also
+// carries the raft index and term the backup reflects and the file size.
+type BackupChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Index         uint64                 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Term          uint64                 `protobuf:"varint,3,opt,name=term,proto3" json:"term,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupChunk) Reset() {
+	*x = BackupChunk{}
+	mi := &file_dump_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupChunk) ProtoMessage() {}
+
+func (x *BackupChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_dump_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupChunk.ProtoReflect.Descriptor instead.
+func (*BackupChunk) Descriptor() ([]byte, []int) {
+	return file_dump_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BackupChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *BackupChunk) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BackupChunk) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *BackupChunk) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+var File_dump_proto protoreflect.FileDescriptor
+
+const file_dump_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"dump.proto\x12\x02pb\x1a\fsearch.proto\"\x84\x01\n" +
+	"\vDumpRequest\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\apattern\x18\x03 \x01(\tR\apattern\x12/\n" +
+	"\x04mode\x18\x04 \x01(\x0e2\x1b.pb.SearchRequest.MatchModeR\x04mode\"\xb1\x01\n" +
+	"\fDumpResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1d\n" +
+	"\n" +
+	"total_keys\x18\x02 \x01(\x05R\ttotalKeys\x12\x1b\n" +
+	"\tfile_size\x18\x03 \x01(\x03R\bfileSize\x12\x12\n" +
+	"\x04path\x18\x04 \x01(\tR\x04path\x12\x16\n" +
+	"\x06format\x18\x05 \x01(\tR\x06format\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x01R\n" +
+	"durationMs\"\x82\x01\n" +
+	"\vLoadRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\apattern\x18\x02 \x01(\tR\apattern\x12/\n" +
+	"\x04mode\x18\x03 \x01(\x0e2\x1b.pb.SearchRequest.MatchModeR\x04mode\x12\x14\n" +
+	"\x05merge\x18\x04 \x01(\bR\x05merge\"\xe3\x01\n" +
+	"\fLoadResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1d\n" +
+	"\n" +
+	"total_keys\x18\x02 \x01(\x05R\ttotalKeys\x12\x1f\n" +
+	"\vloaded_keys\x18\x03 \x01(\x05R\n" +
+	"loadedKeys\x12!\n" +
+	"\fskipped_keys\x18\x04 \x01(\x05R\vskippedKeys\x12\x12\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x01R\n" +
+	"durationMs\x12!\n" +
+	"\fcommit_index\x18\a \x01(\x04R\vcommitIndex\"4\n" +
+	"\rBackupRequest\x12#\n" +
+	"\rfrom_snapshot\x18\x01 \x01(\bR\ffromSnapshot\"_\n" +
+	"\vBackupChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x04R\x05index\x12\x12\n" +
+	"\x04term\x18\x03 \x01(\x04R\x04term\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04sizeB)Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
+
 var (
 	file_dump_proto_rawDescOnce sync.Once
-	file_dump_proto_rawDescData = file_dump_proto_rawDesc
+	file_dump_proto_rawDescData []byte
 )
 
 func file_dump_proto_rawDescGZIP() []byte {
 	file_dump_proto_rawDescOnce.Do(func() {
-		file_dump_proto_rawDescData = protoimpl.X.CompressGZIP(file_dump_proto_rawDescData)
+		file_dump_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dump_proto_rawDesc), len(file_dump_proto_rawDesc)))
 	})
 	return file_dump_proto_rawDescData
 }
 
-var file_dump_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_dump_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_dump_proto_goTypes = []any{
-	(*DumpRequest)(nil),  // 0: pb.DumpRequest
-	(*DumpResponse)(nil), // 1: pb.DumpResponse
-	(*LoadRequest)(nil),  // 2: pb.LoadRequest
-	(*LoadResponse)(nil), // 3: pb.LoadResponse
+	(*DumpRequest)(nil),          // 0: pb.DumpRequest
+	(*DumpResponse)(nil),         // 1: pb.DumpResponse
+	(*LoadRequest)(nil),          // 2: pb.LoadRequest
+	(*LoadResponse)(nil),         // 3: pb.LoadResponse
+	(*BackupRequest)(nil),        // 4: pb.BackupRequest
+	(*BackupChunk)(nil),          // 5: pb.BackupChunk
+	(SearchRequest_MatchMode)(0), // 6: pb.SearchRequest.MatchMode
 }
 var file_dump_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	6, // 0: pb.DumpRequest.mode:type_name -> pb.SearchRequest.MatchMode
+	6, // 1: pb.LoadRequest.mode:type_name -> pb.SearchRequest.MatchMode
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_dump_proto_init() }
@@ -359,13 +534,14 @@ func file_dump_proto_init() {
 	if File_dump_proto != nil {
 		return
 	}
+	file_search_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_dump_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dump_proto_rawDesc), len(file_dump_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -374,7 +550,6 @@ func file_dump_proto_init() {
 		MessageInfos:      file_dump_proto_msgTypes,
 	}.Build()
 	File_dump_proto = out.File
-	file_dump_proto_rawDesc = nil
 	file_dump_proto_goTypes = nil
 	file_dump_proto_depIdxs = nil
 }
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
-// 	protoc        v5.29.3
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
 // source: get.proto
 
 package pb
@@ -12,6 +12,7 @@ import (
 	anypb "google.golang.org/protobuf/types/known/anypb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -21,12 +22,70 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// ReadConsistency selects how a read is served in distributed mode. It is
+// ignored in single mode.
+type ReadConsistency int32
+
+const (
+	// READ_LINEARIZABLE confirms leadership with a quorum heartbeat (ReadIndex).
+	ReadConsistency_READ_LINEARIZABLE ReadConsistency = 0
+	// READ_LEASE trusts the leader lease and skips the heartbeat while it holds;
+	// it assumes bounded clock drift between nodes.
+	ReadConsistency_READ_LEASE ReadConsistency = 1
+	// READ_STALE serves from the local state machine of any node, which may lag.
+	ReadConsistency_READ_STALE ReadConsistency = 2
+)
+
+// Enum value maps for ReadConsistency.
+var (
+	ReadConsistency_name = map[int32]string{
+		0: "READ_LINEARIZABLE",
+		1: "READ_LEASE",
+		2: "READ_STALE",
+	}
+	ReadConsistency_value = map[string]int32{
+		"READ_LINEARIZABLE": 0,
+		"READ_LEASE":        1,
+		"READ_STALE":        2,
+	}
+)
+
+func (x ReadConsistency) Enum() *ReadConsistency {
+	p := new(ReadConsistency)
+	*p = x
+	return p
+}
+
+func (x ReadConsistency) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReadConsistency) Descriptor() protoreflect.EnumDescriptor {
+	return file_get_proto_enumTypes[0].Descriptor()
+}
+
+func (ReadConsistency) Type() protoreflect.EnumType {
+	return &file_get_proto_enumTypes[0]
+}
+
+func (x ReadConsistency) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReadConsistency.Descriptor instead.
+func (ReadConsistency) EnumDescriptor() ([]byte, []int) {
+	return file_get_proto_rawDescGZIP(), []int{0}
+}
+
 type GetRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Key         string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Consistency ReadConsistency        `protobuf:"varint,2,opt,name=consistency,proto3,enum=pb.ReadConsistency" json:"consistency,omitempty"`
+	// min_index makes the serving node wait until it has applied this raft
+	// index, e.g. the commit_index of the caller's last write.
+	MinIndex      uint64 `protobuf:"varint,3,opt,name=min_index,json=minIndex,proto3" json:"min_index,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetRequest) Reset() {
@@ -66,13 +125,32 @@ func (x *GetRequest) GetKey() string {
 	return ""
 }
 
+func (x *GetRequest) GetConsistency() ReadConsistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return ReadConsistency_READ_LINEARIZABLE
+}
+
+func (x *GetRequest) GetMinIndex() uint64 {
+	if x != nil {
+		return x.MinIndex
+	}
+	return 0
+}
+
 type GetResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Value *anypb.Any             `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Found bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	// applied_index is the raft index the serving node had applied (distributed
+	// mode only).
+	AppliedIndex uint64 `protobuf:"varint,3,opt,name=applied_index,json=appliedIndex,proto3" json:"applied_index,omitempty"`
+	// staleness_ms is, for READ_STALE, how long ago the serving node last heard
+	// from the leader: 0 on the leader, -1 if it has no leader.
+	StalenessMs   int64 `protobuf:"varint,4,opt,name=staleness_ms,json=stalenessMs,proto3" json:"staleness_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Value *anypb.Any `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
-	Found bool       `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetResponse) Reset() {
@@ -119,49 +197,70 @@ func (x *GetResponse) GetFound() bool {
 	return false
 }
 
-var File_get_proto protoreflect.FileDescriptor
+func (x *GetResponse) GetAppliedIndex() uint64 {
+	if x != nil {
+		return x.AppliedIndex
+	}
+	return 0
+}
 
-var file_get_proto_rawDesc = []byte{
-	0x0a, 0x09, 0x67, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a,
-	0x19, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2f, 0x61, 0x6e, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x1e, 0x0a, 0x0a, 0x47, 0x65,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x4f, 0x0a, 0x0b, 0x47, 0x65,
-	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x05,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x42, 0x29, 0x5a, 0x27, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x75, 0x73, 0x68, 0x65, 0x6e,
-	0x6c, 0x65, 0x2f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x2d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2f,
-	0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *GetResponse) GetStalenessMs() int64 {
+	if x != nil {
+		return x.StalenessMs
+	}
+	return 0
 }
 
+var File_get_proto protoreflect.FileDescriptor
+
+const file_get_proto_rawDesc = "" +
+	"\n" +
+	"\tget.proto\x12\x02pb\x1a\x19google/protobuf/any.proto\"r\n" +
+	"\n" +
+	"GetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\vconsistency\x18\x02 \x01(\x0e2\x13.pb.ReadConsistencyR\vconsistency\x12\x1b\n" +
+	"\tmin_index\x18\x03 \x01(\x04R\bminIndex\"\x97\x01\n" +
+	"\vGetResponse\x12*\n" +
+	"\x05value\x18\x01 \x01(\v2\x14.google.protobuf.AnyR\x05value\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\x12#\n" +
+	"\rapplied_index\x18\x03 \x01(\x04R\fappliedIndex\x12!\n" +
+	"\fstaleness_ms\x18\x04 \x01(\x03R\vstalenessMs*H\n" +
+	"\x0fReadConsistency\x12\x15\n" +
+	"\x11READ_LINEARIZABLE\x10\x00\x12\x0e\n" +
+	"\n" +
+	"READ_LEASE\x10\x01\x12\x0e\n" +
+	"\n" +
+	"READ_STALE\x10\x02B)Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
+
 var (
 	file_get_proto_rawDescOnce sync.Once
-	file_get_proto_rawDescData = file_get_proto_rawDesc
+	file_get_proto_rawDescData []byte
 )
 
 func file_get_proto_rawDescGZIP() []byte {
 	file_get_proto_rawDescOnce.Do(func() {
-		file_get_proto_rawDescData = protoimpl.X.CompressGZIP(file_get_proto_rawDescData)
+		file_get_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_get_proto_rawDesc), len(file_get_proto_rawDesc)))
 	})
 	return file_get_proto_rawDescData
 }
 
+var file_get_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_get_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_get_proto_goTypes = []any{
-	(*GetRequest)(nil),  // 0: pb.GetRequest
-	(*GetResponse)(nil), // 1: pb.GetResponse
-	(*anypb.Any)(nil),   // 2: google.protobuf.Any
+	(ReadConsistency)(0), // 0: pb.ReadConsistency
+	(*GetRequest)(nil),   // 1: pb.GetRequest
+	(*GetResponse)(nil),  // 2: pb.GetResponse
+	(*anypb.Any)(nil),    // 3: google.protobuf.Any
 }
 var file_get_proto_depIdxs = []int32{
-	2, // 0: pb.GetResponse.value:type_name -> google.protobuf.Any
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0, // 0: pb.GetRequest.consistency:type_name -> pb.ReadConsistency
+	3, // 1: pb.GetResponse.value:type_name -> google.protobuf.Any
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_get_proto_init() }
@@ -173,18 +272,18 @@ func file_get_proto_init() {
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_get_proto_rawDesc,
-			NumEnums:      0,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_get_proto_rawDesc), len(file_get_proto_rawDesc)),
+			NumEnums:      1,
 			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_get_proto_goTypes,
 		DependencyIndexes: file_get_proto_depIdxs,
+		EnumInfos:         file_get_proto_enumTypes,
 		MessageInfos:      file_get_proto_msgTypes,
 	}.Build()
 	File_get_proto = out.File
-	file_get_proto_rawDesc = nil
 	file_get_proto_goTypes = nil
 	file_get_proto_depIdxs = nil
 }
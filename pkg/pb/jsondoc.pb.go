@@ -0,0 +1,553 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: jsondoc.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// JSONSetRequest stores a JSON value at path inside the document under key.
+// path uses "$" for the root, ".name" for object members and "[n]" for
+// array elements, e.g. "$.user.tags[0]". A missing key creates a new
+// document and missing intermediate objects are created.
+type JSONSetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Path  string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// value is JSON text, e.g. "42", "\"text\"" or "{\"a\":1}".
+	Value         string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONSetRequest) Reset() {
+	*x = JSONSetRequest{}
+	mi := &file_jsondoc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONSetRequest) ProtoMessage() {}
+
+func (x *JSONSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jsondoc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONSetRequest.ProtoReflect.Descriptor instead.
+func (*JSONSetRequest) Descriptor() ([]byte, []int) {
+	return file_jsondoc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *JSONSetRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *JSONSetRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *JSONSetRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type JSONSetResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,2,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONSetResponse) Reset() {
+	*x = JSONSetResponse{}
+	mi := &file_jsondoc_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONSetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONSetResponse) ProtoMessage() {}
+
+func (x *JSONSetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jsondoc_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONSetResponse.ProtoReflect.Descriptor instead.
+func (*JSONSetResponse) Descriptor() ([]byte, []int) {
+	return file_jsondoc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *JSONSetResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *JSONSetResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+type JSONGetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONGetRequest) Reset() {
+	*x = JSONGetRequest{}
+	mi := &file_jsondoc_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONGetRequest) ProtoMessage() {}
+
+func (x *JSONGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jsondoc_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONGetRequest.ProtoReflect.Descriptor instead.
+func (*JSONGetRequest) Descriptor() ([]byte, []int) {
+	return file_jsondoc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *JSONGetRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *JSONGetRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type JSONGetResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// value is the JSON text at path.
+	Value         string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Found         bool   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONGetResponse) Reset() {
+	*x = JSONGetResponse{}
+	mi := &file_jsondoc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONGetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONGetResponse) ProtoMessage() {}
+
+func (x *JSONGetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jsondoc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONGetResponse.ProtoReflect.Descriptor instead.
+func (*JSONGetResponse) Descriptor() ([]byte, []int) {
+	return file_jsondoc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *JSONGetResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *JSONGetResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+// JSONDelRequest removes the value at path. Deleting the root removes the key.
+type JSONDelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONDelRequest) Reset() {
+	*x = JSONDelRequest{}
+	mi := &file_jsondoc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONDelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONDelRequest) ProtoMessage() {}
+
+func (x *JSONDelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jsondoc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONDelRequest.ProtoReflect.Descriptor instead.
+func (*JSONDelRequest) Descriptor() ([]byte, []int) {
+	return file_jsondoc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *JSONDelRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *JSONDelRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type JSONDelResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Deleted bool                   `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,2,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONDelResponse) Reset() {
+	*x = JSONDelResponse{}
+	mi := &file_jsondoc_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONDelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONDelResponse) ProtoMessage() {}
+
+func (x *JSONDelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jsondoc_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONDelResponse.ProtoReflect.Descriptor instead.
+func (*JSONDelResponse) Descriptor() ([]byte, []int) {
+	return file_jsondoc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *JSONDelResponse) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *JSONDelResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+// JSONNumIncrByRequest adds delta to the number at path.
+type JSONNumIncrByRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Delta         float64                `protobuf:"fixed64,3,opt,name=delta,proto3" json:"delta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONNumIncrByRequest) Reset() {
+	*x = JSONNumIncrByRequest{}
+	mi := &file_jsondoc_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONNumIncrByRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONNumIncrByRequest) ProtoMessage() {}
+
+func (x *JSONNumIncrByRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jsondoc_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONNumIncrByRequest.ProtoReflect.Descriptor instead.
+func (*JSONNumIncrByRequest) Descriptor() ([]byte, []int) {
+	return file_jsondoc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *JSONNumIncrByRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *JSONNumIncrByRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *JSONNumIncrByRequest) GetDelta() float64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+type JSONNumIncrByResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// value is the new number as JSON text.
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,2,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONNumIncrByResponse) Reset() {
+	*x = JSONNumIncrByResponse{}
+	mi := &file_jsondoc_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONNumIncrByResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONNumIncrByResponse) ProtoMessage() {}
+
+func (x *JSONNumIncrByResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jsondoc_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONNumIncrByResponse.ProtoReflect.Descriptor instead.
+func (*JSONNumIncrByResponse) Descriptor() ([]byte, []int) {
+	return file_jsondoc_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *JSONNumIncrByResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *JSONNumIncrByResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+var File_jsondoc_proto protoreflect.FileDescriptor
+
+const file_jsondoc_proto_rawDesc = "" +
+	"\n" +
+	"\rjsondoc.proto\x12\x02pb\"L\n" +
+	"\x0eJSONSetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"N\n" +
+	"\x0fJSONSetResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
+	"\fcommit_index\x18\x02 \x01(\x04R\vcommitIndex\"6\n" +
+	"\x0eJSONGetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"=\n" +
+	"\x0fJSONGetResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\"6\n" +
+	"\x0eJSONDelRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"N\n" +
+	"\x0fJSONDelResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\bR\adeleted\x12!\n" +
+	"\fcommit_index\x18\x02 \x01(\x04R\vcommitIndex\"R\n" +
+	"\x14JSONNumIncrByRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
+	"\x05delta\x18\x03 \x01(\x01R\x05delta\"P\n" +
+	"\x15JSONNumIncrByResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12!\n" +
+	"\fcommit_index\x18\x02 \x01(\x04R\vcommitIndexB)Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
+
+var (
+	file_jsondoc_proto_rawDescOnce sync.Once
+	file_jsondoc_proto_rawDescData []byte
+)
+
+func file_jsondoc_proto_rawDescGZIP() []byte {
+	file_jsondoc_proto_rawDescOnce.Do(func() {
+		file_jsondoc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_jsondoc_proto_rawDesc), len(file_jsondoc_proto_rawDesc)))
+	})
+	return file_jsondoc_proto_rawDescData
+}
+
+var file_jsondoc_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_jsondoc_proto_goTypes = []any{
+	(*JSONSetRequest)(nil),        // 0: pb.JSONSetRequest
+	(*JSONSetResponse)(nil),       // 1: pb.JSONSetResponse
+	(*JSONGetRequest)(nil),        // 2: pb.JSONGetRequest
+	(*JSONGetResponse)(nil),       // 3: pb.JSONGetResponse
+	(*JSONDelRequest)(nil),        // 4: pb.JSONDelRequest
+	(*JSONDelResponse)(nil),       // 5: pb.JSONDelResponse
+	(*JSONNumIncrByRequest)(nil),  // 6: pb.JSONNumIncrByRequest
+	(*JSONNumIncrByResponse)(nil), // 7: pb.JSONNumIncrByResponse
+}
+var file_jsondoc_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_jsondoc_proto_init() }
+func file_jsondoc_proto_init() {
+	if File_jsondoc_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_jsondoc_proto_rawDesc), len(file_jsondoc_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_jsondoc_proto_goTypes,
+		DependencyIndexes: file_jsondoc_proto_depIdxs,
+		MessageInfos:      file_jsondoc_proto_msgTypes,
+	}.Build()
+	File_jsondoc_proto = out.File
+	file_jsondoc_proto_goTypes = nil
+	file_jsondoc_proto_depIdxs = nil
+}
@@ -0,0 +1,743 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: probabilistic.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// BFReserveRequest creates an empty Bloom filter sized for capacity items
+// at the given false positive rate.
+type BFReserveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ErrorRate     float64                `protobuf:"fixed64,2,opt,name=error_rate,json=errorRate,proto3" json:"error_rate,omitempty"`
+	Capacity      uint64                 `protobuf:"varint,3,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BFReserveRequest) Reset() {
+	*x = BFReserveRequest{}
+	mi := &file_probabilistic_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BFReserveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BFReserveRequest) ProtoMessage() {}
+
+func (x *BFReserveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BFReserveRequest.ProtoReflect.Descriptor instead.
+func (*BFReserveRequest) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BFReserveRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *BFReserveRequest) GetErrorRate() float64 {
+	if x != nil {
+		return x.ErrorRate
+	}
+	return 0
+}
+
+func (x *BFReserveRequest) GetCapacity() uint64 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+type BFReserveResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,2,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BFReserveResponse) Reset() {
+	*x = BFReserveResponse{}
+	mi := &file_probabilistic_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BFReserveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BFReserveResponse) ProtoMessage() {}
+
+func (x *BFReserveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BFReserveResponse.ProtoReflect.Descriptor instead.
+func (*BFReserveResponse) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BFReserveResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *BFReserveResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+// BFAddRequest adds items to a Bloom filter, creating one with default
+// sizing (1% error rate, 1000 items) if the key does not exist.
+type BFAddRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Items         []string               `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BFAddRequest) Reset() {
+	*x = BFAddRequest{}
+	mi := &file_probabilistic_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BFAddRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BFAddRequest) ProtoMessage() {}
+
+func (x *BFAddRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BFAddRequest.ProtoReflect.Descriptor instead.
+func (*BFAddRequest) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BFAddRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *BFAddRequest) GetItems() []string {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BFAddResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// added[i] is false if items[i] was probably already present.
+	Added []bool `protobuf:"varint,1,rep,packed,name=added,proto3" json:"added,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,2,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BFAddResponse) Reset() {
+	*x = BFAddResponse{}
+	mi := &file_probabilistic_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BFAddResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BFAddResponse) ProtoMessage() {}
+
+func (x *BFAddResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BFAddResponse.ProtoReflect.Descriptor instead.
+func (*BFAddResponse) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BFAddResponse) GetAdded() []bool {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *BFAddResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+type BFExistsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Items         []string               `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BFExistsRequest) Reset() {
+	*x = BFExistsRequest{}
+	mi := &file_probabilistic_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BFExistsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BFExistsRequest) ProtoMessage() {}
+
+func (x *BFExistsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BFExistsRequest.ProtoReflect.Descriptor instead.
+func (*BFExistsRequest) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BFExistsRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *BFExistsRequest) GetItems() []string {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BFExistsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// exists[i] is true if items[i] may have been added.
+	Exists        []bool `protobuf:"varint,1,rep,packed,name=exists,proto3" json:"exists,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BFExistsResponse) Reset() {
+	*x = BFExistsResponse{}
+	mi := &file_probabilistic_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BFExistsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BFExistsResponse) ProtoMessage() {}
+
+func (x *BFExistsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BFExistsResponse.ProtoReflect.Descriptor instead.
+func (*BFExistsResponse) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BFExistsResponse) GetExists() []bool {
+	if x != nil {
+		return x.Exists
+	}
+	return nil
+}
+
+type PFAddRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Items         []string               `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PFAddRequest) Reset() {
+	*x = PFAddRequest{}
+	mi := &file_probabilistic_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFAddRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFAddRequest) ProtoMessage() {}
+
+func (x *PFAddRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFAddRequest.ProtoReflect.Descriptor instead.
+func (*PFAddRequest) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PFAddRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PFAddRequest) GetItems() []string {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type PFAddResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// changed is true if the cardinality estimate may have changed.
+	Changed bool `protobuf:"varint,1,opt,name=changed,proto3" json:"changed,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,2,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PFAddResponse) Reset() {
+	*x = PFAddResponse{}
+	mi := &file_probabilistic_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFAddResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFAddResponse) ProtoMessage() {}
+
+func (x *PFAddResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFAddResponse.ProtoReflect.Descriptor instead.
+func (*PFAddResponse) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PFAddResponse) GetChanged() bool {
+	if x != nil {
+		return x.Changed
+	}
+	return false
+}
+
+func (x *PFAddResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+// PFCountRequest estimates the distinct count of the union of keys.
+type PFCountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PFCountRequest) Reset() {
+	*x = PFCountRequest{}
+	mi := &file_probabilistic_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFCountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFCountRequest) ProtoMessage() {}
+
+func (x *PFCountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFCountRequest.ProtoReflect.Descriptor instead.
+func (*PFCountRequest) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PFCountRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type PFCountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         uint64                 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PFCountResponse) Reset() {
+	*x = PFCountResponse{}
+	mi := &file_probabilistic_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFCountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFCountResponse) ProtoMessage() {}
+
+func (x *PFCountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFCountResponse.ProtoReflect.Descriptor instead.
+func (*PFCountResponse) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PFCountResponse) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// PFMergeRequest stores the union of dest and sources into dest.
+type PFMergeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dest          string                 `protobuf:"bytes,1,opt,name=dest,proto3" json:"dest,omitempty"`
+	Sources       []string               `protobuf:"bytes,2,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PFMergeRequest) Reset() {
+	*x = PFMergeRequest{}
+	mi := &file_probabilistic_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFMergeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFMergeRequest) ProtoMessage() {}
+
+func (x *PFMergeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFMergeRequest.ProtoReflect.Descriptor instead.
+func (*PFMergeRequest) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PFMergeRequest) GetDest() string {
+	if x != nil {
+		return x.Dest
+	}
+	return ""
+}
+
+func (x *PFMergeRequest) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type PFMergeResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,2,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PFMergeResponse) Reset() {
+	*x = PFMergeResponse{}
+	mi := &file_probabilistic_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PFMergeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PFMergeResponse) ProtoMessage() {}
+
+func (x *PFMergeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_probabilistic_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PFMergeResponse.ProtoReflect.Descriptor instead.
+func (*PFMergeResponse) Descriptor() ([]byte, []int) {
+	return file_probabilistic_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PFMergeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PFMergeResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+var File_probabilistic_proto protoreflect.FileDescriptor
+
+const file_probabilistic_proto_rawDesc = "" +
+	"\n" +
+	"\x13probabilistic.proto\x12\x02pb\"_\n" +
+	"\x10BFReserveRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1d\n" +
+	"\n" +
+	"error_rate\x18\x02 \x01(\x01R\terrorRate\x12\x1a\n" +
+	"\bcapacity\x18\x03 \x01(\x04R\bcapacity\"P\n" +
+	"\x11BFReserveResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
+	"\fcommit_index\x18\x02 \x01(\x04R\vcommitIndex\"6\n" +
+	"\fBFAddRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05items\x18\x02 \x03(\tR\x05items\"H\n" +
+	"\rBFAddResponse\x12\x14\n" +
+	"\x05added\x18\x01 \x03(\bR\x05added\x12!\n" +
+	"\fcommit_index\x18\x02 \x01(\x04R\vcommitIndex\"9\n" +
+	"\x0fBFExistsRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05items\x18\x02 \x03(\tR\x05items\"*\n" +
+	"\x10BFExistsResponse\x12\x16\n" +
+	"\x06exists\x18\x01 \x03(\bR\x06exists\"6\n" +
+	"\fPFAddRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05items\x18\x02 \x03(\tR\x05items\"L\n" +
+	"\rPFAddResponse\x12\x18\n" +
+	"\achanged\x18\x01 \x01(\bR\achanged\x12!\n" +
+	"\fcommit_index\x18\x02 \x01(\x04R\vcommitIndex\"$\n" +
+	"\x0ePFCountRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"'\n" +
+	"\x0fPFCountResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x04R\x05count\">\n" +
+	"\x0ePFMergeRequest\x12\x12\n" +
+	"\x04dest\x18\x01 \x01(\tR\x04dest\x12\x18\n" +
+	"\asources\x18\x02 \x03(\tR\asources\"N\n" +
+	"\x0fPFMergeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
+	"\fcommit_index\x18\x02 \x01(\x04R\vcommitIndexB)Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
+
+var (
+	file_probabilistic_proto_rawDescOnce sync.Once
+	file_probabilistic_proto_rawDescData []byte
+)
+
+func file_probabilistic_proto_rawDescGZIP() []byte {
+	file_probabilistic_proto_rawDescOnce.Do(func() {
+		file_probabilistic_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_probabilistic_proto_rawDesc), len(file_probabilistic_proto_rawDesc)))
+	})
+	return file_probabilistic_proto_rawDescData
+}
+
+var file_probabilistic_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_probabilistic_proto_goTypes = []any{
+	(*BFReserveRequest)(nil),  // 0: pb.BFReserveRequest
+	(*BFReserveResponse)(nil), // 1: pb.BFReserveResponse
+	(*BFAddRequest)(nil),      // 2: pb.BFAddRequest
+	(*BFAddResponse)(nil),     // 3: pb.BFAddResponse
+	(*BFExistsRequest)(nil),   // 4: pb.BFExistsRequest
+	(*BFExistsResponse)(nil),  // 5: pb.BFExistsResponse
+	(*PFAddRequest)(nil),      // 6: pb.PFAddRequest
+	(*PFAddResponse)(nil),     // 7: pb.PFAddResponse
+	(*PFCountRequest)(nil),    // 8: pb.PFCountRequest
+	(*PFCountResponse)(nil),   // 9: pb.PFCountResponse
+	(*PFMergeRequest)(nil),    // 10: pb.PFMergeRequest
+	(*PFMergeResponse)(nil),   // 11: pb.PFMergeResponse
+}
+var file_probabilistic_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_probabilistic_proto_init() }
+func file_probabilistic_proto_init() {
+	if File_probabilistic_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_probabilistic_proto_rawDesc), len(file_probabilistic_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_probabilistic_proto_goTypes,
+		DependencyIndexes: file_probabilistic_proto_depIdxs,
+		MessageInfos:      file_probabilistic_proto_msgTypes,
+	}.Build()
+	File_probabilistic_proto = out.File
+	file_probabilistic_proto_goTypes = nil
+	file_probabilistic_proto_depIdxs = nil
+}
@@ -0,0 +1,284 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: raft.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RaftService_Append_FullMethodName          = "/pb.RaftService/Append"
+	RaftService_Vote_FullMethodName            = "/pb.RaftService/Vote"
+	RaftService_InstallSnapshot_FullMethodName = "/pb.RaftService/InstallSnapshot"
+	RaftService_ReadIndex_FullMethodName       = "/pb.RaftService/ReadIndex"
+	RaftService_TimeoutNow_FullMethodName      = "/pb.RaftService/TimeoutNow"
+)
+
+// RaftServiceClient is the client API for RaftService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RaftService carries raft RPCs between cluster nodes when raft_transport is
+// grpc. It is served on the raft address next to the JSON HTTP endpoints and
+// mirrors their messages; it is not part of the client API.
+type RaftServiceClient interface {
+	// Append is a long-lived stream per leader and follower: the leader sends
+	// AppendEntries (including heartbeats) and the follower answers each one
+	// in order.
+	Append(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RaftAppendRequest, RaftAppendResponse], error)
+	Vote(ctx context.Context, in *RaftVoteRequest, opts ...grpc.CallOption) (*RaftVoteResponse, error)
+	InstallSnapshot(ctx context.Context, in *RaftInstallSnapshotRequest, opts ...grpc.CallOption) (*RaftInstallSnapshotResponse, error)
+	ReadIndex(ctx context.Context, in *RaftReadIndexRequest, opts ...grpc.CallOption) (*RaftReadIndexResponse, error)
+	// TimeoutNow asks the target of a leadership transfer to campaign now.
+	TimeoutNow(ctx context.Context, in *RaftTimeoutNowRequest, opts ...grpc.CallOption) (*RaftTimeoutNowResponse, error)
+}
+
+type raftServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRaftServiceClient(cc grpc.ClientConnInterface) RaftServiceClient {
+	return &raftServiceClient{cc}
+}
+
+func (c *raftServiceClient) Append(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RaftAppendRequest, RaftAppendResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RaftService_ServiceDesc.Streams[0], RaftService_Append_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RaftAppendRequest, RaftAppendResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RaftService_AppendClient = grpc.BidiStreamingClient[RaftAppendRequest, RaftAppendResponse]
+
+func (c *raftServiceClient) Vote(ctx context.Context, in *RaftVoteRequest, opts ...grpc.CallOption) (*RaftVoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RaftVoteResponse)
+	err := c.cc.Invoke(ctx, RaftService_Vote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *raftServiceClient) InstallSnapshot(ctx context.Context, in *RaftInstallSnapshotRequest, opts ...grpc.CallOption) (*RaftInstallSnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RaftInstallSnapshotResponse)
+	err := c.cc.Invoke(ctx, RaftService_InstallSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *raftServiceClient) ReadIndex(ctx context.Context, in *RaftReadIndexRequest, opts ...grpc.CallOption) (*RaftReadIndexResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RaftReadIndexResponse)
+	err := c.cc.Invoke(ctx, RaftService_ReadIndex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *raftServiceClient) TimeoutNow(ctx context.Context, in *RaftTimeoutNowRequest, opts ...grpc.CallOption) (*RaftTimeoutNowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RaftTimeoutNowResponse)
+	err := c.cc.Invoke(ctx, RaftService_TimeoutNow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RaftServiceServer is the server API for RaftService service.
+// All implementations must embed UnimplementedRaftServiceServer
+// for forward compatibility.
+//
+// RaftService carries raft RPCs between cluster nodes when raft_transport is
+// grpc. It is served on the raft address next to the JSON HTTP endpoints and
+// mirrors their messages; it is not part of the client API.
+type RaftServiceServer interface {
+	// Append is a long-lived stream per leader and follower: the leader sends
+	// AppendEntries (including heartbeats) and the follower answers each one
+	// in order.
+	Append(grpc.BidiStreamingServer[RaftAppendRequest, RaftAppendResponse]) error
+	Vote(context.Context, *RaftVoteRequest) (*RaftVoteResponse, error)
+	InstallSnapshot(context.Context, *RaftInstallSnapshotRequest) (*RaftInstallSnapshotResponse, error)
+	ReadIndex(context.Context, *RaftReadIndexRequest) (*RaftReadIndexResponse, error)
+	// TimeoutNow asks the target of a leadership transfer to campaign now.
+	TimeoutNow(context.Context, *RaftTimeoutNowRequest) (*RaftTimeoutNowResponse, error)
+	mustEmbedUnimplementedRaftServiceServer()
+}
+
+// UnimplementedRaftServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRaftServiceServer struct{}
+
+func (UnimplementedRaftServiceServer) Append(grpc.BidiStreamingServer[RaftAppendRequest, RaftAppendResponse]) error {
+	return status.Error(codes.Unimplemented, "method Append not implemented")
+}
+func (UnimplementedRaftServiceServer) Vote(context.Context, *RaftVoteRequest) (*RaftVoteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Vote not implemented")
+}
+func (UnimplementedRaftServiceServer) InstallSnapshot(context.Context, *RaftInstallSnapshotRequest) (*RaftInstallSnapshotResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method InstallSnapshot not implemented")
+}
+func (UnimplementedRaftServiceServer) ReadIndex(context.Context, *RaftReadIndexRequest) (*RaftReadIndexResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReadIndex not implemented")
+}
+func (UnimplementedRaftServiceServer) TimeoutNow(context.Context, *RaftTimeoutNowRequest) (*RaftTimeoutNowResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TimeoutNow not implemented")
+}
+func (UnimplementedRaftServiceServer) mustEmbedUnimplementedRaftServiceServer() {}
+func (UnimplementedRaftServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeRaftServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RaftServiceServer will
+// result in compilation errors.
+type UnsafeRaftServiceServer interface {
+	mustEmbedUnimplementedRaftServiceServer()
+}
+
+func RegisterRaftServiceServer(s grpc.ServiceRegistrar, srv RaftServiceServer) {
+	// If the following call panics, it indicates UnimplementedRaftServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RaftService_ServiceDesc, srv)
+}
+
+func _RaftService_Append_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RaftServiceServer).Append(&grpc.GenericServerStream[RaftAppendRequest, RaftAppendResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RaftService_AppendServer = grpc.BidiStreamingServer[RaftAppendRequest, RaftAppendResponse]
+
+func _RaftService_Vote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RaftVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RaftServiceServer).Vote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RaftService_Vote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RaftServiceServer).Vote(ctx, req.(*RaftVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RaftService_InstallSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RaftInstallSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RaftServiceServer).InstallSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RaftService_InstallSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RaftServiceServer).InstallSnapshot(ctx, req.(*RaftInstallSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RaftService_ReadIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RaftReadIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RaftServiceServer).ReadIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RaftService_ReadIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RaftServiceServer).ReadIndex(ctx, req.(*RaftReadIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RaftService_TimeoutNow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RaftTimeoutNowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RaftServiceServer).TimeoutNow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RaftService_TimeoutNow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RaftServiceServer).TimeoutNow(ctx, req.(*RaftTimeoutNowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RaftService_ServiceDesc is the grpc.ServiceDesc for RaftService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RaftService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.RaftService",
+	HandlerType: (*RaftServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Vote",
+			Handler:    _RaftService_Vote_Handler,
+		},
+		{
+			MethodName: "InstallSnapshot",
+			Handler:    _RaftService_InstallSnapshot_Handler,
+		},
+		{
+			MethodName: "ReadIndex",
+			Handler:    _RaftService_ReadIndex_Handler,
+		},
+		{
+			MethodName: "TimeoutNow",
+			Handler:    _RaftService_TimeoutNow_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Append",
+			Handler:       _RaftService_Append_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "raft.proto",
+}
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
-// 	protoc        v5.29.3
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
 // source: reset.proto
 
 package pb
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -21,9 +22,9 @@ const (
 )
 
 type ResetRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ResetRequest) Reset() {
@@ -57,12 +58,13 @@ func (*ResetRequest) Descriptor() ([]byte, []int) {
 }
 
 type ResetResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Success     bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	KeysCleared int32                  `protobuf:"varint,2,opt,name=keys_cleared,json=keysCleared,proto3" json:"keys_cleared,omitempty"`
+	// commit_index is as in SetResponse.
+	CommitIndex   uint64 `protobuf:"varint,3,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Success     bool  `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	KeysCleared int32 `protobuf:"varint,2,opt,name=keys_cleared,json=keysCleared,proto3" json:"keys_cleared,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ResetResponse) Reset() {
@@ -109,30 +111,32 @@ func (x *ResetResponse) GetKeysCleared() int32 {
 	return 0
 }
 
+func (x *ResetResponse) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
 var File_reset_proto protoreflect.FileDescriptor
 
-var file_reset_proto_rawDesc = []byte{
-	0x0a, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70,
-	0x62, 0x22, 0x0e, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x22, 0x4c, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x21, 0x0a, 0x0c,
-	0x6b, 0x65, 0x79, 0x73, 0x5f, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x0b, 0x6b, 0x65, 0x79, 0x73, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x42,
-	0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x75,
-	0x73, 0x68, 0x65, 0x6e, 0x6c, 0x65, 0x2f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x2d, 0x63, 0x61,
-	0x63, 0x68, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
-}
+const file_reset_proto_rawDesc = "" +
+	"\n" +
+	"\vreset.proto\x12\x02pb\"\x0e\n" +
+	"\fResetRequest\"o\n" +
+	"\rResetResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
+	"\fkeys_cleared\x18\x02 \x01(\x05R\vkeysCleared\x12!\n" +
+	"\fcommit_index\x18\x03 \x01(\x04R\vcommitIndexB)Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
 
 var (
 	file_reset_proto_rawDescOnce sync.Once
-	file_reset_proto_rawDescData = file_reset_proto_rawDesc
+	file_reset_proto_rawDescData []byte
 )
 
 func file_reset_proto_rawDescGZIP() []byte {
 	file_reset_proto_rawDescOnce.Do(func() {
-		file_reset_proto_rawDescData = protoimpl.X.CompressGZIP(file_reset_proto_rawDescData)
+		file_reset_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_reset_proto_rawDesc), len(file_reset_proto_rawDesc)))
 	})
 	return file_reset_proto_rawDescData
 }
@@ -159,7 +163,7 @@ func file_reset_proto_init() {
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_reset_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_reset_proto_rawDesc), len(file_reset_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   2,
 			NumExtensions: 0,
@@ -170,7 +174,6 @@ func file_reset_proto_init() {
 		MessageInfos:      file_reset_proto_msgTypes,
 	}.Build()
 	File_reset_proto = out.File
-	file_reset_proto_rawDesc = nil
 	file_reset_proto_goTypes = nil
 	file_reset_proto_depIdxs = nil
 }
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
-// 	protoc        v5.29.3
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
 // source: search.proto
 
 package pb
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -67,12 +68,14 @@ func (SearchRequest_MatchMode) EnumDescriptor() ([]byte, []int) {
 }
 
 type SearchRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState  `protogen:"open.v1"`
+	Pattern     string                  `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Mode        SearchRequest_MatchMode `protobuf:"varint,2,opt,name=mode,proto3,enum=pb.SearchRequest_MatchMode" json:"mode,omitempty"`
+	Consistency ReadConsistency         `protobuf:"varint,3,opt,name=consistency,proto3,enum=pb.ReadConsistency" json:"consistency,omitempty"`
+	// min_index is as in GetRequest.
+	MinIndex      uint64 `protobuf:"varint,4,opt,name=min_index,json=minIndex,proto3" json:"min_index,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Pattern string                  `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
-	Mode    SearchRequest_MatchMode `protobuf:"varint,2,opt,name=mode,proto3,enum=pb.SearchRequest_MatchMode" json:"mode,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SearchRequest) Reset() {
@@ -119,12 +122,28 @@ func (x *SearchRequest) GetMode() SearchRequest_MatchMode {
 	return SearchRequest_WILDCARD
 }
 
+func (x *SearchRequest) GetConsistency() ReadConsistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return ReadConsistency_READ_LINEARIZABLE
+}
+
+func (x *SearchRequest) GetMinIndex() uint64 {
+	if x != nil {
+		return x.MinIndex
+	}
+	return 0
+}
+
 type SearchResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Keys  []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	// applied_index and staleness_ms are as in GetResponse.
+	AppliedIndex  uint64 `protobuf:"varint,2,opt,name=applied_index,json=appliedIndex,proto3" json:"applied_index,omitempty"`
+	StalenessMs   int64  `protobuf:"varint,3,opt,name=staleness_ms,json=stalenessMs,proto3" json:"staleness_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Keys []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SearchResponse) Reset() {
@@ -164,34 +183,46 @@ func (x *SearchResponse) GetKeys() []string {
 	return nil
 }
 
-var File_search_proto protoreflect.FileDescriptor
+func (x *SearchResponse) GetAppliedIndex() uint64 {
+	if x != nil {
+		return x.AppliedIndex
+	}
+	return 0
+}
 
-var file_search_proto_rawDesc = []byte{
-	0x0a, 0x0c, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02,
-	0x70, 0x62, 0x22, 0x80, 0x01, 0x0a, 0x0d, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x2f,
-	0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x70,
-	0x62, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
-	0x4d, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22,
-	0x24, 0x0a, 0x09, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0c, 0x0a, 0x08,
-	0x57, 0x49, 0x4c, 0x44, 0x43, 0x41, 0x52, 0x44, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x52, 0x45,
-	0x47, 0x45, 0x58, 0x10, 0x01, 0x22, 0x24, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x42, 0x29, 0x5a, 0x27, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x75, 0x73, 0x68, 0x65, 0x6e,
-	0x6c, 0x65, 0x2f, 0x73, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x2d, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2f,
-	0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *SearchResponse) GetStalenessMs() int64 {
+	if x != nil {
+		return x.StalenessMs
+	}
+	return 0
 }
 
+var File_search_proto protoreflect.FileDescriptor
+
+const file_search_proto_rawDesc = "" +
+	"\n" +
+	"\fsearch.proto\x12\x02pb\x1a\tget.proto\"\xd4\x01\n" +
+	"\rSearchRequest\x12\x18\n" +
+	"\apattern\x18\x01 \x01(\tR\apattern\x12/\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x1b.pb.SearchRequest.MatchModeR\x04mode\x125\n" +
+	"\vconsistency\x18\x03 \x01(\x0e2\x13.pb.ReadConsistencyR\vconsistency\x12\x1b\n" +
+	"\tmin_index\x18\x04 \x01(\x04R\bminIndex\"$\n" +
+	"\tMatchMode\x12\f\n" +
+	"\bWILDCARD\x10\x00\x12\t\n" +
+	"\x05REGEX\x10\x01\"l\n" +
+	"\x0eSearchResponse\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\x12#\n" +
+	"\rapplied_index\x18\x02 \x01(\x04R\fappliedIndex\x12!\n" +
+	"\fstaleness_ms\x18\x03 \x01(\x03R\vstalenessMsB)Z'github.com/lushenle/simple-cache/pkg/pbb\x06proto3"
+
 var (
 	file_search_proto_rawDescOnce sync.Once
-	file_search_proto_rawDescData = file_search_proto_rawDesc
+	file_search_proto_rawDescData []byte
 )
 
 func file_search_proto_rawDescGZIP() []byte {
 	file_search_proto_rawDescOnce.Do(func() {
-		file_search_proto_rawDescData = protoimpl.X.CompressGZIP(file_search_proto_rawDescData)
+		file_search_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_search_proto_rawDesc), len(file_search_proto_rawDesc)))
 	})
 	return file_search_proto_rawDescData
 }
@@ -202,14 +233,16 @@ var file_search_proto_goTypes = []any{
 	(SearchRequest_MatchMode)(0), // 0: pb.SearchRequest.MatchMode
 	(*SearchRequest)(nil),        // 1: pb.SearchRequest
 	(*SearchResponse)(nil),       // 2: pb.SearchResponse
+	(ReadConsistency)(0),         // 3: pb.ReadConsistency
 }
 var file_search_proto_depIdxs = []int32{
 	0, // 0: pb.SearchRequest.mode:type_name -> pb.SearchRequest.MatchMode
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 1: pb.SearchRequest.consistency:type_name -> pb.ReadConsistency
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_search_proto_init() }
@@ -217,11 +250,12 @@ func file_search_proto_init() {
 	if File_search_proto != nil {
 		return
 	}
+	file_get_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_search_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_search_proto_rawDesc), len(file_search_proto_rawDesc)),
 			NumEnums:      1,
 			NumMessages:   2,
 			NumExtensions: 0,
@@ -233,7 +267,6 @@ func file_search_proto_init() {
 		MessageInfos:      file_search_proto_msgTypes,
 	}.Build()
 	File_search_proto = out.File
-	file_search_proto_rawDesc = nil
 	file_search_proto_goTypes = nil
 	file_search_proto_depIdxs = nil
 }
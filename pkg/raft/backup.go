@@ -0,0 +1,172 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrNoSnapshot is returned by Backup when a backup of the latest snapshot
+// is requested but the node has none.
+var ErrNoSnapshot = errors.New("no raft snapshot")
+
+// Backup is a backup file ready to be streamed. It has the snapshot file
+// layout (see snapshotMagic), so its header records the index and term the
+// data reflects, and it is encrypted if the storage has a keyring. Close
+// must be called to release it.
+type Backup struct {
+	Meta SnapshotMeta
+	// Size is the size of the backup file in bytes.
+	Size int64
+
+	f       *os.File
+	tmpPath string // removed on Close; empty for the live snapshot file
+}
+
+func (b *Backup) Read(p []byte) (int, error) { return b.f.Read(p) }
+
+// Close releases the backup and removes its temp file, if any.
+func (b *Backup) Close() error {
+	err := b.f.Close()
+	if b.tmpPath != "" {
+		_ = os.Remove(b.tmpPath)
+	}
+	return err
+}
+
+// Backup takes a backup of the state machine. With fromSnapshot it returns
+// the latest raft snapshot as is, on any node. Otherwise the node must be
+// the leader: it confirms leadership and waits for every committed entry to
+// be applied, as ReadIndex does, then captures the state machine at its
+// applied index. The capture holds up applies only while it is written to a
+// temp file, not while the caller streams it.
+func (n *Node) Backup(ctx context.Context, fromSnapshot bool) (*Backup, error) {
+	if fromSnapshot {
+		return n.storage.openSnapshotBackup()
+	}
+	snapshotter, ok := n.applier.(SnapshotProvider)
+	if !ok {
+		return nil, fmt.Errorf("state machine does not support snapshots")
+	}
+	if _, err := n.ReadIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	n.applyMu.Lock()
+	defer n.applyMu.Unlock()
+	n.mu.Lock()
+	meta := SnapshotMeta{
+		LastIncludedIndex: n.lastApply,
+		LastIncludedTerm:  n.termAtLocked(n.lastApply),
+		NodeID:            n.id,
+		CreatedAt:         time.Now().UTC().Format(time.RFC3339),
+	}
+	n.mu.Unlock()
+
+	tmpPath := fmt.Sprintf("%s.backup-%d", n.storage.snapshotPath(), time.Now().UnixNano())
+	sink, err := n.storage.createSnapshotSink(meta, tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapshotter.Snapshot(n.id, sink); err != nil {
+		sink.Abort()
+		return nil, err
+	}
+	if err := sink.flush(); err != nil {
+		sink.Abort()
+		return nil, err
+	}
+	size, err := sink.f.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = sink.f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		sink.Abort()
+		return nil, err
+	}
+	return &Backup{Meta: meta, Size: size, f: sink.f, tmpPath: tmpPath}, nil
+}
+
+// openSnapshotBackup opens the current snapshot file for a backup. The file
+// stays readable if a newer snapshot replaces it meanwhile.
+func (s *Storage) openSnapshotBackup() (*Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.snapshotPath()
+	meta, r, _, err := openSnapshotFile(path, s.keys)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Close()
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &Backup{Meta: *meta, Size: info.Size(), f: f}, nil
+}
+
+// RestoreBackup bootstraps empty storage from the backup file at path, so
+// that a node of a brand-new cluster starts from the backed-up state. Every
+// node of the new cluster must be restored from the same file before any of
+// them starts.
+//
+// The snapshot data is passed to verify, which should decode it fully, and
+// is rewritten as this storage's snapshot, encrypted with its keyring if it
+// has one. The raft meta starts at the backup's term and index so the new
+// cluster's log continues after it. Peers are left to the node's config.
+func (s *Storage) RestoreBackup(path string, verify func(r io.Reader) error) (*SnapshotMeta, error) {
+	for _, p := range []string{s.path, s.metaPath(), s.snapshotPath()} {
+		if _, err := os.Stat(p); err == nil {
+			return nil, fmt.Errorf("raft state already exists: %s", p)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	meta, data, _, err := openSnapshotFile(path, s.keys)
+	if err != nil {
+		return nil, fmt.Errorf("open backup: %w", err)
+	}
+	defer data.Close()
+	if meta.LastIncludedIndex == 0 {
+		return nil, fmt.Errorf("backup has no raft index")
+	}
+
+	sink, err := s.CreateSnapshot(*meta)
+	if err != nil {
+		return nil, err
+	}
+	tee := io.TeeReader(data, sink)
+	if err := verify(tee); err != nil {
+		sink.Abort()
+		return nil, fmt.Errorf("verify backup: %w", err)
+	}
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		sink.Abort()
+		return nil, err
+	}
+	if err := sink.Commit(); err != nil {
+		return nil, err
+	}
+	if err := s.SaveMeta(&Meta{
+		CurrentTerm:   meta.LastIncludedTerm,
+		CommitIndex:   meta.LastIncludedIndex,
+		SnapshotIndex: meta.LastIncludedIndex,
+		SnapshotTerm:  meta.LastIncludedTerm,
+	}); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
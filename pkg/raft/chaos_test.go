@@ -0,0 +1,156 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/stretchr/testify/require"
+)
+
+// keys returns the keys the applier holds. Values are not compared: the
+// leader applies the submitted command while followers decode it from JSON.
+func (f *fakeApplier) keys() map[string]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]bool, len(f.items))
+	for k := range f.items {
+		out[k] = true
+	}
+	return out
+}
+
+// submitToLeader submits cmd to whichever node leads, retrying across
+// elections until it commits.
+func submitToLeader(t *testing.T, cmd interface{}, nodes ...*Node) *Node {
+	t.Helper()
+	var leader *Node
+	waitForCondition(t, func() bool {
+		for _, n := range nodes {
+			if n.Role() != Leader {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			_, err := n.Submit(ctx, cmd)
+			cancel()
+			if err == nil {
+				leader = n
+				return true
+			}
+		}
+		return false
+	})
+	return leader
+}
+
+// waitForNewLeader waits until one of nodes other than old leads in a term
+// above minTerm.
+func waitForNewLeader(t *testing.T, old *Node, minTerm uint64, nodes ...*Node) *Node {
+	t.Helper()
+	var leader *Node
+	waitForCondition(t, func() bool {
+		for _, n := range nodes {
+			if n == old || n.Role() != Leader {
+				continue
+			}
+			n.mu.Lock()
+			term := n.term
+			n.mu.Unlock()
+			if term > minTerm {
+				leader = n
+				return true
+			}
+		}
+		return false
+	})
+	return leader
+}
+
+func TestMemTransportPartitionedLeaderStepsDown(t *testing.T) {
+	network := NewMemNetwork(1)
+	nodes, appliers := newMemCluster(t, network, 5)
+	leader := submitToLeader(t, &command.SetCommand{Key: "before", Value: "v"}, nodes...)
+
+	leader.mu.Lock()
+	oldTerm := leader.term
+	leader.mu.Unlock()
+	network.Isolate(leader.trans.SelfAddr())
+
+	// The minority side cannot commit.
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	_, err := leader.Submit(ctx, &command.SetCommand{Key: "lost", Value: "v"})
+	cancel()
+	require.Error(t, err)
+	var commitErr ErrCommit
+	var notLeader ErrNotLeader
+	var unknown ErrResultUnknown
+	require.True(t, errors.As(err, &commitErr) || errors.As(err, &notLeader) || errors.As(err, &unknown), "unexpected error %v", err)
+
+	// The majority elects a new leader and keeps accepting writes.
+	newLeader := waitForNewLeader(t, leader, oldTerm, nodes...)
+	_, err = newLeader.Submit(context.Background(), &command.SetCommand{Key: "after", Value: "v"})
+	require.NoError(t, err)
+
+	network.Heal()
+	waitForCondition(t, func() bool { return leader.Role() == Follower })
+	for _, n := range nodes {
+		waitForCondition(t, func() bool { return appliers[n].Has("after") })
+		require.False(t, appliers[n].Has("lost"), "uncommitted write surfaced on %s", n.id)
+	}
+}
+
+func TestMemTransportLeaderChurnUnderFaults(t *testing.T) {
+	network := NewMemNetwork(42)
+	network.SetLatency(0, 3*time.Millisecond)
+	network.SetDropRate(0.05)
+	network.SetDuplicateRate(0.05)
+	network.SetReorder(0.1, 10*time.Millisecond)
+	nodes, appliers := newMemCluster(t, network, 3)
+
+	acked := map[string]bool{}
+	leader := waitForLeader(t, nodes...)
+	for round := 0; round < 4; round++ {
+		for i := 0; i < 15; i++ {
+			key := fmt.Sprintf("r%d-k%d", round, i)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			_, err := leader.Submit(ctx, &command.SetCommand{Key: key, Value: round})
+			cancel()
+			if err == nil {
+				acked[key] = true
+			}
+		}
+		// Churn: cut the leader off long enough for the others to elect a
+		// new one, then let it back in.
+		leader.mu.Lock()
+		term := leader.term
+		leader.mu.Unlock()
+		network.Isolate(leader.trans.SelfAddr())
+		next := waitForNewLeader(t, leader, term, nodes...)
+		network.Heal()
+		waitForCondition(t, func() bool { return leader.Role() != Leader })
+		leader = next
+	}
+	require.NotEmpty(t, acked)
+
+	// Once the network is clean again every node converges on the same
+	// state, and no acknowledged write was lost.
+	network.SetDropRate(0)
+	network.SetDuplicateRate(0)
+	network.SetReorder(0, 0)
+	_, err := leader.Submit(context.Background(), &command.SetCommand{Key: "final", Value: "v"})
+	require.NoError(t, err)
+	for _, n := range nodes {
+		waitForCondition(t, func() bool { return appliers[n].Has("final") })
+	}
+	want := appliers[leader].keys()
+	for key := range acked {
+		require.True(t, want[key], "acknowledged write %s lost", key)
+	}
+	for _, n := range nodes {
+		waitForCondition(t, func() bool { return len(appliers[n].keys()) == len(want) })
+		require.Equal(t, want, appliers[n].keys(), "node %s diverged", n.id)
+	}
+}
@@ -0,0 +1,491 @@
+package raft
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcFallbackPeriod is how long a peer that failed over gRPC but answered
+// over HTTP, such as a node not yet upgraded, is sent HTTP only.
+const grpcFallbackPeriod = 30 * time.Second
+
+// LoadTransportTLS builds the mutual TLS configuration of the raft
+// transport: the node presents certFile/keyFile both as server and as
+// client, and accepts only peers whose certificate is signed by caFile.
+func LoadTransportTLS(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load raft tls key pair: %w", err)
+	}
+	pemData, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read raft tls ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("no certificates in raft tls ca %s", caFile)
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, nil
+}
+
+// raftGRPCServer serves RaftService for the node.
+type raftGRPCServer struct {
+	pb.UnimplementedRaftServiceServer
+	t *HTTPTransport
+}
+
+func (s *raftGRPCServer) authorize(ctx context.Context) error {
+	if s.t.authToken == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if v == "Bearer "+s.t.authToken {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+func (s *raftGRPCServer) Append(stream pb.RaftService_AppendServer) error {
+	if err := s.authorize(stream.Context()); err != nil {
+		return err
+	}
+	h := s.t.handler
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		req := appendReqFromPB(in)
+		if s.t.logger != nil {
+			s.t.logger.Debug("grpc recv append", zap.String("node", s.t.selfAddr), zap.String("leader", req.LeaderID), zap.Uint64("term", req.Term))
+		}
+		resp := h.HandleAppendEntries(req)
+		if err := stream.Send(&pb.RaftAppendResponse{
+			Term:         resp.Term,
+			Success:      resp.Success,
+			MatchIndex:   resp.MatchIndex,
+			LastLogIndex: resp.LastLogIndex,
+			NodeId:       resp.NodeID,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *raftGRPCServer) Vote(ctx context.Context, in *pb.RaftVoteRequest) (*pb.RaftVoteResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	resp := s.t.handler.HandleRequestVote(RequestVoteReq{
+		Term:               in.GetTerm(),
+		CandidateID:        in.GetCandidateId(),
+		LastLogIndex:       in.GetLastLogIndex(),
+		LastLogTerm:        in.GetLastLogTerm(),
+		PreVote:            in.GetPreVote(),
+		LeadershipTransfer: in.GetLeadershipTransfer(),
+	})
+	return &pb.RaftVoteResponse{Term: resp.Term, VoteGranted: resp.VoteGranted}, nil
+}
+
+func (s *raftGRPCServer) InstallSnapshot(ctx context.Context, in *pb.RaftInstallSnapshotRequest) (*pb.RaftInstallSnapshotResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	resp := s.t.handler.HandleInstallSnapshot(InstallSnapshotReq{
+		Term:              in.GetTerm(),
+		LeaderID:          in.GetLeaderId(),
+		LastIncludedIndex: in.GetLastIncludedIndex(),
+		LastIncludedTerm:  in.GetLastIncludedTerm(),
+		Data:              in.GetData(),
+		Offset:            in.GetOffset(),
+		Done:              in.GetDone(),
+		Peers:             in.GetPeers(),
+		Learners:          in.GetLearners(),
+		Joint:             configChangeFromPB(in.GetJoint()),
+	})
+	return &pb.RaftInstallSnapshotResponse{Term: resp.Term, Success: resp.Success}, nil
+}
+
+func (s *raftGRPCServer) ReadIndex(ctx context.Context, in *pb.RaftReadIndexRequest) (*pb.RaftReadIndexResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	resp := s.t.handler.HandleReadIndex(ctx, ReadIndexReq{FollowerID: in.GetFollowerId()})
+	return &pb.RaftReadIndexResponse{
+		Term:     resp.Term,
+		Index:    resp.Index,
+		Success:  resp.Success,
+		LeaderId: resp.LeaderID,
+	}, nil
+}
+
+func (s *raftGRPCServer) TimeoutNow(ctx context.Context, in *pb.RaftTimeoutNowRequest) (*pb.RaftTimeoutNowResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	resp := s.t.handler.HandleTimeoutNow(TimeoutNowReq{Term: in.GetTerm(), LeaderID: in.GetLeaderId()})
+	return &pb.RaftTimeoutNowResponse{Term: resp.Term, Success: resp.Success}, nil
+}
+
+func appendReqToPB(req AppendEntriesReq) *pb.RaftAppendRequest {
+	out := &pb.RaftAppendRequest{
+		Term:         req.Term,
+		LeaderId:     req.LeaderID,
+		PrevLogIndex: req.PrevLogIndex,
+		PrevLogTerm:  req.PrevLogTerm,
+		CommitIndex:  req.CommitIdx,
+		LeaderAddr:   req.LeaderAddr,
+	}
+	if len(req.Entries) > 0 {
+		out.Entries = make([]*pb.RaftLogEntry, len(req.Entries))
+		for i, e := range req.Entries {
+			out.Entries[i] = &pb.RaftLogEntry{Index: e.Index, Term: e.Term, Type: string(e.Type), Data: e.Data}
+		}
+	}
+	return out
+}
+
+func appendReqFromPB(in *pb.RaftAppendRequest) AppendEntriesReq {
+	req := AppendEntriesReq{
+		Term:         in.GetTerm(),
+		LeaderID:     in.GetLeaderId(),
+		PrevLogIndex: in.GetPrevLogIndex(),
+		PrevLogTerm:  in.GetPrevLogTerm(),
+		CommitIdx:    in.GetCommitIndex(),
+		LeaderAddr:   in.GetLeaderAddr(),
+	}
+	if len(in.GetEntries()) > 0 {
+		req.Entries = make([]LogEntry, len(in.GetEntries()))
+		for i, e := range in.GetEntries() {
+			req.Entries[i] = LogEntry{Index: e.GetIndex(), Term: e.GetTerm(), Type: EntryType(e.GetType()), Data: e.GetData()}
+		}
+	}
+	return req
+}
+
+func configChangeToPB(c *ConfigChange) *pb.RaftConfigChange {
+	if c == nil {
+		return nil
+	}
+	return &pb.RaftConfigChange{Old: c.Old, New: c.New}
+}
+
+func configChangeFromPB(in *pb.RaftConfigChange) *ConfigChange {
+	if in == nil {
+		return nil
+	}
+	return &ConfigChange{Old: in.GetOld(), New: in.GetNew()}
+}
+
+// grpcPeer is the client side of RaftService towards one peer.
+type grpcPeer struct {
+	conn   *grpc.ClientConn
+	client pb.RaftServiceClient
+
+	// appendSem serializes use of the append stream: requests and
+	// responses on it are matched by order.
+	appendSem    chan struct{}
+	stream       pb.RaftService_AppendClient
+	cancelStream context.CancelFunc
+}
+
+// grpcSender sends raft RPCs over gRPC and remembers peers that have to be
+// sent HTTP instead.
+type grpcSender struct {
+	t *HTTPTransport
+
+	mu       sync.Mutex
+	peers    map[string]*grpcPeer
+	httpOnly map[string]time.Time
+	closed   bool
+}
+
+func newGRPCSender(t *HTTPTransport) *grpcSender {
+	return &grpcSender{
+		t:        t,
+		peers:    make(map[string]*grpcPeer),
+		httpOnly: make(map[string]time.Time),
+	}
+}
+
+// usable reports whether peer should be tried over gRPC.
+func (g *grpcSender) usable(peer string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return time.Now().After(g.httpOnly[peer])
+}
+
+// fellBack records that peer failed over gRPC but answered over HTTP.
+func (g *grpcSender) fellBack(peer string, err error) {
+	g.mu.Lock()
+	g.httpOnly[peer] = time.Now().Add(grpcFallbackPeriod)
+	g.mu.Unlock()
+	if g.t.logger != nil {
+		g.t.logger.Info("raft peer does not answer grpc, using http", zap.String("peer", peer), zap.Error(err))
+	}
+}
+
+// fallbackable reports whether a gRPC error means the peer did not process
+// the request, so resending it over HTTP is safe.
+func fallbackable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unimplemented, codes.Unavailable:
+		return true
+	}
+	return false
+}
+
+func (g *grpcSender) peer(addr string) (*grpcPeer, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return nil, errors.New("raft grpc transport closed")
+	}
+	if p := g.peers[addr]; p != nil {
+		return p, nil
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	creds := insecure.NewCredentials()
+	if g.t.tlsConfig != nil {
+		creds = credentials.NewTLS(g.t.tlsConfig.Clone())
+	}
+	conn, err := grpc.NewClient("passthrough:///"+u.Host,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(raftSnapshotChunkMaxBody)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	p := &grpcPeer{
+		conn:      conn,
+		client:    pb.NewRaftServiceClient(conn),
+		appendSem: make(chan struct{}, 1),
+	}
+	g.peers[addr] = p
+	return p, nil
+}
+
+func (g *grpcSender) outgoing(ctx context.Context) context.Context {
+	if g.t.authToken == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+g.t.authToken)
+}
+
+// removePeer closes the connection to a peer that left the cluster.
+func (g *grpcSender) removePeer(addr string) {
+	g.mu.Lock()
+	p := g.peers[addr]
+	delete(g.peers, addr)
+	delete(g.httpOnly, addr)
+	g.mu.Unlock()
+	if p != nil {
+		p.close()
+	}
+}
+
+func (g *grpcSender) close() {
+	g.mu.Lock()
+	g.closed = true
+	peers := g.peers
+	g.peers = nil
+	g.mu.Unlock()
+	for _, p := range peers {
+		p.close()
+	}
+}
+
+func (p *grpcPeer) close() {
+	p.appendSem <- struct{}{}
+	p.resetStream()
+	<-p.appendSem
+	_ = p.conn.Close()
+}
+
+// resetStream drops the append stream; the caller holds appendSem.
+func (p *grpcPeer) resetStream() {
+	if p.cancelStream != nil {
+		p.cancelStream()
+	}
+	p.stream, p.cancelStream = nil, nil
+}
+
+func (g *grpcSender) sendAppend(ctx context.Context, peer string, req AppendEntriesReq) (AppendEntriesResp, error) {
+	p, err := g.peer(peer)
+	if err != nil {
+		return AppendEntriesResp{}, err
+	}
+	select {
+	case p.appendSem <- struct{}{}:
+	case <-ctx.Done():
+		return AppendEntriesResp{}, ctx.Err()
+	}
+	defer func() { <-p.appendSem }()
+
+	if p.stream == nil {
+		sctx, cancel := context.WithCancel(g.outgoing(context.Background()))
+		stream, err := p.client.Append(sctx)
+		if err != nil {
+			cancel()
+			return AppendEntriesResp{}, err
+		}
+		p.stream, p.cancelStream = stream, cancel
+	}
+	stream := p.stream
+	if err := stream.Send(appendReqToPB(req)); err != nil {
+		// Send reports io.EOF when the stream broke; Recv has the cause.
+		_, err = stream.Recv()
+		p.resetStream()
+		return AppendEntriesResp{}, err
+	}
+	type result struct {
+		resp *pb.RaftAppendResponse
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		resp, err := stream.Recv()
+		ch <- result{resp, err}
+	}()
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			p.resetStream()
+			return AppendEntriesResp{}, r.err
+		}
+		return AppendEntriesResp{
+			Term:         r.resp.GetTerm(),
+			Success:      r.resp.GetSuccess(),
+			MatchIndex:   r.resp.GetMatchIndex(),
+			LastLogIndex: r.resp.GetLastLogIndex(),
+			NodeID:       r.resp.GetNodeId(),
+		}, nil
+	case <-ctx.Done():
+		// The late response would answer the next request; start over.
+		p.resetStream()
+		return AppendEntriesResp{}, ctx.Err()
+	}
+}
+
+func (g *grpcSender) sendVote(ctx context.Context, peer string, req RequestVoteReq) (RequestVoteResp, error) {
+	p, err := g.peer(peer)
+	if err != nil {
+		return RequestVoteResp{}, err
+	}
+	resp, err := p.client.Vote(g.outgoing(ctx), &pb.RaftVoteRequest{
+		Term:               req.Term,
+		CandidateId:        req.CandidateID,
+		LastLogIndex:       req.LastLogIndex,
+		LastLogTerm:        req.LastLogTerm,
+		PreVote:            req.PreVote,
+		LeadershipTransfer: req.LeadershipTransfer,
+	})
+	if err != nil {
+		return RequestVoteResp{}, err
+	}
+	return RequestVoteResp{Term: resp.GetTerm(), VoteGranted: resp.GetVoteGranted()}, nil
+}
+
+func (g *grpcSender) sendInstallSnapshot(ctx context.Context, peer string, req InstallSnapshotReq) (InstallSnapshotResp, error) {
+	p, err := g.peer(peer)
+	if err != nil {
+		return InstallSnapshotResp{}, err
+	}
+	resp, err := p.client.InstallSnapshot(g.outgoing(ctx), &pb.RaftInstallSnapshotRequest{
+		Term:              req.Term,
+		LeaderId:          req.LeaderID,
+		LastIncludedIndex: req.LastIncludedIndex,
+		LastIncludedTerm:  req.LastIncludedTerm,
+		Data:              req.Data,
+		Offset:            req.Offset,
+		Done:              req.Done,
+		Peers:             req.Peers,
+		Learners:          req.Learners,
+		Joint:             configChangeToPB(req.Joint),
+	})
+	if err != nil {
+		return InstallSnapshotResp{}, err
+	}
+	return InstallSnapshotResp{Term: resp.GetTerm(), Success: resp.GetSuccess()}, nil
+}
+
+func (g *grpcSender) sendReadIndex(ctx context.Context, peer string, req ReadIndexReq) (ReadIndexResp, error) {
+	p, err := g.peer(peer)
+	if err != nil {
+		return ReadIndexResp{}, err
+	}
+	resp, err := p.client.ReadIndex(g.outgoing(ctx), &pb.RaftReadIndexRequest{FollowerId: req.FollowerID})
+	if err != nil {
+		return ReadIndexResp{}, err
+	}
+	return ReadIndexResp{
+		Term:     resp.GetTerm(),
+		Index:    resp.GetIndex(),
+		Success:  resp.GetSuccess(),
+		LeaderID: resp.GetLeaderId(),
+	}, nil
+}
+
+func (g *grpcSender) sendTimeoutNow(ctx context.Context, peer string, req TimeoutNowReq) (TimeoutNowResp, error) {
+	p, err := g.peer(peer)
+	if err != nil {
+		return TimeoutNowResp{}, err
+	}
+	resp, err := p.client.TimeoutNow(g.outgoing(ctx), &pb.RaftTimeoutNowRequest{Term: req.Term, LeaderId: req.LeaderID})
+	if err != nil {
+		return TimeoutNowResp{}, err
+	}
+	return TimeoutNowResp{Term: resp.GetTerm(), Success: resp.GetSuccess()}, nil
+}
+
+// viaGRPC sends one raft RPC over gRPC when enabled for peer, falling back
+// to HTTP if the peer did not take it over gRPC.
+func viaGRPC[Req, Resp any](ctx context.Context, t *HTTPTransport, peer string, req Req,
+	grpcSend func(*grpcSender, context.Context, string, Req) (Resp, error),
+	httpSend func(context.Context, string, Req) (Resp, error)) (Resp, error) {
+	g := t.grpc
+	if g == nil || !g.usable(peer) {
+		return httpSend(ctx, peer, req)
+	}
+	resp, err := grpcSend(g, ctx, peer, req)
+	if err == nil || !fallbackable(err) {
+		return resp, err
+	}
+	resp, httpErr := httpSend(ctx, peer, req)
+	if httpErr != nil {
+		return resp, httpErr
+	}
+	g.fellBack(peer, err)
+	return resp, nil
+}
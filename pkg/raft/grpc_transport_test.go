@@ -0,0 +1,225 @@
+package raft
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTransportCluster starts a three-node cluster; opts[i] configures node i.
+func newTransportCluster(t *testing.T, scheme, token string, opts ...[]NodeOption) ([]*Node, map[*Node]*fakeApplier) {
+	t.Helper()
+	baseDir := t.TempDir()
+	addrs := []string{freeAddr(t), freeAddr(t), freeAddr(t)}
+	peers := make([]string, len(addrs))
+	for i, addr := range addrs {
+		peers[i] = scheme + "://" + addr
+	}
+	var nodes []*Node
+	appliers := map[*Node]*fakeApplier{}
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		applier := newFakeApplier()
+		var nodeOpts []NodeOption
+		if i < len(opts) {
+			nodeOpts = opts[i]
+		}
+		n, err := NewNode(id, addr, peers, NewStorage(filepath.Join(baseDir, id+".wal")), applier, 80*time.Millisecond, 180*time.Millisecond, true, 8, zap.NewNop(), token, nodeOpts...)
+		require.NoError(t, err)
+		t.Cleanup(n.Close)
+		nodes = append(nodes, n)
+		appliers[n] = applier
+	}
+	return nodes, appliers
+}
+
+// grpcPeers reports how many peers n talks to over gRPC and how many it
+// fell back to HTTP for.
+func grpcPeers(n *Node) (grpcCount, httpOnly int) {
+	g := n.trans.(*HTTPTransport).grpc
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.peers), len(g.httpOnly)
+}
+
+func TestGRPCTransportReplicatesAndFailsOver(t *testing.T) {
+	grpcOpts := []NodeOption{WithGRPCTransport()}
+	nodes, appliers := newTransportCluster(t, "http", "secret", grpcOpts, grpcOpts, grpcOpts)
+	leader := waitForLeader(t, nodes...)
+
+	for i := 0; i < 20; i++ {
+		_, err := leader.Submit(context.Background(), &command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
+		require.NoError(t, err)
+	}
+	for _, n := range nodes {
+		waitForCondition(t, func() bool { return appliers[n].Count() == 20 })
+	}
+	conns, fallbacks := grpcPeers(leader)
+	require.Equal(t, 2, conns)
+	require.Zero(t, fallbacks)
+
+	for _, n := range nodes {
+		if n == leader {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := n.FollowerReadIndex(ctx)
+		cancel()
+		require.NoError(t, err)
+	}
+
+	leader.Close()
+	var survivors []*Node
+	for _, n := range nodes {
+		if n != leader {
+			survivors = append(survivors, n)
+		}
+	}
+	newLeader := waitForLeader(t, survivors...)
+	_, err := newLeader.Submit(context.Background(), &command.SetCommand{Key: "after-failover", Value: "v"})
+	require.NoError(t, err)
+	for _, n := range survivors {
+		waitForCondition(t, func() bool { return appliers[n].Has("after-failover") })
+	}
+}
+
+func TestGRPCTransportMixedCluster(t *testing.T) {
+	// Rolling upgrade: one node sends gRPC, the others still send JSON.
+	nodes, appliers := newTransportCluster(t, "http", "", []NodeOption{WithGRPCTransport()})
+	leader := waitForLeader(t, nodes...)
+
+	for i := 0; i < 10; i++ {
+		_, err := leader.Submit(context.Background(), &command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
+		require.NoError(t, err)
+	}
+	for _, n := range nodes {
+		waitForCondition(t, func() bool { return appliers[n].Count() == 10 })
+	}
+}
+
+func TestGRPCTransportFallsBackToHTTP(t *testing.T) {
+	// A peer that predates RaftService only answers the JSON endpoints.
+	old := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/raft/vote" {
+			http.NotFound(w, r)
+			return
+		}
+		out, _ := json.Marshal(RequestVoteResp{Term: 7, VoteGranted: true})
+		w.Write(out)
+	}))
+	defer old.Close()
+
+	trans, err := NewHTTPTransport("127.0.0.1:0", []string{old.URL}, "", nil)
+	require.NoError(t, err)
+	trans.configure(nodeOptions{grpc: true})
+	defer trans.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	resp, err := trans.SendVote(ctx, old.URL, RequestVoteReq{Term: 7, CandidateID: "n1"})
+	require.NoError(t, err)
+	require.True(t, resp.VoteGranted)
+	require.False(t, trans.grpc.usable(old.URL), "peer should be sent HTTP after the fallback")
+}
+
+func TestGRPCTransportRejectsBadToken(t *testing.T) {
+	nodes, _ := newTransportCluster(t, "http", "secret")
+	waitForLeader(t, nodes...)
+
+	trans, err := NewHTTPTransport("127.0.0.1:0", nil, "wrong", nil)
+	require.NoError(t, err)
+	trans.configure(nodeOptions{grpc: true})
+	defer trans.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	peer := nodes[0].trans.Peers()[0]
+	_, err = trans.grpc.sendVote(ctx, peer, RequestVoteReq{Term: 1, CandidateID: "intruder"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unauthenticated")
+}
+
+func TestGRPCTransportMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caFile, certFile, keyFile := writeTestCerts(t, dir)
+	tlsCfg, err := LoadTransportTLS(certFile, keyFile, caFile)
+	require.NoError(t, err)
+
+	opts := []NodeOption{WithGRPCTransport(), WithTransportTLS(tlsCfg)}
+	nodes, appliers := newTransportCluster(t, "https", "", opts, opts, opts)
+	leader := waitForLeader(t, nodes...)
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "k", Value: "v"})
+	require.NoError(t, err)
+	for _, n := range nodes {
+		waitForCondition(t, func() bool { return appliers[n].Has("k") })
+	}
+
+	// A client without a certificate is turned away.
+	plain := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg.Clone()}}
+	plain.Transport.(*http.Transport).TLSClientConfig.Certificates = nil
+	_, err = plain.Post(leader.trans.Peers()[0]+"/raft/vote", "application/json", nil)
+	require.Error(t, err)
+}
+
+// writeTestCerts writes a CA and a node certificate for 127.0.0.1 signed by
+// it, and returns the CA, certificate and key file paths.
+func writeTestCerts(t *testing.T, dir string) (string, string, string) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "raft test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "raft node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "node.pem")
+	keyFile := filepath.Join(dir, "node-key.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600))
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return caFile, certFile, keyFile
+}
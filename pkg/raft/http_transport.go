@@ -3,6 +3,7 @@ package raft
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,7 +14,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/lushenle/simple-cache/pkg/pb"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 // raftHTTPClient is a shared HTTP client with timeout and connection pooling.
@@ -26,14 +29,25 @@ var raftHTTPClient = &http.Client{
 	},
 }
 
+// HTTPTransport is the default Transport: raft RPCs as JSON over HTTP, with
+// RaftService served next to them for peers that send gRPC.
 type HTTPTransport struct {
 	addr      string
 	selfAddr  string
 	authToken string
 	mu        sync.RWMutex
 	peers     []string
-	node      *Node
+	handler   RPCHandler
+	logger    *zap.Logger
 	httpSrv   *http.Server
+	grpcSrv   *grpc.Server
+
+	// tlsConfig enables mutual TLS for serving and sending raft RPCs.
+	tlsConfig *tls.Config
+	// httpClient sends the JSON RPCs; raftHTTPClient unless TLS is on.
+	httpClient *http.Client
+	// grpc sends raft RPCs over gRPC; nil sends JSON over HTTP only.
+	grpc *grpcSender
 }
 
 // Request body limits: raft RPCs are small; InstallSnapshot chunks are
@@ -58,13 +72,32 @@ func NewHTTPTransport(addr string, peers []string, authToken string, logger *zap
 		}
 		normalized = append(normalized, value)
 	}
-	t := &HTTPTransport{addr: addr, peers: normalized, authToken: authToken}
+	t := &HTTPTransport{addr: addr, peers: normalized, authToken: authToken, logger: logger, httpClient: raftHTTPClient}
 	t.selfAddr = findSelfAddr(addr, normalized)
 	return t, nil
 }
 
-func (t *HTTPTransport) Start(node *Node) {
-	t.node = node
+// configure applies the node options to the transport before Start.
+func (t *HTTPTransport) configure(o nodeOptions) {
+	if o.tls != nil {
+		t.tlsConfig = o.tls
+		t.httpClient = &http.Client{
+			Timeout: raftHTTPClient.Timeout,
+			Transport: &http.Transport{
+				TLSClientConfig:     o.tls.Clone(),
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		}
+	}
+	if o.grpc {
+		t.grpc = newGRPCSender(t)
+	}
+}
+
+func (t *HTTPTransport) Start(h RPCHandler) {
+	t.handler = h
 	mux := http.NewServeMux()
 	mux.HandleFunc("/raft/append", func(w http.ResponseWriter, r *http.Request) {
 		if !t.authorized(w, r) {
@@ -81,10 +114,10 @@ func (t *HTTPTransport) Start(node *Node) {
 			http.Error(w, "invalid json", http.StatusBadRequest)
 			return
 		}
-		if node.logger != nil {
-			node.logger.Debug("http recv append", zap.String("node", node.id), zap.String("leader", req.LeaderID), zap.Uint64("term", req.Term))
+		if t.logger != nil {
+			t.logger.Debug("http recv append", zap.String("node", t.selfAddr), zap.String("leader", req.LeaderID), zap.Uint64("term", req.Term))
 		}
-		resp := node.onAppendEntries(req)
+		resp := h.HandleAppendEntries(req)
 		out, _ := json.Marshal(resp)
 		w.WriteHeader(http.StatusOK)
 		w.Write(out)
@@ -104,10 +137,10 @@ func (t *HTTPTransport) Start(node *Node) {
 			http.Error(w, "invalid json", http.StatusBadRequest)
 			return
 		}
-		if node.logger != nil {
-			node.logger.Debug("http recv vote", zap.String("node", node.id), zap.String("candidate", req.CandidateID), zap.Uint64("term", req.Term))
+		if t.logger != nil {
+			t.logger.Debug("http recv vote", zap.String("node", t.selfAddr), zap.String("candidate", req.CandidateID), zap.Uint64("term", req.Term))
 		}
-		resp := node.onRequestVote(req)
+		resp := h.HandleRequestVote(req)
 		out, _ := json.Marshal(resp)
 		w.WriteHeader(http.StatusOK)
 		w.Write(out)
@@ -127,34 +160,138 @@ func (t *HTTPTransport) Start(node *Node) {
 			http.Error(w, "invalid json", http.StatusBadRequest)
 			return
 		}
-		if node.logger != nil {
-			node.logger.Debug("http recv install snapshot", zap.String("node", node.id), zap.String("leader", req.LeaderID), zap.Uint64("term", req.Term), zap.Uint64("index", req.LastIncludedIndex))
+		if t.logger != nil {
+			t.logger.Debug("http recv install snapshot", zap.String("node", t.selfAddr), zap.String("leader", req.LeaderID), zap.Uint64("term", req.Term), zap.Uint64("index", req.LastIncludedIndex))
+		}
+		resp := h.HandleInstallSnapshot(req)
+		out, _ := json.Marshal(resp)
+		w.WriteHeader(http.StatusOK)
+		w.Write(out)
+	})
+	mux.HandleFunc("/raft/read_index", func(w http.ResponseWriter, r *http.Request) {
+		if !t.authorized(w, r) {
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, raftRPCMaxBody)
+		var req ReadIndexReq
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "read body failed", http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(b, &req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		if t.logger != nil {
+			t.logger.Debug("http recv read index", zap.String("node", t.selfAddr), zap.String("follower", req.FollowerID))
+		}
+		resp := h.HandleReadIndex(r.Context(), req)
+		out, _ := json.Marshal(resp)
+		w.WriteHeader(http.StatusOK)
+		w.Write(out)
+	})
+	mux.HandleFunc("/raft/timeout_now", func(w http.ResponseWriter, r *http.Request) {
+		if !t.authorized(w, r) {
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, raftRPCMaxBody)
+		var req TimeoutNowReq
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "read body failed", http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(b, &req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		if t.logger != nil {
+			t.logger.Debug("http recv timeout now", zap.String("node", t.selfAddr), zap.String("leader", req.LeaderID), zap.Uint64("term", req.Term))
 		}
-		resp := node.onInstallSnapshot(req)
+		resp := h.HandleTimeoutNow(req)
 		out, _ := json.Marshal(resp)
 		w.WriteHeader(http.StatusOK)
 		w.Write(out)
 	})
-	t.httpSrv = &http.Server{Addr: t.addr, Handler: mux}
+	// RaftService is always served next to the JSON endpoints so that nodes
+	// using either transport can talk to this one.
+	t.grpcSrv = grpc.NewServer(grpc.MaxRecvMsgSize(raftSnapshotChunkMaxBody))
+	pb.RegisterRaftServiceServer(t.grpcSrv, &raftGRPCServer{t: t})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			t.grpcSrv.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	t.httpSrv = &http.Server{Addr: t.addr, Handler: handler, Protocols: &protocols}
+	if t.tlsConfig != nil {
+		cfg := t.tlsConfig.Clone()
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		t.httpSrv.TLSConfig = cfg
+	}
 	go func() {
-		if err := t.httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			if node.logger != nil {
-				node.logger.Error("raft http transport failed", zap.Error(err))
+		var err error
+		if t.tlsConfig != nil {
+			err = t.httpSrv.ListenAndServeTLS("", "")
+		} else {
+			err = t.httpSrv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			if t.logger != nil {
+				t.logger.Error("raft http transport failed", zap.Error(err))
 			}
 		}
 	}()
 }
 
-// Close gracefully shuts down the raft HTTP server.
+// SelfAddr returns this node's own address from the peer list, if found.
+func (t *HTTPTransport) SelfAddr() string { return t.selfAddr }
+
+// Close gracefully shuts down the raft HTTP server. gRPC streams are
+// long-lived, so they are closed first rather than waited for.
 func (t *HTTPTransport) Close() {
+	if t.grpc != nil {
+		t.grpc.close()
+	}
+	if t.grpcSrv != nil {
+		t.grpcSrv.Stop()
+	}
 	if t.httpSrv != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer cancel()
-		_ = t.httpSrv.Shutdown(ctx)
+		if err := t.httpSrv.Shutdown(ctx); err != nil {
+			_ = t.httpSrv.Close()
+		}
 	}
 }
 
-func (t *HTTPTransport) sendAppend(ctx context.Context, peer string, req AppendEntriesReq) (AppendEntriesResp, error) {
+func (t *HTTPTransport) SendAppend(ctx context.Context, peer string, req AppendEntriesReq) (AppendEntriesResp, error) {
+	return viaGRPC(ctx, t, peer, req, (*grpcSender).sendAppend, t.sendAppendHTTP)
+}
+
+func (t *HTTPTransport) SendVote(ctx context.Context, peer string, req RequestVoteReq) (RequestVoteResp, error) {
+	return viaGRPC(ctx, t, peer, req, (*grpcSender).sendVote, t.sendVoteHTTP)
+}
+
+func (t *HTTPTransport) SendInstallSnapshot(ctx context.Context, peer string, req InstallSnapshotReq) (InstallSnapshotResp, error) {
+	return viaGRPC(ctx, t, peer, req, (*grpcSender).sendInstallSnapshot, t.sendInstallSnapshotHTTP)
+}
+
+func (t *HTTPTransport) SendReadIndex(ctx context.Context, peer string, req ReadIndexReq) (ReadIndexResp, error) {
+	return viaGRPC(ctx, t, peer, req, (*grpcSender).sendReadIndex, t.sendReadIndexHTTP)
+}
+
+func (t *HTTPTransport) SendTimeoutNow(ctx context.Context, peer string, req TimeoutNowReq) (TimeoutNowResp, error) {
+	return viaGRPC(ctx, t, peer, req, (*grpcSender).sendTimeoutNow, t.sendTimeoutNowHTTP)
+}
+
+func (t *HTTPTransport) sendAppendHTTP(ctx context.Context, peer string, req AppendEntriesReq) (AppendEntriesResp, error) {
 	b, err := json.Marshal(req)
 	if err != nil {
 		return AppendEntriesResp{}, err
@@ -167,10 +304,10 @@ func (t *HTTPTransport) sendAppend(ctx context.Context, peer string, req AppendE
 	httpReq.Header.Set("Content-Type", "application/json")
 	t.setAuthHeader(httpReq)
 
-	resp, err := raftHTTPClient.Do(httpReq)
+	resp, err := t.httpClient.Do(httpReq)
 	if err != nil {
-		if t.node != nil && t.node.logger != nil {
-			t.node.logger.Debug("send append failed", zap.String("peer", peer), zap.Error(err))
+		if t.logger != nil {
+			t.logger.Debug("send append failed", zap.String("peer", peer), zap.Error(err))
 		}
 		return AppendEntriesResp{}, err
 	}
@@ -192,7 +329,7 @@ func (t *HTTPTransport) sendAppend(ctx context.Context, peer string, req AppendE
 	return out, nil
 }
 
-func (t *HTTPTransport) sendInstallSnapshot(ctx context.Context, peer string, req InstallSnapshotReq) (InstallSnapshotResp, error) {
+func (t *HTTPTransport) sendInstallSnapshotHTTP(ctx context.Context, peer string, req InstallSnapshotReq) (InstallSnapshotResp, error) {
 	b, err := json.Marshal(req)
 	if err != nil {
 		return InstallSnapshotResp{}, err
@@ -205,10 +342,10 @@ func (t *HTTPTransport) sendInstallSnapshot(ctx context.Context, peer string, re
 	httpReq.Header.Set("Content-Type", "application/json")
 	t.setAuthHeader(httpReq)
 
-	resp, err := raftHTTPClient.Do(httpReq)
+	resp, err := t.httpClient.Do(httpReq)
 	if err != nil {
-		if t.node != nil && t.node.logger != nil {
-			t.node.logger.Debug("send install snapshot failed", zap.String("peer", peer), zap.Error(err))
+		if t.logger != nil {
+			t.logger.Debug("send install snapshot failed", zap.String("peer", peer), zap.Error(err))
 		}
 		return InstallSnapshotResp{}, err
 	}
@@ -229,6 +366,80 @@ func (t *HTTPTransport) sendInstallSnapshot(ctx context.Context, peer string, re
 	return out, nil
 }
 
+func (t *HTTPTransport) sendReadIndexHTTP(ctx context.Context, peer string, req ReadIndexReq) (ReadIndexResp, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return ReadIndexResp{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, peer+"/raft/read_index", bytes.NewReader(b))
+	if err != nil {
+		return ReadIndexResp{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	t.setAuthHeader(httpReq)
+
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		if t.logger != nil {
+			t.logger.Debug("send read index failed", zap.String("peer", peer), zap.Error(err))
+		}
+		return ReadIndexResp{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ReadIndexResp{}, fmt.Errorf("read index request failed with status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ReadIndexResp{}, err
+	}
+	var out ReadIndexResp
+	if err := json.Unmarshal(body, &out); err != nil {
+		return ReadIndexResp{}, err
+	}
+	return out, nil
+}
+
+func (t *HTTPTransport) sendTimeoutNowHTTP(ctx context.Context, peer string, req TimeoutNowReq) (TimeoutNowResp, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return TimeoutNowResp{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, peer+"/raft/timeout_now", bytes.NewReader(b))
+	if err != nil {
+		return TimeoutNowResp{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	t.setAuthHeader(httpReq)
+
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		if t.logger != nil {
+			t.logger.Debug("send timeout now failed", zap.String("peer", peer), zap.Error(err))
+		}
+		return TimeoutNowResp{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return TimeoutNowResp{}, fmt.Errorf("timeout now request failed with status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return TimeoutNowResp{}, err
+	}
+	var out TimeoutNowResp
+	if err := json.Unmarshal(body, &out); err != nil {
+		return TimeoutNowResp{}, err
+	}
+	return out, nil
+}
+
 func (t *HTTPTransport) AddPeer(addr string) bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -255,6 +466,9 @@ func (t *HTTPTransport) RemovePeer(addr string) bool {
 		return false
 	}
 	t.peers = append(t.peers[:idx], t.peers[idx+1:]...)
+	if t.grpc != nil {
+		go t.grpc.removePeer(addr)
+	}
 	return true
 }
 
@@ -267,36 +481,7 @@ func (t *HTTPTransport) Peers() []string {
 	return out
 }
 
-func (t *HTTPTransport) broadcastVote(req RequestVoteReq) int {
-	peers := t.Peers()
-	var mu sync.Mutex
-	granted := 0
-	var wg sync.WaitGroup
-	for _, p := range peers {
-		if t.isSelf(p) {
-			continue
-		}
-		wg.Add(1)
-		go func(peer string) {
-			defer wg.Done()
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-			out, err := t.sendVote(ctx, peer, req)
-			cancel()
-			if err != nil {
-				return
-			}
-			if out.VoteGranted {
-				mu.Lock()
-				granted++
-				mu.Unlock()
-			}
-		}(p)
-	}
-	wg.Wait()
-	return granted
-}
-
-func (t *HTTPTransport) sendVote(ctx context.Context, peer string, req RequestVoteReq) (RequestVoteResp, error) {
+func (t *HTTPTransport) sendVoteHTTP(ctx context.Context, peer string, req RequestVoteReq) (RequestVoteResp, error) {
 	b, err := json.Marshal(req)
 	if err != nil {
 		return RequestVoteResp{}, err
@@ -309,10 +494,10 @@ func (t *HTTPTransport) sendVote(ctx context.Context, peer string, req RequestVo
 	httpReq.Header.Set("Content-Type", "application/json")
 	t.setAuthHeader(httpReq)
 
-	resp, err := raftHTTPClient.Do(httpReq)
+	resp, err := t.httpClient.Do(httpReq)
 	if err != nil {
-		if t.node != nil && t.node.logger != nil {
-			t.node.logger.Debug("send vote failed", zap.String("peer", peer), zap.Error(err))
+		if t.logger != nil {
+			t.logger.Debug("send vote failed", zap.String("peer", peer), zap.Error(err))
 		}
 		return RequestVoteResp{}, err
 	}
@@ -354,7 +539,8 @@ func (t *HTTPTransport) setAuthHeader(req *http.Request) {
 	}
 }
 
-func (t *HTTPTransport) isSelf(peer string) bool {
+// IsSelf reports whether peer is this node's own address.
+func (t *HTTPTransport) IsSelf(peer string) bool {
 	if t.selfAddr != "" && peer == t.selfAddr {
 		return true
 	}
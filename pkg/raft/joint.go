@@ -0,0 +1,266 @@
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Reconfiguration describes a change of the voter set. Voters, if set, is
+// the complete new set; otherwise the new set is the current voters plus
+// Add minus Remove.
+type Reconfiguration struct {
+	Voters []string `json:"voters,omitempty"`
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// Reconfigure moves the cluster to a new voter set in one operation using
+// joint consensus. It first commits C_old,new, under which elections,
+// commits and reads need a majority of both the old and the new voters,
+// and then C_new. Learners named in the new set become voters, removed
+// voters leave the cluster, and a leader outside the new set steps down
+// once C_new is applied. Reconfigure waits until ctx is done for C_new to
+// be applied; if it gives up earlier the leader still completes the change.
+func (n *Node) Reconfigure(ctx context.Context, rc Reconfiguration) error {
+	if n.Role() != Leader {
+		return ErrNotLeader{Leader: n.leaderID.Load().(string)}
+	}
+	entryIndex, isSingle, err := func() (uint64, bool, error) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if n.peerChangeInFlightLocked() {
+			return 0, false, ErrPeerChangeInFlight{}
+		}
+		old := n.votersLocked()
+		slices.Sort(old)
+		target, err := reconfigTarget(old, rc)
+		if err != nil {
+			return 0, false, err
+		}
+		if slices.Equal(old, target) {
+			return 0, false, nil
+		}
+		return n.appendMembershipLocked(EntryTypeJointConfig, ConfigChange{Old: old, New: target})
+	}()
+	if err != nil || entryIndex == 0 {
+		return err
+	}
+	if err := n.commitMembership(entryIndex, isSingle); err != nil {
+		return err
+	}
+	return n.awaitJointDone(ctx, entryIndex)
+}
+
+// reconfigTarget returns the sorted voter set that rc asks for.
+func reconfigTarget(current []string, rc Reconfiguration) ([]string, error) {
+	var target []string
+	if len(rc.Voters) > 0 {
+		if len(rc.Add) > 0 || len(rc.Remove) > 0 {
+			return nil, ErrInvalidPeerChange{}
+		}
+		for _, addr := range rc.Voters {
+			normalized, err := NormalizePeerAddr(addr)
+			if err != nil {
+				return nil, ErrInvalidPeerChange{}
+			}
+			if !containsPeer(target, normalized) {
+				target = append(target, normalized)
+			}
+		}
+	} else {
+		target = slices.Clone(current)
+		for _, addr := range rc.Remove {
+			normalized, err := NormalizePeerAddr(addr)
+			if err != nil {
+				return nil, ErrInvalidPeerChange{}
+			}
+			i := slices.Index(target, normalized)
+			if i < 0 {
+				return nil, ErrPeerNotFound{}
+			}
+			target = slices.Delete(target, i, i+1)
+		}
+		for _, addr := range rc.Add {
+			normalized, err := NormalizePeerAddr(addr)
+			if err != nil {
+				return nil, ErrInvalidPeerChange{}
+			}
+			if containsPeer(target, normalized) {
+				return nil, ErrPeerExists{}
+			}
+			target = append(target, normalized)
+		}
+	}
+	if len(target) == 0 {
+		return nil, ErrInvalidPeerChange{}
+	}
+	slices.Sort(target)
+	return target, nil
+}
+
+// awaitJointDone replicates until the joint configuration appended at
+// jointIndex has been replaced by C_new on this node.
+func (n *Node) awaitJointDone(ctx context.Context, jointIndex uint64) error {
+	for {
+		n.mu.Lock()
+		done := n.lastApply >= jointIndex && n.joint == nil
+		n.mu.Unlock()
+		if done {
+			return nil
+		}
+		if n.Role() != Leader {
+			return ErrNotLeader{Leader: n.leaderID.Load().(string)}
+		}
+		n.finishJoint()
+		n.replicateAllWithDeadline(time.Now().Add(n.hb))
+		select {
+		case <-ctx.Done():
+			return ErrCommit{Err: ctx.Err()}
+		case <-n.stopCh:
+			return ErrCommit{}
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// finishJoint appends C_new once C_old,new is applied on the leader, so a
+// joint configuration completes even if the leader that started it is gone.
+func (n *Node) finishJoint() {
+	n.mu.Lock()
+	var (
+		index  uint64
+		single bool
+		err    error
+	)
+	if n.joint != nil && n.Role() == Leader && n.transferee == "" && !n.membershipPendingLocked() {
+		index, single, err = n.appendMembershipLocked(EntryTypeFinalConfig, ConfigChange{New: n.joint.New})
+	}
+	n.mu.Unlock()
+	if err != nil {
+		if n.logger != nil {
+			n.logger.Warn("append final config failed", zap.String("node", n.id), zap.Error(err))
+		}
+		return
+	}
+	if index != 0 && single {
+		_ = n.applyCommittedEntries()
+	}
+}
+
+// membershipPendingLocked reports whether a membership entry has been
+// appended but not applied yet.
+func (n *Node) membershipPendingLocked() bool {
+	for idx := n.lastApply + 1; idx <= n.lastLogIndex; idx++ {
+		if e, ok := n.entryAtLocked(idx); ok && isMembershipEntry(e.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreMembership replaces the membership with the one recorded in an
+// installed snapshot, which stands in for the membership entries compacted
+// out of the leader's log. Snapshots without it leave the membership alone.
+func (n *Node) restoreMembership(meta SnapshotMeta) {
+	if len(meta.Peers) == 0 {
+		return
+	}
+	var added, removed []string
+	for _, addr := range meta.Peers {
+		if n.trans.AddPeer(addr) {
+			added = append(added, addr)
+		}
+	}
+	for _, peer := range n.trans.Peers() {
+		if !containsPeer(meta.Peers, peer) && n.trans.RemovePeer(peer) {
+			removed = append(removed, peer)
+		}
+	}
+	n.mu.Lock()
+	for _, addr := range added {
+		n.nextIndex[addr] = n.lastLogIndex + 1
+	}
+	for _, addr := range removed {
+		delete(n.nextIndex, addr)
+		delete(n.matchIndex, addr)
+		delete(n.peerAck, addr)
+	}
+	clear(n.learners)
+	for _, addr := range meta.Learners {
+		n.learners[addr] = true
+	}
+	n.joint = meta.Joint
+	n.flushMeta()
+	n.mu.Unlock()
+	metrics.SetPeersTotal(len(n.trans.Peers()))
+}
+
+// applyConfigChange enters or leaves a joint configuration. Entering it
+// adds the new voters to the transport; leaving it removes the old voters
+// that are not in C_new.
+func (n *Node) applyConfigChange(entry LogEntry) error {
+	var change ConfigChange
+	if err := json.Unmarshal(entry.Data, &change); err != nil {
+		return err
+	}
+	if len(change.New) == 0 {
+		return errors.New("config change missing voters")
+	}
+
+	switch entry.Type {
+	case EntryTypeJointConfig:
+		var added []string
+		for _, addr := range change.New {
+			if n.trans.AddPeer(addr) {
+				added = append(added, addr)
+			}
+		}
+		n.mu.Lock()
+		for _, addr := range added {
+			n.nextIndex[addr] = n.lastLogIndex + 1
+		}
+		for _, addr := range change.New {
+			delete(n.learners, addr)
+		}
+		n.joint = &change
+		n.flushMeta()
+		n.mu.Unlock()
+
+	case EntryTypeFinalConfig:
+		n.mu.Lock()
+		var removed []string
+		for _, peer := range n.trans.Peers() {
+			if !containsPeer(change.New, peer) && !n.learners[peer] {
+				removed = append(removed, peer)
+			}
+		}
+		n.mu.Unlock()
+		for _, addr := range removed {
+			n.trans.RemovePeer(addr)
+		}
+		n.mu.Lock()
+		for _, addr := range removed {
+			delete(n.nextIndex, addr)
+			delete(n.matchIndex, addr)
+			delete(n.peerAck, addr)
+		}
+		n.joint = nil
+		if self := n.trans.SelfAddr(); n.Role() == Leader && self != "" && !containsPeer(change.New, self) {
+			// A leader outside C_new hands over once C_new is in effect.
+			n.stepDownLocked(n.term)
+			if n.logger != nil {
+				n.logger.Info("leader removed by reconfiguration, stepping down", zap.String("node", n.id))
+			}
+		}
+		n.flushMeta()
+		n.mu.Unlock()
+	}
+	metrics.SetPeersTotal(len(n.trans.Peers()))
+	return nil
+}
@@ -0,0 +1,124 @@
+package raft
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// learnerCatchUpLag is how many committed entries a learner may still be
+// missing when it is promoted; the rest arrive with the next heartbeats.
+const learnerCatchUpLag = 64
+
+// ErrNotLearner is returned when promoting a peer that is not a learner.
+type ErrNotLearner struct{}
+
+func (e ErrNotLearner) Error() string { return "peer is not a learner" }
+
+// ErrLearnerBehind is returned when a learner has not caught up with the
+// leader's log in time to be promoted.
+type ErrLearnerBehind struct {
+	Match, Commit uint64
+}
+
+func (e ErrLearnerBehind) Error() string {
+	return "learner has not caught up with the leader's log"
+}
+
+// AsLearner starts a node that is about to join an existing cluster as a
+// learner. It does not campaign until it is promoted, which otherwise it
+// would only learn once the membership entry adding it reaches its log.
+// It has no effect on a node that already has membership on disk.
+func AsLearner() NodeOption {
+	return func(o *nodeOptions) { o.learner = true }
+}
+
+// AddLearner adds addr as a learner: it receives the log and snapshots but
+// does not vote and does not count towards commit or read quorums, so the
+// cluster's quorum is unchanged while the new node catches up.
+func (n *Node) AddLearner(addr string) error {
+	return n.submitMembershipChange(EntryTypeAddPeer, addr, true)
+}
+
+// PromoteLearner turns the learner addr into a voter. It waits, until ctx
+// is done, for the learner to catch up with the leader's log first and
+// returns ErrLearnerBehind if it does not.
+func (n *Node) PromoteLearner(ctx context.Context, addr string) error {
+	for {
+		err := n.submitMembershipChange(EntryTypePromotePeer, addr, false)
+		if _, behind := err.(ErrLearnerBehind); !behind {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-n.stopCh:
+			return err
+		case <-time.After(n.hb):
+		}
+	}
+}
+
+// Learners returns the addresses of the learners among the peers.
+func (n *Node) Learners() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.learnersLocked()
+}
+
+// IsLearner reports whether this node is a learner.
+func (n *Node) IsLearner() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.selfLearnerLocked()
+}
+
+func (n *Node) learnersLocked() []string {
+	out := make([]string, 0, len(n.learners))
+	for addr := range n.learners {
+		out = append(out, addr)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (n *Node) selfLearnerLocked() bool {
+	for addr := range n.learners {
+		if n.trans.IsSelf(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// votersLocked returns the peers that vote and count towards quorums.
+func (n *Node) votersLocked() []string {
+	peers := n.trans.Peers()
+	if len(n.learners) == 0 {
+		return peers
+	}
+	voters := peers[:0]
+	for _, peer := range peers {
+		if !n.learners[peer] {
+			voters = append(voters, peer)
+		}
+	}
+	return voters
+}
+
+// voters is votersLocked for callers that do not hold n.mu.
+func (n *Node) voters() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.votersLocked()
+}
+
+// learnerCaughtUpLocked reports whether the learner addr is close enough
+// to the leader's commit index to be promoted.
+func (n *Node) learnerCaughtUpLocked(addr string) error {
+	match := n.matchIndex[addr]
+	if match+learnerCatchUpLag < n.commitIdx || match == 0 && n.commitIdx > 0 {
+		return ErrLearnerBehind{Match: match, Commit: n.commitIdx}
+	}
+	return nil
+}
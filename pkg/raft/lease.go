@@ -0,0 +1,104 @@
+package raft
+
+import (
+	"context"
+	"time"
+)
+
+// leaseDuration is how long a quorum acknowledgement keeps the leader lease
+// valid, measured from when the acknowledged AppendEntries was sent. The
+// acknowledging followers refuse votes for the election timeout after they
+// receive it, so no other leader can be elected within that window; the
+// lease keeps a tenth of it as a margin for clock drift.
+func (n *Node) leaseDuration() time.Duration {
+	return n.el - n.el/10
+}
+
+// recordAckLocked records that peer answered an AppendEntries sent at sent
+// in the current term.
+func (n *Node) recordAckLocked(peer string, sent time.Time) {
+	if sent.After(n.peerAck[peer]) {
+		n.peerAck[peer] = sent
+	}
+}
+
+// leaseValidLocked reports whether a quorum, counting the leader itself,
+// acknowledged an AppendEntries sent within the lease duration.
+func (n *Node) leaseValidLocked(now time.Time) bool {
+	if n.transferee != "" {
+		// The transfer target may be elected without waiting out the
+		// followers' election timeouts.
+		return false
+	}
+	// The quorum-th newest acknowledgement bounds the lease.
+	oldest, ok := quorumValueLocked(n, now, func(peer string) time.Time {
+		return n.peerAck[peer]
+	}, time.Time.Compare)
+	return ok && !oldest.IsZero() && !oldest.Before(n.leaseFence) && now.Sub(oldest) < n.leaseDuration()
+}
+
+// fenceLeaseLocked drops the lease and keeps it from being renewed until an
+// election timeout from now, when no election started before now can still
+// be won without this leader's heartbeats being refused.
+func (n *Node) fenceLeaseLocked(now time.Time) {
+	clear(n.peerAck)
+	n.leaseFence = now.Add(n.el)
+}
+
+// LeaseReadIndex is ReadIndex without the quorum heartbeat while the leader
+// holds its lease: it trusts recent acknowledgements from a quorum, which
+// assumes bounded clock drift between nodes. Without a valid lease it
+// falls back to ReadIndex, whose heartbeat round renews the lease.
+func (n *Node) LeaseReadIndex(ctx context.Context) (uint64, error) {
+	if n.Role() != Leader {
+		return 0, ErrNotLeader{Leader: n.LeaderID()}
+	}
+	n.mu.Lock()
+	idx := n.commitIdx
+	ok := n.termAtLocked(idx) == n.term && n.leaseValidLocked(time.Now())
+	n.mu.Unlock()
+	if !ok {
+		return n.ReadIndex(ctx)
+	}
+	if err := n.waitApplied(ctx, idx, true); err != nil {
+		return 0, err
+	}
+	return idx, nil
+}
+
+// heardFromLeaderWithin reports whether this node accepted a message from
+// the leader within d.
+func (n *Node) heardFromLeaderWithin(d time.Duration) bool {
+	last := n.leaderContact.Load()
+	return last != 0 && time.Since(time.Unix(0, last)) < d
+}
+
+// leaderMayHoldLease reports whether a leader may be counting on an
+// acknowledgement from this node for its lease: the node heard from the
+// leader within the election timeout, or started less than an election
+// timeout ago and cannot know.
+func (n *Node) leaderMayHoldLease() bool {
+	return n.heardFromLeaderWithin(n.el) || time.Since(n.startedAt) < n.el
+}
+
+// AppliedIndex returns the index of the last entry applied to the state
+// machine.
+func (n *Node) AppliedIndex() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.lastApply
+}
+
+// LastLeaderContact returns when this node last heard from the leader: now
+// on the leader itself, zero if a follower has not heard from one in the
+// current term.
+func (n *Node) LastLeaderContact() time.Time {
+	if n.Role() == Leader {
+		return time.Now()
+	}
+	last := n.leaderContact.Load()
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
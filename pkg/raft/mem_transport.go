@@ -0,0 +1,331 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrUnreachable is returned by MemTransport when the peer is partitioned
+// away, closed or not registered on the network.
+var ErrUnreachable = errors.New("raft: peer unreachable")
+
+// ErrDropped is returned by MemTransport when the network dropped the
+// request or its response.
+var ErrDropped = errors.New("raft: message dropped")
+
+// MemNetwork connects MemTransports in one process. Faults are injected per
+// message from a seeded source, so a test replays the same decisions for
+// the same seed and sequence of messages; partitions apply until healed.
+type MemNetwork struct {
+	mu     sync.Mutex
+	rnd    *rand.Rand
+	nodes  map[string]*MemTransport
+	groups map[string]int
+	down   map[string]bool
+
+	minLatency   time.Duration
+	maxLatency   time.Duration
+	dropRate     float64
+	dupRate      float64
+	reorderRate  float64
+	reorderDelay time.Duration
+}
+
+// NewMemNetwork returns a network without faults whose random decisions
+// derive from seed.
+func NewMemNetwork(seed int64) *MemNetwork {
+	return &MemNetwork{
+		rnd:    rand.New(rand.NewSource(seed)),
+		nodes:  make(map[string]*MemTransport),
+		groups: make(map[string]int),
+		down:   make(map[string]bool),
+	}
+}
+
+// SetLatency delays every message, request and response alike, by a
+// uniformly chosen duration in [min, max].
+func (m *MemNetwork) SetLatency(min, max time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if max < min {
+		max = min
+	}
+	m.minLatency, m.maxLatency = min, max
+}
+
+// SetDropRate drops each request and each response with probability p. A
+// dropped response means the peer handled the request but the sender sees
+// ErrDropped.
+func (m *MemNetwork) SetDropRate(p float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropRate = p
+}
+
+// SetDuplicateRate delivers each request a second time with probability p;
+// the response to the copy is discarded.
+func (m *MemNetwork) SetDuplicateRate(p float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dupRate = p
+}
+
+// SetReorder holds each request back by up to delay with probability p, so
+// that later messages overtake it.
+func (m *MemNetwork) SetReorder(p float64, delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reorderRate, m.reorderDelay = p, delay
+}
+
+// Partition splits the network: addresses in different groups cannot reach
+// each other. Addresses not listed keep reaching everyone.
+func (m *MemNetwork) Partition(groups ...[]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.groups = make(map[string]int)
+	for i, g := range groups {
+		for _, addr := range g {
+			m.groups[addr] = i + 1
+		}
+	}
+}
+
+// Isolate cuts addr off from every other address until Heal.
+func (m *MemNetwork) Isolate(addr string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.down[addr] = true
+}
+
+// Heal removes all partitions and isolations.
+func (m *MemNetwork) Heal() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.groups = make(map[string]int)
+	m.down = make(map[string]bool)
+}
+
+// Transport returns the transport for the node at addr with the given peer
+// list (which includes addr itself), and registers it on the network.
+func (m *MemNetwork) Transport(addr string, peers []string) *MemTransport {
+	t := &MemTransport{net: m, addr: addr, peers: append([]string(nil), peers...)}
+	m.mu.Lock()
+	m.nodes[addr] = t
+	m.mu.Unlock()
+	return t
+}
+
+// route decides the fate of one request from src to dst.
+type route struct {
+	dst          *MemTransport
+	reqDelay     time.Duration
+	respDelay    time.Duration
+	dropRequest  bool
+	dropResponse bool
+	duplicate    time.Duration // delay of the duplicate; 0 means none
+}
+
+func (m *MemNetwork) route(src, dst string) (route, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.nodes[dst]
+	if t == nil || !m.reachableLocked(src, dst) {
+		return route{}, ErrUnreachable
+	}
+	r := route{
+		dst:          t,
+		reqDelay:     m.latencyLocked(),
+		respDelay:    m.latencyLocked(),
+		dropRequest:  m.chanceLocked(m.dropRate),
+		dropResponse: m.chanceLocked(m.dropRate),
+	}
+	if m.chanceLocked(m.reorderRate) && m.reorderDelay > 0 {
+		r.reqDelay += time.Duration(m.rnd.Int63n(int64(m.reorderDelay) + 1))
+	}
+	if m.chanceLocked(m.dupRate) {
+		r.duplicate = m.latencyLocked() + time.Nanosecond
+	}
+	return r, nil
+}
+
+func (m *MemNetwork) reachableLocked(src, dst string) bool {
+	if m.down[src] || m.down[dst] {
+		return false
+	}
+	gs, gd := m.groups[src], m.groups[dst]
+	return gs == 0 || gd == 0 || gs == gd
+}
+
+func (m *MemNetwork) latencyLocked() time.Duration {
+	if m.maxLatency <= m.minLatency {
+		return m.minLatency
+	}
+	return m.minLatency + time.Duration(m.rnd.Int63n(int64(m.maxLatency-m.minLatency)+1))
+}
+
+func (m *MemNetwork) chanceLocked(p float64) bool {
+	return p > 0 && m.rnd.Float64() < p
+}
+
+// MemTransport is a Transport on a MemNetwork. Addresses are opaque strings,
+// though they must pass NormalizePeerAddr for membership changes to work.
+type MemTransport struct {
+	net  *MemNetwork
+	addr string
+
+	mu      sync.RWMutex
+	peers   []string
+	handler RPCHandler
+	closed  bool
+}
+
+func (t *MemTransport) Start(h RPCHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handler = h
+}
+
+func (t *MemTransport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+}
+
+func (t *MemTransport) Peers() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	out := make([]string, len(t.peers))
+	copy(out, t.peers)
+	return out
+}
+
+func (t *MemTransport) AddPeer(addr string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if containsPeer(t.peers, addr) {
+		return false
+	}
+	t.peers = append(t.peers, addr)
+	return true
+}
+
+func (t *MemTransport) RemovePeer(addr string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, p := range t.peers {
+		if p == addr {
+			t.peers = append(t.peers[:i], t.peers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t *MemTransport) IsSelf(peer string) bool { return peer == t.addr }
+
+func (t *MemTransport) SelfAddr() string { return t.addr }
+
+// receiver returns the handler of a running transport.
+func (t *MemTransport) receiver() RPCHandler {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return nil
+	}
+	return t.handler
+}
+
+// memCall delivers one request from src to peer through the network's
+// faults and returns the response.
+func memCall[Req, Resp any](ctx context.Context, src *MemTransport, peer string, req Req, handle func(RPCHandler, context.Context, Req) Resp) (Resp, error) {
+	var zero Resp
+	if src.receiver() == nil {
+		return zero, ErrUnreachable
+	}
+	r, err := src.net.route(src.addr, peer)
+	if err != nil {
+		return zero, err
+	}
+	if r.duplicate > 0 {
+		go func() {
+			if sleepCtx(context.Background(), r.duplicate) != nil {
+				return
+			}
+			if h := r.dst.receiver(); h != nil && src.net.reachable(src.addr, peer) {
+				handle(h, context.Background(), req)
+			}
+		}()
+	}
+	if err := sleepCtx(ctx, r.reqDelay); err != nil {
+		return zero, err
+	}
+	if r.dropRequest {
+		return zero, ErrDropped
+	}
+	h := r.dst.receiver()
+	if h == nil || !src.net.reachable(src.addr, peer) {
+		return zero, ErrUnreachable
+	}
+	resp := handle(h, ctx, req)
+	if err := sleepCtx(ctx, r.respDelay); err != nil {
+		return zero, err
+	}
+	if r.dropResponse || !src.net.reachable(peer, src.addr) {
+		return zero, ErrDropped
+	}
+	return resp, nil
+}
+
+func (m *MemNetwork) reachable(src, dst string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reachableLocked(src, dst)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *MemTransport) SendAppend(ctx context.Context, peer string, req AppendEntriesReq) (AppendEntriesResp, error) {
+	return memCall(ctx, t, peer, req, func(h RPCHandler, _ context.Context, req AppendEntriesReq) AppendEntriesResp {
+		return h.HandleAppendEntries(req)
+	})
+}
+
+func (t *MemTransport) SendVote(ctx context.Context, peer string, req RequestVoteReq) (RequestVoteResp, error) {
+	return memCall(ctx, t, peer, req, func(h RPCHandler, _ context.Context, req RequestVoteReq) RequestVoteResp {
+		return h.HandleRequestVote(req)
+	})
+}
+
+func (t *MemTransport) SendInstallSnapshot(ctx context.Context, peer string, req InstallSnapshotReq) (InstallSnapshotResp, error) {
+	return memCall(ctx, t, peer, req, func(h RPCHandler, _ context.Context, req InstallSnapshotReq) InstallSnapshotResp {
+		return h.HandleInstallSnapshot(req)
+	})
+}
+
+func (t *MemTransport) SendReadIndex(ctx context.Context, peer string, req ReadIndexReq) (ReadIndexResp, error) {
+	return memCall(ctx, t, peer, req, func(h RPCHandler, ctx context.Context, req ReadIndexReq) ReadIndexResp {
+		return h.HandleReadIndex(ctx, req)
+	})
+}
+
+func (t *MemTransport) SendTimeoutNow(ctx context.Context, peer string, req TimeoutNowReq) (TimeoutNowResp, error) {
+	return memCall(ctx, t, peer, req, func(h RPCHandler, _ context.Context, req TimeoutNowReq) TimeoutNowResp {
+		return h.HandleTimeoutNow(req)
+	})
+}
@@ -1,12 +1,13 @@
 package raft
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
-	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,8 +24,10 @@ type Applier interface {
 }
 
 type SnapshotProvider interface {
-	Snapshot(nodeID string) ([]byte, error)
-	RestoreSnapshot(nodeID string, data []byte) error
+	// Snapshot streams the FSM state to w; RestoreSnapshot replaces the
+	// FSM state with a snapshot read from r.
+	Snapshot(nodeID string, w io.Writer) error
+	RestoreSnapshot(nodeID string, r io.Reader) error
 }
 
 type applyResult struct {
@@ -38,20 +41,32 @@ type commandEntryData struct {
 }
 
 // pendingSnapshot is the in-progress accumulation of a chunked
-// InstallSnapshot transfer.
+// InstallSnapshot transfer. Chunks are spooled to disk.
 type pendingSnapshot struct {
-	lastIncludedIndex uint64
-	lastIncludedTerm  uint64
-	buf               []byte
+	meta SnapshotMeta
+	sink *SnapshotSink
 }
 
 // installSnapshotChunkSize bounds each InstallSnapshot chunk (P2-14).
 const installSnapshotChunkSize = 4 << 20 // 4 MiB
 
-// maxPendingSnapshotBytes caps the in-memory accumulation of a chunked
-// snapshot so a misbehaving leader cannot exhaust the follower's memory.
-// Variable (not const) so tests can shrink it.
-var maxPendingSnapshotBytes = 1 << 30 // 1 GiB
+// MaxAppendBytes bounds the entry data sent in one AppendEntries request,
+// so the request stays well below raftRPCMaxBody once JSON base64-encodes
+// the data. A larger entry is sent on its own. Callers that propose large
+// commands should keep each one within it, see EntrySize.
+const MaxAppendBytes = 512 << 10 // 512 KiB
+
+// DefaultMaxSnapshotSize caps the spooled size of a chunked snapshot so a
+// misbehaving leader cannot fill the follower's disk, see
+// WithMaxSnapshotSize.
+const DefaultMaxSnapshotSize int64 = 1 << 30 // 1 GiB
+
+// WithMaxSnapshotSize sets the most bytes of snapshot data the node spools
+// for one InstallSnapshot transfer. It must exceed the leader's snapshot,
+// or the node can never catch up once the leader compacts its log.
+func WithMaxSnapshotSize(size int64) NodeOption {
+	return func(o *nodeOptions) { o.maxSnapshot = size }
+}
 
 type Node struct {
 	mu sync.Mutex
@@ -60,6 +75,16 @@ type Node struct {
 	role     atomic.Value
 	term     uint64
 	leaderID atomic.Value
+	// leaderAddr is the leader's raft address as announced in its
+	// AppendEntries; followers forward ReadIndex requests to it.
+	leaderAddr atomic.Value
+	// leaderContact is when this follower last accepted a message from the
+	// leader (UnixNano, 0 if none in the current term).
+	leaderContact atomic.Int64
+	// startedAt is when NewNode created the node. leaderContact is not persisted,
+	// so for an election timeout after a restart a follower cannot tell
+	// whether it recently acknowledged a leader.
+	startedAt time.Time
 
 	commitIdx     uint64
 	lastApply     uint64
@@ -76,13 +101,44 @@ type Node struct {
 	// heartbeat round never double-sends to the same peer (P2-15). Guarded
 	// by n.mu.
 	replicating map[string]bool
+	// peerAck records, per peer, when the leader sent the latest
+	// AppendEntries the peer answered in the current term. It backs the
+	// leader lease. Guarded by n.mu.
+	peerAck map[string]time.Time
+	// leaseFence discards acknowledgements of AppendEntries sent before it
+	// from the lease. An aborted transfer sets it an election timeout
+	// ahead, since the target may still win the election it was asked to
+	// start. Guarded by n.mu.
+	leaseFence time.Time
+
+	// proposeCh queues client proposals for the group commit loop, which
+	// appends them in batches of up to maxBatch entries, waiting up to
+	// batchWindow (nanoseconds) for a batch to fill.
+	proposeCh   chan *Proposal
+	batchWindow atomic.Int64
+	maxBatch    atomic.Int64
 
 	// pendingSnapshot accumulates InstallSnapshot chunks before the final
 	// restore. Guarded by n.mu.
 	pendingSnapshot *pendingSnapshot
+	// maxSnapshotBytes caps pendingSnapshot, see WithMaxSnapshotSize.
+	maxSnapshotBytes int64
+
+	// learners holds the peers that replicate the log without voting.
+	// Guarded by n.mu.
+	learners map[string]bool
+	// joint is the configuration being moved through while the cluster is
+	// in C_old,new, nil otherwise. Guarded by n.mu.
+	joint *ConfigChange
+
+	// transferee is the peer leadership is being handed to; the leader
+	// appends nothing while it is set. peerIDs maps peer addresses to the
+	// node IDs they report in AppendEntries responses. Guarded by n.mu.
+	transferee string
+	peerIDs    map[string]string
 
 	storage *Storage
-	trans   *HTTPTransport
+	trans   Transport
 	applier Applier
 
 	hb                time.Duration
@@ -109,9 +165,16 @@ type Node struct {
 	applyErr atomic.Pointer[raftApplyError]
 	// metaDirty marks that meta needs persisting asynchronously.
 	metaDirty atomic.Bool
+	// campaignNow makes the election loop start a leadership-transfer
+	// election without waiting for the election timeout.
+	campaignNow atomic.Bool
 }
 
-func NewNode(id string, addr string, peers []string, storage *Storage, applier Applier, heartbeat, election time.Duration, snapshotEnabled bool, snapshotThreshold uint64, logger *zap.Logger, authToken string) (*Node, error) {
+func NewNode(id string, addr string, peers []string, storage *Storage, applier Applier, heartbeat, election time.Duration, snapshotEnabled bool, snapshotThreshold uint64, logger *zap.Logger, authToken string, opts ...NodeOption) (*Node, error) {
+	var o nodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	n := &Node{
 		id:                id,
 		storage:           storage,
@@ -121,15 +184,23 @@ func NewNode(id string, addr string, peers []string, storage *Storage, applier A
 		snapshotEnabled:   snapshotEnabled,
 		snapshotThreshold: snapshotThreshold,
 		logger:            logger,
+		startedAt:         time.Now(),
+		maxSnapshotBytes:  DefaultMaxSnapshotSize,
 		stopCh:            make(chan struct{}),
 		nextIndex:         make(map[string]uint64),
 		matchIndex:        make(map[string]uint64),
 		applyWaiter:       make(map[uint64]chan applyResult),
 		replicating:       make(map[string]bool),
+		peerAck:           make(map[string]time.Time),
+		learners:          make(map[string]bool),
+		peerIDs:           make(map[string]string),
+		proposeCh:         make(chan *Proposal, proposeQueueSize),
 	}
+	n.SetProposalBatching(DefaultProposalBatchWindow, DefaultMaxProposalBatch)
 	n.role.Store(Follower)
 	metrics.SetRaftRole(n.id, string(Follower))
 	n.leaderID.Store("")
+	n.leaderAddr.Store("")
 
 	meta, err := storage.LoadMeta()
 	if err != nil {
@@ -144,20 +215,27 @@ func NewNode(id string, addr string, peers []string, storage *Storage, applier A
 		if len(meta.Peers) > 0 {
 			peers = meta.Peers
 		}
+		for _, addr := range meta.Learners {
+			n.learners[addr] = true
+		}
+		n.joint = meta.Joint
 	}
 
-	snapshotMeta, snapshotData, err := storage.LoadSnapshot()
+	snapshotMeta, snapshotData, snapshotSize, err := storage.OpenSnapshot()
 	if err != nil {
 		return nil, fmt.Errorf("load snapshot: %w", err)
 	}
 	if snapshotMeta != nil {
 		n.snapshotIndex = snapshotMeta.LastIncludedIndex
 		n.snapshotTerm = snapshotMeta.LastIncludedTerm
-		if snapshotter, ok := applier.(SnapshotProvider); ok && len(snapshotData) > 0 {
-			if err := snapshotter.RestoreSnapshot(id, snapshotData); err != nil {
+		if snapshotter, ok := applier.(SnapshotProvider); ok && snapshotSize > 0 {
+			err := snapshotter.RestoreSnapshot(id, snapshotData)
+			if err != nil {
+				_ = snapshotData.Close()
 				return nil, fmt.Errorf("restore snapshot: %w", err)
 			}
 		}
+		_ = snapshotData.Close()
 	}
 
 	entries, err := storage.LoadEntries()
@@ -176,11 +254,36 @@ func NewNode(id string, addr string, peers []string, storage *Storage, applier A
 		n.commitIdx = n.lastLogIndex
 	}
 
-	trans, err := NewHTTPTransport(addr, peers, authToken, logger)
-	if err != nil {
-		return nil, fmt.Errorf("raft transport: %w", err)
+	if o.trans != nil {
+		// Membership committed before a restart overrides the peers the
+		// transport was built with, as it does for the default transport.
+		if meta != nil && len(meta.Peers) > 0 {
+			for _, p := range o.trans.Peers() {
+				if !containsPeer(meta.Peers, p) {
+					o.trans.RemovePeer(p)
+				}
+			}
+			for _, p := range meta.Peers {
+				o.trans.AddPeer(p)
+			}
+		}
+		n.trans = o.trans
+	} else {
+		trans, err := NewHTTPTransport(addr, peers, authToken, logger)
+		if err != nil {
+			return nil, fmt.Errorf("raft transport: %w", err)
+		}
+		trans.configure(o)
+		n.trans = trans
+	}
+	if o.learner && (meta == nil || len(meta.Peers) == 0) {
+		if self := n.trans.SelfAddr(); self != "" {
+			n.learners[self] = true
+		}
+	}
+	if o.maxSnapshot > 0 {
+		n.maxSnapshotBytes = o.maxSnapshot
 	}
-	n.trans = trans
 	n.trans.Start(n)
 	metrics.SetPeersTotal(len(n.trans.Peers()))
 
@@ -193,9 +296,10 @@ func NewNode(id string, addr string, peers []string, storage *Storage, applier A
 	_ = n.applyCommittedEntries()
 	n.maybeSnapshot()
 
-	n.wg.Add(2)
+	n.wg.Add(3)
 	go n.loop()
 	go n.electionLoop()
+	go n.proposeLoop()
 	return n, nil
 }
 
@@ -205,6 +309,9 @@ func (n *Node) Close() {
 		close(n.stopCh)
 		n.wg.Wait()
 		n.trans.Close()
+		n.mu.Lock()
+		n.dropPendingSnapshotLocked()
+		n.mu.Unlock()
 	})
 }
 
@@ -229,6 +336,9 @@ func (n *Node) Status() map[string]any {
 		"snapshot_index": n.snapshotIndex,
 		"snapshot_term":  n.snapshotTerm,
 		"peers_total":    len(n.trans.Peers()),
+		"learners":       n.learnersLocked(),
+		"learner":        n.selfLearnerLocked(),
+		"joint":          n.joint != nil,
 	}
 }
 
@@ -247,6 +357,7 @@ func (n *Node) loop() {
 				n.mu.Unlock()
 			}
 			if n.Role() == Leader {
+				n.finishJoint()
 				start := time.Now()
 				n.replicateAll()
 				metrics.ObserveAppendEntriesLatency(time.Since(start))
@@ -267,7 +378,7 @@ func (n *Node) electionLoop() {
 			if n.Role() == Leader {
 				continue
 			}
-			if time.Now().UnixNano() > atomic.LoadInt64(&n.electionDeadline) {
+			if n.campaignNow.Load() || time.Now().UnixNano() > atomic.LoadInt64(&n.electionDeadline) {
 				if n.logger != nil {
 					n.mu.Lock()
 					term := n.term
@@ -299,6 +410,17 @@ func (n *Node) resetElectionDeadlineLocked() {
 }
 
 func (n *Node) startElection() {
+	n.campaign(n.campaignNow.Swap(false))
+}
+
+// campaign runs an election. transfer skips the pre-vote and asks voters to
+// ignore leader stickiness: the leader itself sent TimeoutNow.
+func (n *Node) campaign(transfer bool) {
+	if !n.canCampaign() {
+		// Learners and removed members never campaign; keep following.
+		n.resetElectionDeadline()
+		return
+	}
 	n.role.Store(Candidate)
 	metrics.SetRaftRole(n.id, string(Candidate))
 
@@ -318,9 +440,7 @@ func (n *Node) startElection() {
 		LastLogTerm:  lastLogTerm,
 		PreVote:      true,
 	}
-	votes := 1 + n.trans.broadcastVote(preReq)
-	total := len(n.trans.Peers())
-	if votes < total/2+1 {
+	if !transfer && !n.hasQuorum(n.broadcastVote(preReq)) {
 		// A healthy leader is likely present; retry after the timeout.
 		n.resetElectionDeadline()
 		return
@@ -337,21 +457,22 @@ func (n *Node) startElection() {
 	n.mu.Unlock()
 
 	req := RequestVoteReq{
-		Term:         term,
-		CandidateID:  n.id,
-		LastLogIndex: lastLogIndex,
-		LastLogTerm:  lastLogTerm,
+		Term:               term,
+		CandidateID:        n.id,
+		LastLogIndex:       lastLogIndex,
+		LastLogTerm:        lastLogTerm,
+		LeadershipTransfer: transfer,
 	}
 	if n.logger != nil {
 		n.logger.Debug("start election", zap.String("candidate", n.id), zap.Uint64("term", term), zap.Int("peers", len(n.trans.Peers())))
 	}
-	votes = 1 + n.trans.broadcastVote(req)
-	total = len(n.trans.Peers())
+	granted := n.broadcastVote(req)
+	won := n.hasQuorum(granted)
 	if n.logger != nil {
-		n.logger.Debug("vote result", zap.String("candidate", n.id), zap.Int("votes", votes), zap.Int("total", total), zap.Int("majority", total/2+1))
+		n.logger.Debug("vote result", zap.String("candidate", n.id), zap.Int("votes", 1+len(granted)), zap.Int("total", len(n.voters())), zap.Bool("won", won))
 	}
 
-	if votes >= (total/2 + 1) {
+	if won {
 		n.mu.Lock()
 		if n.term == term {
 			n.role.Store(Leader)
@@ -368,7 +489,7 @@ func (n *Node) startElection() {
 			} else {
 				n.matchIndex[n.id] = entry.Index
 				n.nextIndex[n.id] = entry.Index + 1
-				if n.majorityLocked() == 1 {
+				if n.selfQuorumLocked() {
 					n.commitIdx = entry.Index
 					metrics.SetRaftCommitIndex(n.commitIdx)
 					n.flushMeta()
@@ -387,65 +508,38 @@ func (n *Node) startElection() {
 	n.resetElectionDeadline()
 }
 
-func (n *Node) Submit(cmd interface{}) (interface{}, error) {
-	if n.Role() != Leader {
-		return nil, ErrNotLeader{Leader: n.leaderID.Load().(string)}
-	}
+// Submit replicates cmd and returns the state machine's response. It gives
+// up when ctx is done, or after DefaultSubmitTimeout if ctx has no
+// deadline. ErrCommit means the command never reached the log;
+// ErrResultUnknown means it did but its outcome is not known, see
+// Proposal.Wait.
+func (n *Node) Submit(ctx context.Context, cmd interface{}) (interface{}, error) {
+	resp, _, err := n.SubmitWithIndex(ctx, cmd)
+	return resp, err
+}
 
-	entry, err := n.newCommandEntry(cmd)
+// SubmitWithIndex is Submit that also returns the log index the command
+// committed at. Reads that wait for this index (see WaitApplied) observe
+// the command.
+func (n *Node) SubmitWithIndex(ctx context.Context, cmd interface{}) (interface{}, uint64, error) {
+	p, err := n.Propose(ctx, cmd)
 	if err != nil {
-		return nil, err
-	}
-
-	waiter := make(chan applyResult, 1)
-
-	n.mu.Lock()
-	if n.Role() != Leader {
-		n.mu.Unlock()
-		return nil, ErrNotLeader{Leader: n.leaderID.Load().(string)}
-	}
-	entry.Index = n.lastLogIndex + 1
-	entry.Term = n.term
-	if err := n.appendEntryLocked(entry); err != nil {
-		n.mu.Unlock()
-		return nil, err
-	}
-	n.applyWaiter[entry.Index] = waiter
-	n.matchIndex[n.id] = entry.Index
-	n.nextIndex[n.id] = entry.Index + 1
-	if n.majorityLocked() == 1 {
-		n.commitIdx = entry.Index
-		metrics.SetRaftCommitIndex(n.commitIdx)
-		n.flushMeta()
-	}
-	isSingle := n.majorityLocked() == 1
-	n.mu.Unlock()
-
-	if isSingle {
-		if err := n.applyCommittedEntries(); err != nil {
-			return nil, err
-		}
-		n.maybeSnapshot()
-	}
-
-	if err := n.replicateUntilCommitted(entry.Index); err != nil {
-		n.mu.Lock()
-		delete(n.applyWaiter, entry.Index)
-		n.mu.Unlock()
-		return nil, err
+		return nil, 0, err
 	}
+	return p.Wait(ctx)
+}
 
-	timer := time.NewTimer(2 * time.Second)
-	select {
-	case result := <-waiter:
-		timer.Stop()
-		return result.resp, result.err
-	case <-timer.C:
-		return nil, ErrCommit{}
+func (n *Node) SubmitPeerChange(addr string, remove bool) error {
+	entryType := EntryTypeAddPeer
+	if remove {
+		entryType = EntryTypeRemovePeer
 	}
+	return n.submitMembershipChange(entryType, addr, false)
 }
 
-func (n *Node) SubmitPeerChange(addr string, remove bool) error {
+// submitMembershipChange appends a membership entry of entryType for addr
+// and waits for it to commit. learner applies to EntryTypeAddPeer.
+func (n *Node) submitMembershipChange(entryType EntryType, addr string, learner bool) error {
 	if n.Role() != Leader {
 		return ErrNotLeader{Leader: n.leaderID.Load().(string)}
 	}
@@ -458,71 +552,94 @@ func (n *Node) SubmitPeerChange(addr string, remove bool) error {
 		n.mu.Lock()
 		defer n.mu.Unlock()
 		// Single-member-change model: reject a new change while an earlier
-		// peer-change entry is still uncommitted (P1-8).
+		// peer-change entry is still unapplied or a joint configuration is
+		// in progress (P1-8).
 		if n.peerChangeInFlightLocked() {
 			return 0, false, ErrPeerChangeInFlight{}
 		}
 		exists := containsPeer(n.trans.Peers(), normalizedAddr)
-		if !remove && exists {
-			return 0, false, ErrPeerExists{}
-		}
-		if remove && !exists {
-			return 0, false, ErrPeerNotFound{}
-		}
-		if remove && n.trans.isSelf(normalizedAddr) {
-			return 0, false, ErrInvalidPeerChange{}
-		}
-		payload, err := json.Marshal(PeerChange{Addr: normalizedAddr})
-		if err != nil {
-			return 0, false, err
-		}
-		entryType := EntryTypeAddPeer
-		if remove {
-			entryType = EntryTypeRemovePeer
-		}
-		entry := LogEntry{
-			Index: n.lastLogIndex + 1,
-			Term:  n.term,
-			Type:  entryType,
-			Data:  payload,
-		}
-		if err := n.appendEntryLocked(entry); err != nil {
-			return 0, false, err
-		}
-		n.matchIndex[n.id] = entry.Index
-		n.nextIndex[n.id] = entry.Index + 1
-		// Single-node cluster: commit locally (previously nothing ever
-		// advanced commitIdx for a peer change, so it always timed out).
-		if n.majorityLocked() == 1 {
-			n.commitIdx = entry.Index
-			metrics.SetRaftCommitIndex(n.commitIdx)
-			n.flushMeta()
+		switch entryType {
+		case EntryTypeAddPeer:
+			if exists {
+				return 0, false, ErrPeerExists{}
+			}
+		case EntryTypeRemovePeer:
+			if !exists {
+				return 0, false, ErrPeerNotFound{}
+			}
+			if n.trans.IsSelf(normalizedAddr) {
+				return 0, false, ErrInvalidPeerChange{}
+			}
+		case EntryTypePromotePeer:
+			if !exists {
+				return 0, false, ErrPeerNotFound{}
+			}
+			if !n.learners[normalizedAddr] {
+				return 0, false, ErrNotLearner{}
+			}
+			if err := n.learnerCaughtUpLocked(normalizedAddr); err != nil {
+				return 0, false, err
+			}
 		}
-		return entry.Index, n.majorityLocked() == 1, nil
+		return n.appendMembershipLocked(entryType, PeerChange{Addr: normalizedAddr, Learner: learner})
 	}()
 	if err != nil {
 		return err
 	}
-	if isSingle {
+	return n.commitMembership(entryIndex, isSingle)
+}
+
+// appendMembershipLocked appends a membership entry of entryType carrying
+// payload and reports whether this node alone commits it.
+func (n *Node) appendMembershipLocked(entryType EntryType, payload any) (uint64, bool, error) {
+	if n.transferee != "" {
+		return 0, false, ErrTransferInProgress{}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, false, err
+	}
+	entry := LogEntry{
+		Index: n.lastLogIndex + 1,
+		Term:  n.term,
+		Type:  entryType,
+		Data:  data,
+	}
+	if err := n.appendEntryLocked(entry); err != nil {
+		return 0, false, err
+	}
+	n.matchIndex[n.id] = entry.Index
+	n.nextIndex[n.id] = entry.Index + 1
+	// Single-node cluster: commit locally (previously nothing ever
+	// advanced commitIdx for a peer change, so it always timed out).
+	single := n.selfQuorumLocked()
+	if single {
+		n.commitIdx = entry.Index
+		metrics.SetRaftCommitIndex(n.commitIdx)
+		n.flushMeta()
+	}
+	return entry.Index, single, nil
+}
+
+// commitMembership waits for the membership entry at index to commit, or
+// applies it right away when this node alone committed it.
+func (n *Node) commitMembership(index uint64, single bool) error {
+	if single {
 		if err := n.applyCommittedEntries(); err != nil {
 			return err
 		}
 		n.maybeSnapshot()
 		return nil
 	}
-	return n.replicateUntilCommitted(entryIndex)
+	return n.replicateUntilCommitted(index)
 }
 
-// peerChangeInFlightLocked reports whether an uncommitted peer-change entry
-// exists in the log. Combined with the single-member-change model this keeps
-// membership transitions safe: at most one change may be in flight at a time.
+// peerChangeInFlightLocked reports whether a membership entry has not been
+// applied yet, or the cluster is in a joint configuration. Membership takes
+// effect when its entry is applied, so at most one change may be in flight
+// at a time for transitions to stay safe.
 func (n *Node) peerChangeInFlightLocked() bool {
-	for idx := n.commitIdx + 1; idx <= n.lastLogIndex; idx++ {
-		if e, ok := n.entryAtLocked(idx); ok && (e.Type == EntryTypeAddPeer || e.Type == EntryTypeRemovePeer) {
-			return true
-		}
-	}
-	return false
+	return n.joint != nil || n.membershipPendingLocked()
 }
 
 // onAppendEntries handles an AppendEntries RPC. Lock acquisition is isolated
@@ -538,6 +655,7 @@ func (n *Node) onAppendEntries(req AppendEntriesReq) AppendEntriesResp {
 		_ = n.applyCommittedEntries()
 		n.maybeSnapshot()
 	}
+	resp.NodeID = n.id
 	return resp
 }
 
@@ -560,6 +678,8 @@ func (n *Node) appendEntriesLocked(req AppendEntriesReq) (AppendEntriesResp, boo
 	}
 
 	n.leaderID.Store(req.LeaderID)
+	n.leaderAddr.Store(req.LeaderAddr)
+	n.leaderContact.Store(time.Now().UnixNano())
 	prev := n.Role()
 	if prev != Follower {
 		metrics.IncRaftLeaderChanges()
@@ -650,6 +770,15 @@ func (n *Node) onRequestVote(req RequestVoteReq) RequestVoteResp {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
+	// Leader stickiness: a follower that heard from its leader within the
+	// minimum election timeout, or may have before it restarted, refuses
+	// to help elect another one, without adopting the candidate's term.
+	// Leader leases rely on this; a leader gives up its lease before it
+	// sends TimeoutNow, so a transfer election is exempt.
+	if n.Role() == Follower && n.leaderMayHoldLease() && !req.LeadershipTransfer {
+		return RequestVoteResp{Term: n.term, VoteGranted: false}
+	}
+
 	if req.PreVote {
 		// Pre-vote (P2-12): check term and log freshness without mutating
 		// any state, so a partitioned node stops inflating its term while a
@@ -730,7 +859,7 @@ func (n *Node) replicateAllWithDeadline(deadline time.Time) {
 	sem := make(chan struct{}, maxConcurrentReplicas)
 	var wg sync.WaitGroup
 	for _, peer := range peers {
-		if n.trans.isSelf(peer) {
+		if n.trans.IsSelf(peer) {
 			continue
 		}
 		if time.Now().After(deadline) {
@@ -790,6 +919,7 @@ func (n *Node) replicatePeer(peer string, deadline time.Time) {
 			PrevLogIndex: prevIndex,
 			PrevLogTerm:  n.termAtLocked(prevIndex),
 			CommitIdx:    n.commitIdx,
+			LeaderAddr:   n.trans.SelfAddr(),
 		}
 		if next <= n.lastLogIndex {
 			offset, ok := n.offsetOfLocked(next)
@@ -797,7 +927,7 @@ func (n *Node) replicatePeer(peer string, deadline time.Time) {
 				n.mu.Unlock()
 				return
 			}
-			req.Entries = append([]LogEntry(nil), n.logs[offset:]...)
+			req.Entries = append([]LogEntry(nil), appendBatch(n.logs[offset:])...)
 		}
 		n.mu.Unlock()
 
@@ -809,7 +939,8 @@ func (n *Node) replicatePeer(peer string, deadline time.Time) {
 			remaining = time.Second
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), remaining)
-		resp, err := n.trans.sendAppend(ctx, peer, req)
+		sent := time.Now()
+		resp, err := n.trans.SendAppend(ctx, peer, req)
 		cancel()
 		if err != nil {
 			return
@@ -827,6 +958,10 @@ func (n *Node) replicatePeer(peer string, deadline time.Time) {
 			n.mu.Unlock()
 			return
 		}
+		n.recordAckLocked(peer, sent)
+		if resp.NodeID != "" {
+			n.peerIDs[peer] = resp.NodeID
+		}
 
 		if resp.Success {
 			match := resp.MatchIndex
@@ -868,6 +1003,19 @@ func (n *Node) replicatePeer(peer string, deadline time.Time) {
 	}
 }
 
+// appendBatch returns the longest prefix of entries whose data fits in
+// MaxAppendBytes, and at least the first entry.
+func appendBatch(entries []LogEntry) []LogEntry {
+	size := 0
+	for i, entry := range entries {
+		size += len(entry.Data)
+		if i > 0 && size > MaxAppendBytes {
+			return entries[:i]
+		}
+	}
+	return entries
+}
+
 func (n *Node) maybeSnapshot() {
 	if !n.snapshotEnabled {
 		return
@@ -875,33 +1023,46 @@ func (n *Node) maybeSnapshot() {
 
 	n.mu.Lock()
 	shouldSnapshot := n.snapshotThreshold > 0 && n.lastApply > n.snapshotIndex && (n.lastApply-n.snapshotIndex) >= n.snapshotThreshold
-	snapshotIndex := n.lastApply
-	snapshotTerm := n.termAtLocked(snapshotIndex)
 	n.mu.Unlock()
 	if !shouldSnapshot {
 		return
 	}
-	_ = n.createSnapshot(snapshotIndex, snapshotTerm)
+	_ = n.createSnapshot()
 }
 
-func (n *Node) createSnapshot(index, term uint64) error {
+// createSnapshot snapshots the state machine at the applied index.
+func (n *Node) createSnapshot() error {
 	snapshotter, ok := n.applier.(SnapshotProvider)
 	if !ok {
 		return nil
 	}
 	// Freeze the FSM: no apply can interleave with snapshot capture, so the
-	// snapshot data is guaranteed to cover exactly up to index.
+	// snapshot data and membership cover exactly up to lastApply.
 	n.applyMu.Lock()
 	defer n.applyMu.Unlock()
-	data, err := snapshotter.Snapshot(n.id)
+	n.mu.Lock()
+	meta := SnapshotMeta{
+		LastIncludedIndex: n.lastApply,
+		LastIncludedTerm:  n.termAtLocked(n.lastApply),
+		Peers:             n.trans.Peers(),
+		Learners:          n.learnersLocked(),
+		Joint:             n.joint,
+	}
+	stale := meta.LastIncludedIndex <= n.snapshotIndex
+	n.mu.Unlock()
+	if stale {
+		return nil
+	}
+	index, term := meta.LastIncludedIndex, meta.LastIncludedTerm
+	sink, err := n.storage.CreateSnapshot(meta)
 	if err != nil {
 		return err
 	}
-	meta := SnapshotMeta{
-		LastIncludedIndex: index,
-		LastIncludedTerm:  term,
+	if err := snapshotter.Snapshot(n.id, sink); err != nil {
+		sink.Abort()
+		return err
 	}
-	if err := n.storage.SaveSnapshot(meta, data); err != nil {
+	if err := sink.Commit(); err != nil {
 		return err
 	}
 
@@ -934,10 +1095,11 @@ func (n *Node) createSnapshot(index, term uint64) error {
 }
 
 func (n *Node) installSnapshotToPeer(peer string, deadline time.Time) {
-	meta, data, err := n.storage.LoadSnapshot()
+	meta, data, _, err := n.storage.OpenSnapshot()
 	if err != nil || meta == nil {
 		return
 	}
+	defer data.Close()
 	n.mu.Lock()
 	term := n.term
 	leaderID := n.id
@@ -946,7 +1108,8 @@ func (n *Node) installSnapshotToPeer(peer string, deadline time.Time) {
 	// Stream the snapshot in bounded chunks (P2-14). Each chunk has its own
 	// timeout; if any chunk fails the follower keeps its partial state and
 	// the next round retries from offset 0.
-	for offset := 0; offset < len(data); offset += installSnapshotChunkSize {
+	buf := make([]byte, installSnapshotChunkSize)
+	for offset, done := 0, false; !done; {
 		remaining := time.Until(deadline)
 		if remaining <= 0 {
 			return
@@ -954,19 +1117,25 @@ func (n *Node) installSnapshotToPeer(peer string, deadline time.Time) {
 		if remaining > time.Second {
 			remaining = time.Second
 		}
-		end := offset + installSnapshotChunkSize
-		if end > len(data) {
-			end = len(data)
+		read, err := io.ReadFull(data, buf)
+		switch {
+		case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+			done = true
+		case err != nil:
+			return
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), remaining)
-		resp, err := n.trans.sendInstallSnapshot(ctx, peer, InstallSnapshotReq{
+		resp, err := n.trans.SendInstallSnapshot(ctx, peer, InstallSnapshotReq{
 			Term:              term,
 			LeaderID:          leaderID,
 			LastIncludedIndex: meta.LastIncludedIndex,
 			LastIncludedTerm:  meta.LastIncludedTerm,
-			Data:              data[offset:end],
+			Data:              buf[:read],
 			Offset:            uint64(offset),
-			Done:              end == len(data),
+			Done:              done,
+			Peers:             meta.Peers,
+			Learners:          meta.Learners,
+			Joint:             meta.Joint,
 		})
 		cancel()
 		if err != nil {
@@ -982,6 +1151,7 @@ func (n *Node) installSnapshotToPeer(peer string, deadline time.Time) {
 		if !resp.Success {
 			return
 		}
+		offset += read
 	}
 
 	n.mu.Lock()
@@ -993,129 +1163,167 @@ func (n *Node) installSnapshotToPeer(peer string, deadline time.Time) {
 }
 
 // onInstallSnapshot handles a (possibly chunked) InstallSnapshot RPC. Lock
-// acquisition is isolated (panic-safe); chunks accumulate in order under n.mu
-// and, once Done, the FSM restore runs under applyMu so it can never
-// interleave with a concurrent apply. A snapshot older than what was already
-// applied is rejected.
+// acquisition is isolated (panic-safe); chunks are checked in order under
+// n.mu but written to disk without it, and, once Done, the FSM restore runs
+// under applyMu so it can never interleave with a concurrent apply. A
+// snapshot older than what was already applied is rejected.
 func (n *Node) onInstallSnapshot(req InstallSnapshotReq) InstallSnapshotResp {
-	// Phase 1: term/leader/role bookkeeping + chunk accumulation.
-	var snap *pendingSnapshot
-	resp, proceed := func() (InstallSnapshotResp, bool) {
+	// Phase 1: term/leader/role bookkeeping + chunk validation. The pending
+	// snapshot is detached while its chunk is written, so a concurrent chunk
+	// finds none and fails (the leader then retries from offset 0).
+	snap, resp, ok := func() (*pendingSnapshot, InstallSnapshotResp, bool) {
 		n.mu.Lock()
 		defer n.mu.Unlock()
 		if req.Term < n.term {
-			return InstallSnapshotResp{Term: n.term, Success: false}, false
+			return nil, InstallSnapshotResp{Term: n.term, Success: false}, false
 		}
 		if req.Term > n.term {
 			n.stepDownLocked(req.Term)
 		}
 		n.leaderID.Store(req.LeaderID)
+		n.leaderContact.Store(time.Now().UnixNano())
 		n.role.Store(Follower)
 		metrics.SetRaftRole(n.id, string(Follower))
 		n.resetElectionDeadline()
 
-		if req.Offset == 0 || n.pendingSnapshot == nil || n.pendingSnapshot.lastIncludedIndex != req.LastIncludedIndex {
+		if req.Offset == 0 || n.pendingSnapshot == nil || n.pendingSnapshot.meta.LastIncludedIndex != req.LastIncludedIndex {
 			// New transfer (or leader restarting from offset 0).
-			n.pendingSnapshot = &pendingSnapshot{
-				lastIncludedIndex: req.LastIncludedIndex,
-				lastIncludedTerm:  req.LastIncludedTerm,
+			n.dropPendingSnapshotLocked()
+			meta := SnapshotMeta{
+				LastIncludedIndex: req.LastIncludedIndex,
+				LastIncludedTerm:  req.LastIncludedTerm,
+				Peers:             req.Peers,
+				Learners:          req.Learners,
+				Joint:             req.Joint,
+			}
+			sink, err := n.storage.receiveSnapshot(meta)
+			if err != nil {
+				return nil, InstallSnapshotResp{Term: n.term, Success: false}, false
 			}
+			n.pendingSnapshot = &pendingSnapshot{meta: meta, sink: sink}
 		}
-		if req.Offset != uint64(len(n.pendingSnapshot.buf)) {
+		if req.Offset != uint64(n.pendingSnapshot.sink.Size()) {
 			// Out-of-order, duplicate or corrupted chunk: reset so the leader
 			// retries from offset 0.
-			n.pendingSnapshot = nil
-			return InstallSnapshotResp{Term: n.term, Success: false}, false
+			n.dropPendingSnapshotLocked()
+			return nil, InstallSnapshotResp{Term: n.term, Success: false}, false
 		}
-		if uint64(len(n.pendingSnapshot.buf))+uint64(len(req.Data)) > uint64(maxPendingSnapshotBytes) {
+		if n.pendingSnapshot.sink.Size()+int64(len(req.Data)) > n.maxSnapshotBytes {
 			// The accumulated snapshot exceeds the cap: drop it and fail so
-			// the leader retries from offset 0 (and never OOMs this node).
-			n.pendingSnapshot = nil
-			return InstallSnapshotResp{Term: n.term, Success: false}, false
+			// the leader retries from offset 0.
+			n.dropPendingSnapshotLocked()
+			return nil, InstallSnapshotResp{Term: n.term, Success: false}, false
 		}
-		n.pendingSnapshot.buf = append(n.pendingSnapshot.buf, req.Data...)
-		if !req.Done {
-			return InstallSnapshotResp{Term: n.term, Success: true}, false
-		}
-		snap = n.pendingSnapshot
+		snap := n.pendingSnapshot
 		n.pendingSnapshot = nil
-		return InstallSnapshotResp{}, true
+		return snap, InstallSnapshotResp{Term: n.term}, true
 	}()
-	if !proceed {
+	if !ok {
 		return resp
 	}
+	if _, err := snap.sink.Write(req.Data); err != nil {
+		snap.sink.Abort()
+		return InstallSnapshotResp{Term: resp.Term, Success: false}
+	}
+	if !req.Done {
+		// Re-attach the snapshot unless a newer transfer started or the
+		// node closed while the chunk was being written.
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		select {
+		case <-n.stopCh:
+			snap.sink.Abort()
+			return InstallSnapshotResp{Term: n.term, Success: false}
+		default:
+		}
+		if n.pendingSnapshot != nil || n.term != resp.Term {
+			snap.sink.Abort()
+			return InstallSnapshotResp{Term: n.term, Success: false}
+		}
+		n.pendingSnapshot = snap
+		return InstallSnapshotResp{Term: n.term, Success: true}
+	}
 
 	// Phase 2: restore the FSM under the apply barrier.
 	n.applyMu.Lock()
 	defer n.applyMu.Unlock()
 	snapshotter, ok := n.applier.(SnapshotProvider)
 	if !ok {
+		snap.sink.Abort()
 		return InstallSnapshotResp{Term: req.Term, Success: false}
 	}
 	n.mu.Lock()
-	stale := snap.lastIncludedIndex < n.lastApply
+	stale := snap.meta.LastIncludedIndex < n.lastApply
 	n.mu.Unlock()
 	if stale {
 		// Defensive: a snapshot behind the already-applied point would wipe
 		// newer state; reject it and let the leader retry with its latest.
+		snap.sink.Abort()
 		return InstallSnapshotResp{Term: req.Term, Success: false}
 	}
-	if err := snapshotter.RestoreSnapshot(n.id, snap.buf); err != nil {
+	data, err := snap.sink.Reader()
+	if err != nil {
+		snap.sink.Abort()
+		return InstallSnapshotResp{Term: req.Term, Success: false}
+	}
+	err = snapshotter.RestoreSnapshot(n.id, data)
+	_ = data.Close()
+	if err != nil {
+		snap.sink.Abort()
 		return InstallSnapshotResp{Term: req.Term, Success: false}
 	}
 	// A successful restore repairs any fatal apply error.
 	n.applyErr.Store(nil)
-	if err := n.storage.SaveSnapshot(SnapshotMeta{
-		LastIncludedIndex: snap.lastIncludedIndex,
-		LastIncludedTerm:  snap.lastIncludedTerm,
-	}, snap.buf); err != nil {
+	if err := snap.sink.Commit(); err != nil {
 		return InstallSnapshotResp{Term: req.Term, Success: false}
 	}
 
 	// Phase 3: update state under n.mu.
+	n.restoreMembership(snap.meta)
 	n.mu.Lock()
-	n.snapshotIndex = snap.lastIncludedIndex
-	n.snapshotTerm = snap.lastIncludedTerm
+	n.snapshotIndex = snap.meta.LastIncludedIndex
+	n.snapshotTerm = snap.meta.LastIncludedTerm
 	filtered := make([]LogEntry, 0, len(n.logs))
 	for _, entry := range n.logs {
-		if entry.Index > snap.lastIncludedIndex {
+		if entry.Index > snap.meta.LastIncludedIndex {
 			filtered = append(filtered, entry)
 		}
 	}
 	n.logs = filtered
-	if n.commitIdx < snap.lastIncludedIndex {
-		n.commitIdx = snap.lastIncludedIndex
+	if n.commitIdx < snap.meta.LastIncludedIndex {
+		n.commitIdx = snap.meta.LastIncludedIndex
 	}
-	if n.lastApply < snap.lastIncludedIndex {
-		n.lastApply = snap.lastIncludedIndex
+	if n.lastApply < snap.meta.LastIncludedIndex {
+		n.lastApply = snap.meta.LastIncludedIndex
 	}
 	n.recomputeLastLogLocked()
 	n.flushMeta()
 	n.mu.Unlock()
 
-	_ = n.storage.CompactLog(snap.lastIncludedIndex)
+	_ = n.storage.CompactLog(snap.meta.LastIncludedIndex)
 	return InstallSnapshotResp{Term: req.Term, Success: true}
 }
 
+// dropPendingSnapshotLocked discards any partially received snapshot.
+// Caller must hold n.mu.
+func (n *Node) dropPendingSnapshotLocked() {
+	if n.pendingSnapshot != nil {
+		n.pendingSnapshot.sink.Abort()
+		n.pendingSnapshot = nil
+	}
+}
+
 // advanceCommitLocked advances commitIdx to the highest index replicated by
 // a majority and belonging to the current term (Raft §5.4.2). It derives the
 // candidate from the majority-th largest match index instead of scanning the
 // whole log on every heartbeat (P2-15).
 func (n *Node) advanceCommitLocked() bool {
-	peers := n.trans.Peers()
-	majority := len(peers)/2 + 1
-	matches := make([]uint64, 0, len(peers))
-	matches = append(matches, n.lastLogIndex) // self
-	for peer, matched := range n.matchIndex {
-		if peer != n.id {
-			matches = append(matches, matched)
-		}
-	}
-	if len(matches) < majority {
+	cand, ok := quorumValueLocked(n, n.lastLogIndex, func(peer string) uint64 {
+		return n.matchIndex[peer]
+	}, cmp.Compare[uint64])
+	if !ok {
 		return false
 	}
-	sort.Slice(matches, func(i, j int) bool { return matches[i] > matches[j] })
-	cand := matches[majority-1]
 	if cand > n.lastLogIndex {
 		cand = n.lastLogIndex
 	}
@@ -1168,7 +1376,7 @@ func (n *Node) applyCommittedEntries() error {
 		resp, err := n.applyEntry(entry)
 
 		n.mu.Lock()
-		if err != nil {
+		if err != nil && !errors.As(err, &command.RejectedError{}) {
 			n.mu.Unlock()
 			n.failApply(err)
 			if waiter != nil {
@@ -1198,11 +1406,14 @@ func (n *Node) applyEntry(entry LogEntry) (interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
+		if ic, ok := cmd.(command.Indexed); ok {
+			ic.SetIndex(entry.Index)
+		}
 		return n.applier.Apply(cmd)
-	case EntryTypeAddPeer:
-		return nil, n.applyPeerChange(entry, false)
-	case EntryTypeRemovePeer:
-		return nil, n.applyPeerChange(entry, true)
+	case EntryTypeAddPeer, EntryTypeRemovePeer, EntryTypePromotePeer:
+		return nil, n.applyPeerChange(entry)
+	case EntryTypeJointConfig, EntryTypeFinalConfig:
+		return nil, n.applyConfigChange(entry)
 	case EntryTypeNoop:
 		return nil, nil
 	default:
@@ -1210,7 +1421,7 @@ func (n *Node) applyEntry(entry LogEntry) (interface{}, error) {
 	}
 }
 
-func (n *Node) applyPeerChange(entry LogEntry, remove bool) error {
+func (n *Node) applyPeerChange(entry LogEntry) error {
 	var change PeerChange
 	if err := json.Unmarshal(entry.Data, &change); err != nil {
 		return err
@@ -1220,9 +1431,10 @@ func (n *Node) applyPeerChange(entry LogEntry, remove bool) error {
 	}
 
 	var updated bool
-	if remove {
+	switch entry.Type {
+	case EntryTypeRemovePeer:
 		updated = n.trans.RemovePeer(change.Addr)
-	} else {
+	case EntryTypeAddPeer:
 		updated = n.trans.AddPeer(change.Addr)
 	}
 	if updated {
@@ -1230,18 +1442,36 @@ func (n *Node) applyPeerChange(entry LogEntry, remove bool) error {
 	}
 
 	n.mu.Lock()
-	if remove {
+	switch entry.Type {
+	case EntryTypeRemovePeer:
 		delete(n.nextIndex, change.Addr)
 		delete(n.matchIndex, change.Addr)
-	} else {
-		n.nextIndex[change.Addr] = n.lastLogIndex + 1
+		delete(n.learners, change.Addr)
+	case EntryTypeAddPeer:
+		if updated {
+			n.nextIndex[change.Addr] = n.lastLogIndex + 1
+		}
+		if change.Learner {
+			n.learners[change.Addr] = true
+		}
+	case EntryTypePromotePeer:
+		delete(n.learners, change.Addr)
 	}
 	n.flushMeta()
 	n.mu.Unlock()
 	return nil
 }
 
-func (n *Node) newCommandEntry(cmd interface{}) (LogEntry, error) {
+// EntrySize returns the size of the log entry data cmd is proposed as.
+func EntrySize(cmd interface{}) (int, error) {
+	entry, err := newCommandEntry(cmd)
+	if err != nil {
+		return 0, err
+	}
+	return len(entry.Data), nil
+}
+
+func newCommandEntry(cmd interface{}) (LogEntry, error) {
 	kind, payload, err := command.Encode(cmd)
 	if err != nil {
 		return LogEntry{}, err
@@ -1303,6 +1533,7 @@ func (n *Node) termAtLocked(index uint64) uint64 {
 }
 
 func (n *Node) resetLeaderProgressLocked() {
+	clear(n.peerAck)
 	peers := n.trans.Peers()
 	for _, peer := range peers {
 		n.nextIndex[peer] = n.lastLogIndex + 1
@@ -1312,16 +1543,14 @@ func (n *Node) resetLeaderProgressLocked() {
 	n.nextIndex[n.id] = n.lastLogIndex + 1
 }
 
-func (n *Node) majorityLocked() int {
-	return len(n.trans.Peers())/2 + 1
-}
-
 func (n *Node) metaLocked() *Meta {
 	return &Meta{
 		CurrentTerm:   n.term,
 		VotedFor:      n.votedFor,
 		CommitIndex:   n.commitIdx,
 		Peers:         n.trans.Peers(),
+		Learners:      n.learnersLocked(),
+		Joint:         n.joint,
 		SnapshotIndex: n.snapshotIndex,
 		SnapshotTerm:  n.snapshotTerm,
 	}
@@ -1332,6 +1561,8 @@ func (n *Node) stepDownLocked(term uint64) {
 	n.votedFor = ""
 	n.role.Store(Follower)
 	n.leaderID.Store("")
+	n.leaderAddr.Store("")
+	n.leaderContact.Store(0)
 	metrics.SetRaftRole(n.id, string(Follower))
 	// Notify and clean up any pending command waiters so they don't leak
 	for idx, w := range n.applyWaiter {
@@ -1351,42 +1582,75 @@ func (n *Node) stepDownLocked(term uint64) {
 // commit index, sends a no-op heartbeat to confirm it is still the leader,
 // and returns the commit index when a majority has acknowledged.
 func (n *Node) ReadIndex(ctx context.Context) (uint64, error) {
-	if n.Role() != Leader {
-		return 0, ErrNotLeader{Leader: n.leaderID.Load().(string)}
+	idx, err := n.confirmReadIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Wait until the state machine has applied up to idx so the read cannot
+	// miss committed-but-not-yet-applied entries (linearizability).
+	if err := n.waitApplied(ctx, idx, true); err != nil {
+		return 0, err
+	}
+	return idx, nil
+}
+
+// confirmReadIndex records the leader's commit index and confirms with a
+// quorum heartbeat that this node is still the leader. A new leader first
+// waits for an entry of its own term (the no-op appended on election) to
+// commit; until then its commit index may trail entries committed by the
+// previous leader.
+func (n *Node) confirmReadIndex(ctx context.Context) (uint64, error) {
+	var idx uint64
+	for {
+		if n.Role() != Leader {
+			return 0, ErrNotLeader{Leader: n.LeaderID()}
+		}
+		n.mu.Lock()
+		idx = n.commitIdx
+		current := n.termAtLocked(idx) == n.term
+		n.mu.Unlock()
+		if current {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
 	}
-	// Record the current commit index.
-	n.mu.Lock()
-	idx := n.commitIdx
-	n.mu.Unlock()
 	// Perform a quorum heartbeat to confirm leadership.
 	if err := n.heartbeatRound(ctx); err != nil {
 		return 0, err
 	}
 	// Re-check role after the heartbeat round.
 	if n.Role() != Leader {
-		return 0, ErrNotLeader{Leader: n.leaderID.Load().(string)}
+		return 0, ErrNotLeader{Leader: n.LeaderID()}
 	}
-	// Wait until the state machine has applied up to idx so the read cannot
-	// miss committed-but-not-yet-applied entries (linearizability).
+	return idx, nil
+}
+
+// waitApplied waits, for up to a second, until the state machine has
+// applied idx. With leaderOnly it fails as soon as the node loses
+// leadership.
+func (n *Node) waitApplied(ctx context.Context, idx uint64, leaderOnly bool) error {
 	deadline := time.Now().Add(time.Second)
 	for {
 		n.mu.Lock()
 		applied := n.lastApply
-		isLeader := n.Role() == Leader
 		n.mu.Unlock()
-		if !isLeader {
-			return 0, ErrNotLeader{Leader: n.LeaderID()}
+		if leaderOnly && n.Role() != Leader {
+			return ErrNotLeader{Leader: n.LeaderID()}
 		}
 		if applied >= idx {
-			return idx, nil
+			return nil
 		}
 		select {
 		case <-ctx.Done():
-			return 0, ctx.Err()
+			return ctx.Err()
 		case <-time.After(10 * time.Millisecond):
 		}
 		if time.Now().After(deadline) {
-			return 0, fmt.Errorf("read index apply lag: applied=%d want=%d", applied, idx)
+			return fmt.Errorf("read index apply lag: applied=%d want=%d", applied, idx)
 		}
 	}
 }
@@ -1402,14 +1666,17 @@ func (n *Node) heartbeatRound(ctx context.Context) error {
 		deadline, _ = ctx.Deadline()
 	}
 	type ack struct {
+		peer string
+		sent time.Time
 		term uint64
 		err  error
 	}
-	peers := n.trans.Peers()
+	// Learners do not count towards the quorum that confirms leadership.
+	peers := n.voters()
 	ch := make(chan ack, len(peers))
 	// Send heartbeats concurrently.
 	for _, peer := range peers {
-		if n.trans.isSelf(peer) {
+		if n.trans.IsSelf(peer) {
 			continue
 		}
 		go func(p string) {
@@ -1420,20 +1687,27 @@ func (n *Node) heartbeatRound(ctx context.Context) error {
 				PrevLogIndex: n.lastLogIndex,
 				PrevLogTerm:  n.lastLogTerm,
 				CommitIdx:    n.commitIdx,
+				LeaderAddr:   n.trans.SelfAddr(),
 			}
 			n.mu.Unlock()
 			pctx, cancel := context.WithDeadline(ctx, deadline)
 			defer cancel()
-			resp, err := n.trans.sendAppend(pctx, p, req)
-			ch <- ack{term: resp.Term, err: err}
+			sent := time.Now()
+			resp, err := n.trans.SendAppend(pctx, p, req)
+			ch <- ack{peer: p, sent: sent, term: resp.Term, err: err}
 		}(peer)
 	}
 	// Count responses. An ack counts only when the follower answered with our
 	// term; a higher-term response means we have been deposed and must step
 	// down immediately so the read fails instead of serving stale data.
-	votes := 1 // self-vote
-	total := len(peers)
-	for i := 0; i < total-1; i++ {
+	acked := make(map[string]bool, len(peers))
+	sent := 0
+	for _, peer := range peers {
+		if !n.trans.IsSelf(peer) {
+			sent++
+		}
+	}
+	for i := 0; i < sent; i++ {
 		select {
 		case a := <-ch:
 			if a.err != nil {
@@ -1447,17 +1721,20 @@ func (n *Node) heartbeatRound(ctx context.Context) error {
 				return ErrNotLeader{Leader: ""}
 			}
 			if a.term == n.term {
-				votes++
+				acked[a.peer] = true
+				if n.Role() == Leader {
+					n.recordAckLocked(a.peer, a.sent)
+				}
 			}
 			n.mu.Unlock()
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
-	if votes >= total/2+1 {
+	if n.hasQuorum(acked) {
 		return nil
 	}
-	return fmt.Errorf("heartbeat round failed: got %d/%d votes", votes, total/2+1)
+	return fmt.Errorf("heartbeat round failed: got %d/%d acks", 1+len(acked), len(peers))
 }
 
 // StepDown forces the current leader to step down, incrementing the term
@@ -1476,6 +1753,7 @@ func (n *Node) StepDown() error {
 	n.votedFor = ""
 	n.role.Store(Follower)
 	n.leaderID.Store("")
+	n.leaderAddr.Store("")
 	metrics.SetRaftRole(n.id, string(Follower))
 	for idx, w := range n.applyWaiter {
 		w <- applyResult{resp: nil, err: ErrNotLeader{Leader: ""}}
@@ -1501,9 +1779,58 @@ func (e ErrNotLeader) GRPCStatus() *status.Status {
 	return status.New(codes.FailedPrecondition, msg)
 }
 
-type ErrCommit struct{}
+// ErrCommit reports that a command was not committed when Submit returned:
+// it never reached the log, or replication did not finish in time. In the
+// latter case the entry may still commit later. Err is the cause, such as
+// the caller's context error, if any.
+type ErrCommit struct{ Err error }
+
+func (e ErrCommit) Error() string {
+	if e.Err != nil {
+		return "commit failed: " + e.Err.Error()
+	}
+	return "commit failed"
+}
+
+func (e ErrCommit) Unwrap() error { return e.Err }
 
-func (e ErrCommit) Error() string { return "commit failed" }
+// GRPCStatus maps ErrCommit to the caller's context error, or to
+// codes.Unavailable.
+func (e ErrCommit) GRPCStatus() *status.Status {
+	switch {
+	case errors.Is(e.Err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, e.Error())
+	case errors.Is(e.Err, context.Canceled):
+		return status.New(codes.Canceled, e.Error())
+	}
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// ErrResultUnknown reports that a command reached the log at Index but its
+// response did not arrive before Submit returned. The entry may have been
+// applied, or may still commit under a later leader. Retrying a command
+// that is not idempotent could apply it twice. Err, if set, is why Submit
+// stopped waiting.
+type ErrResultUnknown struct {
+	Index uint64
+	Err   error
+}
+
+func (e ErrResultUnknown) Error() string {
+	msg := fmt.Sprintf("appended at index %d, result unknown", e.Index)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e ErrResultUnknown) Unwrap() error { return e.Err }
+
+// GRPCStatus maps ErrResultUnknown to codes.Unknown, which clients do not
+// retry.
+func (e ErrResultUnknown) GRPCStatus() *status.Status {
+	return status.New(codes.Unknown, e.Error())
+}
 
 type ErrPeerExists struct{}
 
@@ -5,18 +5,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/metrics"
 	"github.com/lushenle/simple-cache/pkg/utils"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -64,13 +70,17 @@ func (f *fakeApplier) Count() int {
 	return len(f.items)
 }
 
-func (f *fakeApplier) Snapshot(nodeID string) ([]byte, error) {
+func (f *fakeApplier) Snapshot(nodeID string, w io.Writer) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
-	return json.Marshal(f.items)
+	return json.NewEncoder(w).Encode(f.items)
 }
 
-func (f *fakeApplier) RestoreSnapshot(nodeID string, data []byte) error {
+func (f *fakeApplier) RestoreSnapshot(nodeID string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	if len(data) == 0 {
@@ -121,6 +131,29 @@ func waitForCondition(t *testing.T, fn func() bool) {
 	t.Fatal("condition not met before timeout")
 }
 
+// newMemCluster starts size nodes connected by network and returns them with
+// their appliers.
+func newMemCluster(t *testing.T, network *MemNetwork, size int) ([]*Node, map[*Node]*fakeApplier) {
+	t.Helper()
+	baseDir := t.TempDir()
+	peers := make([]string, size)
+	for i := range peers {
+		peers[i] = fmt.Sprintf("http://n%d:9090", i+1)
+	}
+	var nodes []*Node
+	appliers := map[*Node]*fakeApplier{}
+	for i, addr := range peers {
+		id := fmt.Sprintf("n%d", i+1)
+		applier := newFakeApplier()
+		n, err := NewNode(id, addr, peers, NewStorage(filepath.Join(baseDir, id+".wal")), applier, 80*time.Millisecond, 180*time.Millisecond, true, 64, zap.NewNop(), "", WithTransport(network.Transport(addr, peers)))
+		require.NoError(t, err)
+		t.Cleanup(n.Close)
+		nodes = append(nodes, n)
+		appliers[n] = applier
+	}
+	return nodes, appliers
+}
+
 func TestNodeReplicationAndFailover(t *testing.T) {
 	logger := zap.NewNop()
 	baseDir := t.TempDir()
@@ -149,7 +182,7 @@ func TestNodeReplicationAndFailover(t *testing.T) {
 	defer n3.Close()
 
 	leader := waitForLeader(t, n1, n2, n3)
-	_, err = leader.Submit(&command.SetCommand{Key: "k1", Value: "v1"})
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "k1", Value: "v1"})
 	require.NoError(t, err)
 
 	waitForCondition(t, func() bool {
@@ -168,7 +201,7 @@ func TestNodeReplicationAndFailover(t *testing.T) {
 	}
 
 	newLeader := waitForLeader(t, survivors...)
-	_, err = newLeader.Submit(&command.SetCommand{Key: "k2", Value: "v2"})
+	_, err = newLeader.Submit(context.Background(), &command.SetCommand{Key: "k2", Value: "v2"})
 	require.NoError(t, err)
 
 	waitForCondition(t, func() bool {
@@ -198,7 +231,7 @@ func TestNodeReplayCommittedEntriesOnRestart(t *testing.T) {
 	node, err := NewNode("node-1", addr, peers, NewStorage(walPath), applier, 80*time.Millisecond, 180*time.Millisecond, true, 2, logger, "")
 	require.NoError(t, err)
 	leader := waitForLeader(t, node)
-	_, err = leader.Submit(&command.SetCommand{Key: "persisted", Value: "value"})
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "persisted", Value: "value"})
 	require.NoError(t, err)
 	waitForCondition(t, func() bool { return applier.Has("persisted") })
 	node.Close()
@@ -248,6 +281,255 @@ func TestNodeReplicatesPeerChange(t *testing.T) {
 	})
 }
 
+func TestLearnerJoinsWithoutChangingQuorum(t *testing.T) {
+	network := NewMemNetwork(3)
+	nodes, _ := newMemCluster(t, network, 3)
+	leader := submitToLeader(t, &command.SetCommand{Key: "before", Value: "v"}, nodes...)
+
+	learnerAddr := "http://n4:9090"
+	peers := append(leader.Peers(), learnerAddr)
+	learnerApplier := newFakeApplier()
+	learner, err := NewNode("n4", learnerAddr, peers, NewStorage(filepath.Join(t.TempDir(), "n4.wal")), learnerApplier, 80*time.Millisecond, 180*time.Millisecond, true, 64, zap.NewNop(), "",
+		WithTransport(network.Transport(learnerAddr, peers)), AsLearner())
+	require.NoError(t, err)
+	defer learner.Close()
+	require.True(t, learner.IsLearner())
+
+	require.NoError(t, leader.AddLearner(learnerAddr))
+	require.ErrorIs(t, leader.PromoteLearner(context.Background(), leader.trans.SelfAddr()), ErrNotLearner{})
+	waitForCondition(t, func() bool { return learnerApplier.Has("before") })
+	for _, n := range nodes {
+		waitForCondition(t, func() bool { return contains(n.Learners(), learnerAddr) })
+	}
+
+	// With both other voters cut off, the learner's acknowledgement must
+	// not make up a quorum.
+	var cut []*Node
+	for _, n := range nodes {
+		if n != leader {
+			cut = append(cut, n)
+			network.Isolate(n.trans.SelfAddr())
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	_, err = leader.Submit(ctx, &command.SetCommand{Key: "minority", Value: "v"})
+	cancel()
+	require.Error(t, err)
+	require.Equal(t, Follower, learner.Role(), "a learner must not campaign")
+	network.Heal()
+
+	leader = submitToLeader(t, &command.SetCommand{Key: "healed", Value: "v"}, nodes...)
+	waitForCondition(t, func() bool { return learnerApplier.Has("healed") })
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, leader.PromoteLearner(ctx, learnerAddr))
+	all := append(nodes, learner)
+	for _, n := range all {
+		waitForCondition(t, func() bool { return len(n.Learners()) == 0 })
+	}
+	require.False(t, learner.IsLearner())
+	require.Len(t, learner.voters(), 4)
+
+	// With four voters, three of four still commit while one is down.
+	network.Isolate(cut[0].trans.SelfAddr())
+	submitToLeader(t, &command.SetCommand{Key: "promoted", Value: "v"}, all...)
+	waitForCondition(t, func() bool { return learnerApplier.Has("promoted") })
+}
+
+func TestInstalledSnapshotCarriesMembership(t *testing.T) {
+	network := NewMemNetwork(3)
+	nodes, _ := newMemCluster(t, network, 3)
+	leader := submitToLeader(t, &command.SetCommand{Key: "before", Value: "v"}, nodes...)
+
+	// A learner that never starts: it exists only in the membership.
+	ghostAddr := "http://n4:9090"
+	require.NoError(t, leader.AddLearner(ghostAddr))
+	leader.mu.Lock()
+	added := leader.lastLogIndex
+	leader.mu.Unlock()
+	for i := 0; i < 80; i++ {
+		_, err := leader.Submit(context.Background(), &command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
+		require.NoError(t, err)
+	}
+	waitForCondition(t, func() bool {
+		leader.mu.Lock()
+		defer leader.mu.Unlock()
+		return leader.snapshotIndex > added
+	})
+
+	// The entry that added the ghost is compacted away, so the new learner
+	// learns about it only from the snapshot it installs.
+	learnerAddr := "http://n5:9090"
+	peers := []string{learnerAddr}
+	for _, n := range nodes {
+		peers = append(peers, n.trans.SelfAddr())
+	}
+	learnerApplier := newFakeApplier()
+	learner, err := NewNode("n5", learnerAddr, peers, NewStorage(filepath.Join(t.TempDir(), "n5.wal")), learnerApplier, 80*time.Millisecond, 180*time.Millisecond, true, 64, zap.NewNop(), "",
+		WithTransport(network.Transport(learnerAddr, peers)), AsLearner())
+	require.NoError(t, err)
+	defer learner.Close()
+	require.NoError(t, leader.AddLearner(learnerAddr))
+	waitForCondition(t, func() bool { return learnerApplier.Has("k79") })
+	require.True(t, contains(learner.Peers(), ghostAddr))
+	require.ElementsMatch(t, []string{ghostAddr, learnerAddr}, learner.Learners())
+	require.Len(t, learner.voters(), 3)
+}
+
+// newMemNode starts a node at addr on network that is not yet a member of
+// the cluster behind peers.
+func newMemNode(t *testing.T, network *MemNetwork, id, addr string, peers []string) (*Node, *fakeApplier) {
+	t.Helper()
+	applier := newFakeApplier()
+	n, err := NewNode(id, addr, peers, NewStorage(filepath.Join(t.TempDir(), id+".wal")), applier, 80*time.Millisecond, 180*time.Millisecond, true, 64, zap.NewNop(), "",
+		WithTransport(network.Transport(addr, peers)))
+	require.NoError(t, err)
+	t.Cleanup(n.Close)
+	return n, applier
+}
+
+// replaceLeaderAndFollower starts n4 and n5 and returns the reconfiguration
+// that swaps them in for the leader and one follower, the members after it
+// (the remaining follower first) and the appliers of n4 and n5.
+func replaceLeaderAndFollower(t *testing.T, network *MemNetwork, leader *Node, nodes []*Node) (Reconfiguration, []*Node, map[*Node]*fakeApplier) {
+	t.Helper()
+	var followers []*Node
+	for _, n := range nodes {
+		if n != leader {
+			followers = append(followers, n)
+		}
+	}
+	peers := append(leader.Peers(), "http://n4:9090", "http://n5:9090")
+	n4, a4 := newMemNode(t, network, "n4", "http://n4:9090", peers)
+	n5, a5 := newMemNode(t, network, "n5", "http://n5:9090", peers)
+	rc := Reconfiguration{
+		Add:    []string{"http://n4:9090", "http://n5:9090"},
+		Remove: []string{leader.trans.SelfAddr(), followers[0].trans.SelfAddr()},
+	}
+	return rc, []*Node{followers[1], n4, n5}, map[*Node]*fakeApplier{n4: a4, n5: a5}
+}
+
+func TestReconfigureReplacesMembersAtomically(t *testing.T) {
+	network := NewMemNetwork(5)
+	nodes, _ := newMemCluster(t, network, 3)
+	leader := submitToLeader(t, &command.SetCommand{Key: "before", Value: "v"}, nodes...)
+	rc, members, added := replaceLeaderAndFollower(t, network, leader, nodes)
+
+	require.ErrorIs(t, leader.Reconfigure(context.Background(), Reconfiguration{Remove: []string{"http://n9:9090"}}), ErrPeerNotFound{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, leader.Reconfigure(ctx, rc))
+
+	want := []string{members[0].trans.SelfAddr(), "http://n4:9090", "http://n5:9090"}
+	slices.Sort(want)
+	waitForCondition(t, func() bool { return leader.Role() != Leader })
+	for _, n := range members {
+		waitForCondition(t, func() bool {
+			voters := n.voters()
+			slices.Sort(voters)
+			return slices.Equal(voters, want) && n.Status()["joint"] == false
+		})
+	}
+
+	// The new members elect a leader among themselves and keep committing.
+	submitToLeader(t, &command.SetCommand{Key: "after", Value: "v"}, members...)
+	for n, applier := range added {
+		waitForCondition(t, func() bool { return applier.Has("before") && applier.Has("after") })
+		require.False(t, n.IsLearner())
+	}
+	require.NotEqual(t, Leader, leader.Role())
+}
+
+func TestJointConfigRequiresBothMajorities(t *testing.T) {
+	network := NewMemNetwork(6)
+	nodes, _ := newMemCluster(t, network, 3)
+	leader := submitToLeader(t, &command.SetCommand{Key: "before", Value: "v"}, nodes...)
+	rc, members, added := replaceLeaderAndFollower(t, network, leader, nodes)
+
+	// With the new members unreachable C_old,new still commits, since it
+	// only needs C_old, but nothing commits after it: C_new has no majority.
+	network.Isolate("http://n4:9090")
+	network.Isolate("http://n5:9090")
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	err := leader.Reconfigure(ctx, rc)
+	cancel()
+	var commitErr ErrCommit
+	require.ErrorAs(t, err, &commitErr)
+	require.Equal(t, true, leader.Status()["joint"])
+	require.ErrorIs(t, leader.SubmitPeerChange("http://n6:9090", false), ErrPeerChangeInFlight{})
+
+	ctx, cancel = context.WithTimeout(context.Background(), 300*time.Millisecond)
+	_, err = leader.Submit(ctx, &command.SetCommand{Key: "joint", Value: "v"})
+	cancel()
+	require.Error(t, err)
+
+	// Once the new members are back the leader completes the change alone.
+	network.Heal()
+	for _, n := range members {
+		waitForCondition(t, func() bool { return n.Status()["joint"] == false && len(n.voters()) == 3 })
+	}
+	submitToLeader(t, &command.SetCommand{Key: "after", Value: "v"}, members...)
+	for _, applier := range added {
+		waitForCondition(t, func() bool { return applier.Has("after") })
+	}
+}
+
+func TestTransferLeadershipToTarget(t *testing.T) {
+	network := NewMemNetwork(7)
+	nodes, appliers := newMemCluster(t, network, 3)
+	leader := submitToLeader(t, &command.SetCommand{Key: "before", Value: "v"}, nodes...)
+	var target, other *Node
+	for _, n := range nodes {
+		if n == leader {
+			continue
+		}
+		if target == nil {
+			target = n
+		} else {
+			other = n
+		}
+	}
+	leader.mu.Lock()
+	term := leader.term
+	leader.mu.Unlock()
+
+	require.ErrorIs(t, leader.TransferLeadership(context.Background(), "n9"), ErrPeerNotFound{})
+	require.NoError(t, leader.TransferLeadership(context.Background(), leader.id))
+
+	// The target is cut off: TimeoutNow cannot reach it, so the transfer is
+	// aborted and the leader carries on.
+	network.Isolate(target.trans.SelfAddr())
+	err := leader.TransferLeadership(context.Background(), target.id)
+	var failed ErrTransferFailed
+	require.ErrorAs(t, err, &failed)
+	require.Equal(t, Leader, leader.Role())
+	// The target might still campaign, so the lease stays down for an
+	// election timeout even though the other follower keeps acknowledging.
+	require.NoError(t, leader.heartbeatRound(context.Background()))
+	leader.mu.Lock()
+	leased := leader.leaseValidLocked(time.Now())
+	leader.mu.Unlock()
+	require.False(t, leased)
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "aborted", Value: "v"})
+	require.NoError(t, err)
+	network.Heal()
+
+	// Addressed by node ID, the caught-up target wins the next term at once
+	// rather than any node after an election timeout.
+	waitForCondition(t, func() bool { return appliers[target].Has("aborted") })
+	require.NoError(t, leader.TransferLeadership(context.Background(), target.id))
+	waitForCondition(t, func() bool { return target.Role() == Leader })
+	target.mu.Lock()
+	require.Equal(t, term+1, target.term)
+	target.mu.Unlock()
+	require.NotEqual(t, Leader, other.Role())
+
+	_, err = target.Submit(context.Background(), &command.SetCommand{Key: "after", Value: "v"})
+	require.NoError(t, err)
+	waitForCondition(t, func() bool { return appliers[leader].Has("after") })
+}
+
 func TestNodeSubmitWithUnreachablePeerDoesNotBlockTooLong(t *testing.T) {
 	logger := zap.NewNop()
 	baseDir := t.TempDir()
@@ -270,7 +552,7 @@ func TestNodeSubmitWithUnreachablePeerDoesNotBlockTooLong(t *testing.T) {
 
 	leader := waitForLeader(t, n1, n2)
 	start := time.Now()
-	_, err = leader.Submit(&command.SetCommand{Key: "k-timeout", Value: "v"})
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "k-timeout", Value: "v"})
 	duration := time.Since(start)
 
 	require.NoError(t, err)
@@ -289,9 +571,9 @@ func TestNodeCreatesSnapshotAndRecoversOnRestart(t *testing.T) {
 	require.NoError(t, err)
 	leader := waitForLeader(t, node)
 
-	_, err = leader.Submit(&command.SetCommand{Key: "k1", Value: "v1"})
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "k1", Value: "v1"})
 	require.NoError(t, err)
-	_, err = leader.Submit(&command.SetCommand{Key: "k2", Value: "v2"})
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "k2", Value: "v2"})
 	require.NoError(t, err)
 	waitForCondition(t, func() bool { return applier.Has("k1") && applier.Has("k2") })
 	waitForCondition(t, func() bool { return node.storage.HasSnapshot() })
@@ -339,14 +621,11 @@ func TestNodeInstallSnapshotRejectsOversizedAccumulation(t *testing.T) {
 	addr := freeAddr(t)
 	peers := []string{"http://" + addr}
 
-	node, err := NewNode("node-1", addr, peers, NewStorage(filepath.Join(baseDir, "node.wal")), newFakeApplier(), 80*time.Millisecond, 180*time.Millisecond, true, 2, logger, "")
+	node, err := NewNode("node-1", addr, peers, NewStorage(filepath.Join(baseDir, "node.wal")), newFakeApplier(), 80*time.Millisecond, 180*time.Millisecond, true, 2, logger, "",
+		WithMaxSnapshotSize(64*1024))
 	require.NoError(t, err)
 	defer node.Close()
 
-	old := maxPendingSnapshotBytes
-	maxPendingSnapshotBytes = 64 * 1024 // 64 KiB
-	defer func() { maxPendingSnapshotBytes = old }()
-
 	resp := node.onInstallSnapshot(InstallSnapshotReq{
 		Term: 2, LeaderID: "leader-1", LastIncludedIndex: 5, LastIncludedTerm: 2,
 		Data: bytes.Repeat([]byte("x"), 40*1024), Offset: 0, Done: false,
@@ -395,7 +674,7 @@ func TestReplicateRepairsDivergentFollower(t *testing.T) {
 	defer n3.Close()
 
 	leader := waitForLeader(t, n1, n2, n3)
-	_, err = leader.Submit(&command.SetCommand{Key: "k0", Value: "v"})
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "k0", Value: "v"})
 	require.NoError(t, err)
 	waitForCondition(t, func() bool {
 		return applier1.Has("k0") && applier2.Has("k0") && applier3.Has("k0")
@@ -419,7 +698,7 @@ func TestReplicateRepairsDivergentFollower(t *testing.T) {
 	follower.mu.Unlock()
 
 	// The next write must drive the follower back to consensus.
-	_, err = leader.Submit(&command.SetCommand{Key: "k1", Value: "v"})
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "k1", Value: "v"})
 	require.NoError(t, err)
 	waitForCondition(t, func() bool {
 		return applier1.Has("k1") && applier2.Has("k1") && applier3.Has("k1")
@@ -490,7 +769,7 @@ func TestNodeFastCatchUp(t *testing.T) {
 
 	start := time.Now()
 	for i := 0; i < 50; i++ {
-		_, err = leader.Submit(&command.SetCommand{Key: fmt.Sprintf("fast-%d", i), Value: "v"})
+		_, err = leader.Submit(context.Background(), &command.SetCommand{Key: fmt.Sprintf("fast-%d", i), Value: "v"})
 		require.NoError(t, err)
 	}
 	waitForCondition(t, func() bool {
@@ -500,6 +779,20 @@ func TestNodeFastCatchUp(t *testing.T) {
 	require.Less(t, time.Since(start), 3*time.Second)
 }
 
+func TestAppendBatchBoundsBytes(t *testing.T) {
+	entry := func(size int) LogEntry { return LogEntry{Data: make([]byte, size)} }
+
+	small := []LogEntry{entry(10), entry(10), entry(10)}
+	require.Len(t, appendBatch(small), 3)
+
+	third := MaxAppendBytes / 3
+	require.Len(t, appendBatch([]LogEntry{entry(third), entry(third), entry(third), entry(third)}), 3)
+
+	// An entry over the limit is still sent, on its own.
+	require.Len(t, appendBatch([]LogEntry{entry(MaxAppendBytes + 1), entry(10)}), 1)
+	require.Len(t, appendBatch([]LogEntry{entry(10), entry(MaxAppendBytes)}), 1)
+}
+
 // TestNodeInstallSnapshotChunked verifies the chunked InstallSnapshot path
 // (P2-14): chunks accumulate in order and the FSM is restored only on the
 // final chunk; an out-of-order chunk is rejected.
@@ -582,9 +875,9 @@ func TestRequestVoteLogComparison(t *testing.T) {
 	waitForLeader(t, node)
 
 	// Submit a few entries so the node has some log state.
-	_, err = node.Submit(&command.SetCommand{Key: "a", Value: "1"})
+	_, err = node.Submit(context.Background(), &command.SetCommand{Key: "a", Value: "1"})
 	require.NoError(t, err)
-	_, err = node.Submit(&command.SetCommand{Key: "b", Value: "2"})
+	_, err = node.Submit(context.Background(), &command.SetCommand{Key: "b", Value: "2"})
 	require.NoError(t, err)
 	waitForCondition(t, func() bool { return applier.Has("a") && applier.Has("b") })
 
@@ -688,7 +981,7 @@ func TestNodeAppendAfterSnapshot(t *testing.T) {
 
 	// Drive enough entries for every node to create a snapshot (threshold 2).
 	for i := 0; i < 4; i++ {
-		_, err = leader.Submit(&command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
+		_, err = leader.Submit(context.Background(), &command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
 		require.NoError(t, err)
 	}
 	waitForCondition(t, func() bool {
@@ -700,7 +993,7 @@ func TestNodeAppendAfterSnapshot(t *testing.T) {
 
 	// Followers now have snapshotIndex > 0; incremental appends must work.
 	for i := 4; i < 8; i++ {
-		_, err = leader.Submit(&command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
+		_, err = leader.Submit(context.Background(), &command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
 		require.NoError(t, err)
 	}
 	waitForCondition(t, func() bool {
@@ -891,3 +1184,405 @@ func TestReadIndexRejectsDeposedLeader(t *testing.T) {
 	// The deposed leader must have stepped down.
 	require.NotEqual(t, Leader, leader.Role())
 }
+
+func TestReadIndexFailsOnIsolatedLeader(t *testing.T) {
+	network := NewMemNetwork(7)
+	nodes, _ := newMemCluster(t, network, 3)
+	leader := waitForLeader(t, nodes...)
+	waitForCondition(t, func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := leader.ReadIndex(ctx)
+		return err == nil
+	})
+
+	network.Isolate(leader.trans.SelfAddr())
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_, err := leader.ReadIndex(ctx)
+	require.Error(t, err)
+}
+
+func TestFollowerReadIndex(t *testing.T) {
+	logger := zap.NewNop()
+	baseDir := t.TempDir()
+
+	addrs := []string{freeAddr(t), freeAddr(t), freeAddr(t)}
+	peers := make([]string, len(addrs))
+	for i, addr := range addrs {
+		peers[i] = "http://" + addr
+	}
+	var nodes []*Node
+	appliers := map[*Node]*fakeApplier{}
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		applier := newFakeApplier()
+		n, err := NewNode(id, addr, peers, NewStorage(filepath.Join(baseDir, id+".wal")), applier, 80*time.Millisecond, 180*time.Millisecond, true, 8, logger, "")
+		require.NoError(t, err)
+		defer n.Close()
+		nodes = append(nodes, n)
+		appliers[n] = applier
+	}
+	leader := waitForLeader(t, nodes...)
+
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("k%d", i)
+		_, err := leader.Submit(context.Background(), &command.SetCommand{Key: key, Value: "v"})
+		require.NoError(t, err)
+
+		// Right after the write is acknowledged, a follower read that went
+		// through FollowerReadIndex must already see it.
+		for _, n := range nodes {
+			if n == leader {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			idx, err := n.FollowerReadIndex(ctx)
+			cancel()
+			require.NoError(t, err)
+			require.NotZero(t, idx)
+			require.True(t, appliers[n].Has(key), "follower %s missed %s", n.id, key)
+		}
+	}
+
+	for _, n := range nodes {
+		waitForCondition(t, n.CaughtUp)
+	}
+
+	// After a failover the surviving follower forwards to the new leader.
+	leader.Close()
+	var survivors []*Node
+	for _, n := range nodes {
+		if n != leader {
+			survivors = append(survivors, n)
+		}
+	}
+	newLeader := waitForLeader(t, survivors...)
+	_, err := newLeader.Submit(context.Background(), &command.SetCommand{Key: "after-failover", Value: "v"})
+	require.NoError(t, err)
+	for _, n := range survivors {
+		if n == newLeader {
+			continue
+		}
+		waitForCondition(t, func() bool {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			_, err := n.FollowerReadIndex(ctx)
+			return err == nil
+		})
+		require.True(t, appliers[n].Has("after-failover"))
+	}
+}
+
+func TestLeaseReadIndex(t *testing.T) {
+	logger := zap.NewNop()
+	baseDir := t.TempDir()
+
+	addrs := []string{freeAddr(t), freeAddr(t), freeAddr(t)}
+	peers := make([]string, len(addrs))
+	for i, addr := range addrs {
+		peers[i] = "http://" + addr
+	}
+	var nodes []*Node
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		n, err := NewNode(id, addr, peers, NewStorage(filepath.Join(baseDir, id+".wal")), newFakeApplier(), 80*time.Millisecond, 600*time.Millisecond, true, 8, logger, "")
+		require.NoError(t, err)
+		defer n.Close()
+		nodes = append(nodes, n)
+	}
+	leader := waitForLeader(t, nodes...)
+	_, err := leader.Submit(context.Background(), &command.SetCommand{Key: "k", Value: "v"})
+	require.NoError(t, err)
+
+	// A follower that just heard from the leader refuses to vote for a
+	// candidate, even at a higher term, and keeps its own term.
+	var follower *Node
+	for _, n := range nodes {
+		if n != leader {
+			follower = n
+			break
+		}
+	}
+	waitForCondition(t, func() bool { return follower.heardFromLeaderWithin(follower.el) })
+	follower.mu.Lock()
+	term := follower.term
+	follower.mu.Unlock()
+	for _, preVote := range []bool{true, false} {
+		resp := follower.onRequestVote(RequestVoteReq{Term: term + 1, CandidateID: "intruder", LastLogIndex: 1 << 20, LastLogTerm: term + 1, PreVote: preVote})
+		require.False(t, resp.VoteGranted)
+		require.Equal(t, term, resp.Term)
+	}
+
+	// leaderContact does not survive a restart, so a follower that just
+	// started refuses votes for an election timeout as if it had just
+	// heard from the leader.
+	restarted, err := NewNode("n9", freeAddr(t), peers, NewStorage(filepath.Join(baseDir, "n9.wal")), newFakeApplier(), 80*time.Millisecond, 600*time.Millisecond, true, 8, logger, "")
+	require.NoError(t, err)
+	defer restarted.Close()
+	vote := RequestVoteReq{Term: term + 1, CandidateID: "intruder", LastLogIndex: 1 << 20, LastLogTerm: term + 1, PreVote: true}
+	require.False(t, restarted.onRequestVote(vote).VoteGranted)
+	time.Sleep(restarted.el)
+	require.True(t, restarted.onRequestVote(vote).VoteGranted)
+
+	// Cut the leader off from both followers. It keeps serving lease reads
+	// until the lease runs out, then falls back to ReadIndex and fails.
+	for _, n := range nodes {
+		if n != leader {
+			n.Close()
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	idx, err := leader.LeaseReadIndex(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, idx)
+
+	time.Sleep(leader.leaseDuration())
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Second)
+	defer cancel2()
+	_, err = leader.LeaseReadIndex(ctx2)
+	require.Error(t, err)
+}
+
+func TestNodeBackupBootstrapsNewCluster(t *testing.T) {
+	logger := zap.NewNop()
+	baseDir := t.TempDir()
+	addr := freeAddr(t)
+	peers := []string{"http://" + addr}
+
+	applier := newFakeApplier()
+	node, err := NewNode("old", addr, peers, NewStorage(filepath.Join(baseDir, "old.wal")), applier, 80*time.Millisecond, 180*time.Millisecond, true, 100, logger, "")
+	require.NoError(t, err)
+	leader := waitForLeader(t, node)
+	for i := 0; i < 3; i++ {
+		_, err = leader.Submit(context.Background(), &command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
+		require.NoError(t, err)
+	}
+	_, err = node.Backup(context.Background(), true)
+	require.ErrorIs(t, err, ErrNoSnapshot)
+
+	backup, err := node.Backup(context.Background(), false)
+	require.NoError(t, err)
+	node.mu.Lock()
+	wantIndex, wantTerm := node.lastApply, node.term
+	node.mu.Unlock()
+	require.Equal(t, wantIndex, backup.Meta.LastIncludedIndex)
+	require.Equal(t, wantTerm, backup.Meta.LastIncludedTerm)
+	require.Equal(t, "old", backup.Meta.NodeID)
+	path := filepath.Join(baseDir, "cluster.backup")
+	out, err := os.Create(path)
+	require.NoError(t, err)
+	n, err := io.Copy(out, backup)
+	require.NoError(t, err)
+	require.Equal(t, backup.Size, n)
+	require.NoError(t, out.Close())
+	require.NoError(t, backup.Close())
+	matches, _ := filepath.Glob(filepath.Join(baseDir, "*.backup-*"))
+	require.Empty(t, matches, "temp file must be removed on close")
+	node.Close()
+
+	newStorage := NewStorage(filepath.Join(baseDir, "new.wal"))
+	verify := newFakeApplier()
+	meta, err := newStorage.RestoreBackup(path, func(r io.Reader) error { return verify.RestoreSnapshot("new", r) })
+	require.NoError(t, err)
+	require.Equal(t, wantIndex, meta.LastIncludedIndex)
+	require.Equal(t, 3, verify.Count())
+	_, err = newStorage.RestoreBackup(path, func(io.Reader) error { return nil })
+	require.ErrorContains(t, err, "raft state already exists")
+
+	restored := newFakeApplier()
+	newAddr := freeAddr(t)
+	node2, err := NewNode("new", newAddr, []string{"http://" + newAddr}, newStorage, restored, 80*time.Millisecond, 180*time.Millisecond, true, 100, logger, "")
+	require.NoError(t, err)
+	defer node2.Close()
+	require.Equal(t, 3, restored.Count())
+	leader = waitForLeader(t, node2)
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "after", Value: "v"})
+	require.NoError(t, err)
+	node2.mu.Lock()
+	require.Greater(t, node2.term, wantTerm)
+	require.Greater(t, node2.lastApply, wantIndex)
+	node2.mu.Unlock()
+}
+
+func TestProposalGroupCommit(t *testing.T) {
+	logger := zap.NewNop()
+	baseDir := t.TempDir()
+
+	addrs := []string{freeAddr(t), freeAddr(t), freeAddr(t)}
+	peers := make([]string, len(addrs))
+	for i, addr := range addrs {
+		peers[i] = "http://" + addr
+	}
+	var nodes []*Node
+	appliers := make(map[*Node]*fakeApplier)
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		applier := newFakeApplier()
+		n, err := NewNode(id, addr, peers, NewStorage(filepath.Join(baseDir, id+".wal")), applier, 80*time.Millisecond, 600*time.Millisecond, true, 1024, logger, "")
+		require.NoError(t, err)
+		defer n.Close()
+		nodes = append(nodes, n)
+		appliers[n] = applier
+	}
+	leader := waitForLeader(t, nodes...)
+	leader.SetProposalBatching(50*time.Millisecond, 64)
+
+	batchStats := func() (count uint64, sum float64) {
+		var m dto.Metric
+		require.NoError(t, metrics.RaftProposalBatchSize.Write(&m))
+		return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+	}
+	count0, sum0 := batchStats()
+
+	// Concurrent submits share batches; each still gets its own index.
+	const writers = 32
+	indexes := make([]uint64, writers)
+	errs := make([]error, writers)
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, indexes[i], errs[i] = leader.SubmitWithIndex(context.Background(), &command.SetCommand{Key: fmt.Sprintf("k%d", i), Value: "v"})
+		}()
+	}
+	wg.Wait()
+	seen := make(map[uint64]bool)
+	for i, idx := range indexes {
+		require.NoError(t, errs[i])
+		require.NotZero(t, idx)
+		require.False(t, seen[idx])
+		seen[idx] = true
+	}
+	count1, sum1 := batchStats()
+	require.Equal(t, float64(writers), sum1-sum0)
+	require.Less(t, count1-count0, uint64(writers))
+
+	// Proposals queued by one goroutine enter the log in order, so the
+	// last write to a key wins on every replica.
+	var props []*Proposal
+	for i := range 20 {
+		p, err := leader.Propose(context.Background(), &command.SetCommand{Key: "ordered", Value: fmt.Sprintf("v%d", i)})
+		require.NoError(t, err)
+		props = append(props, p)
+	}
+	var last uint64
+	for _, p := range props {
+		_, idx, err := p.Wait(context.Background())
+		require.NoError(t, err)
+		require.Greater(t, idx, last)
+		last = idx
+	}
+	for _, n := range nodes {
+		applier := appliers[n]
+		waitForCondition(t, func() bool {
+			applier.mu.Lock()
+			defer applier.mu.Unlock()
+			return applier.items["ordered"] == "v19" && len(applier.items) == writers+1
+		})
+	}
+}
+
+// slowApplier blocks applying the key "slow" until release is closed.
+type slowApplier struct {
+	*fakeApplier
+	release chan struct{}
+}
+
+func (s *slowApplier) Apply(cmd interface{}) (interface{}, error) {
+	if c, ok := cmd.(*command.SetCommand); ok && c.Key == "slow" {
+		<-s.release
+	}
+	return s.fakeApplier.Apply(cmd)
+}
+
+func TestSubmitStorageFailureIsErrCommit(t *testing.T) {
+	logger := zap.NewNop()
+	addr := freeAddr(t)
+	wal := filepath.Join(t.TempDir(), "solo.wal")
+	applier := newFakeApplier()
+	node, err := NewNode("solo", addr, []string{"http://" + addr}, NewStorage(wal), applier, 80*time.Millisecond, 180*time.Millisecond, true, 1024, logger, "")
+	require.NoError(t, err)
+	defer node.Close()
+	leader := waitForLeader(t, node)
+
+	// A directory in place of the WAL makes every append fail.
+	require.NoError(t, os.RemoveAll(wal))
+	require.NoError(t, os.Mkdir(wal, 0o755))
+
+	_, err = leader.Submit(context.Background(), &command.SetCommand{Key: "k", Value: "v"})
+	require.ErrorAs(t, err, &ErrCommit{})
+	require.NotErrorAs(t, err, &ErrResultUnknown{})
+	require.False(t, applier.Has("k"))
+}
+
+func TestSubmitHonoursContext(t *testing.T) {
+	logger := zap.NewNop()
+	baseDir := t.TempDir()
+
+	t.Run("CommittedResultUnknown", func(t *testing.T) {
+		addr := freeAddr(t)
+		applier := &slowApplier{fakeApplier: newFakeApplier(), release: make(chan struct{})}
+		node, err := NewNode("solo", addr, []string{"http://" + addr}, NewStorage(filepath.Join(baseDir, "solo.wal")), applier, 80*time.Millisecond, 180*time.Millisecond, true, 1024, logger, "")
+		require.NoError(t, err)
+		defer node.Close()
+		defer close(applier.release)
+		leader := waitForLeader(t, node)
+
+		// A cancelled context keeps the command out of the log.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = leader.Submit(ctx, &command.SetCommand{Key: "k", Value: "v"})
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorAs(t, err, &ErrCommit{})
+
+		ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = leader.Submit(ctx, &command.SetCommand{Key: "slow", Value: "v"})
+		require.Less(t, time.Since(start), time.Second)
+		var unknown ErrResultUnknown
+		require.ErrorAs(t, err, &unknown)
+		require.NotZero(t, unknown.Index)
+		require.False(t, applier.Has("k"))
+	})
+
+	t.Run("NotCommitted", func(t *testing.T) {
+		addrs := []string{freeAddr(t), freeAddr(t), freeAddr(t)}
+		peers := make([]string, len(addrs))
+		for i, addr := range addrs {
+			peers[i] = "http://" + addr
+		}
+		var nodes []*Node
+		for i, addr := range addrs {
+			id := fmt.Sprintf("n%d", i+1)
+			n, err := NewNode(id, addr, peers, NewStorage(filepath.Join(baseDir, id+".wal")), newFakeApplier(), 80*time.Millisecond, 600*time.Millisecond, true, 1024, logger, "")
+			require.NoError(t, err)
+			defer n.Close()
+			nodes = append(nodes, n)
+		}
+		leader := waitForLeader(t, nodes...)
+		for _, n := range nodes {
+			if n != leader {
+				n.Close()
+			}
+		}
+
+		// Without a quorum the write cannot commit; the caller's deadline,
+		// not the replication timeout, bounds the wait. The entry is in the
+		// leader's log and could still commit, so the outcome is unknown
+		// rather than a retryable failure.
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := leader.Submit(ctx, &command.SetCommand{Key: "k", Value: "v"})
+		require.Less(t, time.Since(start), time.Second)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		var unknown ErrResultUnknown
+		require.ErrorAs(t, err, &unknown)
+		require.NotZero(t, unknown.Index)
+		require.Equal(t, codes.Unknown, status.Code(err))
+	})
+}
@@ -60,7 +60,7 @@ func TestIsSelf(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tr := &HTTPTransport{addr: tt.addr}
-			require.Equal(t, tt.want, tr.isSelf(tt.peer))
+			require.Equal(t, tt.want, tr.IsSelf(tt.peer))
 		})
 	}
 }
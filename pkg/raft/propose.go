@@ -0,0 +1,299 @@
+package raft
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/metrics"
+)
+
+// Defaults for proposal batching (group commit), see SetProposalBatching.
+const (
+	DefaultProposalBatchWindow = 500 * time.Microsecond
+	DefaultMaxProposalBatch    = 256
+)
+
+// DefaultSubmitTimeout bounds Submit and Proposal.Wait when the caller's
+// context has no deadline.
+const DefaultSubmitTimeout = 5 * time.Second
+
+// proposeQueueSize bounds the proposals waiting for the batcher; Propose
+// blocks while it is full.
+const proposeQueueSize = 1024
+
+// Proposal states: a queued proposal is either taken by the batcher, after
+// which it may reach the log, or dropped by Wait, after which it never will.
+const (
+	proposalQueued int32 = iota
+	proposalTaken
+	proposalDropped
+)
+
+// Proposal is a command queued on the leader by Propose. Its entry is
+// appended to the log together with the other proposals of its batch;
+// Wait reports the outcome of this command alone.
+type Proposal struct {
+	n *Node
+	// ctx is the proposer's context; the batcher drops the proposal
+	// instead of appending it if ctx is done by then.
+	ctx      context.Context
+	entry    LogEntry
+	waiter   chan applyResult
+	appended chan proposalAppended
+	state    atomic.Int32
+}
+
+// proposalAppended tells a proposal where its entry landed in the log.
+type proposalAppended struct {
+	index uint64
+	batch *proposalBatch
+	err   error
+}
+
+// proposalBatch is the group of proposals appended with one WAL fsync and
+// replicated together. done is closed once the batch is committed (err nil)
+// or replication gave up (err set).
+type proposalBatch struct {
+	done chan struct{}
+	err  error
+}
+
+// SetProposalBatching sets how long the leader waits for more concurrent
+// proposals before appending a batch, and the most entries one batch takes.
+// A zero window batches only proposals that queued up while the previous
+// batch was written; maxEntries <= 0 restores the default.
+func (n *Node) SetProposalBatching(window time.Duration, maxEntries int) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxProposalBatch
+	}
+	n.batchWindow.Store(int64(max(window, 0)))
+	n.maxBatch.Store(int64(maxEntries))
+}
+
+// Propose queues cmd for replication and returns without waiting for it to
+// commit. Proposals made one after another by the same goroutine are
+// appended to the log in that order, so a caller can pipeline a stream of
+// writes and then Wait for each of them. A proposal whose ctx is done
+// before it reaches the log is dropped.
+func (n *Node) Propose(ctx context.Context, cmd interface{}) (*Proposal, error) {
+	if n.Role() != Leader {
+		return nil, ErrNotLeader{Leader: n.LeaderID()}
+	}
+	entry, err := newCommandEntry(cmd)
+	if err != nil {
+		return nil, err
+	}
+	p := &Proposal{
+		n:        n,
+		ctx:      ctx,
+		entry:    entry,
+		waiter:   make(chan applyResult, 1),
+		appended: make(chan proposalAppended, 1),
+	}
+	select {
+	case n.proposeCh <- p:
+		return p, nil
+	case <-ctx.Done():
+		return nil, ErrCommit{Err: ctx.Err()}
+	case <-n.stopCh:
+		return nil, ErrCommit{}
+	}
+}
+
+// Wait blocks until the proposal is applied and returns the state machine
+// response and the log index it committed at. It gives up when ctx is
+// done, or after DefaultSubmitTimeout if ctx has no deadline. ErrCommit
+// means the command never reached the log. Once it has, any failure is
+// ErrResultUnknown: the entry may still commit, under this leader or the
+// next, so the caller must not retry a command that is not idempotent.
+func (p *Proposal) Wait(ctx context.Context) (interface{}, uint64, error) {
+	n := p.n
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultSubmitTimeout)
+		defer cancel()
+	}
+
+	var a proposalAppended
+	select {
+	case a = <-p.appended:
+	case <-ctx.Done():
+		if p.state.CompareAndSwap(proposalQueued, proposalDropped) {
+			return nil, 0, ErrCommit{Err: ctx.Err()}
+		}
+		// The batcher has taken it and reports where it landed shortly.
+		a = <-p.appended
+	case <-n.stopCh:
+		if p.state.CompareAndSwap(proposalQueued, proposalDropped) {
+			return nil, 0, ErrCommit{}
+		}
+		a = <-p.appended
+	}
+	if a.err != nil {
+		return nil, 0, a.err
+	}
+
+	select {
+	case <-a.batch.done:
+		if a.batch.err != nil {
+			p.forget(a.index)
+			return nil, 0, ErrResultUnknown{Index: a.index, Err: a.batch.err}
+		}
+	case <-ctx.Done():
+		p.forget(a.index)
+		return nil, 0, ErrResultUnknown{Index: a.index, Err: ctx.Err()}
+	}
+
+	select {
+	case result := <-p.waiter:
+		return result.resp, a.index, result.err
+	case <-ctx.Done():
+		return nil, 0, ErrResultUnknown{Index: a.index, Err: ctx.Err()}
+	case <-n.stopCh:
+		return nil, 0, ErrResultUnknown{Index: a.index}
+	}
+}
+
+// forget stops waiting for the response to the entry at index.
+func (p *Proposal) forget(index uint64) {
+	n := p.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.applyWaiter[index] == p.waiter {
+		delete(n.applyWaiter, index)
+	}
+}
+
+// proposeLoop is the leader's group commit: it drains the proposal queue
+// in batches, appending each batch to the log with a single WAL write and
+// fsync and replicating it with a single round of AppendEntries.
+func (n *Node) proposeLoop() {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.stopCh:
+			return
+		case p := <-n.proposeCh:
+			n.appendProposals(n.collectProposals(p))
+		}
+	}
+}
+
+// collectProposals gathers the proposals queued behind first, waiting up to
+// the batch window for more while the batch has room.
+func (n *Node) collectProposals(first *Proposal) []*Proposal {
+	props := []*Proposal{first}
+	limit := int(n.maxBatch.Load())
+	var timeout <-chan time.Time
+	if window := time.Duration(n.batchWindow.Load()); window > 0 {
+		timer := time.NewTimer(window)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for len(props) < limit {
+		select {
+		case p := <-n.proposeCh:
+			props = append(props, p)
+			continue
+		default:
+		}
+		if timeout == nil {
+			break
+		}
+		select {
+		case p := <-n.proposeCh:
+			props = append(props, p)
+			continue
+		case <-timeout:
+		case <-n.stopCh:
+		}
+		break
+	}
+	return props
+}
+
+// appendProposals appends a batch to the log and starts replicating it.
+// Every proposal learns its index, or the error that kept it out of the
+// log, before the loop takes the next batch.
+func (n *Node) appendProposals(props []*Proposal) {
+	live := props[:0]
+	for _, p := range props {
+		if !p.state.CompareAndSwap(proposalQueued, proposalTaken) {
+			// Wait gave up on it already.
+			continue
+		}
+		if err := p.ctx.Err(); err != nil {
+			p.appended <- proposalAppended{err: ErrCommit{Err: err}}
+			continue
+		}
+		live = append(live, p)
+	}
+	props = live
+	if len(props) == 0 {
+		return
+	}
+	fail := func(err error) {
+		for _, p := range props {
+			p.appended <- proposalAppended{err: err}
+		}
+	}
+
+	n.mu.Lock()
+	if n.Role() != Leader {
+		n.mu.Unlock()
+		fail(ErrNotLeader{Leader: n.LeaderID()})
+		return
+	}
+	if n.transferee != "" {
+		n.mu.Unlock()
+		fail(ErrTransferInProgress{})
+		return
+	}
+	entries := make([]LogEntry, len(props))
+	for i, p := range props {
+		p.entry.Index = n.lastLogIndex + 1 + uint64(i)
+		p.entry.Term = n.term
+		entries[i] = p.entry
+	}
+	if err := n.storage.AppendEntries(entries); err != nil {
+		n.mu.Unlock()
+		// Nothing reached the log, so the commands are known not to have
+		// been applied.
+		fail(ErrCommit{Err: err})
+		return
+	}
+	last := entries[len(entries)-1]
+	n.logs = append(n.logs, entries...)
+	n.lastLogIndex = last.Index
+	n.lastLogTerm = last.Term
+	for _, p := range props {
+		n.applyWaiter[p.entry.Index] = p.waiter
+	}
+	n.matchIndex[n.id] = last.Index
+	n.nextIndex[n.id] = last.Index + 1
+	isSingle := n.selfQuorumLocked()
+	if isSingle {
+		n.commitIdx = last.Index
+		metrics.SetRaftCommitIndex(n.commitIdx)
+		n.flushMeta()
+	}
+	n.mu.Unlock()
+	metrics.ObserveRaftProposalBatch(len(props))
+
+	batch := &proposalBatch{done: make(chan struct{})}
+	for _, p := range props {
+		p.appended <- proposalAppended{index: p.entry.Index, batch: batch}
+	}
+	// Replicate in the background so the next batch can be appended while
+	// this one is in flight.
+	go func() {
+		defer close(batch.done)
+		if isSingle {
+			batch.err = n.applyCommittedEntries()
+			n.maybeSnapshot()
+			return
+		}
+		batch.err = n.replicateUntilCommitted(last.Index)
+	}()
+}
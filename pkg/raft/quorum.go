@@ -0,0 +1,88 @@
+package raft
+
+import "slices"
+
+// voterSetsLocked returns the voter sets that each need a majority: the
+// voters, or during a joint configuration both the old and the new voters.
+// Outside a joint configuration this node always counts for itself, so a
+// node that is not in its own peer list still runs as a single-node
+// cluster; in a joint configuration it counts only where it is a member.
+func (n *Node) voterSetsLocked() (sets [][]string, alwaysSelf bool) {
+	if n.joint != nil {
+		return [][]string{n.joint.Old, n.joint.New}, false
+	}
+	return [][]string{n.votersLocked()}, true
+}
+
+// quorumValueLocked returns the highest value reached by a majority of
+// every voter set, where self is this node's value and peer gives another
+// voter's. It reports false if some set cannot reach a majority at all.
+func quorumValueLocked[T any](n *Node, self T, peer func(addr string) T, cmp func(a, b T) int) (T, bool) {
+	var out T
+	found := false
+	sets, alwaysSelf := n.voterSetsLocked()
+	for _, set := range sets {
+		values := make([]T, 0, len(set)+1)
+		counted := alwaysSelf
+		for _, addr := range set {
+			if n.trans.IsSelf(addr) {
+				counted = true
+				continue
+			}
+			values = append(values, peer(addr))
+		}
+		if counted {
+			values = append(values, self)
+		}
+		majority := len(set)/2 + 1
+		if len(values) < majority {
+			return out, false
+		}
+		// Largest first: the majority-th largest value is held by a majority.
+		slices.SortFunc(values, func(a, b T) int { return cmp(b, a) })
+		if v := values[majority-1]; !found || cmp(v, out) < 0 {
+			out, found = v, true
+		}
+	}
+	return out, found
+}
+
+// quorumLocked reports whether this node and the peers in acked form a
+// majority of every voter set.
+func (n *Node) quorumLocked(acked map[string]bool) bool {
+	reached, _ := quorumValueLocked(n, true, func(peer string) bool { return acked[peer] }, compareBool)
+	return reached
+}
+
+// hasQuorum is quorumLocked for callers that do not hold n.mu.
+func (n *Node) hasQuorum(acked map[string]bool) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.quorumLocked(acked)
+}
+
+// selfQuorumLocked reports whether this node alone forms a quorum, so it
+// commits its own entries without replicating them.
+func (n *Node) selfQuorumLocked() bool {
+	return n.quorumLocked(nil)
+}
+
+// canCampaign reports whether this node may stand for election: it is a
+// voter, or it does not know its own address among the peers.
+func (n *Node) canCampaign() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	self := n.trans.SelfAddr()
+	return self == "" || containsPeer(n.votersLocked(), self)
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
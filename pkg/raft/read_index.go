@@ -0,0 +1,98 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// FollowerReadIndex lets any node serve a linearizable read. On the leader
+// it is ReadIndex. A follower asks the leader for a read index over the raft
+// transport, then waits until its own state machine has applied that index,
+// so a read served locally afterwards reflects every write committed before
+// the call.
+func (n *Node) FollowerReadIndex(ctx context.Context) (uint64, error) {
+	if n.Role() == Leader {
+		return n.ReadIndex(ctx)
+	}
+	idx, err := n.requestReadIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := n.waitApplied(ctx, idx, false); err != nil {
+		return 0, err
+	}
+	return idx, nil
+}
+
+// WaitApplied waits, for up to a second, until this node has applied idx,
+// such as the commit index of a client's last write, so a local read
+// afterwards observes it.
+func (n *Node) WaitApplied(ctx context.Context, idx uint64) error {
+	return n.waitApplied(ctx, idx, false)
+}
+
+// requestReadIndex asks the leader for a read index. The leader announces
+// its address in AppendEntries; if it has not, every peer is tried and only
+// the leader answers successfully.
+func (n *Node) requestReadIndex(ctx context.Context) (uint64, error) {
+	targets := n.trans.Peers()
+	if addr, _ := n.leaderAddr.Load().(string); addr != "" {
+		targets = []string{addr}
+	}
+	req := ReadIndexReq{FollowerID: n.id}
+	var lastErr error
+	for _, peer := range targets {
+		if n.trans.IsSelf(peer) {
+			continue
+		}
+		resp, err := n.trans.SendReadIndex(ctx, peer, req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.Success {
+			return resp.Index, nil
+		}
+	}
+	if lastErr != nil {
+		return 0, fmt.Errorf("forward read index: %w", lastErr)
+	}
+	return 0, ErrNotLeader{Leader: n.LeaderID()}
+}
+
+// onReadIndex serves a follower's ReadIndex request. The leader does not
+// wait for its own state machine: the follower waits for its own.
+func (n *Node) onReadIndex(ctx context.Context, req ReadIndexReq) ReadIndexResp {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	idx, err := n.confirmReadIndex(ctx)
+	n.mu.Lock()
+	term := n.term
+	n.mu.Unlock()
+	if err != nil {
+		return ReadIndexResp{Term: term, LeaderID: n.LeaderID()}
+	}
+	return ReadIndexResp{Term: term, Index: idx, Success: true, LeaderID: n.id}
+}
+
+// CaughtUp reports whether the node can serve reads without a long wait:
+// the leader always can, and a follower can while it hears from a leader
+// within the election timeout and has applied everything it knows to be
+// committed.
+func (n *Node) CaughtUp() bool {
+	switch n.Role() {
+	case Leader:
+		return true
+	case Follower:
+	default:
+		return false
+	}
+	if n.LeaderID() == "" || time.Now().UnixNano() > atomic.LoadInt64(&n.electionDeadline) {
+		return false
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.lastApply >= n.commitIdx
+}
@@ -11,22 +11,33 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"github.com/lushenle/simple-cache/pkg/encrypt"
 )
 
 type Storage struct {
 	path string
 	mu   sync.Mutex
+	keys *encrypt.Keyring // encrypts WAL entries and snapshots; nil writes plaintext
 }
 
 func NewStorage(path string) *Storage { return &Storage{path: path} }
 
+// UseKeyring encrypts WAL entries and snapshot files written from now on
+// with the active key of k, and lets reads decrypt data written with any
+// key in k. Plaintext data written before still reads. It must be called
+// before the storage is used.
+func (s *Storage) UseKeyring(k *encrypt.Keyring) { s.keys = k }
+
 type Meta struct {
-	CurrentTerm   uint64   `json:"current_term"`
-	VotedFor      string   `json:"voted_for"`
-	CommitIndex   uint64   `json:"commit_index"`
-	Peers         []string `json:"peers,omitempty"`
-	SnapshotIndex uint64   `json:"snapshot_index"`
-	SnapshotTerm  uint64   `json:"snapshot_term"`
+	CurrentTerm   uint64        `json:"current_term"`
+	VotedFor      string        `json:"voted_for"`
+	CommitIndex   uint64        `json:"commit_index"`
+	Peers         []string      `json:"peers,omitempty"`
+	Learners      []string      `json:"learners,omitempty"` // subset of Peers that do not vote
+	Joint         *ConfigChange `json:"joint,omitempty"`    // set while in a joint configuration
+	SnapshotIndex uint64        `json:"snapshot_index"`
+	SnapshotTerm  uint64        `json:"snapshot_term"`
 }
 
 type snapshotFile struct {
@@ -41,6 +52,11 @@ func (s *Storage) AppendEntry(entry LogEntry) error {
 // binaryWALVersion marks the binary format version.
 const binaryWALVersion byte = 1
 
+// encryptedEntryMarker starts an encrypted WAL record:
+// marker | length uint32 | sealed binary entry. A plaintext entry starts
+// with the high byte of its index, which never reaches the marker.
+const encryptedEntryMarker byte = 0xEC
+
 // encodeEntryBinary serializes a single log entry to binary.
 func encodeEntryBinary(e LogEntry) []byte {
 	buf := make([]byte, 0, 32+len(e.Type)+len(e.Data))
@@ -86,10 +102,21 @@ func decodeEntryBinary(data []byte) (LogEntry, []byte, error) {
 	return entry, data[offset:], nil
 }
 
-// appendEntriesBinary writes entries in binary format to the WAL file.
-func appendEntriesBinary(f *os.File, entries []LogEntry) error {
+// appendEntriesBinary writes entries in binary format to the WAL file,
+// sealing each one with keys if set.
+func appendEntriesBinary(f *os.File, entries []LogEntry, keys *encrypt.Keyring) error {
 	for _, entry := range entries {
 		b := encodeEntryBinary(entry)
+		if keys != nil {
+			sealed, err := keys.Seal(b)
+			if err != nil {
+				return err
+			}
+			b = make([]byte, 0, 5+len(sealed))
+			b = append(b, encryptedEntryMarker)
+			b = binary.BigEndian.AppendUint32(b, uint32(len(sealed)))
+			b = append(b, sealed...)
+		}
 		if _, err := f.Write(b); err != nil {
 			return err
 		}
@@ -106,11 +133,31 @@ func isBinaryWAL(data []byte) bool {
 	return data[0] != '{'
 }
 
-// loadEntriesBinary reads all entries from binary WAL data.
-func loadEntriesBinary(data []byte) ([]LogEntry, error) {
+// loadEntriesBinary reads all entries from binary WAL data, decrypting
+// encrypted records with keys.
+func loadEntriesBinary(data []byte, keys *encrypt.Keyring) ([]LogEntry, error) {
 	var entries []LogEntry
 	remaining := data
 	for len(remaining) > 0 {
+		if remaining[0] == encryptedEntryMarker {
+			if len(remaining) < 5 || uint64(len(remaining)) < 5+uint64(binary.BigEndian.Uint32(remaining[1:5])) {
+				return entries, nil // torn tail, as below
+			}
+			end := 5 + int(binary.BigEndian.Uint32(remaining[1:5]))
+			// A complete record that fails to decrypt is corruption or a
+			// missing key, not a torn write.
+			plain, err := keys.Open(remaining[5:end])
+			if err != nil {
+				return nil, fmt.Errorf("decode wal entry %d: %w", len(entries), err)
+			}
+			entry, rest, err := decodeEntryBinary(plain)
+			if err != nil || len(rest) != 0 {
+				return nil, fmt.Errorf("decode wal entry %d: malformed encrypted entry", len(entries))
+			}
+			entries = append(entries, entry)
+			remaining = remaining[end:]
+			continue
+		}
 		entry, rest, err := decodeEntryBinary(remaining)
 		if err != nil {
 			// Torn tail left by a crash mid-append: drop the partial record
@@ -165,7 +212,7 @@ func (s *Storage) AppendEntries(entries []LogEntry) error {
 	}
 	defer f.Close()
 
-	if err := appendEntriesBinary(f, entries); err != nil {
+	if err := appendEntriesBinary(f, entries, s.keys); err != nil {
 		return err
 	}
 	if err := f.Sync(); err != nil {
@@ -201,7 +248,7 @@ func (s *Storage) LoadEntries() ([]LogEntry, error) {
 	}
 
 	if isBinaryWAL(data) {
-		return loadEntriesBinary(data)
+		return loadEntriesBinary(data, s.keys)
 	}
 	return loadEntriesJSON(data)
 }
@@ -220,7 +267,7 @@ func (s *Storage) RewriteEntries(entries []LogEntry) error {
 		return err
 	}
 
-	if err := appendEntriesBinary(f, entries); err != nil {
+	if err := appendEntriesBinary(f, entries, s.keys); err != nil {
 		_ = f.Close()
 		_ = os.Remove(tmpPath)
 		return err
@@ -334,76 +381,235 @@ func (s *Storage) SaveMeta(m *Meta) error {
 	return syncDir(filepath.Dir(mp))
 }
 
-func (s *Storage) SaveSnapshot(meta SnapshotMeta, data []byte) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// snapshotMagic starts a streamed snapshot file: magic, uint32 meta
+// length, meta JSON, then the raw FSM data. Older snapshot files are a
+// single JSON object with the data inlined and are still readable. With a
+// keyring the whole file is written as an encrypted stream.
+const snapshotMagic = "RSNP"
+
+// SnapshotSink receives snapshot data for a given meta and installs it as
+// the current snapshot on Commit. The data is written to a temp file so it
+// never has to be held in memory.
+type SnapshotSink struct {
+	s       *Storage
+	f       *os.File
+	w       *bufio.Writer
+	enc     *encrypt.Writer // nil unless the snapshot is encrypted
+	out     io.Writer
+	tmpPath string
+	size    int64
+}
 
-	path := s.snapshotPath()
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
-	}
+// CreateSnapshot starts writing a new snapshot for meta.
+func (s *Storage) CreateSnapshot(meta SnapshotMeta) (*SnapshotSink, error) {
+	return s.createSnapshotSink(meta, s.snapshotPath()+".tmp")
+}
 
-	tmpPath := path + ".tmp"
-	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+// receiveSnapshot is CreateSnapshot for a snapshot arriving from the leader.
+// It uses its own temp file so it cannot collide with a local snapshot.
+func (s *Storage) receiveSnapshot(meta SnapshotMeta) (*SnapshotSink, error) {
+	return s.createSnapshotSink(meta, s.snapshotPath()+".recv")
+}
+
+func (s *Storage) createSnapshotSink(meta SnapshotMeta, tmpPath string) (*SnapshotSink, error) {
+	if err := os.MkdirAll(filepath.Dir(tmpPath), 0o755); err != nil {
+		return nil, err
+	}
+	metaJSON, err := json.Marshal(meta)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	payload, err := json.Marshal(snapshotFile{
-		Meta: meta,
-		Data: data,
-	})
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o644)
 	if err != nil {
-		_ = f.Close()
-		_ = os.Remove(tmpPath)
-		return err
+		return nil, err
 	}
-	if _, err := f.Write(payload); err != nil {
-		_ = f.Close()
-		_ = os.Remove(tmpPath)
+	w := bufio.NewWriterSize(f, 1<<20)
+	k := &SnapshotSink{s: s, f: f, w: w, out: w, tmpPath: tmpPath}
+	if s.keys != nil {
+		if k.enc, err = s.keys.NewWriter(w); err != nil {
+			k.Abort()
+			return nil, err
+		}
+		k.out = k.enc
+	}
+	hdr := make([]byte, 0, len(snapshotMagic)+4+len(metaJSON))
+	hdr = append(hdr, snapshotMagic...)
+	hdr = binary.BigEndian.AppendUint32(hdr, uint32(len(metaJSON)))
+	hdr = append(hdr, metaJSON...)
+	if _, err := k.out.Write(hdr); err != nil {
+		k.Abort()
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *SnapshotSink) Write(p []byte) (int, error) {
+	n, err := k.out.Write(p)
+	k.size += int64(n)
+	return n, err
+}
+
+// Size returns the number of data bytes written so far.
+func (k *SnapshotSink) Size() int64 { return k.size }
+
+// flush ends the encrypted stream, if any, and flushes buffered data to
+// the temp file.
+func (k *SnapshotSink) flush() error {
+	if k.enc != nil {
+		if err := k.enc.Close(); err != nil {
+			return err
+		}
+	}
+	return k.w.Flush()
+}
+
+// Reader flushes the sink and returns a reader over the data written so
+// far. Nothing may be written to an encrypted sink after calling it.
+func (k *SnapshotSink) Reader() (io.ReadCloser, error) {
+	if err := k.flush(); err != nil {
+		return nil, err
+	}
+	_, r, _, err := openSnapshotFile(k.tmpPath, k.s.keys)
+	return r, err
+}
+
+// Commit makes the written snapshot durable and replaces the current one.
+func (k *SnapshotSink) Commit() error {
+	if err := k.flush(); err != nil {
+		k.Abort()
 		return err
 	}
-	if err := f.Sync(); err != nil {
-		_ = f.Close()
-		_ = os.Remove(tmpPath)
+	if err := k.f.Sync(); err != nil {
+		k.Abort()
 		return err
 	}
-	if err := f.Close(); err != nil {
-		_ = os.Remove(tmpPath)
+	if err := k.f.Close(); err != nil {
+		_ = os.Remove(k.tmpPath)
 		return err
 	}
-	if err := os.Rename(tmpPath, path); err != nil {
-		_ = os.Remove(tmpPath)
+
+	k.s.mu.Lock()
+	defer k.s.mu.Unlock()
+	path := k.s.snapshotPath()
+	if err := os.Rename(k.tmpPath, path); err != nil {
+		_ = os.Remove(k.tmpPath)
 		return err
 	}
 	return syncDir(filepath.Dir(path))
 }
 
-func (s *Storage) LoadSnapshot() (*SnapshotMeta, []byte, error) {
+// Abort discards the sink.
+func (k *SnapshotSink) Abort() {
+	_ = k.f.Close()
+	_ = os.Remove(k.tmpPath)
+}
+
+func (s *Storage) SaveSnapshot(meta SnapshotMeta, data []byte) error {
+	sink, err := s.CreateSnapshot(meta)
+	if err != nil {
+		return err
+	}
+	if _, err := sink.Write(data); err != nil {
+		sink.Abort()
+		return err
+	}
+	return sink.Commit()
+}
+
+// OpenSnapshot returns the current snapshot's meta, a reader over its data
+// and the data size. It returns a nil meta if there is no snapshot.
+func (s *Storage) OpenSnapshot() (*SnapshotMeta, io.ReadCloser, int64, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	f, err := os.Open(s.snapshotPath())
+	meta, r, size, err := openSnapshotFile(s.snapshotPath(), s.keys)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, 0, nil
+	}
+	return meta, r, size, err
+}
+
+func openSnapshotFile(path string, keys *encrypt.Keyring) (*SnapshotMeta, io.ReadCloser, int64, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, nil, nil
+		return nil, nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, 0, err
+	}
+
+	var r io.Reader
+	br := bufio.NewReader(f)
+	head, err := br.Peek(len(snapshotMagic))
+	size := info.Size()
+	switch {
+	case err == nil && encrypt.IsEncrypted(head):
+		er, err := encrypt.NewReader(br, keys)
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, 0, fmt.Errorf("open snapshot: %w", err)
 		}
-		return nil, nil, err
+		r, size = er, er.PlaintextSize(size)
+	case err != nil || string(head) != snapshotMagic:
+		// Legacy JSON snapshot file.
+		defer f.Close()
+		var payload snapshotFile
+		if err := json.NewDecoder(br).Decode(&payload); err != nil {
+			return nil, nil, 0, err
+		}
+		meta := payload.Meta
+		return &meta, io.NopCloser(bytes.NewReader(payload.Data)), int64(len(payload.Data)), nil
+	default:
+		r = br
 	}
-	defer f.Close()
 
+	hdr := make([]byte, len(snapshotMagic)+4)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		_ = f.Close()
+		return nil, nil, 0, err
+	}
+	if string(hdr[:len(snapshotMagic)]) != snapshotMagic {
+		_ = f.Close()
+		return nil, nil, 0, fmt.Errorf("corrupt snapshot file: bad magic")
+	}
+	metaLen := binary.BigEndian.Uint32(hdr[len(snapshotMagic):])
+	if int64(len(hdr))+int64(metaLen) > size {
+		_ = f.Close()
+		return nil, nil, 0, fmt.Errorf("corrupt snapshot file: meta length %d", metaLen)
+	}
+	metaJSON := make([]byte, metaLen)
+	if _, err := io.ReadFull(r, metaJSON); err != nil {
+		_ = f.Close()
+		return nil, nil, 0, err
+	}
 	var meta SnapshotMeta
-	var payload snapshotFile
-	dec := json.NewDecoder(f)
-	if err := dec.Decode(&payload); err != nil {
+	if err := json.Unmarshal(metaJSON, &meta); err != nil {
+		_ = f.Close()
+		return nil, nil, 0, fmt.Errorf("corrupt snapshot meta: %w", err)
+	}
+	size -= int64(len(hdr)) + int64(metaLen)
+	return &meta, struct {
+		io.Reader
+		io.Closer
+	}{r, f}, size, nil
+}
+
+func (s *Storage) LoadSnapshot() (*SnapshotMeta, []byte, error) {
+	meta, r, size, err := s.OpenSnapshot()
+	if err != nil || meta == nil {
 		return nil, nil, err
 	}
-	meta = payload.Meta
-	data := payload.Data
-	if len(data) == 0 {
-		return &meta, nil, nil
+	defer r.Close()
+	if size == 0 {
+		return meta, nil, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
 	}
-	return &meta, data, nil
+	return meta, data, nil
 }
 
 func (s *Storage) HasSnapshot() bool {
@@ -2,10 +2,13 @@ package raft
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/lushenle/simple-cache/pkg/encrypt"
 	"github.com/stretchr/testify/require"
 )
 
@@ -126,3 +129,119 @@ func TestStorageCompactLog(t *testing.T) {
 	require.Equal(t, uint64(3), entries[0].Index)
 	require.Equal(t, uint64(4), entries[1].Index)
 }
+
+func TestStorageSnapshotSinkAndLegacyFile(t *testing.T) {
+	st := NewStorage(filepath.Join(t.TempDir(), "raft.wal"))
+
+	// A snapshot written by older versions is a single JSON object.
+	legacy, err := json.Marshal(snapshotFile{
+		Meta: SnapshotMeta{LastIncludedIndex: 3, LastIncludedTerm: 1},
+		Data: []byte("old-data"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(st.snapshotPath(), legacy, 0o644))
+	meta, data, err := st.LoadSnapshot()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), meta.LastIncludedIndex)
+	require.Equal(t, []byte("old-data"), data)
+
+	sink, err := st.CreateSnapshot(SnapshotMeta{LastIncludedIndex: 9, LastIncludedTerm: 2})
+	require.NoError(t, err)
+	_, err = sink.Write([]byte("new-"))
+	require.NoError(t, err)
+	_, err = sink.Write([]byte("data"))
+	require.NoError(t, err)
+	// Aborting a second sink must not disturb the committed snapshot.
+	other, err := st.receiveSnapshot(SnapshotMeta{LastIncludedIndex: 10})
+	require.NoError(t, err)
+	other.Abort()
+	require.NoError(t, sink.Commit())
+
+	meta, r, size, err := st.OpenSnapshot()
+	require.NoError(t, err)
+	defer r.Close()
+	require.Equal(t, uint64(9), meta.LastIncludedIndex)
+	require.Equal(t, int64(8), size)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, []byte("new-data"), got)
+}
+
+func TestStorageEncryptedWALAndSnapshot(t *testing.T) {
+	const (
+		oldKey = "old:000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+		newKey = "new:1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+	)
+	path := filepath.Join(t.TempDir(), "raft.wal")
+	st := NewStorage(path)
+	// Entries written before encryption was enabled stay readable.
+	require.NoError(t, st.AppendEntries([]LogEntry{{Index: 1, Term: 1, Type: EntryTypeCommand, Data: []byte("plain")}}))
+
+	old, err := encrypt.ParseKeyring(oldKey)
+	require.NoError(t, err)
+	st = NewStorage(path)
+	st.UseKeyring(old)
+	require.NoError(t, st.AppendEntries([]LogEntry{{Index: 2, Term: 1, Type: EntryTypeCommand, Data: []byte("secret-entry")}}))
+	sink, err := st.CreateSnapshot(SnapshotMeta{LastIncludedIndex: 1, LastIncludedTerm: 1})
+	require.NoError(t, err)
+	_, err = sink.Write([]byte("secret-snapshot"))
+	require.NoError(t, err)
+	r, err := sink.Reader()
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, []byte("secret-snapshot"), got)
+	require.NoError(t, sink.Commit())
+
+	for _, p := range []string{path, st.snapshotPath()} {
+		raw, err := os.ReadFile(p)
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(raw, []byte("secret")), p)
+	}
+
+	rotated, err := encrypt.ParseKeyring(newKey + "\n" + oldKey)
+	require.NoError(t, err)
+	st = NewStorage(path)
+	st.UseKeyring(rotated)
+	require.NoError(t, st.AppendEntries([]LogEntry{{Index: 3, Term: 2, Type: EntryTypeCommand, Data: []byte("c")}}))
+	entries, err := st.LoadEntries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, []byte("plain"), entries[0].Data)
+	require.Equal(t, []byte("secret-entry"), entries[1].Data)
+	require.Equal(t, uint64(3), entries[2].Index)
+
+	meta, r, size, err := st.OpenSnapshot()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), meta.LastIncludedIndex)
+	require.Equal(t, int64(len("secret-snapshot")), size)
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, []byte("secret-snapshot"), got)
+
+	// Without the key the data cannot be read back.
+	st = NewStorage(path)
+	_, err = st.LoadEntries()
+	require.ErrorIs(t, err, encrypt.ErrNoKey)
+	_, _, _, err = st.OpenSnapshot()
+	require.ErrorIs(t, err, encrypt.ErrNoKey)
+}
+
+func TestStorageSnapshotBackupIsTheSnapshotFile(t *testing.T) {
+	st := NewStorage(filepath.Join(t.TempDir(), "raft.wal"))
+	require.NoError(t, st.SaveSnapshot(SnapshotMeta{LastIncludedIndex: 7, LastIncludedTerm: 2}, []byte("state")))
+
+	b, err := st.openSnapshotBackup()
+	require.NoError(t, err)
+	got, err := io.ReadAll(b)
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+	want, err := os.ReadFile(st.snapshotPath())
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Equal(t, int64(len(want)), b.Size)
+	require.Equal(t, uint64(7), b.Meta.LastIncludedIndex)
+	require.FileExists(t, st.snapshotPath())
+}
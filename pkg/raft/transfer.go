@@ -0,0 +1,199 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrTransferInProgress is returned for proposals and membership changes
+// while the leader hands leadership over, and for a second transfer.
+type ErrTransferInProgress struct{}
+
+func (e ErrTransferInProgress) Error() string { return "leadership transfer in progress" }
+
+// GRPCStatus maps ErrTransferInProgress to codes.Unavailable: the client
+// should retry against the new leader.
+func (e ErrTransferInProgress) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// ErrTransferFailed is returned when the target of a leadership transfer
+// did not take over in time. The leader keeps leading and accepts
+// proposals again.
+type ErrTransferFailed struct {
+	Target string
+	Err    error
+}
+
+func (e ErrTransferFailed) Error() string {
+	return "leadership transfer to " + e.Target + " failed: " + e.Err.Error()
+}
+
+func (e ErrTransferFailed) Unwrap() error { return e.Err }
+
+// errTransferTimeout is the ErrTransferFailed cause when the target did not
+// win an election within an election timeout.
+var errTransferTimeout = errors.New("target did not take over within an election timeout")
+
+// TransferLeadership hands leadership to target, a voter's node ID or raft
+// address. The leader stops accepting proposals, brings the target's log
+// up to date and sends it TimeoutNow so that it campaigns at once instead
+// of after an election timeout. If the target has not taken over within
+// an election timeout (or ctx is done first) the transfer is aborted with
+// ErrTransferFailed and this node keeps leading.
+func (n *Node) TransferLeadership(ctx context.Context, target string) error {
+	if n.Role() != Leader {
+		return ErrNotLeader{Leader: n.LeaderID()}
+	}
+	deadline := time.Now().Add(n.el)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	addr, err := func() (string, error) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if n.transferee != "" {
+			return "", ErrTransferInProgress{}
+		}
+		addr, self, err := n.resolvePeerLocked(target)
+		if err != nil || self {
+			return "", err
+		}
+		if !containsPeer(n.votersLocked(), addr) {
+			// Learners cannot be elected.
+			return "", ErrInvalidPeerChange{}
+		}
+		n.transferee = addr
+		return addr, nil
+	}()
+	if err != nil || addr == "" {
+		return err
+	}
+	defer func() {
+		n.mu.Lock()
+		n.transferee = ""
+		n.mu.Unlock()
+	}()
+	fail := func(err error) error {
+		if n.logger != nil {
+			n.logger.Info("leadership transfer aborted", zap.String("node", n.id), zap.String("target", addr), zap.Error(err))
+		}
+		return ErrTransferFailed{Target: target, Err: err}
+	}
+
+	// No entries are appended from here on, so the target catches up.
+	var term uint64
+	for {
+		if n.Role() != Leader {
+			return ErrNotLeader{Leader: n.LeaderID()}
+		}
+		n.mu.Lock()
+		caughtUp := n.matchIndex[addr] >= n.lastLogIndex
+		term = n.term
+		n.mu.Unlock()
+		if caughtUp {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			return fail(errTransferTimeout)
+		}
+		n.replicateAllWithDeadline(deadline)
+		if err := n.sleepUntil(ctx, deadline, 10*time.Millisecond); err != nil {
+			return fail(err)
+		}
+	}
+
+	// From here on the target may campaign even if the transfer is
+	// aborted, and voters grant a transfer vote regardless of this leader's
+	// heartbeats, so an abort must not leave the lease standing.
+	fail = func(err error) error {
+		n.mu.Lock()
+		n.fenceLeaseLocked(time.Now())
+		n.mu.Unlock()
+		if n.logger != nil {
+			n.logger.Info("leadership transfer aborted", zap.String("node", n.id), zap.String("target", addr), zap.Error(err))
+		}
+		return ErrTransferFailed{Target: target, Err: err}
+	}
+	tctx, cancel := context.WithDeadline(ctx, deadline)
+	resp, err := n.trans.SendTimeoutNow(tctx, addr, TimeoutNowReq{Term: term, LeaderID: n.id})
+	cancel()
+	if err != nil {
+		return fail(err)
+	}
+	if !resp.Success && resp.Term <= term {
+		return fail(errors.New("target refused to campaign"))
+	}
+	if n.logger != nil {
+		n.logger.Info("leadership transfer started", zap.String("node", n.id), zap.String("target", addr), zap.Uint64("term", term))
+	}
+
+	// The target's election, at a higher term, deposes this leader.
+	for n.Role() == Leader {
+		if !time.Now().Before(deadline) {
+			return fail(errTransferTimeout)
+		}
+		if err := n.sleepUntil(ctx, deadline, 10*time.Millisecond); err != nil {
+			return fail(err)
+		}
+	}
+	return nil
+}
+
+// sleepUntil waits for d, but not past deadline. It returns ctx's error if
+// ctx is done first, or ErrCommit if the node stops.
+func (n *Node) sleepUntil(ctx context.Context, deadline time.Time, d time.Duration) error {
+	if rest := time.Until(deadline); rest < d {
+		d = max(rest, 0)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-n.stopCh:
+		return ErrCommit{}
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// resolvePeerLocked maps target, a node ID or a peer address, to the peer's
+// address. self reports that target is this node.
+func (n *Node) resolvePeerLocked(target string) (addr string, self bool, err error) {
+	if target == n.id {
+		return "", true, nil
+	}
+	peers := n.trans.Peers()
+	if normalized, err := NormalizePeerAddr(target); err == nil && containsPeer(peers, normalized) {
+		return normalized, n.trans.IsSelf(normalized), nil
+	}
+	for peer, id := range n.peerIDs {
+		if id == target && containsPeer(peers, peer) {
+			return peer, false, nil
+		}
+	}
+	return "", false, ErrPeerNotFound{}
+}
+
+// onTimeoutNow starts an election right away on behalf of the leader that
+// is transferring leadership to this node.
+func (n *Node) onTimeoutNow(req TimeoutNowReq) TimeoutNowResp {
+	n.mu.Lock()
+	term := n.term
+	ok := req.Term == term && n.Role() == Follower
+	n.mu.Unlock()
+	if !ok || !n.canCampaign() {
+		return TimeoutNowResp{Term: term}
+	}
+	if n.logger != nil {
+		n.logger.Info("timeout now, campaigning", zap.String("node", n.id), zap.String("leader", req.LeaderID), zap.Uint64("term", term))
+	}
+	// The election loop picks this up on its next tick, regardless of the
+	// leader's heartbeats still arriving until the election.
+	n.campaignNow.Store(true)
+	return TimeoutNowResp{Term: term, Success: true}
+}
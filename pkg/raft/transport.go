@@ -0,0 +1,135 @@
+package raft
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"time"
+)
+
+// Transport carries raft RPCs between the nodes of a cluster and keeps the
+// node's view of its peers. HTTPTransport is the default; MemTransport runs
+// a whole cluster in one process for tests.
+type Transport interface {
+	// Start begins delivering RPCs addressed to this node to h.
+	Start(h RPCHandler)
+	// Close stops serving and releases connections to peers.
+	Close()
+
+	// Peers returns a copy of the peer addresses, this node included.
+	Peers() []string
+	// AddPeer and RemovePeer change the peer list and report whether it
+	// changed.
+	AddPeer(addr string) bool
+	RemovePeer(addr string) bool
+	// IsSelf reports whether peer is this node's own address.
+	IsSelf(peer string) bool
+	// SelfAddr returns this node's own address from the peer list, or ""
+	// if it is not known.
+	SelfAddr() string
+
+	SendAppend(ctx context.Context, peer string, req AppendEntriesReq) (AppendEntriesResp, error)
+	SendVote(ctx context.Context, peer string, req RequestVoteReq) (RequestVoteResp, error)
+	SendInstallSnapshot(ctx context.Context, peer string, req InstallSnapshotReq) (InstallSnapshotResp, error)
+	SendReadIndex(ctx context.Context, peer string, req ReadIndexReq) (ReadIndexResp, error)
+	SendTimeoutNow(ctx context.Context, peer string, req TimeoutNowReq) (TimeoutNowResp, error)
+}
+
+// RPCHandler processes raft RPCs received by a Transport. *Node implements
+// it.
+type RPCHandler interface {
+	HandleAppendEntries(req AppendEntriesReq) AppendEntriesResp
+	HandleRequestVote(req RequestVoteReq) RequestVoteResp
+	HandleInstallSnapshot(req InstallSnapshotReq) InstallSnapshotResp
+	HandleReadIndex(ctx context.Context, req ReadIndexReq) ReadIndexResp
+	HandleTimeoutNow(req TimeoutNowReq) TimeoutNowResp
+}
+
+// HandleAppendEntries implements RPCHandler.
+func (n *Node) HandleAppendEntries(req AppendEntriesReq) AppendEntriesResp {
+	return n.onAppendEntries(req)
+}
+
+// HandleRequestVote implements RPCHandler.
+func (n *Node) HandleRequestVote(req RequestVoteReq) RequestVoteResp {
+	return n.onRequestVote(req)
+}
+
+// HandleInstallSnapshot implements RPCHandler.
+func (n *Node) HandleInstallSnapshot(req InstallSnapshotReq) InstallSnapshotResp {
+	return n.onInstallSnapshot(req)
+}
+
+// HandleReadIndex implements RPCHandler.
+func (n *Node) HandleReadIndex(ctx context.Context, req ReadIndexReq) ReadIndexResp {
+	return n.onReadIndex(ctx, req)
+}
+
+// HandleTimeoutNow implements RPCHandler.
+func (n *Node) HandleTimeoutNow(req TimeoutNowReq) TimeoutNowResp {
+	return n.onTimeoutNow(req)
+}
+
+// NodeOption configures optional Node behaviour.
+type NodeOption func(*nodeOptions)
+
+type nodeOptions struct {
+	trans   Transport
+	grpc    bool
+	tls     *tls.Config
+	learner bool
+	// maxSnapshot is set by WithMaxSnapshotSize; 0 means the default.
+	maxSnapshot int64
+}
+
+// WithTransport makes the node use t instead of an HTTPTransport on its
+// address. The node closes t when it is closed. WithGRPCTransport and
+// WithTransportTLS only configure the HTTPTransport and are ignored.
+func WithTransport(t Transport) NodeOption {
+	return func(o *nodeOptions) { o.trans = t }
+}
+
+// WithGRPCTransport sends raft RPCs to peers over gRPC, with one long-lived
+// AppendEntries stream per peer, instead of JSON over HTTP/1.1. Every node
+// serves both on its raft address, so nodes with either setting can run in
+// one cluster; a peer that does not answer gRPC is sent HTTP.
+func WithGRPCTransport() NodeOption {
+	return func(o *nodeOptions) { o.grpc = true }
+}
+
+// WithTransportTLS serves and sends raft RPCs over TLS with cfg, as built by
+// LoadTransportTLS. Peers must then be addressed as https:// URLs.
+func WithTransportTLS(cfg *tls.Config) NodeOption {
+	return func(o *nodeOptions) { o.tls = cfg }
+}
+
+// broadcastVote asks every other voter for its vote and returns those that
+// granted it.
+func (n *Node) broadcastVote(req RequestVoteReq) map[string]bool {
+	peers := n.voters()
+	var mu sync.Mutex
+	granted := make(map[string]bool, len(peers))
+	var wg sync.WaitGroup
+	for _, p := range peers {
+		if n.trans.IsSelf(p) {
+			continue
+		}
+		wg.Add(1)
+		go func(peer string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			out, err := n.trans.SendVote(ctx, peer, req)
+			cancel()
+			if err != nil {
+				return
+			}
+			if out.VoteGranted {
+				mu.Lock()
+				granted[peer] = true
+				mu.Unlock()
+			}
+		}(p)
+	}
+	wg.Wait()
+	return granted
+}
@@ -14,9 +14,24 @@ const (
 	EntryTypeCommand    EntryType = "command"
 	EntryTypeAddPeer    EntryType = "add_peer"
 	EntryTypeRemovePeer EntryType = "remove_peer"
-	EntryTypeNoop       EntryType = "noop"
+	// EntryTypePromotePeer turns a learner into a voter.
+	EntryTypePromotePeer EntryType = "promote_peer"
+	// EntryTypeJointConfig enters the joint configuration C_old,new and
+	// EntryTypeFinalConfig leaves it for C_new.
+	EntryTypeJointConfig EntryType = "joint_config"
+	EntryTypeFinalConfig EntryType = "final_config"
+	EntryTypeNoop        EntryType = "noop"
 )
 
+// isMembershipEntry reports whether entries of type t change the peer set.
+func isMembershipEntry(t EntryType) bool {
+	switch t {
+	case EntryTypeAddPeer, EntryTypeRemovePeer, EntryTypePromotePeer, EntryTypeJointConfig, EntryTypeFinalConfig:
+		return true
+	}
+	return false
+}
+
 type LogEntry struct {
 	Index uint64    `json:"index"`
 	Term  uint64    `json:"term"`
@@ -26,6 +41,15 @@ type LogEntry struct {
 
 type PeerChange struct {
 	Addr string `json:"addr"`
+	// Learner adds the peer as a non-voting learner (add_peer only).
+	Learner bool `json:"learner,omitempty"`
+}
+
+// ConfigChange is the payload of joint_config and final_config entries.
+// Old and New are voter sets; Old is empty in final_config.
+type ConfigChange struct {
+	Old []string `json:"old,omitempty"`
+	New []string `json:"new"`
 }
 
 type AppendEntriesReq struct {
@@ -35,6 +59,10 @@ type AppendEntriesReq struct {
 	PrevLogTerm  uint64
 	Entries      []LogEntry
 	CommitIdx    uint64
+	// LeaderAddr is the leader's raft transport address, which followers
+	// use to forward ReadIndex requests. Empty if the leader does not know
+	// its own address.
+	LeaderAddr string `json:"leader_addr,omitempty"`
 }
 
 type AppendEntriesResp struct {
@@ -42,6 +70,9 @@ type AppendEntriesResp struct {
 	Success      bool
 	MatchIndex   uint64
 	LastLogIndex uint64
+	// NodeID is the follower's node ID, so the leader can address it by ID
+	// in TransferLeadership.
+	NodeID string `json:"node_id,omitempty"`
 }
 
 type InstallSnapshotReq struct {
@@ -54,6 +85,11 @@ type InstallSnapshotReq struct {
 	// final chunk. Chunks must arrive in order (P2-14).
 	Offset uint64 `json:"offset,omitempty"`
 	Done   bool   `json:"done,omitempty"`
+	// Peers, Learners and Joint carry the snapshot's membership, see
+	// SnapshotMeta.
+	Peers    []string      `json:"peers,omitempty"`
+	Learners []string      `json:"learners,omitempty"`
+	Joint    *ConfigChange `json:"joint,omitempty"`
 }
 
 type InstallSnapshotResp struct {
@@ -69,6 +105,10 @@ type RequestVoteReq struct {
 	// PreVote marks a pre-vote request (Raft PreVote): the receiver checks
 	// term and log freshness without mutating any state.
 	PreVote bool `json:"pre_vote,omitempty"`
+	// LeadershipTransfer marks an election started on the leader's
+	// TimeoutNow; voters grant it even if they heard from the leader within
+	// the election timeout.
+	LeadershipTransfer bool `json:"leadership_transfer,omitempty"`
 }
 
 type RequestVoteResp struct {
@@ -76,7 +116,44 @@ type RequestVoteResp struct {
 	VoteGranted bool
 }
 
+// ReadIndexReq asks the leader for a read index on behalf of a follower.
+type ReadIndexReq struct {
+	FollowerID string
+}
+
+// ReadIndexResp carries the leader's commit index once it has confirmed its
+// leadership with a quorum. Success is false if the receiver is not the
+// leader or could not confirm it; LeaderID then names the leader if known.
+type ReadIndexResp struct {
+	Term     uint64
+	Index    uint64
+	Success  bool
+	LeaderID string
+}
+
+// TimeoutNowReq tells the target of a leadership transfer, which the leader
+// has brought up to date, to start an election right away.
+type TimeoutNowReq struct {
+	Term     uint64
+	LeaderID string
+}
+
+type TimeoutNowResp struct {
+	Term    uint64
+	Success bool
+}
+
 type SnapshotMeta struct {
 	LastIncludedIndex uint64 `json:"last_included_index"`
 	LastIncludedTerm  uint64 `json:"last_included_term"`
+	// NodeID and CreatedAt (RFC 3339) record where and when a backup was
+	// taken. They are empty in ordinary snapshots.
+	NodeID    string `json:"node_id,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	// Peers, Learners and Joint are the membership as of LastIncludedIndex,
+	// since the membership entries before it are compacted out of the log.
+	// Peers is empty in backups and in snapshots that predate it.
+	Peers    []string      `json:"peers,omitempty"`
+	Learners []string      `json:"learners,omitempty"`
+	Joint    *ConfigChange `json:"joint,omitempty"`
 }
@@ -3,16 +3,20 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	gwruntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/lushenle/simple-cache/pkg/config"
 	"github.com/lushenle/simple-cache/pkg/pb"
 	"github.com/lushenle/simple-cache/pkg/utils"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc/status"
 )
 
 // AdminHandler serves the admin API endpoints used by the frontend SPA.
@@ -35,6 +39,8 @@ func (h *AdminHandler) Register(mux *http.ServeMux) {
 	mux.HandleFunc("/admin/api/subscriptions/", h.subscriptionByID)
 	mux.HandleFunc("/admin/api/cluster/nodes", h.clusterNodes)
 	mux.HandleFunc("/admin/api/raft", h.raftState)
+	mux.HandleFunc("/admin/api/load", h.loadProgress)
+	mux.HandleFunc("/admin/api/backup", h.backup)
 	mux.HandleFunc("/admin/api/keys/stats", h.keysStats)
 	mux.HandleFunc("/admin/api/set/", h.adminSet)
 	mux.HandleFunc("/admin/api/watch", h.watchSSE)
@@ -447,6 +453,38 @@ func (h *AdminHandler) raftState(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, status)
 }
 
+// ---------- GET /admin/api/load ----------
+
+func (h *AdminHandler) loadProgress(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.srv.LoadProgress())
+}
+
+// ---------- GET /admin/api/backup ----------
+
+// backup streams a backup file as the response body. The raft index and
+// term it reflects are sent as headers before the body.
+func (h *AdminHandler) backup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	b, err := h.srv.openBackup(r.Context(), r.URL.Query().Get("from_snapshot") == "true")
+	if err != nil {
+		writeError(w, gwruntime.HTTPStatusFromCode(status.Code(err)), err.Error())
+		return
+	}
+	defer b.Close()
+
+	index := strconv.FormatUint(b.Meta.LastIncludedIndex, 10)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(b.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="simple-cache-%s.backup"`, index))
+	w.Header().Set("X-Raft-Index", index)
+	w.Header().Set("X-Raft-Term", strconv.FormatUint(b.Meta.LastIncludedTerm, 10))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, b)
+}
+
 // ---------- GET /admin/api/keys/stats ----------
 
 func (h *AdminHandler) keysStats(w http.ResponseWriter, r *http.Request) {
@@ -26,6 +26,19 @@ func UnaryAuthInterceptor(token string) grpc.UnaryServerInterceptor {
 	}
 }
 
+// StreamAuthInterceptor is UnaryAuthInterceptor for streaming RPCs.
+func StreamAuthInterceptor(token string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if token == "" || !requiresRPCAuth(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		if !hasAuthorizedMetadata(ss.Context(), token) {
+			return status.Error(codes.Unauthenticated, "missing or invalid auth token")
+		}
+		return handler(srv, ss)
+	}
+}
+
 func HTTPAuthMiddleware(next http.Handler, token string) http.Handler {
 	if token == "" {
 		return next
@@ -50,7 +63,23 @@ func requiresRPCAuth(method string) bool {
 		"/pb.CacheService/ExpireKey",
 		"/pb.CacheService/Reset",
 		"/pb.CacheService/Dump",
-		"/pb.CacheService/Load":
+		"/pb.CacheService/Load",
+		"/pb.CacheService/Backup",
+		"/pb.CacheService/AcquireLock",
+		"/pb.CacheService/RenewLock",
+		"/pb.CacheService/ReleaseLock",
+		"/pb.CacheService/Publish",
+		"/pb.CacheService/XAdd",
+		"/pb.CacheService/XTrim",
+		"/pb.CacheService/XGroupCreate",
+		"/pb.CacheService/XCommit",
+		"/pb.CacheService/BFReserve",
+		"/pb.CacheService/BFAdd",
+		"/pb.CacheService/PFAdd",
+		"/pb.CacheService/PFMerge",
+		"/pb.CacheService/JSONSet",
+		"/pb.CacheService/JSONDel",
+		"/pb.CacheService/JSONNumIncrBy":
 		return true
 	default:
 		return false
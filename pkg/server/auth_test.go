@@ -39,6 +39,30 @@ func TestUnaryAuthInterceptor(t *testing.T) {
 	})
 }
 
+type authTestStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authTestStream) Context() context.Context { return s.ctx }
+
+func TestStreamAuthInterceptor(t *testing.T) {
+	interceptor := StreamAuthInterceptor("secret")
+	handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+	info := &grpc.StreamServerInfo{FullMethod: "/pb.CacheService/Backup"}
+
+	err := interceptor(nil, authTestStream{ctx: context.Background()}, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-token", "secret"))
+	assert.NoError(t, interceptor(nil, authTestStream{ctx: ctx}, info, handler))
+
+	err = interceptor(nil, authTestStream{ctx: context.Background()}, &grpc.StreamServerInfo{
+		FullMethod: "/pb.CacheService/Watch",
+	}, handler)
+	assert.NoError(t, err)
+}
+
 func TestHTTPAuthMiddleware(t *testing.T) {
 	handler := HTTPAuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
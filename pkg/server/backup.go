@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"github.com/lushenle/simple-cache/pkg/raft"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backupChunkSize bounds the data carried by each BackupChunk.
+const backupChunkSize = 256 << 10
+
+// openBackup takes a backup through the raft node. The backup file can be
+// restored into a new cluster with the simple-cache-restore tool.
+func (s *CacheService) openBackup(ctx context.Context, fromSnapshot bool) (*raft.Backup, error) {
+	if s.node == nil {
+		return nil, status.Error(codes.FailedPrecondition, "backup requires distributed mode; use dump in single mode")
+	}
+	b, err := s.node.Backup(ctx, fromSnapshot)
+	if err == nil {
+		return b, nil
+	}
+	var notLeader raft.ErrNotLeader
+	switch {
+	case errors.As(err, &notLeader):
+		return nil, err
+	case errors.Is(err, raft.ErrNoSnapshot):
+		return nil, status.Error(codes.NotFound, err.Error())
+	default:
+		return nil, status.Errorf(codes.Internal, "backup failed: %v", err)
+	}
+}
+
+// Backup streams a backup file. The first chunk carries the raft index and
+// term the backup reflects.
+func (s *CacheService) Backup(req *pb.BackupRequest, stream pb.CacheService_BackupServer) error {
+	b, err := s.openBackup(stream.Context(), req.GetFromSnapshot())
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	first := &pb.BackupChunk{
+		Index: b.Meta.LastIncludedIndex,
+		Term:  b.Meta.LastIncludedTerm,
+		Size:  b.Size,
+	}
+	buf := make([]byte, backupChunkSize)
+	for {
+		n, err := io.ReadFull(b, buf)
+		if n > 0 || first != nil {
+			chunk := &pb.BackupChunk{}
+			if first != nil {
+				chunk, first = first, nil
+			}
+			chunk.Data = buf[:n]
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "read backup: %v", err)
+		}
+	}
+}
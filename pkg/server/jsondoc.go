@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *CacheService) JSONSet(ctx context.Context, req *pb.JSONSetRequest) (*pb.JSONSetResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.JSONSetCommand{
+		Key:   req.GetKey(),
+		Path:  req.GetPath(),
+		Value: req.GetValue(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.JSONSetResponse), nil
+}
+
+func (s *CacheService) JSONDel(ctx context.Context, req *pb.JSONDelRequest) (*pb.JSONDelResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.JSONDelCommand{
+		Key:  req.GetKey(),
+		Path: req.GetPath(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.JSONDelResponse), nil
+}
+
+func (s *CacheService) JSONNumIncrBy(ctx context.Context, req *pb.JSONNumIncrByRequest) (*pb.JSONNumIncrByResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.JSONNumIncrByCommand{
+		Key:   req.GetKey(),
+		Path:  req.GetPath(),
+		Delta: req.GetDelta(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.JSONNumIncrByResponse), nil
+}
+
+func (s *CacheService) JSONGet(ctx context.Context, req *pb.JSONGetRequest) (*pb.JSONGetResponse, error) {
+	if err := s.checkLeaderRead(ctx); err != nil {
+		return nil, err
+	}
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	value, found, err := s.fsm.Cache.JSONGet(req.GetKey(), req.GetPath())
+	if err != nil {
+		return nil, valueStatusError(err)
+	}
+	return &pb.JSONGetResponse{Value: value, Found: found}, nil
+}
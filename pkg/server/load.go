@@ -0,0 +1,296 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/cache"
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/fsm"
+	"github.com/lushenle/simple-cache/pkg/metrics"
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"github.com/lushenle/simple-cache/pkg/raft"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// A replicated load proposes the dump in batches bounded by both limits so
+// that no single log entry grows with the dump. importBatchBytes bounds the
+// encoded log entry, which raft must send in one AppendEntries request.
+const (
+	importBatchKeys  = 1000
+	importBatchBytes = raft.MaxAppendBytes
+)
+
+// errImportEntryTooLarge rejects a dump with an entry that does not fit in
+// an import batch on its own. It is raised before anything is proposed.
+var errImportEntryTooLarge = errors.New("dump entry too large to replicate")
+
+// importSubmitTimeout bounds each proposal of a replicated load. The load
+// itself is detached from the client's context: once ImportBeginCommand
+// has cleared the keys in scope, a client deadline or disconnect must not
+// leave the cluster wiped and only partly restored.
+const importSubmitTimeout = 30 * time.Second
+
+// errLoadInProgress rejects writes on the leader while a replicated load
+// runs. Writes that get past it are still rejected when applied, see
+// fsm.ErrLoadInProgress.
+var errLoadInProgress = fsm.ErrLoadInProgress
+
+// LoadProgress reports the running or most recent replicated load.
+type LoadProgress struct {
+	Active       bool      `json:"active"`
+	Path         string    `json:"path,omitempty"`
+	Pattern      string    `json:"pattern,omitempty"`
+	Merge        bool      `json:"merge"`
+	TotalKeys    int       `json:"total_keys"`
+	ImportedKeys int       `json:"imported_keys"`
+	LoadedKeys   int       `json:"loaded_keys"`
+	SkippedKeys  int       `json:"skipped_keys"`
+	Batches      int       `json:"batches"`
+	StartedAt    time.Time `json:"started_at,omitempty"`
+	FinishedAt   time.Time `json:"finished_at,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// LoadProgress returns the progress of the running replicated load, or the
+// outcome of the last one. It is zero if this node never led a load.
+func (s *CacheService) LoadProgress() LoadProgress {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	return s.loadProgress
+}
+
+func (s *CacheService) updateLoadProgress(fn func(p *LoadProgress)) {
+	s.loadMu.Lock()
+	fn(&s.loadProgress)
+	s.loadMu.Unlock()
+}
+
+// submit proposes a client write through Raft and stamps the response with
+// the index it committed at. Cache writes are rejected while a replicated
+// load runs; published messages never touch the cache and still go through.
+func (s *CacheService) submit(ctx context.Context, cmd interface{}) (interface{}, error) {
+	resp, idx, err := s.submitIndexed(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	stampCommitIndex(resp, idx)
+	return resp, nil
+}
+
+func (s *CacheService) submitIndexed(ctx context.Context, cmd interface{}) (interface{}, uint64, error) {
+	p, err := s.proposeAsync(ctx, cmd)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p.Wait(ctx)
+}
+
+// proposeAsync queues a client write without waiting for it to commit,
+// under the same load gate as submit.
+func (s *CacheService) proposeAsync(ctx context.Context, cmd interface{}) (*raft.Proposal, error) {
+	if _, ok := cmd.(command.Deliverable); !ok {
+		if s.loading.Load() {
+			return nil, errLoadInProgress
+		}
+		if s.fsm.Importing() {
+			if err := s.endStaleImport(ctx); err != nil {
+				return nil, errLoadInProgress
+			}
+		}
+	}
+	return s.node.Propose(ctx, cmd)
+}
+
+// endStaleImport ends a load that this leader is not running: one started
+// by a former leader, or one whose ImportEndCommand did not commit. Such a
+// load can make no more progress, and without this the cluster would keep
+// rejecting writes.
+func (s *CacheService) endStaleImport(ctx context.Context) error {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	if s.loading.Load() {
+		return errLoadInProgress
+	}
+	if !s.fsm.Importing() {
+		return nil
+	}
+	_, _, err := s.node.SubmitWithIndex(ctx, &command.ImportEndCommand{})
+	return err
+}
+
+// stampCommitIndex sets the commit_index field of a write response, if it
+// has one.
+func stampCommitIndex(resp interface{}, idx uint64) {
+	m, ok := resp.(proto.Message)
+	if !ok {
+		return
+	}
+	r := m.ProtoReflect()
+	fd := r.Descriptor().Fields().ByName("commit_index")
+	if fd == nil || fd.Kind() != protoreflect.Uint64Kind {
+		return
+	}
+	r.Set(fd, protoreflect.ValueOfUint64(idx))
+}
+
+// replicatedLoad restores a dump in distributed mode. The leader reads the
+// dump from its own disk, verifies the whole file, then proposes an
+// ImportBeginCommand that clears the keys in scope followed by batches of
+// entries, so every replica applies the same data through the log.
+//
+// ImportBeginCommand also makes every replica reject other writes until the
+// load proposes ImportEndCommand, or a later leader ends it on the next
+// write. The load is not atomic: other nodes can serve partially loaded
+// data while it runs, and a failure part-way, such as losing leadership,
+// leaves the batches committed so far in place. Rerun the load to converge.
+func (s *CacheService) replicatedLoad(ctx context.Context, req *pb.LoadRequest, filter *cache.KeyFilter) (*pb.LoadResponse, error) {
+	if s.node.Role() != raft.Leader {
+		return nil, raft.ErrNotLeader{Leader: s.node.LeaderID()}
+	}
+	path := req.GetPath()
+	if path == "" {
+		if path = cache.FindDefaultDump(s.NodeID()); path == "" {
+			return &pb.LoadResponse{Success: true, Path: "none"}, nil
+		}
+	}
+	s.importMu.Lock()
+	started := s.loading.CompareAndSwap(false, true)
+	s.importMu.Unlock()
+	if !started {
+		return nil, status.Error(codes.Aborted, "another load is in progress")
+	}
+	defer s.loading.Store(false)
+
+	start := time.Now()
+	s.updateLoadProgress(func(p *LoadProgress) {
+		*p = LoadProgress{
+			Active:    true,
+			Path:      path,
+			Pattern:   req.GetPattern(),
+			Merge:     req.GetMerge(),
+			StartedAt: start,
+		}
+	})
+	ictx := context.WithoutCancel(ctx)
+	result, err := s.importDump(ictx, path, filter, req.GetMerge())
+	if _, _, endErr := s.submitImport(ictx, &command.ImportEndCommand{}); endErr != nil && err == nil {
+		err = fmt.Errorf("end import: %w", endErr)
+	}
+	s.updateLoadProgress(func(p *LoadProgress) {
+		p.Active = false
+		p.FinishedAt = time.Now()
+		if err != nil {
+			p.Error = err.Error()
+		}
+	})
+	if err != nil {
+		metrics.IncPersistenceOp("load", "error")
+		if errors.Is(err, errImportEntryTooLarge) {
+			return nil, status.Errorf(codes.FailedPrecondition, "load failed: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "load failed: %v", err)
+	}
+	metrics.IncPersistenceOp("load", "success")
+	metrics.ObservePersistenceDuration("load", time.Since(start).Seconds())
+	metrics.SetLoadKeys(int64(result.LoadedKeys), int64(result.SkippedKeys))
+
+	result.Success = true
+	result.Path = path
+	result.DurationMs = float64(time.Since(start).Microseconds()) / 1000.0
+	return result, nil
+}
+
+func (s *CacheService) importDump(ctx context.Context, path string, filter *cache.KeyFilter, merge bool) (*pb.LoadResponse, error) {
+	// Read the file once without proposing anything, so a corrupt dump, a
+	// missing key or an entry too large to replicate leaves the cluster
+	// untouched.
+	total := 0
+	_, fence, err := s.fsm.Cache.ScanDump(path, filter, func(entry cache.DumpEntry) error {
+		size, err := importEntrySize(entry)
+		if err != nil {
+			return err
+		}
+		if size > importBatchBytes {
+			return fmt.Errorf("key %q: %w (%d bytes encoded, limit %d)", entry.Key, errImportEntryTooLarge, size, importBatchBytes)
+		}
+		total++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.updateLoadProgress(func(p *LoadProgress) { p.TotalKeys = total })
+
+	begin := &command.ImportBeginCommand{Merge: merge, Fence: fence}
+	if filter != nil {
+		begin.Pattern, begin.UseRegex = filter.Pattern, filter.UseRegex
+	}
+	_, idx, err := s.submitImport(ctx, begin)
+	if err != nil {
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+
+	result := &pb.LoadResponse{CommitIndex: idx}
+	var batch []cache.DumpEntry
+	size := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		resp, idx, err := s.submitImport(ctx, &command.ImportBatchCommand{Entries: batch, Now: time.Now()})
+		if err != nil {
+			return fmt.Errorf("import batch: %w", err)
+		}
+		result.CommitIndex = idx
+		r := resp.(*pb.LoadResponse)
+		result.TotalKeys += r.TotalKeys
+		result.LoadedKeys += r.LoadedKeys
+		result.SkippedKeys += r.SkippedKeys
+		s.updateLoadProgress(func(p *LoadProgress) {
+			p.ImportedKeys = int(result.TotalKeys)
+			p.LoadedKeys = int(result.LoadedKeys)
+			p.SkippedKeys = int(result.SkippedKeys)
+			p.Batches++
+		})
+		batch, size = nil, 0
+		return nil
+	}
+	if _, _, err := s.fsm.Cache.ScanDump(path, filter, func(entry cache.DumpEntry) error {
+		n, err := importEntrySize(entry)
+		if err != nil {
+			return err
+		}
+		// Flush before the entry that would push the batch over a limit.
+		if len(batch) >= importBatchKeys || size+n > importBatchBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		batch = append(batch, entry)
+		size += n
+		return nil
+	}); err != nil {
+		return result, err
+	}
+	return result, flush()
+}
+
+// importEntrySize returns the log entry size of a batch holding only entry.
+// Summed over the entries of a batch it bounds the size of the batch.
+func importEntrySize(entry cache.DumpEntry) (int, error) {
+	return raft.EntrySize(&command.ImportBatchCommand{Entries: []cache.DumpEntry{entry}})
+}
+
+// submitImport proposes one command of a replicated load, bounded by
+// importSubmitTimeout.
+func (s *CacheService) submitImport(ctx context.Context, cmd interface{}) (interface{}, uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, importSubmitTimeout)
+	defer cancel()
+	return s.node.SubmitWithIndex(ctx, cmd)
+}
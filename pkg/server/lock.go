@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A blocking AcquireLock re-proposes only once the holder's lease has ended
+// or the lock was released through this node. When the lease is unknown
+// (e.g. it ran out between the proposal and the check), retries back off
+// from lockRetryMin to lockRetryMax.
+const (
+	lockRetryMin = 50 * time.Millisecond
+	lockRetryMax = time.Second
+)
+
+// lockWait is closed when the lock it belongs to is released; n counts the
+// blocking acquires still holding it.
+type lockWait struct {
+	ch chan struct{}
+	n  int
+}
+
+// AcquireLock takes a lease-based lock. When req.Wait is set, the call
+// blocks until the lock is acquired, the wait elapses or the client goes
+// away; the last observed holder is returned on timeout. While blocked it
+// sleeps until the holder's lease ends or the holder releases the lock
+// instead of re-proposing at a fixed interval.
+func (s *CacheService) AcquireLock(ctx context.Context, req *pb.AcquireLockRequest) (*pb.AcquireLockResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	var wait time.Duration
+	if req.GetWait() != "" {
+		d, err := time.ParseDuration(req.GetWait())
+		if err != nil || d < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid wait %q", req.GetWait())
+		}
+		wait = d
+	}
+	deadline := time.Now().Add(wait)
+	backoff := lockRetryMin
+
+	for {
+		// Watch for a release before proposing so one that lands between
+		// the proposal and the wait is not missed.
+		released, unwatch := s.watchLockRelease(req.GetName())
+		resp, err := s.propose(ctx, &command.AcquireLockCommand{
+			Name:  req.GetName(),
+			Owner: req.GetOwner(),
+			TTL:   req.GetTtl(),
+			Now:   time.Now().UnixNano(),
+		})
+		if err != nil {
+			unwatch()
+			return nil, err
+		}
+		out := resp.(*pb.AcquireLockResponse)
+		remaining := time.Until(deadline)
+		if out.Acquired || remaining <= 0 {
+			unwatch()
+			return out, nil
+		}
+
+		// The proposal has been applied here, so the local cache knows
+		// when the current lease ends.
+		delay := backoff
+		if _, expiration, held := s.fsm.Cache.LockLease(req.GetName(), time.Now()); held {
+			delay = time.Until(expiration)
+			backoff = lockRetryMin
+		} else {
+			backoff = min(2*backoff, lockRetryMax)
+		}
+		timer := time.NewTimer(min(remaining, max(delay, 0)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			unwatch()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-released:
+			timer.Stop()
+		case <-timer.C:
+		}
+		unwatch()
+	}
+}
+
+// watchLockRelease returns a channel that is closed the next time the lock
+// under name is released through this node. unwatch must be called once
+// the caller stops waiting.
+func (s *CacheService) watchLockRelease(name string) (released <-chan struct{}, unwatch func()) {
+	s.lockMu.Lock()
+	defer s.lockMu.Unlock()
+
+	if s.lockWaits == nil {
+		s.lockWaits = make(map[string]*lockWait)
+	}
+	w, ok := s.lockWaits[name]
+	if !ok {
+		w = &lockWait{ch: make(chan struct{})}
+		s.lockWaits[name] = w
+	}
+	w.n++
+	return w.ch, func() {
+		s.lockMu.Lock()
+		defer s.lockMu.Unlock()
+		w.n--
+		if w.n == 0 && s.lockWaits[name] == w {
+			delete(s.lockWaits, name)
+		}
+	}
+}
+
+// notifyLockReleased wakes the blocking acquires waiting on name.
+func (s *CacheService) notifyLockReleased(name string) {
+	s.lockMu.Lock()
+	defer s.lockMu.Unlock()
+
+	if w, ok := s.lockWaits[name]; ok {
+		close(w.ch)
+		delete(s.lockWaits, name)
+	}
+}
+
+// RenewLock extends a held lease. Renewed is false once the lease is lost.
+func (s *CacheService) RenewLock(ctx context.Context, req *pb.RenewLockRequest) (*pb.RenewLockResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.RenewLockCommand{
+		Name:  req.GetName(),
+		Token: req.GetToken(),
+		TTL:   req.GetTtl(),
+		Now:   time.Now().UnixNano(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.RenewLockResponse), nil
+}
+
+// ReleaseLock frees a held lock. Releasing a lost lease is a no-op.
+func (s *CacheService) ReleaseLock(ctx context.Context, req *pb.ReleaseLockRequest) (*pb.ReleaseLockResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.ReleaseLockCommand{
+		Name:  req.GetName(),
+		Token: req.GetToken(),
+		Now:   time.Now().UnixNano(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*pb.ReleaseLockResponse)
+	if out.Released {
+		s.notifyLockReleased(req.GetName())
+	}
+	return out, nil
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *CacheService) BFReserve(ctx context.Context, req *pb.BFReserveRequest) (*pb.BFReserveResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.BFReserveCommand{
+		Key:       req.GetKey(),
+		ErrorRate: req.GetErrorRate(),
+		Capacity:  req.GetCapacity(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.BFReserveResponse), nil
+}
+
+func (s *CacheService) BFAdd(ctx context.Context, req *pb.BFAddRequest) (*pb.BFAddResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.BFAddCommand{
+		Key:   req.GetKey(),
+		Items: req.GetItems(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.BFAddResponse), nil
+}
+
+func (s *CacheService) BFExists(ctx context.Context, req *pb.BFExistsRequest) (*pb.BFExistsResponse, error) {
+	if err := s.checkLeaderRead(ctx); err != nil {
+		return nil, err
+	}
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	exists, err := s.fsm.Cache.BloomExists(req.GetKey(), req.GetItems())
+	if err != nil {
+		return nil, valueStatusError(err)
+	}
+	return &pb.BFExistsResponse{Exists: exists}, nil
+}
+
+func (s *CacheService) PFAdd(ctx context.Context, req *pb.PFAddRequest) (*pb.PFAddResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.PFAddCommand{
+		Key:   req.GetKey(),
+		Items: req.GetItems(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PFAddResponse), nil
+}
+
+func (s *CacheService) PFCount(ctx context.Context, req *pb.PFCountRequest) (*pb.PFCountResponse, error) {
+	if err := s.checkLeaderRead(ctx); err != nil {
+		return nil, err
+	}
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	if len(req.GetKeys()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one key is required")
+	}
+
+	count, err := s.fsm.Cache.HLLCount(req.GetKeys()...)
+	if err != nil {
+		return nil, valueStatusError(err)
+	}
+	return &pb.PFCountResponse{Count: count}, nil
+}
+
+func (s *CacheService) PFMerge(ctx context.Context, req *pb.PFMergeRequest) (*pb.PFMergeResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.PFMergeCommand{
+		Dest:    req.GetDest(),
+		Sources: req.GetSources(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PFMergeResponse), nil
+}
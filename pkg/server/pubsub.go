@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	droppedMessages  int64
+	channelSubIDSeed int64
+)
+
+// ChannelSubscriber receives messages published on its channels or
+// channel patterns.
+type ChannelSubscriber struct {
+	ID       string
+	Channels map[string]struct{}
+	Patterns []string
+	Ch       chan *pb.PubSubMessage
+	mu       sync.Mutex
+	closed   bool
+}
+
+func (s *ChannelSubscriber) Send(msg *pb.PubSubMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.Ch <- msg:
+	default:
+		atomic.AddInt64(&droppedMessages, 1)
+	}
+}
+
+func (s *ChannelSubscriber) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		close(s.Ch)
+		s.closed = true
+	}
+}
+
+// match reports whether channel is subscribed to, and by which pattern.
+// An exact channel subscription wins over patterns and reports "".
+func (s *ChannelSubscriber) match(channel string) (string, bool) {
+	if _, ok := s.Channels[channel]; ok {
+		return "", true
+	}
+	for _, p := range s.Patterns {
+		if matched, _ := filepath.Match(p, channel); matched {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// PubSubService manages channel subscribers on this node. It implements
+// command.Broker so replicated publish commands are delivered locally on
+// every node that applies them.
+type PubSubService struct {
+	mu          sync.RWMutex
+	subscribers []*ChannelSubscriber
+}
+
+var _ command.Broker = (*PubSubService)(nil)
+
+// NewPubSubService creates a new PubSubService.
+func NewPubSubService() *PubSubService {
+	return &PubSubService{}
+}
+
+// Subscribe registers a subscriber for the given channels and patterns.
+func (p *PubSubService) Subscribe(channels, patterns []string) (*ChannelSubscriber, error) {
+	if len(channels) == 0 && len(patterns) == 0 {
+		return nil, fmt.Errorf("at least one channel or pattern is required")
+	}
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	s := &ChannelSubscriber{
+		ID:       fmt.Sprintf("psub-%d", atomic.AddInt64(&channelSubIDSeed, 1)),
+		Channels: make(map[string]struct{}, len(channels)),
+		Patterns: append([]string(nil), patterns...),
+		Ch:       make(chan *pb.PubSubMessage, 64),
+	}
+	for _, ch := range channels {
+		s.Channels[ch] = struct{}{}
+	}
+	p.mu.Lock()
+	p.subscribers = append(p.subscribers, s)
+	p.mu.Unlock()
+	return s, nil
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (p *PubSubService) Unsubscribe(s *ChannelSubscriber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, sub := range p.subscribers {
+		if sub == s {
+			p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+			sub.Close()
+			return
+		}
+	}
+}
+
+// List returns a snapshot of all active subscribers.
+func (p *PubSubService) List() []*ChannelSubscriber {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]*ChannelSubscriber, len(p.subscribers))
+	copy(out, p.subscribers)
+	return out
+}
+
+// Publish delivers a message to all matching local subscribers and returns
+// how many received it.
+func (p *PubSubService) Publish(channel string, payload []byte) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	n := 0
+	for _, s := range p.subscribers {
+		pattern, ok := s.match(channel)
+		if !ok {
+			continue
+		}
+		s.Send(&pb.PubSubMessage{
+			Channel: channel,
+			Pattern: pattern,
+			Payload: payload,
+		})
+		n++
+	}
+	return n
+}
+
+// Close removes and closes all subscribers.
+func (p *PubSubService) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range p.subscribers {
+		s.Close()
+	}
+	p.subscribers = nil
+}
+
+// ---------------------------------------------------------------------------
+// CacheService Publish / Subscribe handlers
+// ---------------------------------------------------------------------------
+
+// Publish implements pb.CacheServiceServer.
+func (s *CacheService) Publish(ctx context.Context, req *pb.PublishRequest) (*pb.PublishResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	if req.GetChannel() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel must not be empty")
+	}
+
+	resp, err := s.propose(ctx, &command.PublishCommand{
+		Channel:     req.GetChannel(),
+		Payload:     req.GetPayload(),
+		PublishedAt: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PublishResponse), nil
+}
+
+// Subscribe implements pb.CacheServiceServer.
+func (s *CacheService) Subscribe(req *pb.SubscribeRequest, stream pb.CacheService_SubscribeServer) error {
+	if s.pubsubSvc == nil {
+		return status.Error(codes.FailedPrecondition, "pub/sub service not available")
+	}
+	sub, err := s.pubsubSvc.Subscribe(req.GetChannels(), req.GetPatterns())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer s.pubsubSvc.Unsubscribe(sub)
+
+	for {
+		select {
+		case msg, ok := <-sub.Ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
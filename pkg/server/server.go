@@ -2,10 +2,13 @@ package server
 
 import (
 	"context"
+	"errors"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/lushenle/simple-cache/pkg/aof"
 	"github.com/lushenle/simple-cache/pkg/cache"
 	"github.com/lushenle/simple-cache/pkg/command"
 	"github.com/lushenle/simple-cache/pkg/common"
@@ -113,6 +116,21 @@ type CacheService struct {
 	peerMap  map[string]string // nodeID → gRPC address for all known peers
 	rl       *simpleRateLimiter
 	watchSvc *WatchService
+	// pubsubSvc delivers replicated pub/sub messages to local subscribers.
+	pubsubSvc *PubSubService
+	// readPolicy selects whether followers serve reads in distributed mode.
+	readPolicy common.ReadPolicy
+	// loading gates writes while this node leads a replicated load.
+	loading atomic.Bool
+	// importMu serializes starting a load with ending one left behind by a
+	// former leader.
+	importMu     sync.Mutex
+	loadMu       sync.Mutex
+	loadProgress LoadProgress
+	// lockMu guards lockWaits, which wakes blocking AcquireLock calls when a
+	// lock is released through this node.
+	lockMu    sync.Mutex
+	lockWaits map[string]*lockWait
 }
 
 // New creates a CacheService in single-node mode.
@@ -134,6 +152,21 @@ func (s *CacheService) SetWatchService(ws *WatchService) {
 	s.watchSvc = ws
 }
 
+// SetPubSubService enables pub/sub channels. Published messages are
+// delivered to ps on every node that applies them.
+func (s *CacheService) SetPubSubService(ps *PubSubService) {
+	s.pubsubSvc = ps
+	s.fsm.Broker = ps
+}
+
+// SetReadPolicy selects which nodes serve reads in distributed mode. With
+// common.ReadPolicyFollower a follower serves reads locally after a
+// ReadIndex forwarded to the leader; the default sends every read to the
+// leader.
+func (s *CacheService) SetReadPolicy(p common.ReadPolicy) {
+	s.readPolicy = p
+}
+
 // SetGRPCAddr sets this node's gRPC address.
 func (s *CacheService) SetGRPCAddr(addr string) {
 	s.grpcAddr = addr
@@ -144,6 +177,11 @@ func (s *CacheService) SetPeerMap(m map[string]string) {
 	s.peerMap = m
 }
 
+// UseAOF enables the append-only file for single mode.
+func (s *CacheService) UseAOF(a *aof.AOF) {
+	s.fsm.AOF = a
+}
+
 // UseRaft attaches a Raft node for distributed mode.
 func (s *CacheService) UseRaft(n *raft.Node) {
 	s.node = n
@@ -153,12 +191,12 @@ func (s *CacheService) Apply(cmd interface{}) (interface{}, error) {
 	return s.fsm.Apply(cmd)
 }
 
-func (s *CacheService) Snapshot(nodeID string) ([]byte, error) {
-	return s.fsm.Snapshot(nodeID)
+func (s *CacheService) Snapshot(nodeID string, w io.Writer) error {
+	return s.fsm.Snapshot(nodeID, w)
 }
 
-func (s *CacheService) RestoreSnapshot(nodeID string, data []byte) error {
-	return s.fsm.RestoreSnapshot(nodeID, data)
+func (s *CacheService) RestoreSnapshot(nodeID string, r io.Reader) error {
+	return s.fsm.RestoreSnapshot(nodeID, r)
 }
 
 func (s *CacheService) Role() string {
@@ -201,6 +239,15 @@ func (s *CacheService) StepDown(ctx context.Context) error {
 	return s.node.StepDown()
 }
 
+// TransferLeadership hands leadership to target, a node ID or raft address,
+// see raft.Node.TransferLeadership.
+func (s *CacheService) TransferLeadership(ctx context.Context, target string) error {
+	if s.node == nil {
+		return nil // single mode, no-op
+	}
+	return s.node.TransferLeadership(ctx, target)
+}
+
 // HealthStatus returns the node's health.
 func (s *CacheService) HealthStatus() ProbeStatus {
 	status := ProbeStatus{
@@ -233,8 +280,10 @@ func (s *CacheService) ReadinessStatus() ProbeStatus {
 	switch s.node.Role() {
 	case raft.Leader:
 		status.Ready = true
-	case raft.Follower, raft.Candidate:
-		status.Ready = false
+	case raft.Follower:
+		// A follower serves reads only under the follower read policy, and
+		// only while it keeps up with the leader.
+		status.Ready = s.readPolicy == common.ReadPolicyFollower && s.node.CaughtUp()
 	default:
 		status.Ready = false
 	}
@@ -258,6 +307,31 @@ func (s *CacheService) RemovePeer(addr string) error {
 	return s.node.RemovePeer(addr)
 }
 
+// AddLearner adds addr as a non-voting learner, see raft.Node.AddLearner.
+func (s *CacheService) AddLearner(addr string) error {
+	if s.node == nil {
+		return raft.ErrInvalidPeerChange{}
+	}
+	return s.node.AddLearner(addr)
+}
+
+// PromoteLearner makes the learner addr a voter once it has caught up.
+func (s *CacheService) PromoteLearner(ctx context.Context, addr string) error {
+	if s.node == nil {
+		return raft.ErrInvalidPeerChange{}
+	}
+	return s.node.PromoteLearner(ctx, addr)
+}
+
+// Reconfigure changes several voters in one joint-consensus operation, see
+// raft.Node.Reconfigure.
+func (s *CacheService) Reconfigure(ctx context.Context, rc raft.Reconfiguration) error {
+	if s.node == nil {
+		return raft.ErrInvalidPeerChange{}
+	}
+	return s.node.Reconfigure(ctx, rc)
+}
+
 func (s *CacheService) Peers() []string {
 	if s.node == nil {
 		return nil
@@ -265,39 +339,143 @@ func (s *CacheService) Peers() []string {
 	return s.node.Peers()
 }
 
+// propose replicates cmd through Raft in distributed mode, or applies it
+// directly to the local FSM in single mode.
+func (s *CacheService) propose(ctx context.Context, cmd interface{}) (interface{}, error) {
+	var resp interface{}
+	var err error
+	if s.node != nil {
+		resp, err = s.submit(ctx, cmd)
+	} else {
+		resp, err = s.fsm.Apply(cmd)
+	}
+	if err != nil {
+		return nil, valueStatusError(err)
+	}
+	return resp, nil
+}
+
+// valueStatusError maps errors from structured value types to gRPC status
+// codes. Other errors are returned unchanged.
+func valueStatusError(err error) error {
+	var notFound cache.ErrGroupNotFound
+	var wrongType cache.ErrWrongType
+	var exists cache.ErrKeyExists
+	var maxKeys cache.ErrMaxKeysReached
+	var tooLarge cache.ErrValueTooLarge
+	var invalidJSON cache.ErrInvalidJSON
+	var jsonPath cache.ErrJSONPath
+	switch {
+	case errors.As(err, &notFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &invalidJSON):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &wrongType), errors.As(err, &jsonPath):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.As(err, &exists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.As(err, &maxKeys), errors.As(err, &tooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return err
+	}
+}
+
 // checkLeaderRead returns nil if the node can safely serve reads.
 // In distributed mode it runs the ReadIndex protocol to guarantee
-// linearizable consistency.
+// linearizable consistency. Under the follower read policy a follower
+// forwards the ReadIndex to the leader instead of rejecting the read.
 func (s *CacheService) checkLeaderRead(ctx context.Context) error {
-	if s.node == nil {
-		return nil // single mode
+	return s.checkRead(ctx, pb.ReadConsistency_READ_LINEARIZABLE)
+}
+
+// checkRead is checkLeaderRead for the requested consistency level. Lease
+// reads skip the heartbeat while the leader holds its lease; a follower
+// under the follower read policy still forwards a ReadIndex. Stale reads
+// are served by any node without a check.
+func (s *CacheService) checkRead(ctx context.Context, level pb.ReadConsistency) error {
+	switch level {
+	case pb.ReadConsistency_READ_LINEARIZABLE, pb.ReadConsistency_READ_LEASE, pb.ReadConsistency_READ_STALE:
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown read consistency %d", level)
+	}
+	if s.node == nil || level == pb.ReadConsistency_READ_STALE {
+		return nil
 	}
 	// ReadIndex now waits for the state machine to catch up with the
 	// recorded commit index (up to 1s); allow the full wait.
 	riCtx, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
-	if _, err := s.node.ReadIndex(riCtx); err != nil {
+	readIndex := s.node.ReadIndex
+	switch {
+	case s.readPolicy == common.ReadPolicyFollower && s.node.Role() != raft.Leader:
+		readIndex = s.node.FollowerReadIndex
+	case level == pb.ReadConsistency_READ_LEASE:
+		readIndex = s.node.LeaseReadIndex
+	}
+	if _, err := readIndex(riCtx); err != nil {
 		return status.Errorf(codes.FailedPrecondition, "read index check failed: %v", err)
 	}
 	return nil
 }
 
+// waitMinIndex holds a read until this node has applied minIndex, the
+// commit index of a write the client already saw, so every consistency
+// level, including stale reads on a follower, reflects the client's own
+// writes. It is a no-op in single mode or when minIndex is zero.
+func (s *CacheService) waitMinIndex(ctx context.Context, minIndex uint64) error {
+	if s.node == nil || minIndex == 0 {
+		return nil
+	}
+	if err := s.node.WaitApplied(ctx, minIndex); err != nil {
+		return status.Errorf(codes.Unavailable, "applied index %d behind min_index %d: %v",
+			s.node.AppliedIndex(), minIndex, err)
+	}
+	return nil
+}
+
+// readPosition returns the raft index this node has applied, which a read
+// taken afterwards reflects at least, and, for stale
+// reads, how long ago it last heard from the leader in milliseconds (-1 if
+// it has no leader). Both are zero in single mode.
+func (s *CacheService) readPosition(level pb.ReadConsistency) (applied uint64, stalenessMs int64) {
+	if s.node == nil {
+		return 0, 0
+	}
+	applied = s.node.AppliedIndex()
+	if level != pb.ReadConsistency_READ_STALE {
+		return applied, 0
+	}
+	contact := s.node.LastLeaderContact()
+	if contact.IsZero() {
+		return applied, -1
+	}
+	return applied, time.Since(contact).Milliseconds()
+}
+
 func (s *CacheService) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
-	if err := s.checkLeaderRead(ctx); err != nil {
+	if err := s.checkRead(ctx, req.GetConsistency()); err != nil {
+		return nil, err
+	}
+	if err := s.waitMinIndex(ctx, req.GetMinIndex()); err != nil {
 		return nil, err
 	}
 	if !s.rl.Allow(clientPeerAddr(ctx)) {
 		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
 	}
 
+	applied, staleness := s.readPosition(req.GetConsistency())
 	value, found := s.fsm.Cache.Get(req.Key)
+	if cache.IsStructured(value) {
+		return nil, valueStatusError(cache.ErrWrongType{Key: req.Key, Want: "plain"})
+	}
 
 	val, convErr := utils.ConvertToAnyPB(value)
 	if convErr != nil {
 		return &pb.GetResponse{Value: nil, Found: false}, status.Error(codes.InvalidArgument, convErr.Error())
 	}
 
-	return &pb.GetResponse{Value: val, Found: found}, nil
+	return &pb.GetResponse{Value: val, Found: found, AppliedIndex: applied, StalenessMs: staleness}, nil
 }
 
 func (s *CacheService) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse, error) {
@@ -313,7 +491,7 @@ func (s *CacheService) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResp
 	var resp interface{}
 	var err error
 	if s.node != nil {
-		resp, err = s.node.Submit(cmd)
+		resp, err = s.submit(ctx, cmd)
 	} else {
 		resp, err = s.fsm.Apply(cmd)
 	}
@@ -335,7 +513,7 @@ func (s *CacheService) Del(ctx context.Context, req *pb.DelRequest) (*pb.DelResp
 	var resp interface{}
 	var err error
 	if s.node != nil {
-		resp, err = s.node.Submit(cmd)
+		resp, err = s.submit(ctx, cmd)
 	} else {
 		resp, err = s.fsm.Apply(cmd)
 	}
@@ -357,7 +535,7 @@ func (s *CacheService) ExpireKey(ctx context.Context, req *pb.ExpireKeyRequest)
 	var resp interface{}
 	var err error
 	if s.node != nil {
-		resp, err = s.node.Submit(cmd)
+		resp, err = s.submit(ctx, cmd)
 	} else {
 		resp, err = s.fsm.Apply(cmd)
 	}
@@ -379,7 +557,7 @@ func (s *CacheService) Reset(ctx context.Context, req *pb.ResetRequest) (*pb.Res
 	var resp interface{}
 	var err error
 	if s.node != nil {
-		resp, err = s.node.Submit(cmd)
+		resp, err = s.submit(ctx, cmd)
 	} else {
 		resp, err = s.fsm.Apply(cmd)
 	}
@@ -390,7 +568,10 @@ func (s *CacheService) Reset(ctx context.Context, req *pb.ResetRequest) (*pb.Res
 }
 
 func (s *CacheService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
-	if err := s.checkLeaderRead(ctx); err != nil {
+	if err := s.checkRead(ctx, req.GetConsistency()); err != nil {
+		return nil, err
+	}
+	if err := s.waitMinIndex(ctx, req.GetMinIndex()); err != nil {
 		return nil, err
 	}
 	if !s.rl.Allow(clientPeerAddr(ctx)) {
@@ -402,12 +583,15 @@ func (s *CacheService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.S
 		UseRegex: req.Mode == pb.SearchRequest_REGEX,
 	}
 
+	applied, staleness := s.readPosition(req.GetConsistency())
 	resp, err := s.fsm.Apply(cmd)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid pattern: %v", err)
 	}
 
-	return resp.(*pb.SearchResponse), nil
+	out := resp.(*pb.SearchResponse)
+	out.AppliedIndex, out.StalenessMs = applied, staleness
+	return out, nil
 }
 
 // Dump exports cache data to a file.
@@ -417,7 +601,11 @@ func (s *CacheService) Dump(ctx context.Context, req *pb.DumpRequest) (*pb.DumpR
 		format = common.DumpFormatBinary.String()
 	}
 
-	result, err := s.fsm.Cache.Dump(s.NodeID(), format, req.GetPath())
+	filter := keyFilter(req.GetPattern(), req.GetMode())
+	if err := filter.Validate(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid pattern: %v", err)
+	}
+	result, err := s.fsm.Cache.DumpWith(s.NodeID(), format, req.GetPath(), cache.DumpOptions{Filter: filter})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "dump failed: %v", err)
 	}
@@ -432,15 +620,37 @@ func (s *CacheService) Dump(ctx context.Context, req *pb.DumpRequest) (*pb.DumpR
 	}, nil
 }
 
-// Load imports cache data from a file.
+// keyFilter turns a Dump or Load request's pattern into a cache filter.
+func keyFilter(pattern string, mode pb.SearchRequest_MatchMode) *cache.KeyFilter {
+	if pattern == "" {
+		return nil
+	}
+	return &cache.KeyFilter{Pattern: pattern, UseRegex: mode == pb.SearchRequest_REGEX}
+}
+
+// Load imports cache data from a file. In distributed mode it must run on
+// the leader, which replicates the data to every node (see replicatedLoad).
 func (s *CacheService) Load(ctx context.Context, req *pb.LoadRequest) (*pb.LoadResponse, error) {
+	filter := keyFilter(req.GetPattern(), req.GetMode())
+	if err := filter.Validate(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid pattern: %v", err)
+	}
 	if s.node != nil {
-		return nil, status.Error(codes.FailedPrecondition, "load is disabled in distributed mode")
+		return s.replicatedLoad(ctx, req, filter)
 	}
-	result, err := s.fsm.Cache.Load(s.NodeID(), req.GetPath())
+	result, err := s.fsm.Cache.LoadWith(s.NodeID(), req.GetPath(), cache.LoadOptions{
+		Filter: filter,
+		Merge:  req.GetMerge(),
+	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "load failed: %v", err)
 	}
+	// The log no longer describes the cache; start over from the loaded data.
+	if s.fsm.AOF != nil {
+		if err := s.fsm.AOF.Rewrite(); err != nil {
+			return nil, status.Errorf(codes.Internal, "aof rewrite after load failed: %v", err)
+		}
+	}
 
 	return &pb.LoadResponse{
 		Success:     true,
@@ -452,28 +662,56 @@ func (s *CacheService) Load(ctx context.Context, req *pb.LoadRequest) (*pb.LoadR
 	}, nil
 }
 
+// batchSetPipeline bounds the BatchSet writes queued in raft but not yet
+// committed.
+const batchSetPipeline = 256
+
 // BatchSet receives a stream of BatchSetRequest, processes them as individual
-// Set operations, and returns the count of successes and failures.
+// Set operations, and returns the count of successes and failures. In
+// distributed mode the writes are pipelined: each one is queued with
+// Node.Propose as it arrives, so concurrent items share WAL fsyncs and
+// AppendEntries rounds, and their outcomes are collected afterwards in
+// stream order.
 func (s *CacheService) BatchSet(stream pb.CacheService_BatchSetServer) error {
 	var successCount, errorCount int32
 	var firstErr string
+	var commitIndex uint64
+	record := func(err error) {
+		if err != nil {
+			errorCount++
+			if firstErr == "" {
+				firstErr = err.Error()
+			}
+		} else {
+			successCount++
+		}
+	}
+	var pending []*raft.Proposal
+	drain := func() {
+		for _, p := range pending {
+			_, idx, err := p.Wait(stream.Context())
+			commitIndex = max(commitIndex, idx)
+			record(err)
+		}
+		pending = pending[:0]
+	}
 	for {
 		req, err := stream.Recv()
 		if err == io.EOF {
+			drain()
 			return stream.SendAndClose(&pb.BatchSetResponse{
 				SuccessCount: successCount,
 				ErrorCount:   errorCount,
 				FirstError:   firstErr,
+				CommitIndex:  commitIndex,
 			})
 		}
 		if err != nil {
+			drain()
 			return err
 		}
 		if !s.rl.Allow(clientPeerAddr(stream.Context())) {
-			errorCount++
-			if firstErr == "" {
-				firstErr = "rate limit exceeded"
-			}
+			record(errors.New("rate limit exceeded"))
 			continue
 		}
 		cmd := &command.SetCommand{
@@ -481,19 +719,19 @@ func (s *CacheService) BatchSet(stream pb.CacheService_BatchSetServer) error {
 			Value:  req.Value,
 			Expire: req.Expire,
 		}
-		var errApply error
-		if s.node != nil {
-			_, errApply = s.node.Submit(cmd)
-		} else {
-			_, errApply = s.fsm.Apply(cmd)
+		if s.node == nil {
+			_, errApply := s.fsm.Apply(cmd)
+			record(errApply)
+			continue
 		}
-		if errApply != nil {
-			errorCount++
-			if firstErr == "" {
-				firstErr = errApply.Error()
-			}
-		} else {
-			successCount++
+		p, errPropose := s.proposeAsync(stream.Context(), cmd)
+		if errPropose != nil {
+			record(errPropose)
+			continue
+		}
+		pending = append(pending, p)
+		if len(pending) >= batchSetPipeline {
+			drain()
 		}
 	}
 }
@@ -2,11 +2,17 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/lushenle/simple-cache/pkg/cache"
+	"github.com/lushenle/simple-cache/pkg/command"
 	"github.com/lushenle/simple-cache/pkg/common"
 	"github.com/lushenle/simple-cache/pkg/log"
 	"github.com/lushenle/simple-cache/pkg/pb"
@@ -15,8 +21,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 func TestGRPCServer(t *testing.T) {
@@ -43,6 +51,13 @@ func TestGRPCServer(t *testing.T) {
 		assert.Equal(t, "value", got)
 	})
 
+	t.Run("GetStructuredValue", func(t *testing.T) {
+		_, _, err := c.AcquireLock("get-lock", "owner", time.Minute, 0, time.Now())
+		require.NoError(t, err)
+		_, err = srv.Get(context.Background(), &pb.GetRequest{Key: "get-lock"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
 	t.Run("Search", func(t *testing.T) {
 		val, err := utils.ConvertToAnyPB("data")
 		assert.Nil(t, err)
@@ -55,7 +70,35 @@ func TestGRPCServer(t *testing.T) {
 		assert.Contains(t, resp.Keys, "user:100")
 	})
 
-	t.Run("LoadDisabledInDistributedMode", func(t *testing.T) {
+	t.Run("PartialDumpAndMergeLoad", func(t *testing.T) {
+		single := New(cache.New(time.Minute, logger), "single-node")
+		for _, k := range []string{"tenant-a:1", "tenant-b:1"} {
+			val, err := utils.ConvertToAnyPB(k)
+			require.NoError(t, err)
+			_, err = single.Set(context.Background(), &pb.SetRequest{Key: k, Value: val})
+			require.NoError(t, err)
+		}
+		path := filepath.Join(t.TempDir(), "tenant-a.dump")
+		dumped, err := single.Dump(context.Background(), &pb.DumpRequest{Path: path, Pattern: "^tenant-a:", Mode: pb.SearchRequest_REGEX})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), dumped.TotalKeys)
+
+		_, err = single.Dump(context.Background(), &pb.DumpRequest{Path: path, Pattern: "[", Mode: pb.SearchRequest_REGEX})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = single.Del(context.Background(), &pb.DelRequest{Key: "tenant-a:1"})
+		require.NoError(t, err)
+		loaded, err := single.Load(context.Background(), &pb.LoadRequest{Path: path, Merge: true})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), loaded.LoadedKeys)
+		for _, k := range []string{"tenant-a:1", "tenant-b:1"} {
+			resp, err := single.Get(context.Background(), &pb.GetRequest{Key: k})
+			require.NoError(t, err, k)
+			assert.True(t, resp.Found, k)
+		}
+	})
+
+	t.Run("LoadRequiresLeaderInDistributedMode", func(t *testing.T) {
 		transportAddr := "127.0.0.1:0"
 		node, err := raft.NewNode(
 			"test-node",
@@ -154,3 +197,682 @@ func TestSearchRejectsNonLeader(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, codes.FailedPrecondition, st.Code())
 }
+
+func TestPubSubChannelsAndPatterns(t *testing.T) {
+	ps := NewPubSubService()
+	defer ps.Close()
+
+	exact, err := ps.Subscribe([]string{"orders"}, nil)
+	require.NoError(t, err)
+	pattern, err := ps.Subscribe(nil, []string{"invalidate:*"})
+	require.NoError(t, err)
+
+	_, err = ps.Subscribe(nil, nil)
+	assert.Error(t, err)
+	_, err = ps.Subscribe(nil, []string{"[bad"})
+	assert.Error(t, err)
+
+	assert.Equal(t, 1, ps.Publish("orders", []byte("o1")))
+	assert.Equal(t, 1, ps.Publish("invalidate:user", []byte("u1")))
+	assert.Equal(t, 0, ps.Publish("other", []byte("x")))
+
+	msg := <-exact.Ch
+	assert.Equal(t, "orders", msg.Channel)
+	assert.Empty(t, msg.Pattern)
+	msg = <-pattern.Ch
+	assert.Equal(t, "invalidate:user", msg.Channel)
+	assert.Equal(t, "invalidate:*", msg.Pattern)
+	assert.Equal(t, []byte("u1"), msg.Payload)
+}
+
+func TestPublishReplicatedThroughRaft(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.DebugLevel)
+	logger := log.NewLogger(plugin)
+
+	c := cache.New(time.Second*3, logger)
+	srv := New(c, "test-node-pubsub")
+	ps := NewPubSubService()
+	srv.SetPubSubService(ps)
+
+	transportAddr := "127.0.0.1:0"
+	node, err := raft.NewNode(
+		"test-node-pubsub",
+		transportAddr,
+		[]string{"http://" + transportAddr},
+		raft.NewStorage(filepath.Join(t.TempDir(), "raft-pubsub.wal")),
+		srv,
+		50*time.Millisecond,
+		120*time.Millisecond,
+		true,
+		8,
+		logger,
+		"",
+	)
+	require.NoError(t, err)
+	defer node.Close()
+	srv.UseRaft(node)
+	require.Eventually(t, func() bool { return node.Role() == raft.Leader }, 3*time.Second, 20*time.Millisecond)
+
+	sub, err := ps.Subscribe([]string{"events"}, nil)
+	require.NoError(t, err)
+
+	_, err = srv.Publish(context.Background(), &pb.PublishRequest{Channel: "events", Payload: []byte("hello")})
+	require.NoError(t, err)
+
+	select {
+	case msg := <-sub.Ch:
+		assert.Equal(t, []byte("hello"), msg.Payload)
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered")
+	}
+	keys, err := c.Search("*", false)
+	require.NoError(t, err)
+	assert.Empty(t, keys, "published messages must not be stored as keys")
+}
+
+func TestRejectedWritesDoNotStopRaft(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.DebugLevel)
+	logger := log.NewLogger(plugin)
+
+	c := cache.NewWithLimits(time.Second*3, 0, 256, string(cache.EvictionNone), logger)
+	srv := New(c, "test-node-rejected")
+	transportAddr := "127.0.0.1:0"
+	node, err := raft.NewNode(
+		"test-node-rejected",
+		transportAddr,
+		[]string{"http://" + transportAddr},
+		raft.NewStorage(filepath.Join(t.TempDir(), "raft-rejected.wal")),
+		srv,
+		50*time.Millisecond,
+		120*time.Millisecond,
+		true,
+		8,
+		logger,
+		"",
+	)
+	require.NoError(t, err)
+	defer node.Close()
+	srv.UseRaft(node)
+	require.Eventually(t, func() bool { return node.Role() == raft.Leader }, 3*time.Second, 20*time.Millisecond)
+
+	ctx := context.Background()
+	fields := map[string]string{"payload": fmt.Sprintf("%0100d", 0)}
+	for i := 0; ; i++ {
+		_, err = srv.XAdd(ctx, &pb.XAddRequest{Key: "s", Fields: fields})
+		if err != nil {
+			break
+		}
+		require.Less(t, i, 10)
+	}
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = srv.JSONSet(ctx, &pb.JSONSetRequest{Key: "doc", Path: "$", Value: `{"a":1}`})
+	require.NoError(t, err)
+	_, err = srv.JSONSet(ctx, &pb.JSONSetRequest{Key: "doc", Path: "$.a.b", Value: `1`})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// The rejections were applied like any other entry; the node still
+	// takes writes.
+	_, err = srv.JSONSet(ctx, &pb.JSONSetRequest{Key: "doc", Path: "$.b", Value: `2`})
+	require.NoError(t, err)
+}
+
+func TestBlockingAcquireLockWaitsForLease(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.DebugLevel)
+	logger := log.NewLogger(plugin)
+
+	c := cache.New(time.Second*3, logger)
+	srv := New(c, "test-node-lock")
+	transportAddr := "127.0.0.1:0"
+	node, err := raft.NewNode(
+		"test-node-lock",
+		transportAddr,
+		[]string{"http://" + transportAddr},
+		raft.NewStorage(filepath.Join(t.TempDir(), "raft-lock.wal")),
+		srv,
+		50*time.Millisecond,
+		120*time.Millisecond,
+		true,
+		8,
+		logger,
+		"",
+	)
+	require.NoError(t, err)
+	defer node.Close()
+	srv.UseRaft(node)
+	require.Eventually(t, func() bool { return node.Role() == raft.Leader }, 3*time.Second, 20*time.Millisecond)
+
+	ctx := context.Background()
+	held, err := srv.AcquireLock(ctx, &pb.AcquireLockRequest{Name: "job", Owner: "a", Ttl: "10s"})
+	require.NoError(t, err)
+	require.True(t, held.Acquired)
+
+	// A waiter that times out proposes once up front and once when the
+	// wait elapses, not every few milliseconds in between.
+	out, err := srv.AcquireLock(ctx, &pb.AcquireLockRequest{Name: "job", Owner: "b", Ttl: "10s", Wait: "600ms"})
+	require.NoError(t, err)
+	assert.False(t, out.Acquired)
+	assert.LessOrEqual(t, out.CommitIndex-held.CommitIndex, uint64(2))
+
+	// A release wakes the waiter long before the lease would have ended.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = srv.ReleaseLock(ctx, &pb.ReleaseLockRequest{Name: "job", Token: held.Token})
+	}()
+	start := time.Now()
+	out, err = srv.AcquireLock(ctx, &pb.AcquireLockRequest{Name: "job", Owner: "b", Ttl: "10s", Wait: "5s"})
+	require.NoError(t, err)
+	assert.True(t, out.Acquired)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// Without a release the waiter takes over once the lease ends.
+	out, err = srv.AcquireLock(ctx, &pb.AcquireLockRequest{Name: "short", Owner: "a", Ttl: "200ms"})
+	require.NoError(t, err)
+	require.True(t, out.Acquired)
+	out, err = srv.AcquireLock(ctx, &pb.AcquireLockRequest{Name: "short", Owner: "b", Ttl: "1s", Wait: "2s"})
+	require.NoError(t, err)
+	assert.True(t, out.Acquired)
+	assert.Equal(t, "b", out.Owner)
+}
+
+func TestReplicatedLoad(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.InfoLevel)
+	logger := log.NewLogger(plugin)
+
+	src := cache.New(time.Minute, logger)
+	for i := 0; i < 2500; i++ {
+		require.NoError(t, src.Set(fmt.Sprintf("tenant-a:%d", i), "v", ""))
+	}
+	require.NoError(t, src.Set("tenant-b:1", "from-dump", ""))
+	path := filepath.Join(t.TempDir(), "seed.dump")
+	_, err := src.Dump("src", common.DumpFormatBinary.String(), path)
+	require.NoError(t, err)
+
+	addrs := []string{freeAddr(t), freeAddr(t)}
+	peers := []string{"http://" + addrs[0], "http://" + addrs[1]}
+	var srvs []*CacheService
+	var nodes []*raft.Node
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		srv := New(cache.New(time.Minute, logger), id)
+		node, err := raft.NewNode(id, addr, peers, raft.NewStorage(filepath.Join(t.TempDir(), id+".wal")),
+			srv, 50*time.Millisecond, 300*time.Millisecond, true, 1024, logger, "")
+		require.NoError(t, err)
+		defer node.Close()
+		srv.UseRaft(node)
+		srvs = append(srvs, srv)
+		nodes = append(nodes, node)
+	}
+	var leader *CacheService
+	require.Eventually(t, func() bool {
+		for i, n := range nodes {
+			if n.Role() == raft.Leader {
+				leader = srvs[i]
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 20*time.Millisecond)
+
+	val, err := utils.ConvertToAnyPB("existing")
+	require.NoError(t, err)
+	for _, k := range []string{"tenant-a:stale", "tenant-b:1"} {
+		_, err = leader.Set(context.Background(), &pb.SetRequest{Key: k, Value: val})
+		require.NoError(t, err)
+	}
+
+	resp, err := leader.Load(context.Background(), &pb.LoadRequest{Path: path, Pattern: "tenant-a:*"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2500, resp.TotalKeys)
+	assert.EqualValues(t, 2500, resp.LoadedKeys)
+	assert.NotZero(t, resp.CommitIndex)
+
+	progress := leader.LoadProgress()
+	assert.False(t, progress.Active)
+	assert.Equal(t, 2500, progress.ImportedKeys)
+	assert.Equal(t, 3, progress.Batches)
+	assert.Empty(t, progress.Error)
+
+	// Every replica converges: the stale key in scope is gone and the key
+	// outside the pattern keeps its value.
+	for _, srv := range srvs {
+		c := srv.fsm.Cache
+		require.Eventually(t, func() bool {
+			keys, err := c.Search("tenant-a:*", false)
+			return err == nil && len(keys) == 2500
+		}, 5*time.Second, 20*time.Millisecond)
+		_, ok := c.Get("tenant-a:stale")
+		assert.False(t, ok)
+		v, ok := c.Get("tenant-b:1")
+		require.True(t, ok)
+		got, err := utils.FromAnyPB(v.(*anypb.Any))
+		require.NoError(t, err)
+		assert.Equal(t, "existing", got)
+	}
+
+	// Writes are rejected while a load runs.
+	leader.loading.Store(true)
+	_, err = leader.Set(context.Background(), &pb.SetRequest{Key: "k", Value: val})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	_, err = leader.Load(context.Background(), &pb.LoadRequest{Path: path})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	leader.loading.Store(false)
+	_, err = leader.Set(context.Background(), &pb.SetRequest{Key: "k", Value: val})
+	assert.NoError(t, err)
+	for _, srv := range srvs {
+		assert.False(t, srv.fsm.Importing())
+	}
+
+	// A write that reaches the log during a load is rejected by every
+	// replica, and the replicas keep applying.
+	_, _, err = leader.node.SubmitWithIndex(context.Background(), &command.ImportBeginCommand{Merge: true})
+	require.NoError(t, err)
+	_, _, err = leader.node.SubmitWithIndex(context.Background(), &command.SetCommand{Key: "gated", Value: val})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	for _, srv := range srvs {
+		require.Eventually(t, srv.fsm.Importing, 5*time.Second, 20*time.Millisecond)
+		_, ok := srv.fsm.Cache.Get("gated")
+		assert.False(t, ok)
+	}
+	// No load runs on the leader, so the next write ends the stale one.
+	_, err = leader.Set(context.Background(), &pb.SetRequest{Key: "gated", Value: val})
+	require.NoError(t, err)
+	for _, srv := range srvs {
+		require.Eventually(t, func() bool { return !srv.fsm.Importing() }, 5*time.Second, 20*time.Millisecond)
+	}
+
+	_, err = leader.Load(context.Background(), &pb.LoadRequest{Path: filepath.Join(t.TempDir(), "missing.dump")})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotEmpty(t, leader.LoadProgress().Error)
+}
+
+func TestReplicatedLoadLargeValues(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.InfoLevel)
+	logger := log.NewLogger(plugin)
+
+	// 200 keys of 10 KB are far more than one AppendEntries request can
+	// carry over the HTTP transport once encoded.
+	src := cache.New(time.Minute, logger)
+	for i := 0; i < 200; i++ {
+		require.NoError(t, src.Set(fmt.Sprintf("big:%d", i), strings.Repeat("x", 10_000), ""))
+	}
+	path := filepath.Join(t.TempDir(), "big.dump")
+	_, err := src.Dump("src", common.DumpFormatBinary.String(), path)
+	require.NoError(t, err)
+
+	huge := cache.New(time.Minute, logger)
+	require.NoError(t, huge.Set("huge", strings.Repeat("x", importBatchBytes), ""))
+	hugePath := filepath.Join(t.TempDir(), "huge.dump")
+	_, err = huge.Dump("huge", common.DumpFormatBinary.String(), hugePath)
+	require.NoError(t, err)
+
+	addrs := []string{freeAddr(t), freeAddr(t), freeAddr(t)}
+	peers := []string{"http://" + addrs[0], "http://" + addrs[1], "http://" + addrs[2]}
+	var srvs []*CacheService
+	var nodes []*raft.Node
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		srv := New(cache.New(time.Minute, logger), id)
+		node, err := raft.NewNode(id, addr, peers, raft.NewStorage(filepath.Join(t.TempDir(), id+".wal")),
+			srv, 50*time.Millisecond, 300*time.Millisecond, true, 1024, logger, "")
+		require.NoError(t, err)
+		defer node.Close()
+		srv.UseRaft(node)
+		srvs = append(srvs, srv)
+		nodes = append(nodes, node)
+	}
+	var leader *CacheService
+	var leaderNode *raft.Node
+	require.Eventually(t, func() bool {
+		for i, n := range nodes {
+			if n.Role() == raft.Leader {
+				leader, leaderNode = srvs[i], n
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 20*time.Millisecond)
+
+	resp, err := leader.Load(context.Background(), &pb.LoadRequest{Path: path})
+	require.NoError(t, err)
+	assert.EqualValues(t, 200, resp.LoadedKeys)
+	assert.Greater(t, leader.LoadProgress().Batches, 1)
+	assert.Equal(t, raft.Leader, leaderNode.Role())
+	for _, srv := range srvs {
+		c := srv.fsm.Cache
+		require.Eventually(t, func() bool {
+			keys, err := c.Search("big:*", false)
+			return err == nil && len(keys) == 200
+		}, 5*time.Second, 20*time.Millisecond)
+	}
+
+	// An entry that cannot be replicated fails the load before anything
+	// is proposed: the keys it would replace stay in place.
+	_, err = leader.Load(context.Background(), &pb.LoadRequest{Path: hugePath})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	keys, err := leader.fsm.Cache.Search("big:*", false)
+	require.NoError(t, err)
+	assert.Len(t, keys, 200)
+	assert.False(t, leader.fsm.Importing())
+}
+
+func TestFollowerReadPolicy(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.InfoLevel)
+	logger := log.NewLogger(plugin)
+
+	addrs := []string{freeAddr(t), freeAddr(t)}
+	peers := []string{"http://" + addrs[0], "http://" + addrs[1]}
+	var srvs []*CacheService
+	var nodes []*raft.Node
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		srv := New(cache.New(time.Minute, logger), id)
+		srv.SetReadPolicy(common.ReadPolicyFollower)
+		node, err := raft.NewNode(id, addr, peers, raft.NewStorage(filepath.Join(t.TempDir(), id+".wal")),
+			srv, 50*time.Millisecond, 300*time.Millisecond, true, 1024, logger, "")
+		require.NoError(t, err)
+		defer node.Close()
+		srv.UseRaft(node)
+		srvs = append(srvs, srv)
+		nodes = append(nodes, node)
+	}
+	var leader, follower *CacheService
+	require.Eventually(t, func() bool {
+		for i, n := range nodes {
+			if n.Role() == raft.Leader {
+				leader, follower = srvs[i], srvs[1-i]
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 20*time.Millisecond)
+
+	val, err := utils.ConvertToAnyPB("v")
+	require.NoError(t, err)
+	_, err = leader.Set(context.Background(), &pb.SetRequest{Key: "k", Value: val})
+	require.NoError(t, err)
+
+	// The follower serves the read itself and sees the acknowledged write.
+	resp, err := follower.Get(context.Background(), &pb.GetRequest{Key: "k"})
+	require.NoError(t, err)
+	assert.True(t, resp.Found)
+	require.Eventually(t, func() bool { return follower.ReadinessStatus().Ready }, 3*time.Second, 20*time.Millisecond)
+
+	// Under the leader policy the follower still rejects reads and is not
+	// ready.
+	follower.SetReadPolicy(common.ReadPolicyLeader)
+	_, err = follower.Get(context.Background(), &pb.GetRequest{Key: "k"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.False(t, follower.ReadinessStatus().Ready)
+}
+
+func TestReadConsistencyLevels(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.InfoLevel)
+	logger := log.NewLogger(plugin)
+
+	t.Run("SingleModeIgnoresLevel", func(t *testing.T) {
+		srv := New(cache.New(time.Minute, logger), "single")
+		resp, err := srv.Get(context.Background(), &pb.GetRequest{Key: "k", Consistency: pb.ReadConsistency_READ_STALE})
+		require.NoError(t, err)
+		assert.Zero(t, resp.AppliedIndex)
+		_, err = srv.Get(context.Background(), &pb.GetRequest{Key: "k", Consistency: pb.ReadConsistency(42)})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	addrs := []string{freeAddr(t), freeAddr(t)}
+	peers := []string{"http://" + addrs[0], "http://" + addrs[1]}
+	var srvs []*CacheService
+	var nodes []*raft.Node
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		srv := New(cache.New(time.Minute, logger), id)
+		node, err := raft.NewNode(id, addr, peers, raft.NewStorage(filepath.Join(t.TempDir(), id+".wal")),
+			srv, 50*time.Millisecond, 300*time.Millisecond, true, 1024, logger, "")
+		require.NoError(t, err)
+		defer node.Close()
+		srv.UseRaft(node)
+		srvs = append(srvs, srv)
+		nodes = append(nodes, node)
+	}
+	var leader, follower *CacheService
+	require.Eventually(t, func() bool {
+		for i, n := range nodes {
+			if n.Role() == raft.Leader {
+				leader, follower = srvs[i], srvs[1-i]
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 20*time.Millisecond)
+
+	val, err := utils.ConvertToAnyPB("v")
+	require.NoError(t, err)
+	_, err = leader.Set(context.Background(), &pb.SetRequest{Key: "flag:a", Value: val})
+	require.NoError(t, err)
+
+	for _, level := range []pb.ReadConsistency{pb.ReadConsistency_READ_LINEARIZABLE, pb.ReadConsistency_READ_LEASE} {
+		resp, err := leader.Get(context.Background(), &pb.GetRequest{Key: "flag:a", Consistency: level})
+		require.NoError(t, err, level)
+		assert.True(t, resp.Found)
+		assert.NotZero(t, resp.AppliedIndex)
+	}
+
+	// Under the leader read policy a follower still rejects linearizable and
+	// lease reads, but serves stale reads from its own state machine.
+	_, err = follower.Get(context.Background(), &pb.GetRequest{Key: "flag:a", Consistency: pb.ReadConsistency_READ_LEASE})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Eventually(t, func() bool {
+		resp, err := follower.Get(context.Background(), &pb.GetRequest{Key: "flag:a", Consistency: pb.ReadConsistency_READ_STALE})
+		return err == nil && resp.Found
+	}, 3*time.Second, 20*time.Millisecond)
+	search, err := follower.Search(context.Background(), &pb.SearchRequest{Pattern: "flag:*", Consistency: pb.ReadConsistency_READ_STALE})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"flag:a"}, search.Keys)
+	assert.NotZero(t, search.AppliedIndex)
+	assert.GreaterOrEqual(t, search.StalenessMs, int64(0))
+}
+
+func TestCommitIndexReadYourWrites(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.InfoLevel)
+	logger := log.NewLogger(plugin)
+
+	addrs := []string{freeAddr(t), freeAddr(t)}
+	peers := []string{"http://" + addrs[0], "http://" + addrs[1]}
+	var srvs []*CacheService
+	var nodes []*raft.Node
+	for i, addr := range addrs {
+		id := fmt.Sprintf("n%d", i+1)
+		srv := New(cache.New(time.Minute, logger), id)
+		node, err := raft.NewNode(id, addr, peers, raft.NewStorage(filepath.Join(t.TempDir(), id+".wal")),
+			srv, 50*time.Millisecond, 300*time.Millisecond, true, 1024, logger, "")
+		require.NoError(t, err)
+		defer node.Close()
+		srv.UseRaft(node)
+		srvs = append(srvs, srv)
+		nodes = append(nodes, node)
+	}
+	var leader, follower *CacheService
+	require.Eventually(t, func() bool {
+		for i, n := range nodes {
+			if n.Role() == raft.Leader {
+				leader, follower = srvs[i], srvs[1-i]
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 20*time.Millisecond)
+
+	val, err := utils.ConvertToAnyPB("v")
+	require.NoError(t, err)
+	set, err := leader.Set(context.Background(), &pb.SetRequest{Key: "session:a", Value: val})
+	require.NoError(t, err)
+	assert.NotZero(t, set.CommitIndex)
+	pub, err := leader.Publish(context.Background(), &pb.PublishRequest{Channel: "c", Payload: []byte("x")})
+	require.NoError(t, err)
+	assert.Greater(t, pub.CommitIndex, set.CommitIndex)
+	del, err := leader.Del(context.Background(), &pb.DelRequest{Key: "session:a"})
+	require.NoError(t, err)
+	assert.Greater(t, del.CommitIndex, pub.CommitIndex)
+
+	// A stale read on the follower that names the delete's index waits for
+	// it instead of returning the value the follower may still hold.
+	resp, err := follower.Get(context.Background(), &pb.GetRequest{
+		Key:         "session:a",
+		Consistency: pb.ReadConsistency_READ_STALE,
+		MinIndex:    del.CommitIndex,
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Found)
+	assert.GreaterOrEqual(t, resp.AppliedIndex, del.CommitIndex)
+
+	_, err = follower.Search(context.Background(), &pb.SearchRequest{
+		Pattern:     "session:*",
+		Consistency: pb.ReadConsistency_READ_STALE,
+		MinIndex:    del.CommitIndex + 1000,
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+type batchSetTestStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	items []*pb.BatchSetRequest
+	resp  *pb.BatchSetResponse
+}
+
+func (s *batchSetTestStream) Context() context.Context { return s.ctx }
+
+func (s *batchSetTestStream) Recv() (*pb.BatchSetRequest, error) {
+	if len(s.items) == 0 {
+		return nil, io.EOF
+	}
+	req := s.items[0]
+	s.items = s.items[1:]
+	return req, nil
+}
+
+func (s *batchSetTestStream) SendAndClose(resp *pb.BatchSetResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestWritesHonourRequestContext(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.InfoLevel)
+	logger := log.NewLogger(plugin)
+
+	addr := freeAddr(t)
+	srv := New(cache.New(time.Minute, logger), "n1")
+	node, err := raft.NewNode("n1", addr, []string{"http://" + addr}, raft.NewStorage(filepath.Join(t.TempDir(), "n1.wal")),
+		srv, 50*time.Millisecond, 120*time.Millisecond, true, 1024, logger, "")
+	require.NoError(t, err)
+	defer node.Close()
+	srv.UseRaft(node)
+	require.Eventually(t, func() bool { return node.Role() == raft.Leader }, 3*time.Second, 20*time.Millisecond)
+
+	val, err := utils.ConvertToAnyPB("v")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = srv.Set(ctx, &pb.SetRequest{Key: "k", Value: val})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	_, found := srv.fsm.Cache.Get("k")
+	assert.False(t, found)
+
+	stream := &batchSetTestStream{ctx: ctx, items: []*pb.BatchSetRequest{{Key: "a", Value: val}, {Key: "b", Value: val}}}
+	require.NoError(t, srv.BatchSet(stream))
+	assert.EqualValues(t, 2, stream.resp.ErrorCount)
+	assert.Contains(t, stream.resp.FirstError, "context canceled")
+
+	stream = &batchSetTestStream{ctx: context.Background(), items: []*pb.BatchSetRequest{{Key: "a", Value: val}, {Key: "b", Value: val}}}
+	require.NoError(t, srv.BatchSet(stream))
+	assert.EqualValues(t, 2, stream.resp.SuccessCount)
+	assert.NotZero(t, stream.resp.CommitIndex)
+}
+
+type backupTestStream struct {
+	grpc.ServerStream
+	chunks []*pb.BackupChunk
+}
+
+func (s *backupTestStream) Context() context.Context { return context.Background() }
+
+func (s *backupTestStream) Send(c *pb.BackupChunk) error {
+	c.Data = append([]byte(nil), c.Data...)
+	s.chunks = append(s.chunks, c)
+	return nil
+}
+
+func TestBackupRestoresIntoNewCluster(t *testing.T) {
+	plugin := log.NewStdoutPlugin(zapcore.InfoLevel)
+	logger := log.NewLogger(plugin)
+
+	t.Run("RequiresDistributedMode", func(t *testing.T) {
+		srv := New(cache.New(time.Minute, logger), "single")
+		err := srv.Backup(&pb.BackupRequest{}, &backupTestStream{})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	addr := freeAddr(t)
+	srv := New(cache.New(time.Minute, logger), "n1")
+	node, err := raft.NewNode("n1", addr, []string{"http://" + addr},
+		raft.NewStorage(filepath.Join(t.TempDir(), "n1.wal")),
+		srv, 50*time.Millisecond, 120*time.Millisecond, true, 1024, logger, "")
+	require.NoError(t, err)
+	defer node.Close()
+	srv.UseRaft(node)
+	require.Eventually(t, func() bool { return node.Role() == raft.Leader }, 3*time.Second, 20*time.Millisecond)
+
+	// No snapshot has been taken yet.
+	err = srv.Backup(&pb.BackupRequest{FromSnapshot: true}, &backupTestStream{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	val, err := utils.ConvertToAnyPB("v")
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		_, err = srv.Set(context.Background(), &pb.SetRequest{Key: fmt.Sprintf("k%d", i), Value: val})
+		require.NoError(t, err)
+	}
+
+	stream := &backupTestStream{}
+	require.NoError(t, srv.Backup(&pb.BackupRequest{}, stream))
+	require.NotEmpty(t, stream.chunks)
+	first := stream.chunks[0]
+	assert.GreaterOrEqual(t, first.Index, uint64(100))
+	assert.NotZero(t, first.Term)
+	var data []byte
+	for _, c := range stream.chunks {
+		data = append(data, c.Data...)
+	}
+	assert.EqualValues(t, first.Size, len(data))
+	backupPath := filepath.Join(t.TempDir(), "cluster.backup")
+	require.NoError(t, os.WriteFile(backupPath, data, 0o644))
+
+	// Bootstrap a brand-new node from the backup.
+	st := raft.NewStorage(filepath.Join(t.TempDir(), "m1.wal"))
+	meta, err := st.RestoreBackup(backupPath, func(r io.Reader) error {
+		_, err := cache.New(time.Minute, logger).LoadFrom(r, "m1")
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, first.Index, meta.LastIncludedIndex)
+	assert.Equal(t, "n1", meta.NodeID)
+
+	addr2 := freeAddr(t)
+	restored := New(cache.New(time.Minute, logger), "m1")
+	node2, err := raft.NewNode("m1", addr2, []string{"http://" + addr2}, st,
+		restored, 50*time.Millisecond, 120*time.Millisecond, true, 1024, logger, "")
+	require.NoError(t, err)
+	defer node2.Close()
+	restored.UseRaft(node2)
+	keys, err := restored.fsm.Cache.Search("k*", false)
+	require.NoError(t, err)
+	assert.Len(t, keys, 100)
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
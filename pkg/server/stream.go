@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/lushenle/simple-cache/pkg/cache"
+	"github.com/lushenle/simple-cache/pkg/command"
+	"github.com/lushenle/simple-cache/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// streamPollInterval is how often a blocking stream read re-checks for new
+// entries.
+const streamPollInterval = 50 * time.Millisecond
+
+func (s *CacheService) XAdd(ctx context.Context, req *pb.XAddRequest) (*pb.XAddResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.XAddCommand{
+		Key:    req.GetKey(),
+		Fields: req.GetFields(),
+		MaxLen: req.GetMaxLen(),
+		MaxAge: req.GetMaxAge(),
+		Now:    time.Now().UnixNano(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.XAddResponse), nil
+}
+
+func (s *CacheService) XTrim(ctx context.Context, req *pb.XTrimRequest) (*pb.XTrimResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.XTrimCommand{
+		Key:    req.GetKey(),
+		MaxLen: req.GetMaxLen(),
+		MaxAge: req.GetMaxAge(),
+		Now:    time.Now().UnixNano(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.XTrimResponse), nil
+}
+
+func (s *CacheService) XGroupCreate(ctx context.Context, req *pb.XGroupCreateRequest) (*pb.XGroupCreateResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.XGroupCreateCommand{
+		Key:   req.GetKey(),
+		Group: req.GetGroup(),
+		Start: req.GetStart(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.XGroupCreateResponse), nil
+}
+
+func (s *CacheService) XCommit(ctx context.Context, req *pb.XCommitRequest) (*pb.XCommitResponse, error) {
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	resp, err := s.propose(ctx, &command.XCommitCommand{
+		Key:   req.GetKey(),
+		Group: req.GetGroup(),
+		ID:    req.GetId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.XCommitResponse), nil
+}
+
+func (s *CacheService) XRange(ctx context.Context, req *pb.XRangeRequest) (*pb.XRangeResponse, error) {
+	if err := s.checkLeaderRead(ctx); err != nil {
+		return nil, err
+	}
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	start := cache.StreamID{}
+	if req.GetStart() != "" && req.GetStart() != "-" {
+		id, err := cache.ParseStreamID(req.GetStart())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		start = id
+	}
+	end := cache.StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}
+	if req.GetEnd() != "" && req.GetEnd() != "+" {
+		id, err := cache.ParseStreamID(req.GetEnd())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		end = id
+	}
+
+	entries, err := s.fsm.Cache.StreamRange(req.GetKey(), start, end, int(req.GetCount()))
+	if err != nil {
+		return nil, valueStatusError(err)
+	}
+	return &pb.XRangeResponse{Entries: toPBStreamEntries(entries)}, nil
+}
+
+func (s *CacheService) XRead(ctx context.Context, req *pb.XReadRequest) (*pb.XReadResponse, error) {
+	if err := s.checkLeaderRead(ctx); err != nil {
+		return nil, err
+	}
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	var after cache.StreamID
+	switch req.GetAfter() {
+	case "", "0", "-":
+	case "$":
+		last, err := s.fsm.Cache.StreamLastID(req.GetKey())
+		if err != nil {
+			return nil, valueStatusError(err)
+		}
+		after = last
+	default:
+		id, err := cache.ParseStreamID(req.GetAfter())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		after = id
+	}
+
+	entries, err := s.pollStream(ctx, req.GetBlock(), func() ([]cache.StreamEntry, error) {
+		return s.fsm.Cache.StreamRead(req.GetKey(), after, int(req.GetCount()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.XReadResponse{Entries: toPBStreamEntries(entries)}, nil
+}
+
+func (s *CacheService) XReadGroup(ctx context.Context, req *pb.XReadGroupRequest) (*pb.XReadGroupResponse, error) {
+	if err := s.checkLeaderRead(ctx); err != nil {
+		return nil, err
+	}
+	if !s.rl.Allow(clientPeerAddr(ctx)) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	entries, err := s.pollStream(ctx, req.GetBlock(), func() ([]cache.StreamEntry, error) {
+		return s.fsm.Cache.StreamReadGroup(req.GetKey(), req.GetGroup(), int(req.GetCount()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.XReadGroupResponse{Entries: toPBStreamEntries(entries)}, nil
+}
+
+// pollStream calls read until it returns entries or the block duration
+// elapses. An empty block reads once.
+func (s *CacheService) pollStream(ctx context.Context, block string, read func() ([]cache.StreamEntry, error)) ([]cache.StreamEntry, error) {
+	var wait time.Duration
+	if block != "" {
+		d, err := time.ParseDuration(block)
+		if err != nil || d < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid block %q", block)
+		}
+		wait = d
+	}
+	deadline := time.Now().Add(wait)
+
+	for {
+		entries, err := read()
+		if err != nil {
+			return nil, valueStatusError(err)
+		}
+		remaining := time.Until(deadline)
+		if len(entries) > 0 || remaining <= 0 {
+			return entries, nil
+		}
+		timer := time.NewTimer(min(remaining, streamPollInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func toPBStreamEntries(entries []cache.StreamEntry) []*pb.StreamEntry {
+	out := make([]*pb.StreamEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, &pb.StreamEntry{
+			Id:     e.ID.String(),
+			Fields: e.Fields,
+		})
+	}
+	return out
+}